@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/internal/runner"
 )
@@ -9,6 +11,69 @@ func main() {
 	// Parse the command line flags and read config files
 	options := runner.ParseOptions()
 
+	if options.Service != "" {
+		if err := runner.RunService(options); err != nil {
+			gologger.Fatalf("Could not run the %s service command: %s\n", options.Service, err)
+		}
+		return
+	}
+
+	if options.SelfTest {
+		if err := runner.SelfTest(options); err != nil {
+			gologger.Fatalf("Self-test failed: %s\n", err)
+		}
+		return
+	}
+
+	if options.SignKey != "" {
+		if err := runner.SignTemplates(options); err != nil {
+			gologger.Fatalf("Could not sign templates: %s\n", err)
+		}
+		return
+	}
+
+	if options.MigrateTemplates {
+		if err := runner.MigrateTemplates(options); err != nil {
+			gologger.Fatalf("Could not migrate templates: %s\n", err)
+		}
+		return
+	}
+
+	if options.Validate {
+		if err := runner.ValidateTemplates(options); err != nil {
+			gologger.Fatalf("Template validation failed: %s\n", err)
+		}
+		return
+	}
+
+	if options.Replay != "" {
+		if err := runner.Replay(options); err != nil {
+			gologger.Fatalf("Could not replay findings: %s\n", err)
+		}
+		return
+	}
+
+	if options.Canaries != "" {
+		if err := runner.CheckCanaries(options); err != nil {
+			gologger.Fatalf("Canary check failed: %s\n", err)
+		}
+		return
+	}
+
+	if options.DebugTemplate != "" {
+		if err := runner.DebugTemplate(options); err != nil {
+			gologger.Fatalf("Could not debug template: %s\n", err)
+		}
+		return
+	}
+
+	if options.Queue != "" {
+		if err := runner.RunQueue(options); err != nil {
+			gologger.Fatalf("Queue worker failed: %s\n", err)
+		}
+		return
+	}
+
 	nucleiRunner, err := runner.New(options)
 	if err != nil {
 		gologger.Fatalf("Could not create runner: %s\n", err)
@@ -16,4 +81,8 @@ func main() {
 
 	nucleiRunner.RunEnumeration()
 	nucleiRunner.Close()
+
+	if nucleiRunner.Failed() {
+		os.Exit(1)
+	}
 }
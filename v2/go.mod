@@ -4,6 +4,7 @@ go 1.14
 
 require (
 	github.com/Knetic/govaluate v3.0.0+incompatible
+	github.com/andybalholm/brotli v1.0.1
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/coocood/freecache v1.1.1 // indirect
 	github.com/d5/tengo/v2 v2.6.2
@@ -22,6 +23,7 @@ require (
 	github.com/projectdiscovery/rawhttp v0.0.4
 	github.com/projectdiscovery/retryabledns v1.0.4
 	github.com/projectdiscovery/retryablehttp-go v1.0.1
+	github.com/refraction-networking/utls v1.0.0
 	github.com/remeh/sizedwaitgroup v1.0.0
 	github.com/spaolacci/murmur3 v1.1.0
 	github.com/stretchr/testify v1.5.1
@@ -30,6 +32,6 @@ require (
 	go.uber.org/ratelimit v0.1.0
 	golang.org/x/crypto v0.0.0-20201016220609-9e8e0b390897 // indirect
 	golang.org/x/net v0.0.0-20201022231255-08b38378de70
-	golang.org/x/sys v0.0.0-20201022201747-fb209a7c41cd // indirect
+	golang.org/x/sys v0.0.0-20201022201747-fb209a7c41cd
 	gopkg.in/yaml.v2 v2.3.0
 )
@@ -6,66 +6,157 @@ import (
 	"sync"
 )
 
-// Writer is a mutex protected buffered writer
+const (
+	// queueSize is the maximum number of writes buffered in memory
+	queueSize = 1024
+	// highWatermark is the number of pending writes above which producers are held back
+	highWatermark = queueSize * 3 / 4
+)
+
+// Writer is an asynchronous buffered writer with a bounded queue.
+//
+// Writes are queued and flushed to the file by a background goroutine,
+// a full queue blocks the writers so that findings are never dropped.
 type Writer struct {
 	file   *os.File
 	writer *bufio.Writer
-	mutex  *sync.Mutex
+	queue  chan []byte
+	done   chan struct{}
+
+	mutex   *sync.Mutex
+	ready   *sync.Cond
+	pending int
+	closed  bool
+	err     error
 }
 
-// New creates a new mutex protected buffered writer for a file
+// New creates a new asynchronous buffered writer for a file
 func New(file string) (*Writer, error) {
 	output, err := os.Create(file)
 	if err != nil {
 		return nil, err
 	}
-	return &Writer{file: output, writer: bufio.NewWriter(output), mutex: &sync.Mutex{}}, nil
+
+	mutex := &sync.Mutex{}
+	w := &Writer{
+		file:   output,
+		writer: bufio.NewWriter(output),
+		queue:  make(chan []byte, queueSize),
+		done:   make(chan struct{}),
+		mutex:  mutex,
+		ready:  sync.NewCond(mutex),
+	}
+	go w.run()
+
+	return w, nil
 }
 
-// Write writes a byte slice to the underlying file
+// run writes the queued data to the file until the writer is closed
+func (w *Writer) run() {
+	defer close(w.done)
+
+	for data := range w.queue {
+		_, err := w.writer.Write(data)
+		if err == nil && data[len(data)-1] != '\n' {
+			_, err = w.writer.WriteRune('\n')
+		}
+
+		// flush when idle so findings reach the disk without waiting for close
+		if err == nil && len(w.queue) == 0 {
+			err = w.writer.Flush()
+		}
+
+		w.mutex.Lock()
+		if err != nil && w.err == nil {
+			w.err = err
+		}
+		w.pending--
+		w.ready.Broadcast()
+		w.mutex.Unlock()
+	}
+}
+
+// Write queues a byte slice to be written to the underlying file
 //
 // It also writes a newline if the last byte isn't a newline character.
+// The error of a previous failed write is returned if any.
 func (w *Writer) Write(data []byte) error {
 	if len(data) == 0 {
 		return nil
 	}
-	w.mutex.Lock()
-	defer w.mutex.Unlock()
 
-	_, err := w.writer.Write(data)
-	if err != nil {
-		return err
-	}
-	if data[len(data)-1] != '\n' {
-		_, err = w.writer.WriteRune('\n')
-	}
-	return err
+	queued := make([]byte, len(data))
+	copy(queued, data)
+
+	return w.enqueue(queued)
 }
 
-// WriteString writes a string to the underlying file
+// WriteString queues a string to be written to the underlying file
 //
 // It also writes a newline if the last byte isn't a newline character.
+// The error of a previous failed write is returned if any.
 func (w *Writer) WriteString(data string) error {
 	if data == "" {
 		return nil
 	}
+
+	return w.enqueue([]byte(data))
+}
+
+// enqueue adds data to the write queue, blocking while it is full
+func (w *Writer) enqueue(data []byte) error {
 	w.mutex.Lock()
-	defer w.mutex.Unlock()
+	for w.pending >= queueSize && !w.closed {
+		w.ready.Wait()
+	}
 
-	_, err := w.writer.WriteString(data)
-	if err != nil {
+	if w.closed {
+		w.mutex.Unlock()
+		return os.ErrClosed
+	}
+
+	if w.err != nil {
+		err := w.err
+		w.mutex.Unlock()
 		return err
 	}
-	if data[len(data)-1] != '\n' {
-		_, err = w.writer.WriteRune('\n')
+
+	// the send can't block as the queue holds at most the pending writes
+	w.pending++
+	w.queue <- data
+	w.mutex.Unlock()
+
+	return nil
+}
+
+// WaitReady blocks while the number of pending writes is above the high
+// watermark, allowing producers to slow down when the file is slow.
+func (w *Writer) WaitReady() {
+	if w == nil {
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for w.pending >= highWatermark && !w.closed {
+		w.ready.Wait()
 	}
-	return err
 }
 
-// Close closes the underlying writer flushing everything to disk
+// Close waits for the queued writes and closes the underlying file
 func (w *Writer) Close() error {
 	w.mutex.Lock()
-	defer w.mutex.Unlock()
+	if w.closed {
+		w.mutex.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.ready.Broadcast()
+	w.mutex.Unlock()
+
+	close(w.queue)
+	<-w.done
 
 	w.writer.Flush()
 	//nolint:errcheck // we don't care whether sync failed or succeeded.
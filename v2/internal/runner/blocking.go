@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/severity"
+)
+
+// blockedHosts keeps track of the hosts which got a finding at or above
+// the blocking severity, so the remaining templates skip them.
+type blockedHosts struct {
+	sync.RWMutex
+	threshold string
+	hosts     map[string]struct{}
+}
+
+// newBlockedHosts creates a new blocked hosts tracker for a severity threshold
+func newBlockedHosts(threshold string) *blockedHosts {
+	return &blockedHosts{threshold: threshold, hosts: make(map[string]struct{})}
+}
+
+// isBlocked returns true if the target host already had a blocking finding
+func (b *blockedHosts) isBlocked(target string) bool {
+	if b == nil {
+		return false
+	}
+
+	b.RLock()
+	defer b.RUnlock()
+
+	_, ok := b.hosts[targetHostname(target)]
+
+	return ok
+}
+
+// report marks the target host as blocked if the finding severity is high enough
+func (b *blockedHosts) report(target, templateID, templateSeverity string) {
+	if b == nil || !severity.AtLeast(templateSeverity, b.threshold) {
+		return
+	}
+
+	host := targetHostname(target)
+
+	b.Lock()
+	defer b.Unlock()
+
+	if _, ok := b.hosts[host]; ok {
+		return
+	}
+	b.hosts[host] = struct{}{}
+
+	gologger.Infof("Stopping scan of %s after %s finding from %s\n", host, templateSeverity, templateID)
+}
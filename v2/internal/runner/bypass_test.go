@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const bypassTemplate = `id: admin-panel
+info:
+  name: Admin panel
+  author: nuclei
+  severity: info
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}/admin"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+func TestBypassForbiddenBaseline(t *testing.T) {
+	// the admin panel is only reachable through the rewrite header, every other path is the home page
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("X-Original-URL") == "/admin":
+			fmt.Fprint(w, "<html><body><h1>Administration</h1><form>users settings logs</form></body></html>")
+		case r.URL.Path == "/admin":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			fmt.Fprint(w, "<html><body><h1>Welcome to the home page</h1></body></html>")
+		}
+	}))
+	defer server.Close()
+
+	directory := writeWorkflowFiles(t, map[string]string{"admin.yaml": bypassTemplate})
+	defer os.RemoveAll(directory)
+
+	output := filepath.Join(directory, "output.txt")
+	options := &Options{
+		Target:          server.URL,
+		Templates:       []string{filepath.Join(directory, "admin.yaml")},
+		Output:          output,
+		BypassForbidden: true,
+		NoInteractsh:    true,
+		Timeout:         5,
+		Threads:         1,
+		BulkSize:        1,
+		TemplateThreads: 1,
+		RateLimit:       150,
+	}
+
+	nucleiRunner, err := New(options)
+	require.Nil(t, err, "could not create runner")
+	nucleiRunner.RunEnumeration()
+	nucleiRunner.Close()
+
+	data, err := ioutil.ReadFile(output)
+	require.Nil(t, err, "could not read the findings")
+
+	findings := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, findings, 1, "the bypasses answered with the home page were reported: %s", data)
+	require.Contains(t, findings[0], "x-original-url", "the rewrite header bypass was not reported")
+}
@@ -0,0 +1,245 @@
+package runner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/logrusorgru/aurora"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"gopkg.in/yaml.v2"
+)
+
+// canaryConfig contains the canary targets the templates are checked on
+type canaryConfig struct {
+	Canaries []*canaryTarget `yaml:"canaries"`
+}
+
+// canaryTarget is a target whose findings are known in advance. The
+// known-good targets list no templates or tags, nothing may match them.
+type canaryTarget struct {
+	// Target is the URL or host of the canary
+	Target string `yaml:"target"`
+	// Templates contains the ids of the templates expected to match the target
+	Templates []string `yaml:"templates,omitempty"`
+	// Tags contains the tags of the templates expected to match the target
+	Tags []string `yaml:"tags,omitempty"`
+}
+
+// readCanaries reads the canary targets from a yaml file.
+func readCanaries(file string) (*canaryConfig, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config := &canaryConfig{}
+	if err := yaml.NewDecoder(f).Decode(config); err != nil {
+		return nil, err
+	}
+
+	if len(config.Canaries) == 0 {
+		return nil, errors.New("no canary targets configured")
+	}
+	for _, canary := range config.Canaries {
+		if strings.TrimSpace(canary.Target) == "" {
+			return nil, errors.New("canary without target")
+		}
+		canary.Target = normalizeInput(canary.Target)
+	}
+
+	return config, nil
+}
+
+// expects returns true if a template with the provided id and info must match the canary
+func (c *canaryTarget) expects(id string, info map[string]string) bool {
+	for _, expected := range c.Templates {
+		if expected == id {
+			return true
+		}
+	}
+
+	tags := templateTags(info)
+	for _, tag := range c.Tags {
+		if _, ok := tags[strings.ToLower(tag)]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// owns returns true if a matched URL or host of a finding belongs to the canary
+func (c *canaryTarget) owns(matched string) bool {
+	return matched == c.Target || findingTarget(matched) == findingTarget(c.Target)
+}
+
+// canaryTemplate is a template checked on the canaries
+type canaryTemplate struct {
+	id   string
+	info map[string]string
+}
+
+// CheckCanaries runs the templates selected with -t on the canary targets
+// and reports the matches on targets they aren't expected to match and the
+// expected matches which are missing. It fails if any check does, so that
+// new or changed templates can be held back before the production scans.
+func CheckCanaries(options *Options) error {
+	config, err := readCanaries(options.Canaries)
+	if err != nil {
+		return err
+	}
+
+	r := &Runner{options: options}
+	if templatesConfig, err := readConfiguration(); err == nil {
+		r.templatesConfig = templatesConfig
+	}
+
+	var checked []canaryTemplate
+	for _, file := range r.getTemplatesFor(options.Templates) {
+		id, info, workflow, err := templates.ReadInfo(file)
+		if err != nil || workflow {
+			continue
+		}
+		checked = append(checked, canaryTemplate{id: id, info: info})
+	}
+	if len(checked) == 0 {
+		return errors.New("no templates to check on the canaries")
+	}
+
+	directory, err := ioutil.TempDir("", "nuclei-canaries-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(directory)
+
+	targets := make([]string, 0, len(config.Canaries))
+	for _, canary := range config.Canaries {
+		targets = append(targets, canary.Target)
+	}
+
+	targetsFile := filepath.Join(directory, "targets.txt")
+	if err := ioutil.WriteFile(targetsFile, []byte(strings.Join(targets, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+	output := filepath.Join(directory, "output.json")
+
+	// the scan keeps the settings of the user, without the outputs and the
+	// limits which would hide or publish the findings of the canaries
+	canaryOptions := *options
+	canaryOptions.Canaries = ""
+	canaryOptions.Target, canaryOptions.Stdin, canaryOptions.Targets = "", false, targetsFile
+	canaryOptions.Output, canaryOptions.JSON, canaryOptions.OutputDir = output, true, ""
+	canaryOptions.OutputWriters, canaryOptions.ReportConfig, canaryOptions.InventoryOutput = "", "", ""
+	canaryOptions.SummaryJSON, canaryOptions.LoadReport, canaryOptions.Screenshots = "", "", ""
+	canaryOptions.Resume, canaryOptions.Verify, canaryOptions.TargetRoutes = "", "", ""
+	canaryOptions.MaxFindings, canaryOptions.MaxFindingsPerHost, canaryOptions.StopAtSeverity = 0, 0, ""
+	canaryOptions.Exposures, canaryOptions.WhatIf, canaryOptions.UpdateTemplates = false, false, false
+
+	gologger.Infof("Checking %d templates on %d canary targets\n", len(checked), len(targets))
+
+	nucleiRunner, err := New(&canaryOptions)
+	if err != nil {
+		return err
+	}
+	nucleiRunner.RunEnumeration()
+	nucleiRunner.Close()
+
+	matches, err := readCanaryFindings(output, config)
+	if err != nil {
+		return err
+	}
+
+	au := aurora.NewAurora(!options.NoColor)
+	unexpected, missed := 0, 0
+
+	for _, template := range checked {
+		for _, canary := range config.Canaries {
+			expected := canary.expects(template.id, template.info)
+			matched := matches[template.id][canary.Target]
+
+			switch {
+			case matched != nil && !expected:
+				unexpected++
+				gologger.Warningf("[%s] %s matched %s, expected no match (%s)\n", au.Red("unexpected").String(), template.id, canary.Target, strings.Join(matched, ", "))
+			case matched == nil && expected:
+				missed++
+				gologger.Warningf("[%s] %s did not match %s\n", au.Red("missed").String(), template.id, canary.Target)
+			case expected:
+				gologger.Verbosef("%s matched %s\n", "canary", template.id, canary.Target)
+			}
+		}
+	}
+
+	if unexpected > 0 || missed > 0 {
+		return fmt.Errorf("%d unexpected matches and %d missed matches on the canaries", unexpected, missed)
+	}
+
+	gologger.Infof("All the templates matched the canaries as expected\n")
+
+	return nil
+}
+
+// canaryFinding contains the fields of a finding checked on the canaries
+type canaryFinding struct {
+	Template string `json:"template"`
+	Matched  string `json:"matched"`
+}
+
+// readCanaryFindings reads the matched URLs of the canary scan by template and canary target
+func readCanaryFindings(output string, config *canaryConfig) (map[string]map[string][]string, error) {
+	matches := make(map[string]map[string][]string)
+
+	file, err := os.Open(output)
+	if os.IsNotExist(err) {
+		return matches, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxFindingSize)
+
+	for scanner.Scan() {
+		finding := &canaryFinding{}
+		if err := jsoniter.Unmarshal(scanner.Bytes(), finding); err != nil || finding.Template == "" {
+			continue
+		}
+
+		for _, canary := range config.Canaries {
+			if !canary.owns(finding.Matched) {
+				continue
+			}
+			if _, ok := matches[finding.Template]; !ok {
+				matches[finding.Template] = make(map[string][]string)
+			}
+			matches[finding.Template][canary.Target] = appendUnique(matches[finding.Template][canary.Target], finding.Matched)
+		}
+	}
+
+	return matches, scanner.Err()
+}
+
+// appendUnique adds a matched URL to the sorted list of a canary if it's not in it
+func appendUnique(matched []string, value string) []string {
+	i := sort.SearchStrings(matched, value)
+	if i < len(matched) && matched[i] == value {
+		return matched
+	}
+
+	matched = append(matched, "")
+	copy(matched[i+1:], matched[i:])
+	matched[i] = value
+
+	return matched
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/crawler"
+	"github.com/projectdiscovery/nuclei/v2/pkg/globalratelimiter"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
+	"github.com/remeh/sizedwaitgroup"
+)
+
+// newCrawlClient returns the http client used to crawl and seed the targets
+func (r *Runner) newCrawlClient() *http.Client {
+	// the global settings were validated with the options
+	tlsConfig, _ := tlsconfig.New(r.options.tlsOptions())
+
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	if r.options.ProxyURL != "" {
+		if proxyURL, err := url.Parse(r.options.ProxyURL); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	return &http.Client{Transport: transport, Timeout: time.Duration(r.options.Timeout) * time.Second}
+}
+
+// crawlInput spiders the http targets and adds the discovered endpoints
+// to the input, so templates are run on every page and not only the root.
+//
+// GET forms are added with placeholder values for their fields, the
+// other forms can't be expressed as targets and are only counted.
+func (r *Runner) crawlInput(usedInput map[string]struct{}) {
+	crawl := crawler.New(r.newCrawlClient(), r.seeds, r.options.CrawlDepth, r.options.CrawlMaxPages)
+
+	var (
+		mutex      sync.Mutex
+		discovered []string
+		forms      int
+	)
+
+	swg := sizedwaitgroup.New(r.options.BulkSize)
+	for _, target := range strings.Split(strings.TrimSpace(r.input), "\n") {
+		if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+			continue
+		}
+
+		swg.Add()
+		go func(target string) {
+			defer swg.Done()
+
+			result, err := crawl.Crawl(target)
+			if err != nil {
+				gologger.Warningf("Could not crawl %s: %s\n", target, err)
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			discovered = append(discovered, result.URLs...)
+			for i := range result.Forms {
+				if submitURL := result.Forms[i].SubmitURL(); submitURL != "" {
+					discovered = append(discovered, submitURL)
+				} else {
+					forms++
+				}
+			}
+		}(target)
+	}
+	swg.Wait()
+
+	added := 0
+	builder := &strings.Builder{}
+	builder.WriteString(r.input)
+
+	for _, URL := range discovered {
+		if _, ok := usedInput[URL]; ok {
+			continue
+		}
+		usedInput[URL] = struct{}{}
+		globalratelimiter.Add(URL, r.options.RateLimit)
+
+		builder.WriteString(URL)
+		builder.WriteString("\n")
+		added++
+	}
+
+	r.input = builder.String()
+	r.inputCount += int64(added)
+
+	gologger.Infof("Crawling discovered %d new endpoints (%d non-GET forms skipped)", added, forms)
+}
@@ -0,0 +1,308 @@
+package runner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/projectdiscovery/httpx/common/cache"
+	"github.com/projectdiscovery/nuclei/v2/internal/tracelog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+)
+
+// debugHelp lists the commands of the -debug-template prompt
+const debugHelp = `Commands:
+  [enter], n  send the request, or go to the next one once sent
+  r           send the request again, with the current variables
+  e           edit the path or raw request of the step ($EDITOR, or inline until a line with a single .)
+  v           show the variables and the payload values of the step
+  s           skip the request
+  q           quit
+`
+
+// DebugTemplate steps through the http requests of a template on a target,
+// showing each rendered request, its response, the variables and the result
+// of every matcher, and waiting for a command between the steps.
+func DebugTemplate(options *Options) error {
+	r := &Runner{options: options}
+	r.colorizer = *colorizer.NewNucleiColorizer(aurora.NewAurora(!options.NoColor))
+
+	keys, err := readTrustedKeys(options.TrustedKeys)
+	if err != nil {
+		return err
+	}
+	r.trustedKeys = keys
+
+	template, err := r.parseTemplate(options.DebugTemplate)
+	if err != nil {
+		return err
+	}
+	if len(template.BulkRequestsHTTP) == 0 {
+		return errors.New("only the http requests of a template can be debugged")
+	}
+
+	dialer, err := newDialer(options, cache.DefaultOptions)
+	if err != nil {
+		return err
+	}
+
+	session := &debugSession{
+		colorizer: r.colorizer.Colorizer,
+		input:     bufio.NewReader(os.Stdin),
+		output:    os.Stderr,
+	}
+	target := normalizeInput(options.Target)
+	values := executer.NewSharedValues()
+	jar := templateCookieJar(nil, template)
+
+	for i, request := range template.BulkRequestsHTTP {
+		debugger, err := executer.NewDebugger(&executer.HTTPOptions{
+			CommonOptions: executer.CommonOptions{
+				Template:  template,
+				Colorizer: r.colorizer,
+				TraceLog:  &tracelog.NoopLogger{},
+			},
+			BulkHTTPRequest: request,
+			Timeout:         options.Timeout,
+			Retries:         options.Retries,
+			ProxyURL:        options.ProxyURL,
+			ProxySocksURL:   options.ProxySocksURL,
+			Proxy:           template.Proxy,
+			CustomHeaders:   options.CustomHeaders,
+			CookieReuse:     request.CookieReuse,
+			CookieJar:       jar,
+			Dialer:          &dialer,
+			HTTP2:           options.HTTP2,
+			TLS:             options.tlsOptions(),
+			Values:          values,
+		}, target)
+		if err != nil {
+			return err
+		}
+
+		for debugger.Next() {
+			fmt.Fprintf(session.output, "\n%s\n", session.colorizer.Bold(fmt.Sprintf("[%s] request %d, step %d/%d", template.ID, i+1, debugger.Position(), request.GetRequestCount())))
+			if !session.step(debugger) {
+				return nil
+			}
+		}
+	}
+	fmt.Fprintf(session.output, "\nAll the requests of %s were sent\n", template.ID)
+
+	return nil
+}
+
+// debugSession reads the commands of the user and prints the steps
+type debugSession struct {
+	colorizer aurora.Aurora
+	input     *bufio.Reader
+	output    io.Writer
+}
+
+// step runs the commands on the current step of a debugger until the user
+// moves to the next one. It returns false if the user quits.
+func (s *debugSession) step(debugger *executer.Debugger) bool {
+	s.render(debugger)
+
+	sent := false
+	for {
+		prompt := "[enter] send, (e)dit, (v)ariables, (s)kip, (q)uit, (h)elp > "
+		if sent {
+			prompt = "[enter] next, (r)e-run, (e)dit, (v)ariables, (q)uit, (h)elp > "
+		}
+		fmt.Fprint(s.output, prompt)
+
+		line, err := s.input.ReadString('\n')
+		if err != nil && line == "" {
+			return false
+		}
+
+		switch strings.TrimSpace(line) {
+		case "", "n":
+			if sent {
+				return true
+			}
+			sent = s.send(debugger)
+		case "r":
+			if s.render(debugger) {
+				sent = s.send(debugger)
+			}
+		case "e":
+			data, err := s.edit(debugger.Data())
+			if err != nil {
+				fmt.Fprintf(s.output, "Could not edit the request: %s\n", err)
+				continue
+			}
+			debugger.SetData(data)
+			s.render(debugger)
+			sent = false
+		case "v":
+			s.variables(debugger)
+		case "s":
+			return true
+		case "q":
+			return false
+		case "h", "?":
+			fmt.Fprint(s.output, debugHelp)
+		default:
+			fmt.Fprintf(s.output, "Unknown command %s, h for help\n", strings.TrimSpace(line))
+		}
+	}
+}
+
+// render builds the request of the step and prints it
+func (s *debugSession) render(debugger *executer.Debugger) bool {
+	dump, err := debugger.Render()
+	if err != nil {
+		fmt.Fprintf(s.output, "Could not build the request: %s\n", err)
+		return false
+	}
+	fmt.Fprintf(s.output, "%s\n%s\n", s.colorizer.Cyan("Request:"), dump)
+
+	return true
+}
+
+// send sends the request of the step and prints the response with the
+// result of the matchers and extractors
+func (s *debugSession) send(debugger *executer.Debugger) bool {
+	step, err := debugger.Send()
+	if err != nil {
+		fmt.Fprintf(s.output, "%s\n", s.colorizer.Red(err.Error()))
+		return false
+	}
+
+	fmt.Fprintf(s.output, "%s (%s)\n%s\n\n", s.colorizer.Cyan("Response:"), step.Duration, step.Response)
+
+	fmt.Fprintf(s.output, "%s\n", s.colorizer.Cyan("Matchers:"))
+	for i, matcher := range step.Matchers {
+		name := matcher.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		fmt.Fprintf(s.output, "  %s %s (%s)\n", s.result(matcher.Matched), name, matcher.Type)
+	}
+	fmt.Fprintf(s.output, "  => %s\n", s.result(step.Matched))
+
+	if len(step.Extracted) > 0 {
+		fmt.Fprintf(s.output, "%s\n", s.colorizer.Cyan("Extracted:"))
+		for _, name := range sortedKeys(step.Extracted) {
+			fmt.Fprintf(s.output, "  %s: %s\n", name, strings.Join(step.Extracted[name], ", "))
+		}
+	}
+
+	return true
+}
+
+// result returns the colored result of a matcher
+func (s *debugSession) result(matched bool) string {
+	if matched {
+		return s.colorizer.Green("matched").String()
+	}
+
+	return s.colorizer.Red("not matched").String()
+}
+
+// variables prints the variables of the debugger and the payloads of the step
+func (s *debugSession) variables(debugger *executer.Debugger) {
+	fmt.Fprintf(s.output, "%s\n", s.colorizer.Cyan("Variables:"))
+	printValues(s.output, debugger.Variables)
+
+	if payloads := debugger.Payloads(); len(payloads) > 0 {
+		fmt.Fprintf(s.output, "%s\n", s.colorizer.Cyan("Payloads:"))
+		printValues(s.output, payloads)
+	}
+}
+
+// edit returns the data of a step changed in the editor of the user, or read
+// from the input until a line with a single dot if no editor is set
+func (s *debugSession) edit(data string) (string, error) {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		fmt.Fprintf(s.output, "Current:\n%s\nEnter the new path or raw request, ending with a line with a single .\n", data)
+
+		var lines []string
+		for {
+			line, err := s.input.ReadString('\n')
+			if err != nil {
+				return "", err
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if line == "." {
+				break
+			}
+			lines = append(lines, line)
+		}
+		switch len(lines) {
+		case 0:
+			return data, nil
+		case 1:
+			return lines[0], nil
+		}
+		// the raw requests end with a new line
+		return strings.Join(lines, "\n") + "\n", nil
+	}
+
+	file, err := ioutil.TempFile("", "nuclei-step-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(data); err != nil {
+		file.Close()
+		return "", err
+	}
+	file.Close()
+
+	// the editors can be set with their flags, eg. code --wait
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	edited, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+
+	// the editors add a new line to the paths
+	if !strings.Contains(strings.TrimSpace(string(edited)), "\n") {
+		return strings.TrimSpace(string(edited)), nil
+	}
+
+	return string(edited), nil
+}
+
+// printValues prints a map sorted by key
+func printValues(w io.Writer, values map[string]interface{}) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s = %v\n", key, values[key])
+	}
+}
+
+// sortedKeys returns the names of the extractors of a step in order
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
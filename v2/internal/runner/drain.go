@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// drainOnSignal stops scheduling new targets on SIGINT or SIGTERM, so that the
+// requests in flight complete and the outputs are flushed before exiting, as
+// expected when a pod is terminated. A second signal exits right away.
+func (r *Runner) drainOnSignal() (stop func()) {
+	signals := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case received := <-signals:
+			gologger.Warningf("Received %s, waiting for the requests in flight (send it again to exit now)\n", received)
+			r.drain.Set(true)
+		case <-done:
+			return
+		}
+
+		select {
+		case <-signals:
+			gologger.Fatalf("Exiting without waiting for the requests in flight\n")
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// draining returns true once the scan is stopping
+func (r *Runner) draining() bool {
+	return r.drain != nil && r.drain.Get()
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+)
+
+// templateErrors disables the templates failing with template errors
+// on too many consecutive targets for the rest of the scan.
+type templateErrors struct {
+	sync.RWMutex
+	limit int
+	// consecutive is the number of consecutive failed targets by template ID
+	consecutive map[string]int
+	// disabled contains the last error of the disabled templates
+	disabled map[string]error
+}
+
+// newTemplateErrors creates a new tracker disabling templates after limit consecutive errors
+func newTemplateErrors(limit int) *templateErrors {
+	return &templateErrors{limit: limit, consecutive: make(map[string]int), disabled: make(map[string]error)}
+}
+
+// isDisabled returns true if the template was disabled
+func (t *templateErrors) isDisabled(templateID string) bool {
+	if t == nil {
+		return false
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+
+	_, ok := t.disabled[templateID]
+
+	return ok
+}
+
+// report records the outcome of a template on a target, only the errors
+// caused by the template count towards the limit.
+func (t *templateErrors) report(templateID string, err error) {
+	if t == nil {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	if err == nil || !executer.IsTemplateError(err) {
+		t.consecutive[templateID] = 0
+		return
+	}
+
+	t.consecutive[templateID]++
+	if t.consecutive[templateID] < t.limit {
+		return
+	}
+
+	if _, ok := t.disabled[templateID]; !ok {
+		t.disabled[templateID] = err
+		gologger.Warningf("[%s] Disabling template after errors on %d consecutive targets: %s\n", templateID, t.limit, err)
+	}
+}
+
+// recoverTemplate recovers from a panic during the execution of a template
+// on a target, or on all of them if empty, recording it as a template error.
+// It must be deferred.
+func (r *Runner) recoverTemplate(templateID, target string) {
+	if recovered := recover(); recovered != nil {
+		r.reportPanic(templateID, target, recovered)
+	}
+}
+
+// reportPanic records a panic recovered during the execution of a template
+// on a target as a template error, failing the scan.
+func (r *Runner) reportPanic(templateID, target string, recovered interface{}) {
+	r.panicked.Set(true)
+
+	err := &executer.TemplateError{Err: fmt.Errorf("panic: %v", recovered)}
+
+	if target != "" {
+		gologger.Errorf("[%s] Recovered from panic on %s: %v\n", templateID, target, recovered)
+	} else {
+		gologger.Errorf("[%s] Recovered from panic: %v\n", templateID, recovered)
+	}
+	gologger.Debugf("%s\n", debug.Stack())
+
+	r.traceLog.Request(templateID, target, "panic", err)
+	r.templateErrors.report(templateID, err)
+}
+
+// Failed returns true if a template panicked during the scan
+func (r *Runner) Failed() bool {
+	return r.panicked.Get()
+}
+
+// printSummary warns about the templates disabled during the scan
+func (t *templateErrors) printSummary() {
+	if t == nil || len(t.disabled) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(t.disabled))
+	for id := range t.disabled {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	gologger.Warningf("%d templates were disabled after repeated errors:\n", len(ids))
+
+	for _, id := range ids {
+		gologger.Warningf("[%s] %s\n", id, t.disabled[id])
+	}
+}
@@ -0,0 +1,199 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/karrick/godirwalk"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+)
+
+// resolveWorkflowPath resolves a path referenced by a workflow variable,
+// relative paths are looked up in the usual places and then in the workflow folder.
+func (r *Runner) resolveWorkflowPath(workflowPath, value string) (string, error) {
+	if !isRelative(value) {
+		return value, nil
+	}
+
+	newPath, err := r.resolvePath(value)
+	if err != nil {
+		return resolvePathWithBaseFolder(filepath.Dir(workflowPath), value)
+	}
+
+	return newPath, nil
+}
+
+// validateWorkflows builds the dependency graph of the parsed workflows and
+// drops the workflows with missing references or dependency cycles.
+func (r *Runner) validateWorkflows(parsed []interface{}) (valid []interface{}, workflowCount int) {
+	graph := workflows.NewGraph()
+	visited := make(map[string]struct{})
+	errs := make(map[string][]error)
+
+	for _, t := range parsed {
+		if workflow, ok := t.(*workflows.Workflow); ok {
+			r.addWorkflowToGraph(graph, workflow, visited, errs)
+		}
+	}
+
+	for _, cycle := range graph.Cycles() {
+		labels := make([]string, 0, len(cycle))
+		for _, node := range cycle {
+			labels = append(labels, graph.Label(node))
+		}
+
+		err := fmt.Errorf("dependency cycle detected: %s", strings.Join(labels, " -> "))
+		for _, node := range cycle[:len(cycle)-1] {
+			errs[node] = append(errs[node], err)
+		}
+	}
+
+	for _, t := range parsed {
+		workflow, ok := t.(*workflows.Workflow)
+		if !ok {
+			valid = append(valid, t)
+			continue
+		}
+
+		if workflowErrs := errs[workflow.GetPath()]; len(workflowErrs) > 0 {
+			for _, err := range workflowErrs {
+				gologger.Errorf("Invalid workflow '%s': %s\n", workflow.ID, err)
+			}
+
+			continue
+		}
+
+		valid = append(valid, workflow)
+		workflowCount++
+	}
+
+	if r.options.Graph != "" {
+		if err := writeGraph(graph, r.options.Graph); err != nil {
+			gologger.Errorf("Could not write workflow graph to '%s': %s\n", r.options.Graph, err)
+		}
+	}
+
+	return valid, workflowCount
+}
+
+// addWorkflowToGraph adds a workflow and its references to the graph, recording
+// the reference errors by workflow path.
+func (r *Runner) addWorkflowToGraph(graph *workflows.Graph, workflow *workflows.Workflow, visited map[string]struct{}, errs map[string][]error) {
+	workflowPath := workflow.GetPath()
+	if _, ok := visited[workflowPath]; ok {
+		return
+	}
+	visited[workflowPath] = struct{}{}
+
+	graph.AddNode(workflowPath, workflow.ID)
+
+	names := make([]string, 0, len(workflow.Variables))
+	for name := range workflow.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		location := variableLocation(workflowPath, name)
+
+		files, err := r.workflowVariableFiles(workflowPath, workflow.Variables[name])
+		if err != nil {
+			errs[workflowPath] = append(errs[workflowPath], fmt.Errorf("%s: variable '%s': %s", location, name, err))
+			continue
+		}
+
+		for _, file := range files {
+			t, err := r.parseTemplateFile(file)
+			if err != nil {
+				errs[workflowPath] = append(errs[workflowPath], fmt.Errorf("%s: variable '%s': invalid reference '%s': %s", location, name, file, err))
+				continue
+			}
+
+			switch tp := t.(type) {
+			case *templates.Template:
+				graph.AddNode(file, tp.ID)
+				graph.AddEdge(workflowPath, file)
+			case *workflows.Workflow:
+				graph.AddNode(file, tp.ID)
+				graph.AddEdge(workflowPath, file)
+				errs[workflowPath] = append(errs[workflowPath], fmt.Errorf("%s: variable '%s': references workflow '%s' which can't be used as a step", location, name, tp.ID))
+
+				r.addWorkflowToGraph(graph, tp, visited, errs)
+			}
+		}
+	}
+}
+
+// workflowVariableFiles returns the template files referenced by a workflow variable
+func (r *Runner) workflowVariableFiles(workflowPath, value string) ([]string, error) {
+	resolved, err := r.resolveWorkflowPath(workflowPath, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(resolved, ".yaml") {
+		if _, err := os.Stat(resolved); err != nil {
+			return nil, fmt.Errorf("no such path found: %s", value)
+		}
+
+		return []string{resolved}, nil
+	}
+
+	var files []string
+
+	err = directoryWalker(resolved, func(path string, d *godirwalk.Dirent) error {
+		if !d.IsDir() && strings.HasSuffix(path, ".yaml") {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no match found in the directory %s", value)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// variableLocation returns the file:line location of a workflow variable definition
+func variableLocation(file, name string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return file
+	}
+	defer f.Close()
+
+	definition := regexp.MustCompile(`^\s+["']?` + regexp.QuoteMeta(name) + `["']?\s*:`)
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if definition.MatchString(scanner.Text()) {
+			return fmt.Sprintf("%s:%d", file, line)
+		}
+	}
+
+	return file
+}
+
+// writeGraph writes the workflow dependency graph to a DOT file
+func writeGraph(graph *workflows.Graph, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return graph.WriteDOT(f)
+}
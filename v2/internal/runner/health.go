@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// serveHealth serves the endpoints probing the daemon on an address:
+// /healthz answers as long as the daemon runs and /readyz until it starts
+// draining, so that it's taken out of service before it exits.
+func serveHealth(address string, s *supervisor) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{Handler: healthHandler(s)}
+	go server.Serve(listener) //nolint:errcheck // the server is closed when the daemon stops
+
+	return server, nil
+}
+
+// healthHandler returns the handler of the liveness and readiness endpoints
+func healthHandler(s *supervisor) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if s.draining() {
+			http.Error(w, "draining", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	return mux
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// labelPrefix starts the labels given to a target in the input, as in
+// label=internal,prod https://acme.local
+const labelPrefix = "label="
+
+// parseInputLine returns the target of an input line and the lowercased
+// labels preceding it.
+func parseInputLine(line string) (target string, labels []string) {
+	line = strings.TrimSpace(line)
+
+	for strings.HasPrefix(line, labelPrefix) {
+		end := strings.IndexAny(line, " \t")
+		if end == -1 {
+			break
+		}
+		for _, label := range strings.Split(line[len(labelPrefix):end], ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				labels = append(labels, strings.ToLower(label))
+			}
+		}
+		line = strings.TrimSpace(line[end:])
+	}
+
+	return line, labels
+}
+
+// normalizeInput returns the canonical form of a target, so that targets
+// only differing in case, default ports, trailing slashes or in the
+// encoding of international domains are scanned once.
+//
+// Targets which can't be parsed and the local paths scanned by the file
+// templates are returned as is.
+func normalizeInput(target string) string {
+	target = strings.TrimSpace(target)
+	if strings.HasPrefix(target, "/") || strings.HasPrefix(target, ".") || filepath.IsAbs(target) {
+		return target
+	}
+
+	if !strings.Contains(target, "://") {
+		return normalizeHost(target)
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return target
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+
+	host, port := parsed.Hostname(), parsed.Port()
+	if (parsed.Scheme == "http" && port == "80") || (parsed.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	host = normalizeHost(host)
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	parsed.Host = host
+
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.RawPath = strings.TrimRight(parsed.RawPath, "/")
+	parsed.Fragment = ""
+
+	return parsed.String()
+}
+
+// normalizeHost lowercases a host and converts an international domain to punycode
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		return ascii
+	}
+
+	return host
+}
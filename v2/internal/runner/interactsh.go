@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/interactsh"
+)
+
+// printLateInteractions reports the interactions received after the responses
+// of their requests were matched, with the request which sent the url. They
+// aren't findings as the matchers were already evaluated, but may point to a
+// blind vulnerability triggered later.
+func printLateInteractions(client *interactsh.Client) {
+	if client == nil {
+		return
+	}
+
+	for _, late := range client.Late() {
+		protocols := make(map[string]struct{})
+		for _, interaction := range late.Interactions {
+			protocols[interaction.Protocol] = struct{}{}
+		}
+
+		request := "all requests"
+		if late.Correlation.Request > 0 {
+			request = fmt.Sprintf("request %d", late.Correlation.Request)
+		}
+		if len(late.Correlation.Payloads) > 0 {
+			request += " with " + formatPayloads(late.Correlation.Payloads)
+		}
+
+		gologger.Warningf("[%s] %d late %s interactions from %s (%s)\n", late.Correlation.Template, len(late.Interactions), strings.Join(sortedSet(protocols), ","), late.Correlation.Target, request)
+	}
+}
+
+// formatPayloads returns the payload values of a request sorted by name
+func formatPayloads(payloads map[string]interface{}) string {
+	values := make([]string, 0, len(payloads))
+	for name, value := range payloads {
+		values = append(values, fmt.Sprintf("%s=%v", name, value))
+	}
+	sort.Strings(values)
+
+	return strings.Join(values, ", ")
+}
+
+// sortedSet returns the values of a set in order
+func sortedSet(set map[string]struct{}) []string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	return values
+}
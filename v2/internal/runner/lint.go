@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// ValidateTemplates lints the templates selected with -t, printing their
+// issues and failing if any is found so that the template repositories can
+// be checked in their pipelines.
+func ValidateTemplates(options *Options) error {
+	r := &Runner{options: options}
+	if config, err := readConfiguration(); err == nil {
+		r.templatesConfig = config
+	}
+
+	files := r.getTemplatesFor(options.Templates)
+	issues := templates.Lint(files)
+
+	invalid := make(map[string]struct{})
+	for _, issue := range issues {
+		gologger.Warningf("%s\n", issue)
+		invalid[issue.File] = struct{}{}
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d issues in %d of %d templates", len(issues), len(invalid), len(files))
+	}
+
+	gologger.Infof("Validated %d templates\n", len(files))
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"gopkg.in/yaml.v2"
+)
+
+// yamlLineRegex finds the line of a yaml error in its message
+var yamlLineRegex = regexp.MustCompile(`line (\d+)`)
+
+// loadReport writes a json line for each template failing to load as soon as
+// it fails, so that the template repositories can check them in their ci.
+type loadReport struct {
+	mutex  sync.Mutex
+	file   *os.File
+	failed int
+}
+
+// loadError is a template which failed to load as written to the report
+type loadError struct {
+	File string `json:"file"`
+	// Line is the line of the yaml error, 0 if the error has no position
+	Line int `json:"line,omitempty"`
+	// Category is read, syntax, signature or validation
+	Category string `json:"category"`
+	Error    string `json:"error"`
+}
+
+// newLoadReport creates the report file
+func newLoadReport(path string) (*loadReport, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &loadReport{file: file}, nil
+}
+
+// report writes the error of a template file
+func (l *loadReport) report(file string, err error) {
+	if l == nil || err == nil {
+		return
+	}
+
+	entry := &loadError{File: file, Category: loadErrorCategory(err), Error: err.Error()}
+	if match := yamlLineRegex.FindStringSubmatch(entry.Error); entry.Category == "syntax" && match != nil {
+		entry.Line, _ = strconv.Atoi(match[1])
+	}
+
+	data, marshalErr := jsoniter.Marshal(entry)
+	if marshalErr != nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.failed++
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		gologger.Warningf("Could not write template load report: %s\n", err)
+	}
+}
+
+// close closes the report file, logging the number of templates reported
+func (l *loadReport) close() {
+	if l == nil {
+		return
+	}
+
+	if l.failed > 0 {
+		gologger.Infof("%d templates failed to load, see %s\n", l.failed, l.file.Name())
+	}
+	l.file.Close()
+}
+
+// loadErrorCategory returns the kind of a template load error
+func loadErrorCategory(err error) string {
+	var pathError *os.PathError
+	var typeError *yaml.TypeError
+
+	switch {
+	case errors.As(err, &pathError):
+		return "read"
+	case errors.Is(err, templates.ErrUnsigned), errors.Is(err, templates.ErrUntrusted):
+		return "signature"
+	case errors.As(err, &typeError), strings.HasPrefix(err.Error(), "yaml:"):
+		return "syntax"
+	}
+
+	return "validation"
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// MigrateTemplates rewrites the templates selected with -t which are written
+// for an older schema version to the current one, in place.
+func MigrateTemplates(options *Options) error {
+	r := &Runner{options: options}
+	if config, err := readConfiguration(); err == nil {
+		r.templatesConfig = config
+	}
+
+	migrated := 0
+	for _, file := range r.getTemplatesFor(options.Templates) {
+		if _, _, workflow, err := templates.ReadInfo(file); err != nil || workflow {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		result, from, err := templates.Migrate(data)
+		if err != nil {
+			gologger.Warningf("Could not migrate %s: %s\n", file, err)
+			continue
+		}
+		if from == templates.CurrentSchemaVersion {
+			continue
+		}
+
+		if err := ioutil.WriteFile(file, result, info.Mode()); err != nil {
+			return err
+		}
+		if bytes.Contains(data, []byte("\n# digest: ")) {
+			gologger.Warningf("Migrated %s has to be signed again\n", file)
+		}
+		gologger.Verbosef("Migrated %s from schema version %d\n", "migrate", file, from)
+		migrated++
+	}
+
+	gologger.Infof("Migrated %d templates to schema version %d\n", migrated, templates.CurrentSchemaVersion)
+
+	return nil
+}
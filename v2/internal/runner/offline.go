@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// resolvConf is the file the system resolvers are read from in offline mode
+const resolvConf = "/etc/resolv.conf"
+
+// systemResolvers returns the nameservers configured on the system so
+// that the public default resolvers are never queried in offline mode.
+func systemResolvers() ([]string, error) {
+	file, err := os.Open(resolvConf)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var resolvers []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		resolvers = append(resolvers, net.JoinHostPort(fields[1], "53"))
+	}
+
+	if len(resolvers) == 0 {
+		return nil, errors.New("no nameserver found in " + resolvConf)
+	}
+
+	return resolvers, scanner.Err()
+}
+
+// requiresExternalServices returns true if a template matches on the
+// interactions received by an external service such as burp collaborator.
+func requiresExternalServices(template *templates.Template) bool {
+	for _, request := range template.Requests() {
+		for _, matcher := range request.Operators.Matchers {
+			if matcher.NeedsInteractions() {
+				return true
+			}
+			for _, expression := range matcher.DSL {
+				if strings.Contains(expression, "collab(") {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
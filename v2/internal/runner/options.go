@@ -3,11 +3,17 @@ package runner
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/interactsh"
 	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
 )
 
 // Options contains the configuration options for tuning
@@ -22,6 +28,7 @@ type Options struct {
 	UpdateTemplates      bool                   // UpdateTemplates updates the templates installed at startup
 	JSON                 bool                   // JSON writes json output to files
 	JSONRequests         bool                   // write requests/responses for matches in JSON output
+	TraceFindings        bool                   // TraceFindings attaches the requests leading to each http finding to its JSON output
 	EnableProgressBar    bool                   // Enable progrss bar
 	TemplatesVersion     bool                   // Show the templates installed version
 	TemplateList         bool                   // List available templates
@@ -35,12 +42,23 @@ type Options struct {
 	Timeout              int                    // Timeout is the seconds to wait for a response from the server.
 	Retries              int                    // Retries is the number of times to retry the request
 	RateLimit            int                    // Rate-Limit of requests per specified target
+	GlobalRateLimit      int                    // GlobalRateLimit is the maximum number of http requests per second of the whole scan
+	HostConcurrency      int                    // HostConcurrency is the maximum number of concurrent http requests to a host
+	AdaptiveRate         bool                   // AdaptiveRate delays the requests to the hosts answering 429/503 or failing
 	Severity             string                 // Filter templates based on their severity and only run the matching ones.
 	Target               string                 // Target is a single URL/Domain to scan usng a template
 	Targets              string                 // Targets specifies the targets to scan using templates.
 	Output               string                 // Output is the file to write found subdomains to.
+	OutputDir            string                 // OutputDir is the directory a timestamped workspace holding the findings, logs and state files of the scan is created in
 	ProxyURL             string                 // ProxyURL is the URL for the proxy server
 	ProxySocksURL        string                 // ProxySocksURL is the URL for the proxy socks server
+	ProxyList            string                 // ProxyList is a file of proxies the requests are rotated over
+	ProxyRotation        string                 // ProxyRotation rotates the proxies per request or per host
+	RequestJitter        int                    // RequestJitter is the maximum random delay in milliseconds added before each request
+	TLSProfile           string                 // TLSProfile is the browser whose tls client hello the https requests mimic
+	RandomizeHeaders     bool                   // RandomizeHeaders writes the headers of the http requests in a random order
+	SourcePorts          string                 // SourcePorts is the range of local ports the connections are made from, a random one for each
+	HTTP2                bool                   // HTTP2 negotiates http2 with the servers supporting it
 	TemplatesDirectory   string                 // TemplatesDirectory is the directory to use for storing templates
 	TraceLogFile         string                 // TraceLogFile specifies a file to write with the trace of all requests
 	Templates            multiStringFlag        // Signature specifies the template/templates to use
@@ -48,6 +66,87 @@ type Options struct {
 	CustomHeaders        requests.CustomHeaders // Custom global headers
 	Threads              int                    // Thread controls the number of concurrent requests to make.
 	BurpCollaboratorBiid string                 // Burp Collaborator BIID for polling
+	TargetRoutes         string                 // TargetRoutes is a yaml file mapping target hostnames and labels to template tags
+	StopAtSeverity       string                 // StopAtSeverity stops scanning a host after a finding at or above the severity
+	Replay               string                 // Replay is a json output file whose findings are re-sent and re-matched
+	DebugTemplate        string                 // DebugTemplate is a template whose http requests are stepped through interactively on the target
+	Verify               string                 // Verify is a json output file whose template/target pairs are scanned again
+	SelfTest             bool                   // SelfTest runs the bundled canary templates against internal mock servers
+	Canaries             string                 // Canaries is a yaml file of known-good and known-vulnerable targets the templates are checked on
+	Resolvers            []string               // Resolvers overrides the default DNS resolvers
+	Resolve              multiStringFlag        // Resolve maps hosts to addresses as host:ip, consulted before DNS
+	HostsFile            string                 // HostsFile is a hosts file whose mappings are consulted before DNS
+	Deterministic        bool                   // Deterministic fixes random seeds and runs templates and targets in a stable order
+	Graph                string                 // Graph is a file to write the workflow dependency graph to in DOT format
+	WorkflowConcurrency  int                    // WorkflowConcurrency is the default number of sibling workflow templates executed in parallel
+	TemplateCache        string                 // TemplateCache is the directory to cache the decoded templates in
+	TemplateErrorLimit   int                    // TemplateErrorLimit is the number of consecutive targets a template can fail on before being disabled
+	RegexMaxInput        int                    // RegexMaxInput is the maximum number of bytes of a response evaluated by regexes
+	BypassForbidden      bool                   // BypassForbidden retries 401/403 responses with access control bypass mutations
+	AllowIntrusive       bool                   // AllowIntrusive runs the templates classified as intrusive or destructive
+	AllowDoS             bool                   // AllowDoS runs the templates tagged as denial of service
+	DoSDelay             int                    // DoSDelay is the delay in milliseconds before each request of the dos templates
+	Screenshots          string                 // Screenshots is the directory to save the screenshots of the matched URLs in
+	BrowserPath          string                 // BrowserPath is the chromium based browser used for headless operations
+	Headless             bool                   // Headless enables the execution of the headless templates
+	HeadlessPages        int                    // HeadlessPages is the maximum number of browser pages open at the same time
+	VerifyXSS            bool                   // VerifyXSS confirms the findings of xss templates by executing them in a browser
+	CrawlDepth           int                    // CrawlDepth is the depth the http targets are crawled to before scanning, 0 disables crawling
+	CrawlMaxPages        int                    // CrawlMaxPages is the maximum number of pages fetched while crawling a target
+	NoDNS                bool                   // NoDNS disables the dns requests of all the templates and workflows
+	NoHeadless           bool                   // NoHeadless disables the headless requests and every other browser use
+	TemplateSources      string                 // TemplateSources is a yaml file naming the template directories with their trust level
+	Offline              bool                   // Offline disables all the network access not directed at the targets
+	TLSMinVersion        string                 // TLSMinVersion is the minimum tls version accepted by the clients
+	TLSMaxVersion        string                 // TLSMaxVersion is the maximum tls version accepted by the clients
+	TLSCipherSuites      string                 // TLSCipherSuites is a comma separated list of the cipher suites offered by the clients
+	InsecureSkipVerify   bool                   // InsecureSkipVerify disables the verification of the server certificates
+	CACert               string                 // CACert is a pem bundle of root certificates trusted by all the clients
+	ShowMatch            bool                   // ShowMatch adds the matched snippets of the responses to the findings
+	MatchContext         int                    // MatchContext is the number of bytes shown around the matched snippets
+	GroupBy              string                 // GroupBy groups the console findings by host or template at the end of the scan
+	SeverityIcons        bool                   // SeverityIcons shows an icon with the severities and aligns the columns after them
+	LoadReport           string                 // LoadReport is the json lines file the templates failing to load are reported to
+	SummaryJSON          string                 // SummaryJSON is the file the json statistics of the scan are written to
+	Probe                bool                   // Probe checks the http targets are alive before the scan and records their title, status and technologies
+	WhatIf               bool                   // WhatIf reports the templates and requests which would run on each target without sending any
+	MaxFindingsPerHost   int                    // MaxFindingsPerHost is the maximum number of findings written for a template on a host, 0 for no limit
+	MaxFindings          int                    // MaxFindings is the maximum number of findings written for the scan, 0 for no limit
+	InventoryOutput      string                 // InventoryOutput is the file the assets detected by the templates with product metadata are written to
+	OutputWriters        string                 // OutputWriters is a comma separated list of format=path writers the findings are also sent to
+	ReportConfig         string                 // ReportConfig is the yaml config of the issue trackers the findings are filed in
+	Resume               string                 // Resume is the file the progress is saved to, the scan continues from it if it exists
+	TrustedKeys          string                 // TrustedKeys are the comma separated public key files the templates must be signed with
+	UnsignedTemplates    string                 // UnsignedTemplates is reject or warn for the templates not signed by a trusted key
+	SignKey              string                 // SignKey is the private key file the templates are signed with before exiting
+	MigrateTemplates     bool                   // MigrateTemplates rewrites the templates to the current schema version before exiting
+	Validate             bool                   // Validate lints the templates and exits, failing if any issue is found
+	Tags                 string                 // Tags only runs the templates with one of the comma separated tags
+	ExcludeTags          string                 // ExcludeTags skips the templates with one of the comma separated tags
+	Author               string                 // Author only runs the templates of one of the comma separated authors
+	InteractshServer     string                 // InteractshServer is the url of the interactsh server the interactsh urls are created on
+	InteractshWait       int                    // InteractshWait is the time in seconds waited for the interactions of a request
+	NoInteractsh         bool                   // NoInteractsh disables the interactsh urls and matchers
+	NoClustering         bool                   // NoClustering sends the identical requests of different templates separately
+	Exposures            bool                   // Exposures writes the findings of the fingerprint templates to the inventory file only
+	RemoteTemplatesDir   string                 // RemoteTemplatesDir is the directory the templates loaded from urls and git repositories are cached in
+	RemoteCached         bool                   // RemoteCached loads the cached copies of the remote templates without downloading them
+	Service              string                 // Service is the install, uninstall or start command of the supervised daemon mode
+	ServiceArgs          []string               // ServiceArgs are the flags of the service command the daemon runs the scans with
+	ServiceName          string                 // ServiceName is the name of the service the daemon is installed as
+	DaemonDirectory      string                 // DaemonDirectory is the directory the daemon runs the scans in
+	DaemonInterval       int                    // DaemonInterval is the time in minutes between the starts of the scans of the daemon
+	DaemonLog            string                 // DaemonLog is the file the output of the scans of the daemon is written to
+	DaemonLogSize        int                    // DaemonLogSize is the size in megabytes the daemon log is rotated at
+	DaemonLogBackups     int                    // DaemonLogBackups is the number of rotated daemon logs kept
+	DaemonConfig         string                 // DaemonConfig is a file of flags added to the ones of the scans of the daemon, read again on SIGHUP
+	HealthAddr           string                 // HealthAddr is the address the daemon serves its /healthz and /readyz endpoints on
+	Queue                string                 // Queue is the url of the message queue the targets are received from, eg. nats://localhost:4222
+	QueueTargets         string                 // QueueTargets is the subject of the queue the targets are received from
+	QueueFindings        string                 // QueueFindings is the subject of the queue the findings are published to
+	QueueGroup           string                 // QueueGroup is the queue group sharing the targets between the workers
+	QueueBatchSize       int                    // QueueBatchSize is the maximum number of targets received from the queue scanned together
+	QueueLinger          int                    // QueueLinger is the time in seconds waited for more targets before a batch is scanned
 }
 
 type multiStringFlag []string
@@ -64,40 +163,31 @@ func (m *multiStringFlag) Set(value string) error {
 // ParseOptions parses the command line flags provided by a user
 func ParseOptions() *Options {
 	options := &Options{}
+	options.registerFlags(flag.CommandLine)
 
-	flag.StringVar(&options.Target, "target", "", "Target is a single target to scan using template")
-	flag.Var(&options.Templates, "t", "Template input dir/file/files to run on host. Can be used multiple times. Supports globbing.")
-	flag.Var(&options.ExcludedTemplates, "exclude", "Template input dir/file/files to exclude. Can be used multiple times. Supports globbing.")
-	flag.StringVar(&options.Severity, "severity", "", "Filter templates based on their severity and only run the matching ones. Comma-separated values can be used to specify multiple severities.")
-	flag.StringVar(&options.Targets, "l", "", "List of URLs to run templates on")
-	flag.StringVar(&options.Output, "o", "", "File to write output to (optional)")
-	flag.StringVar(&options.ProxyURL, "proxy-url", "", "URL of the proxy server")
-	flag.StringVar(&options.ProxySocksURL, "proxy-socks-url", "", "URL of the proxy socks server")
-	flag.BoolVar(&options.Silent, "silent", false, "Show only results in output")
-	flag.BoolVar(&options.Version, "version", false, "Show version of nuclei")
-	flag.BoolVar(&options.Verbose, "v", false, "Show Verbose output")
-	flag.BoolVar(&options.NoColor, "nC", false, "Don't Use colors in output")
-	flag.IntVar(&options.Timeout, "timeout", 5, "Time to wait in seconds before timeout")
-	flag.IntVar(&options.Retries, "retries", 1, "Number of times to retry a failed request")
-	flag.Var(&options.CustomHeaders, "H", "Custom Header.")
-	flag.BoolVar(&options.Debug, "debug", false, "Allow debugging of request/responses")
-	flag.BoolVar(&options.UpdateTemplates, "update-templates", false, "Update Templates updates the installed templates (optional)")
-	flag.StringVar(&options.TraceLogFile, "trace-log", "", "File to write sent requests trace log")
-	flag.StringVar(&options.TemplatesDirectory, "update-directory", "", "Directory to use for storing nuclei-templates")
-	flag.BoolVar(&options.JSON, "json", false, "Write json output to files")
-	flag.BoolVar(&options.JSONRequests, "json-requests", false, "Write requests/responses for matches in JSON output")
-	flag.BoolVar(&options.EnableProgressBar, "pbar", false, "Enable the progress bar")
-	flag.BoolVar(&options.TemplateList, "tl", false, "List available templates")
-	flag.IntVar(&options.RateLimit, "rate-limit", 150, "Rate-Limit Per Target (maximum requests/second")
-	flag.BoolVar(&options.StopAtFirstMatch, "stop-at-first-match", false, "Stop processing http requests at first match (this may break template/workflow logic)")
-	flag.IntVar(&options.BulkSize, "bulk-size", 25, "Maximum Number of hosts analyzed in parallel per template")
-	flag.IntVar(&options.TemplateThreads, "c", 10, "Maximum Number of templates executed in parallel")
-	flag.BoolVar(&options.Project, "project", false, "Use a project folder to avoid sending same request multiple times")
-	flag.StringVar(&options.ProjectPath, "project-path", "", "Use a user defined project folder, temporary folder is used if not specified but enabled")
-	flag.BoolVar(&options.NoMeta, "no-meta", false, "Don't display metadata for the matches")
-	flag.BoolVar(&options.TemplatesVersion, "templates-version", false, "Shows the installed nuclei-templates version")
-	flag.StringVar(&options.BurpCollaboratorBiid, "burp-collaborator-biid", "", "Burp Collaborator BIID")
-	flag.Parse()
+	// nuclei replay [flags] finding.json re-sends the requests of stored findings
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		_ = flag.CommandLine.Parse(os.Args[2:])
+		options.Replay = flag.Arg(0)
+		if options.Replay == "" {
+			gologger.Fatalf("Program exiting: no finding file provided to replay\n")
+		}
+	} else if len(os.Args) > 1 && os.Args[1] == "service" {
+		// nuclei service install|uninstall|start [flags] runs the scan of the flags as a supervised daemon
+		if len(os.Args) < 3 {
+			gologger.Fatalf("Program exiting: no service command provided (install, uninstall or start)\n")
+		}
+		options.Service = os.Args[2]
+		options.ServiceArgs = os.Args[3:]
+		_ = flag.CommandLine.Parse(options.ServiceArgs)
+	} else {
+		flag.Parse()
+	}
+
+	// a custom ca is only useful if the certificates are verified
+	if options.CACert != "" && !isFlagSet("insecure-skip-verify") {
+		options.InsecureSkipVerify = false
+	}
 
 	// Check if stdin pipe was given
 	options.Stdin = hasStdin()
@@ -131,6 +221,152 @@ func ParseOptions() *Options {
 	return options
 }
 
+// registerFlags defines the flags of the options on a flag set, setting
+// the options to the defaults of the flags
+func (options *Options) registerFlags(set *flag.FlagSet) {
+	set.StringVar(&options.Target, "target", "", "Target is a single target to scan using template")
+	set.Var(&options.Templates, "t", "Template input dir/file/files to run on host. Can be used multiple times. Supports globbing.")
+	set.Var(&options.ExcludedTemplates, "exclude", "Template input dir/file/files to exclude. Can be used multiple times. Supports globbing.")
+	set.StringVar(&options.Severity, "severity", "", "Filter templates based on their severity and only run the matching ones. Comma-separated values can be used to specify multiple severities.")
+	set.StringVar(&options.Targets, "l", "", "List of URLs to run templates on, a line can start with label=a,b labels for the target routes")
+	set.StringVar(&options.Output, "o", "", "File to write output to (optional)")
+	set.StringVar(&options.OutputDir, "output-dir", "", "Directory to create a timestamped workspace in, holding the findings, stored responses, trace log, resume file and summary not set by their own flags")
+	set.StringVar(&options.ProxyURL, "proxy-url", "", "URL of the proxy server")
+	set.StringVar(&options.ProxySocksURL, "proxy-socks-url", "", "URL of the proxy socks server")
+	set.StringVar(&options.ProxyList, "proxy-list", "", "File of http and socks5 proxies, one per line, the requests are rotated over")
+	set.IntVar(&options.RequestJitter, "request-jitter", 0, "Maximum random delay in milliseconds added before each http request")
+	set.StringVar(&options.TLSProfile, "tls-profile", "", "Browser whose tls client hello the https requests mimic (chrome, firefox, ios, or random for one per template), not applied through http proxies")
+	set.BoolVar(&options.RandomizeHeaders, "randomize-headers", false, "Write the headers of the http/1.1 requests in a random order, not applied through http proxies")
+	set.StringVar(&options.SourcePorts, "source-ports", "", "Range of local ports the connections are made from, a random one for each connection (eg. 20000-60000)")
+	set.BoolVar(&options.HTTP2, "http2", false, "Negotiate http2 with the servers supporting it, unless the protocol of a request is set")
+	set.Var(&options.Resolve, "resolve", "Address a host resolves to as host:ip, consulted before DNS. Can be used multiple times.")
+	set.StringVar(&options.HostsFile, "hosts-file", "", "Hosts file whose mappings are consulted before DNS")
+	set.StringVar(&options.ProxyRotation, "proxy-rotation", "request", "Rotate the proxies of the proxy list per request or per host")
+	set.BoolVar(&options.Silent, "silent", false, "Show only results in output")
+	set.BoolVar(&options.Version, "version", false, "Show version of nuclei")
+	set.BoolVar(&options.Verbose, "v", false, "Show Verbose output")
+	set.BoolVar(&options.NoColor, "nC", false, "Don't Use colors in output")
+	set.IntVar(&options.Timeout, "timeout", 5, "Time to wait in seconds before timeout")
+	set.IntVar(&options.Retries, "retries", 1, "Number of times to retry a failed request")
+	set.Var(&options.CustomHeaders, "H", "Custom Header.")
+	set.BoolVar(&options.Debug, "debug", false, "Allow debugging of request/responses")
+	set.BoolVar(&options.UpdateTemplates, "update-templates", false, "Update Templates updates the installed templates (optional)")
+	set.StringVar(&options.TraceLogFile, "trace-log", "", "File to write sent requests trace log")
+	set.StringVar(&options.TemplatesDirectory, "update-directory", "", "Directory to use for storing nuclei-templates")
+	set.BoolVar(&options.JSON, "json", false, "Write json output to files")
+	set.BoolVar(&options.JSONRequests, "json-requests", false, "Write requests/responses for matches in JSON output")
+	set.BoolVar(&options.TraceFindings, "trace-findings", false, "Attach the sequence of requests and variables leading to each http finding to its JSON output")
+	set.BoolVar(&options.EnableProgressBar, "pbar", false, "Enable the progress bar")
+	set.BoolVar(&options.TemplateList, "tl", false, "List available templates")
+	set.IntVar(&options.RateLimit, "rate-limit", 150, "Rate-Limit Per Target (maximum requests/second")
+	set.IntVar(&options.GlobalRateLimit, "global-rate-limit", 0, "Maximum number of http requests per second of the whole scan (0 for unlimited)")
+	set.IntVar(&options.HostConcurrency, "host-concurrency", 0, "Maximum number of concurrent http requests to a host (0 for unlimited)")
+	set.BoolVar(&options.AdaptiveRate, "adaptive-rate", false, "Back off from the hosts answering 429/503 or failing, and speed up again once they recover")
+	set.BoolVar(&options.StopAtFirstMatch, "stop-at-first-match", false, "Stop processing http requests at first match (this may break template/workflow logic)")
+	set.IntVar(&options.BulkSize, "bulk-size", 25, "Maximum Number of hosts analyzed in parallel per template")
+	set.IntVar(&options.TemplateThreads, "c", 10, "Maximum Number of templates executed in parallel")
+	set.BoolVar(&options.Project, "project", false, "Use a project folder to avoid sending same request multiple times")
+	set.StringVar(&options.ProjectPath, "project-path", "", "Use a user defined project folder, temporary folder is used if not specified but enabled")
+	set.BoolVar(&options.NoMeta, "no-meta", false, "Don't display metadata for the matches")
+	set.BoolVar(&options.TemplatesVersion, "templates-version", false, "Shows the installed nuclei-templates version")
+	set.StringVar(&options.BurpCollaboratorBiid, "burp-collaborator-biid", "", "Burp Collaborator BIID")
+	set.StringVar(&options.StopAtSeverity, "stop-at-severity", "", "Stop running templates on a host after a finding at or above the severity (eg. critical)")
+	set.StringVar(&options.Verify, "verify", "", "Run only the template/target pairs from a previous json output file and report which findings are still reproducible")
+	set.BoolVar(&options.SelfTest, "self-test", false, "Run the bundled canary templates against internal mock servers and exit")
+	set.StringVar(&options.Canaries, "canaries", "", "Run the -t templates on the known-good and known-vulnerable targets of a yaml file and fail on the unexpected matches or misses")
+	set.BoolVar(&options.Deterministic, "deterministic", false, "Fix random seeds and run templates and targets serially in a stable order for reproducible output")
+	set.IntVar(&options.WorkflowConcurrency, "workflow-concurrency", 1, "Default number of templates of a workflow variable executed in parallel")
+	set.StringVar(&options.Graph, "graph", "", "Write the workflow dependency graph to a DOT file and exit")
+	set.StringVar(&options.TemplateCache, "template-cache", "", "Directory to cache decoded templates in to speed up later runs")
+	set.IntVar(&options.TemplateErrorLimit, "template-error-limit", 30, "Number of consecutive targets a template can fail on before being disabled (0 to never disable)")
+	set.IntVar(&options.RegexMaxInput, "regex-max-input", 0, "Maximum number of bytes of a response evaluated by regexes (0 for the whole response)")
+	set.BoolVar(&options.BypassForbidden, "bypass-forbidden", false, "Retry 401/403 responses with known access control bypass mutations and report the successful ones")
+	set.BoolVar(&options.AllowIntrusive, "allow-intrusive", false, "Run the templates classified as intrusive or destructive in their safety info")
+	set.BoolVar(&options.AllowDoS, "allow-dos", false, "Run the templates tagged dos or resource-exhaustion, one request at a time")
+	set.IntVar(&options.DoSDelay, "dos-delay", 1000, "Delay in milliseconds before each request of the dos templates (minimum 1000)")
+	set.StringVar(&options.Screenshots, "screenshot", "", "Directory to save screenshots of the matched URLs in, taken with a headless browser")
+	set.StringVar(&options.BrowserPath, "browser-path", "", "Path of the chromium based browser used for headless operations (looked up in PATH if empty)")
+	set.BoolVar(&options.Headless, "headless", false, "Execute the headless templates with a chromium based browser")
+	set.IntVar(&options.HeadlessPages, "headless-pages", 10, "Maximum number of browser pages open at the same time by headless templates")
+	set.BoolVar(&options.VerifyXSS, "verify-xss", false, "Report the findings of xss tagged templates only if their URL opens a javascript dialog in a headless browser")
+	set.IntVar(&options.CrawlDepth, "crawl-depth", 0, "Crawl the http targets to this depth and scan the discovered endpoints too (0 to disable)")
+	set.IntVar(&options.CrawlMaxPages, "crawl-max-pages", 100, "Maximum number of pages fetched while crawling a target")
+	set.BoolVar(&options.NoDNS, "no-dns", false, "Disable the dns protocol, no dns request of any template or workflow is sent")
+	set.BoolVar(&options.NoHeadless, "no-headless", false, "Disable the headless protocol, screenshots and xss verification so no browser is ever started")
+	set.StringVar(&options.TemplateSources, "template-sources", "", "Yaml file naming the template directories with their trust level (untrusted, trusted or official)")
+	set.BoolVar(&options.Offline, "offline", false, "Air-gapped mode, no update check, collaborator polling or public resolver is used and templates needing external services are refused")
+	set.StringVar(&options.TLSMinVersion, "tls-min-version", "", "Minimum tls version accepted (tls10, tls11, tls12 or tls13)")
+	set.StringVar(&options.TLSMaxVersion, "tls-max-version", "", "Maximum tls version accepted (tls10, tls11, tls12 or tls13)")
+	set.StringVar(&options.TLSCipherSuites, "tls-ciphers", "", "Comma separated list of the tls cipher suites offered (eg. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256)")
+	set.BoolVar(&options.InsecureSkipVerify, "insecure-skip-verify", true, "Skip the verification of the server certificates, use -insecure-skip-verify=false to enable it")
+	set.StringVar(&options.CACert, "ca-cert", "", "Pem bundle of root certificates added to the system ones, enables the certificate verification unless -insecure-skip-verify is set")
+	set.BoolVar(&options.ShowMatch, "show-match", false, "Show the matched parts of the responses with the findings")
+	set.IntVar(&options.MatchContext, "match-context", 20, "Number of bytes shown before and after the matched parts with -show-match")
+	set.StringVar(&options.GroupBy, "group-by", "", "Print the console findings grouped by host or template once the scan is done")
+	set.BoolVar(&options.SeverityIcons, "severity-icons", false, "Show an icon with the severities and align the columns of the console findings")
+	set.StringVar(&options.LoadReport, "load-report", "", "File to write a json line to for each template failing to load, with its line and error category")
+	set.StringVar(&options.SummaryJSON, "summary-json", "", "File to write the json statistics of the scan to (duration, requests, errors, slowest templates and findings)")
+	set.BoolVar(&options.Probe, "probe", false, "Probe the http targets before the scan, skipping the dead ones and following their redirects")
+	set.BoolVar(&options.WhatIf, "what-if", false, "Report the templates and number of requests which would run on each target, without sending any traffic")
+	set.IntVar(&options.MaxFindingsPerHost, "max-findings-per-template-per-host", 0, "Maximum number of findings written for a template on a host (0 for no limit)")
+	set.IntVar(&options.MaxFindings, "max-findings", 0, "Maximum number of findings written for the scan (0 for no limit)")
+	set.StringVar(&options.OutputWriters, "output-writers", "", "Comma separated list of format=path writers the findings are also written to (jsonl, sarif), eg. sarif=results.sarif")
+	set.StringVar(&options.Resume, "resume", "", "File to save the progress of the scan to, continuing from it if it exists (removed once the scan completes)")
+	set.StringVar(&options.TrustedKeys, "trusted-keys", "", "Comma separated ed25519 public key pem files, only the templates signed with one of them are run")
+	set.StringVar(&options.UnsignedTemplates, "unsigned-templates", "reject", "Action for the templates not signed by a trusted key when -trusted-keys is set (reject, warn)")
+	set.StringVar(&options.SignKey, "sign-key", "", "Sign the -t templates in place with an ed25519 private key pem file and exit")
+	set.BoolVar(&options.MigrateTemplates, "migrate-templates", false, "Rewrite the -t templates written for an older schema version to the current one in place and exit")
+	set.BoolVar(&options.Validate, "validate", false, "Lint the -t templates for duplicate ids, unknown parts, unused extractors, invalid regexes, missing severities and payload files and exit")
+	set.StringVar(&options.DebugTemplate, "debug-template", "", "Step interactively through the http requests of a template on the -target, showing the requests, responses, variables and matchers")
+	set.StringVar(&options.ReportConfig, "report-config", "", "Yaml config of the github, gitlab or jira issue trackers to file the findings in")
+	set.StringVar(&options.InventoryOutput, "inventory-output", "", "File to write the assets detected by templates with cpe, vendor or product info to as json lines")
+	set.StringVar(&options.Tags, "tags", "", "Only run the templates with one of the comma separated tags")
+	set.StringVar(&options.ExcludeTags, "exclude-tags", "", "Skip the templates with one of the comma separated tags (eg. dos,fuzz)")
+	set.StringVar(&options.Author, "author", "", "Only run the templates of one of the comma separated authors")
+	set.StringVar(&options.InteractshServer, "interactsh-server", interactsh.DefaultServer, "Interactsh server the {{interactsh-url}} of the templates are created on")
+	set.IntVar(&options.InteractshWait, "interactsh-wait", 10, "Seconds to wait for the interactions of a request with an interactsh matcher")
+	set.BoolVar(&options.NoInteractsh, "no-interactsh", false, "Disable the interactsh urls and matchers")
+	set.BoolVar(&options.NoClustering, "no-clustering", false, "Send the identical http requests of different templates separately instead of once per target")
+	set.BoolVar(&options.Exposures, "exposures", false, "Write the findings of fingerprint and tech templates as assets to the inventory file (assets.jsonl by default) instead of the output")
+	set.StringVar(&options.RemoteTemplatesDir, "remote-templates-dir", "", "Directory to cache the templates loaded from urls and git+ repositories in (user cache directory by default)")
+	set.BoolVar(&options.RemoteCached, "remote-cached", false, "Load the cached copies of the templates from urls and git+ repositories without downloading them, allowed in offline mode")
+	set.StringVar(&options.TargetRoutes, "target-routes", "", "Yaml file mapping target hostname patterns and labels to the template tags allowed on them")
+	set.StringVar(&options.ServiceName, "service-name", "nuclei", "Name of the service the daemon is installed as with nuclei service install")
+	set.StringVar(&options.DaemonDirectory, "daemon-dir", "", "Directory the daemon runs the scans in (the directory of nuclei service install by default)")
+	set.IntVar(&options.DaemonInterval, "daemon-interval", 1440, "Minutes between the starts of the scans of the daemon")
+	set.StringVar(&options.DaemonLog, "daemon-log", "nuclei-daemon.log", "File the output of the scans of the daemon is written to")
+	set.IntVar(&options.DaemonLogSize, "daemon-log-size", 10, "Size in megabytes the daemon log is rotated at")
+	set.IntVar(&options.DaemonLogBackups, "daemon-log-backups", 5, "Number of rotated daemon logs kept")
+	set.StringVar(&options.DaemonConfig, "daemon-config", "", "File of flags, one per line with its value, added to the ones of the scans of the daemon and read again on SIGHUP")
+	set.StringVar(&options.HealthAddr, "health-addr", "", "Address the daemon serves its /healthz liveness and /readyz readiness endpoints on, eg. :8080")
+	set.StringVar(&options.Queue, "queue", "", "Message queue the targets are continuously received from and the findings published to (nats://host:port)")
+	set.StringVar(&options.QueueTargets, "queue-targets", "nuclei.targets", "Subject of the queue the targets are received from, one or more per message")
+	set.StringVar(&options.QueueFindings, "queue-findings", "nuclei.findings", "Subject of the queue the json findings are published to")
+	set.StringVar(&options.QueueGroup, "queue-group", "nuclei", "Queue group sharing the targets between the workers")
+	set.IntVar(&options.QueueBatchSize, "queue-batch-size", 100, "Maximum number of targets received from the queue scanned together")
+	set.IntVar(&options.QueueLinger, "queue-linger", 5, "Seconds waited for more targets before a batch received from the queue is scanned")
+}
+
+// defaultOptions returns the options of the flags left to their defaults
+func defaultOptions() *Options {
+	options := &Options{}
+	options.registerFlags(flag.NewFlagSet("nuclei", flag.ContinueOnError))
+
+	return options
+}
+
+// isFlagSet returns true if a flag was provided on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
 func hasStdin() bool {
 	stat, err := os.Stdin.Stat()
 	if err != nil {
@@ -150,17 +386,78 @@ func (options *Options) validateOptions() error {
 		return errors.New("both verbose and silent mode specified")
 	}
 
-	if !options.TemplateList {
+	switch options.Service {
+	case "", "install", "uninstall", "start":
+	default:
+		return fmt.Errorf("unknown service command %s, use install, uninstall or start", options.Service)
+	}
+	if options.Service != "" && options.DaemonInterval <= 0 {
+		return errors.New("the daemon interval must be positive")
+	}
+
+	if !options.TemplateList && !options.SelfTest && options.Replay == "" && options.Verify == "" && options.SignKey == "" && !options.MigrateTemplates && options.DebugTemplate == "" && options.Canaries == "" && !options.Validate && options.Service != "uninstall" {
 		// Check if a list of templates was provided and it exists
 		if len(options.Templates) == 0 && !options.UpdateTemplates {
 			return errors.New("no template/templates provided")
 		}
 
-		if options.Targets == "" && !options.Stdin && options.Target == "" && !options.UpdateTemplates {
+		if options.Targets == "" && !options.Stdin && options.Target == "" && !options.UpdateTemplates && options.Graph == "" && options.Queue == "" {
 			return errors.New("no target input provided")
 		}
 	}
 
+	if options.Queue != "" && (options.QueueBatchSize <= 0 || options.QueueLinger < 0) {
+		return errors.New("the queue batch size must be positive and the linger time not negative")
+	}
+
+	if options.StopAtSeverity != "" && !severity.IsValid(options.StopAtSeverity) {
+		return errors.New("invalid severity specified for stop-at-severity")
+	}
+
+	if options.UnsignedTemplates != "reject" && options.UnsignedTemplates != "warn" {
+		return errors.New("invalid action specified for unsigned-templates")
+	}
+
+	if options.SignKey != "" && len(options.Templates) == 0 {
+		return errors.New("no templates provided to sign")
+	}
+
+	if options.MigrateTemplates && len(options.Templates) == 0 {
+		return errors.New("no templates provided to migrate")
+	}
+
+	if options.Validate && len(options.Templates) == 0 {
+		return errors.New("no templates provided to validate")
+	}
+
+	if options.Canaries != "" && len(options.Templates) == 0 {
+		return errors.New("no templates provided to check on the canaries")
+	}
+
+	if options.DebugTemplate != "" && options.Target == "" {
+		return errors.New("no target provided to debug the template on")
+	}
+
+	if options.Offline && options.UpdateTemplates {
+		return errors.New("templates can't be updated in offline mode")
+	}
+
+	if options.Offline && options.BurpCollaboratorBiid != "" {
+		return errors.New("burp collaborator can't be polled in offline mode")
+	}
+
+	if options.Offline && !options.RemoteCached {
+		for _, definition := range options.Templates {
+			if isRemoteDefinition(definition) {
+				return fmt.Errorf("remote templates '%s' can't be downloaded in offline mode, use -remote-cached to load their cached copy", redactURL(definition))
+			}
+		}
+	}
+
+	if err := options.tlsOptions().Validate(); err != nil {
+		return err
+	}
+
 	// Validate proxy options if provided
 	err := validateProxyURL(
 		options.ProxyURL,
@@ -178,9 +475,40 @@ func (options *Options) validateOptions() error {
 		return err
 	}
 
+	if options.ProxyList != "" && (options.ProxyURL != "" || options.ProxySocksURL != "") {
+		return errors.New("a proxy list can't be used with a proxy url")
+	}
+	if options.ProxyRotation != "request" && options.ProxyRotation != "host" {
+		return errors.New("proxy rotation must be request or host")
+	}
+
+	if _, ok := executer.TLSProfiles[options.TLSProfile]; !ok && options.TLSProfile != "" && options.TLSProfile != executer.RandomTLSProfile {
+		return fmt.Errorf("unknown tls profile %s", options.TLSProfile)
+	}
+	if options.SourcePorts != "" {
+		if _, err := parsePortRange(options.SourcePorts); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// tlsOptions returns the global tls settings of the clients
+func (options *Options) tlsOptions() *tlsconfig.Options {
+	tlsOptions := &tlsconfig.Options{
+		MinVersion:         options.TLSMinVersion,
+		MaxVersion:         options.TLSMaxVersion,
+		InsecureSkipVerify: &options.InsecureSkipVerify,
+		CACert:             options.CACert,
+	}
+	if options.TLSCipherSuites != "" {
+		tlsOptions.CipherSuites = strings.Split(options.TLSCipherSuites, ",")
+	}
+
+	return tlsOptions
+}
+
 func validateProxyURL(proxyURL, message string) error {
 	if proxyURL != "" && !isValidURL(proxyURL) {
 		return errors.New(message)
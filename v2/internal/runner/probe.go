@@ -0,0 +1,202 @@
+package runner
+
+import (
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/globalratelimiter"
+	"github.com/remeh/sizedwaitgroup"
+)
+
+// maxProbeBody is the maximum number of bytes of a probed page read for its title and technologies
+const maxProbeBody = 1 << 20
+
+var (
+	reTitle     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	reGenerator = regexp.MustCompile(`(?i)<meta[^>]+name=["']generator["'][^>]+content=["']([^"' /]+)`)
+)
+
+// probeCookies maps the session cookie names to the technology setting them
+var probeCookies = map[string]string{
+	"phpsessid":         "php",
+	"jsessionid":        "java",
+	"asp.net_sessionid": "asp.net",
+	"laravel_session":   "laravel",
+	"csrftoken":         "django",
+	"_rails_session":    "rails",
+	"connect.sid":       "express",
+}
+
+// probeResult contains what the probe found on a live http target
+type probeResult struct {
+	URL        string
+	StatusCode int
+	Title      string
+	Tech       []string
+}
+
+// probeResults contains the probe results by target hostname
+type probeResults struct {
+	mutex sync.RWMutex
+	hosts map[string]*probeResult
+}
+
+// tech returns the technologies found on the host of a target
+func (p *probeResults) tech(target string) []string {
+	if p == nil {
+		return nil
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if result, ok := p.hosts[targetHostname(target)]; ok {
+		return result.Tech
+	}
+
+	return nil
+}
+
+// probeInput checks the http targets are alive before running the templates.
+//
+// Dead targets are removed from the input and the targets redirecting on
+// the same host are replaced with the URL they redirect to. The status,
+// title and technologies of the live targets are kept for the routes.
+func (r *Runner) probeInput(usedInput map[string]struct{}) {
+	client := r.newCrawlClient()
+
+	r.probes = &probeResults{hosts: make(map[string]*probeResult)}
+
+	var (
+		mutex sync.Mutex
+		final = make(map[string]string)
+	)
+
+	targets := strings.Split(strings.TrimSpace(r.input), "\n")
+
+	swg := sizedwaitgroup.New(r.options.BulkSize)
+	for _, target := range targets {
+		if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+			continue
+		}
+
+		swg.Add()
+		go func(target string) {
+			defer swg.Done()
+
+			result, err := probe(client, target)
+			if err != nil {
+				gologger.Verbosef("Target %s is not alive: %s\n", "probe", target, err)
+				return
+			}
+			gologger.Verbosef("%s [%d] [%s] [%s]\n", "probe", result.URL, result.StatusCode, result.Title, strings.Join(result.Tech, ","))
+
+			r.probes.mutex.Lock()
+			r.probes.hosts[targetHostname(target)] = result
+			r.probes.mutex.Unlock()
+
+			mutex.Lock()
+			final[target] = result.URL
+			mutex.Unlock()
+		}(target)
+	}
+	swg.Wait()
+
+	dead := 0
+	builder := &strings.Builder{}
+
+	for _, target := range targets {
+		if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			finalURL, ok := final[target]
+			if !ok {
+				delete(usedInput, target)
+				dead++
+				continue
+			}
+
+			if finalURL != target && targetHostname(finalURL) == targetHostname(target) {
+				delete(usedInput, target)
+				if _, ok := usedInput[finalURL]; ok {
+					continue
+				}
+				usedInput[finalURL] = struct{}{}
+				globalratelimiter.Add(finalURL, r.options.RateLimit)
+				target = finalURL
+			}
+		}
+
+		builder.WriteString(target)
+		builder.WriteString("\n")
+	}
+
+	r.input = builder.String()
+	r.inputCount = int64(len(usedInput))
+
+	gologger.Infof("Probing found %d live targets (%d dead skipped)\n", len(final), dead)
+}
+
+// probe requests a target following its redirects
+func probe(client *http.Client, target string) (*probeResult, error) {
+	resp, err := client.Get(target)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+
+	result := &probeResult{URL: resp.Request.URL.String(), StatusCode: resp.StatusCode}
+	if match := reTitle.FindSubmatch(body); match != nil {
+		result.Title = strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " ")
+	}
+	result.Tech = probeTech(resp, body)
+
+	return result, nil
+}
+
+// probeTech returns the technologies revealed by the headers, cookies and generator of a page
+func probeTech(resp *http.Response, body []byte) []string {
+	tech := make(map[string]struct{})
+
+	add := func(value string) {
+		// only the product is kept, eg. nginx from nginx/1.18.0 (Ubuntu)
+		if i := strings.IndexAny(value, "/ ("); i != -1 {
+			value = value[:i]
+		}
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			tech[value] = struct{}{}
+		}
+	}
+
+	for _, header := range []string{"Server", "X-Powered-By", "X-Generator"} {
+		for _, value := range resp.Header.Values(header) {
+			add(value)
+		}
+	}
+	if resp.Header.Get("X-AspNet-Version") != "" {
+		add("asp.net")
+	}
+	for _, cookie := range resp.Cookies() {
+		if name, ok := probeCookies[strings.ToLower(cookie.Name)]; ok {
+			add(name)
+		}
+	}
+	if match := reGenerator.FindSubmatch(body); match != nil {
+		add(string(match[1]))
+	}
+
+	sorted := make([]string, 0, len(tech))
+	for value := range tech {
+		sorted = append(sorted, value)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
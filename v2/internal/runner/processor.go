@@ -3,12 +3,15 @@ package runner
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"net/http/cookiejar"
 	"os"
 	"path"
-	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	tengo "github.com/d5/tengo/v2"
 	"github.com/d5/tengo/v2/stdlib"
@@ -20,7 +23,6 @@ import (
 	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
 	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
-	"github.com/remeh/sizedwaitgroup"
 )
 
 // workflowTemplates contains the initialized workflow templates per template group
@@ -29,73 +31,254 @@ type workflowTemplates struct {
 	Templates []*workflows.Template
 }
 
+// sharedValues returns the store of the values extracted by the http requests
+// of a template on the targets, the same for all the requests of the template.
+func (r *Runner) sharedValues(template *templates.Template) *executer.SharedValues {
+	values := newSharedValues(template)
+	if values == nil {
+		return nil
+	}
+
+	stored, _ := r.values.LoadOrStore(template.ID, values)
+	return stored.(*executer.SharedValues)
+}
+
+// newSharedValues returns an empty store of extracted values for a template,
+// nil if it has a single http request which has nothing to pass them on to.
+func newSharedValues(template *templates.Template) *executer.SharedValues {
+	if len(template.BulkRequestsHTTP) < 2 {
+		return nil
+	}
+
+	return executer.NewSharedValues()
+}
+
+// cookieJar returns the cookie jar shared by the http requests of a template
+// reusing the cookies, nil if it doesn't.
+func (r *Runner) cookieJar(template *templates.Template) *cookiejar.Jar {
+	jar := templateCookieJar(nil, template)
+	if jar == nil {
+		return nil
+	}
+
+	stored, _ := r.jars.LoadOrStore(template.ID, jar)
+	return stored.(*cookiejar.Jar)
+}
+
+// templateCookieJar returns the cookie jar of a template run by a workflow,
+// the one of the workflow if it reuses the cookies or else a new one if the
+// template does.
+func templateCookieJar(workflowJar *cookiejar.Jar, template *templates.Template) *cookiejar.Jar {
+	if workflowJar != nil || !template.CookieReuse {
+		return workflowJar
+	}
+
+	// cookiejar.New never fails without options
+	jar, _ := cookiejar.New(nil)
+	return jar
+}
+
+// templateProxy returns the proxy of a template run by a workflow, its own
+// one or else the one of the workflow.
+func templateProxy(workflowProxy string, template *templates.Template) string {
+	if template.Proxy != "" {
+		return template.Proxy
+	}
+
+	return workflowProxy
+}
+
+// commonOptions returns the options shared by all the executers of a template
+func (r *Runner) commonOptions(template *templates.Template) executer.CommonOptions {
+	return executer.CommonOptions{
+		TraceLog:      r.traceLog,
+		Debug:         r.options.Debug,
+		Template:      template,
+		Writer:        r.output,
+		JSON:          r.options.JSON,
+		JSONRequests:  r.options.JSONRequests,
+		NoMeta:        r.options.NoMeta,
+		ShowMatch:     r.options.ShowMatch,
+		MatchContext:  r.options.MatchContext,
+		Console:       r.console,
+		Scan:          r.scan,
+		Limits:        r.limits,
+		Inventory:     r.inventory,
+		Writers:       r.writers,
+		ColoredOutput: !r.options.NoColor,
+		Colorizer:     r.colorizer,
+		Decolorizer:   r.decolorizer,
+	}
+}
+
 // processTemplateWithList processes a template and runs the enumeration on all the targets
 func (r *Runner) processTemplateWithList(p progress.IProgress, template *templates.Template, request interface{}) bool {
+	defer r.recoverTemplate(template.ID, "")
+
 	var httpExecuter *executer.HTTPExecuter
 	var dnsExecuter *executer.DNSExecuter
+	var headlessExecuter *executer.HeadlessExecuter
+	var networkExecuter *executer.NetworkExecuter
+	var sslExecuter *executer.SSLExecuter
+	var whoisExecuter *executer.WhoisExecuter
+	var websocketExecuter *executer.WebsocketExecuter
+	var fileExecuter *executer.FileExecuter
+	var grpcExecuter *executer.GRPCExecuter
+	var requestCount int64
 	var err error
 
 	// Create an executer based on the request type.
 	switch value := request.(type) {
 	case *requests.DNSRequest:
+		requestCount = value.GetRequestCount()
 		dnsExecuter = executer.NewDNSExecuter(&executer.DNSOptions{
-			TraceLog:      r.traceLog,
-			Debug:         r.options.Debug,
-			Template:      template,
+			CommonOptions: r.commonOptions(template),
 			DNSRequest:    value,
-			Writer:        r.output,
-			JSON:          r.options.JSON,
-			JSONRequests:  r.options.JSONRequests,
-			NoMeta:        r.options.NoMeta,
-			ColoredOutput: !r.options.NoColor,
-			Colorizer:     r.colorizer,
-			Decolorizer:   r.decolorizer,
+			Resolvers:     r.options.Resolvers,
+		})
+	case *requests.HeadlessRequest:
+		requestCount = value.GetRequestCount()
+		headlessExecuter = executer.NewHeadlessExecuter(&executer.HeadlessOptions{
+			CommonOptions:   r.commonOptions(template),
+			HeadlessRequest: value,
+			Pool:            r.headlessPool,
+			Timeout:         r.headlessTimeout(),
+		})
+	case *requests.NetworkRequest:
+		requestCount = value.GetRequestCount()
+		networkExecuter, err = executer.NewNetworkExecuter(&executer.NetworkOptions{
+			CommonOptions:  r.commonOptions(template),
+			NetworkRequest: value,
+			Timeout:        r.options.Timeout,
+			Dialer:         &r.dialer,
+			TLS:            r.options.tlsOptions(),
+		})
+	case *requests.SSLRequest:
+		requestCount = value.GetRequestCount()
+		sslExecuter, err = executer.NewSSLExecuter(&executer.SSLOptions{
+			CommonOptions: r.commonOptions(template),
+			SSLRequest:    value,
+			Timeout:       r.options.Timeout,
+			Dialer:        &r.dialer,
+			TLS:           r.options.tlsOptions(),
+		})
+	case *requests.WhoisRequest:
+		requestCount = value.GetRequestCount()
+		whoisExecuter = executer.NewWhoisExecuter(&executer.WhoisOptions{
+			CommonOptions: r.commonOptions(template),
+			WhoisRequest:  value,
+			Timeout:       r.options.Timeout,
+			Dialer:        &r.dialer,
+		})
+	case *requests.FileRequest:
+		requestCount = value.GetRequestCount()
+		fileExecuter = executer.NewFileExecuter(&executer.FileOptions{
+			CommonOptions: r.commonOptions(template),
+			FileRequest:   value,
+		})
+	case *requests.WebsocketRequest:
+		requestCount = value.GetRequestCount()
+		websocketExecuter, err = executer.NewWebsocketExecuter(&executer.WebsocketOptions{
+			CommonOptions:    r.commonOptions(template),
+			WebsocketRequest: value,
+			CustomHeaders:    r.options.CustomHeaders,
+			Timeout:          r.options.Timeout,
+			Dialer:           &r.dialer,
+			TLS:              r.options.tlsOptions(),
+		})
+	case *requests.GRPCRequest:
+		requestCount = value.GetRequestCount()
+		grpcExecuter, err = executer.NewGRPCExecuter(&executer.GRPCOptions{
+			CommonOptions: r.commonOptions(template),
+			GRPCRequest:   value,
+			CustomHeaders: r.options.CustomHeaders,
+			Timeout:       r.options.Timeout,
+			Dialer:        &r.dialer,
+			TLS:           r.options.tlsOptions(),
 		})
 	case *requests.BulkHTTPRequest:
+		requestCount = value.GetRequestCount()
 		httpExecuter, err = executer.NewHTTPExecuter(&executer.HTTPOptions{
-			TraceLog:         r.traceLog,
-			Debug:            r.options.Debug,
-			Template:         template,
+			CommonOptions:    r.commonOptions(template),
 			BulkHTTPRequest:  value,
-			Writer:           r.output,
 			Timeout:          r.options.Timeout,
 			Retries:          r.options.Retries,
 			ProxyURL:         r.options.ProxyURL,
 			ProxySocksURL:    r.options.ProxySocksURL,
+			Proxies:          r.proxies,
+			Proxy:            template.Proxy,
+			Cluster:          r.cluster,
 			CustomHeaders:    r.options.CustomHeaders,
-			JSON:             r.options.JSON,
-			JSONRequests:     r.options.JSONRequests,
-			NoMeta:           r.options.NoMeta,
+			Interactsh:       r.interactsh,
+			Throttle:         r.throttle,
+			InteractshWait:   time.Duration(r.options.InteractshWait) * time.Second,
+			RequestJitter:    time.Duration(r.options.RequestJitter) * time.Millisecond,
+			RequestDelay:     r.requestDelay(template),
+			TLSProfile:       r.options.TLSProfile,
+			RandomizeHeaders: r.options.RandomizeHeaders,
+			HTTP2:            r.options.HTTP2,
 			CookieReuse:      value.CookieReuse,
-			ColoredOutput:    !r.options.NoColor,
-			Colorizer:        &r.colorizer,
-			Decolorizer:      r.decolorizer,
+			CookieJar:        r.cookieJar(template),
 			StopAtFirstMatch: r.options.StopAtFirstMatch,
 			PF:               r.pf,
 			Dialer:           &r.dialer,
+			MatcherPool:      r.matcherPool,
+			BypassForbidden:  r.options.BypassForbidden,
+			TraceFindings:    r.options.TraceFindings,
+			Screenshots:      r.screenshots,
+			XSSVerifier:      r.xssVerifier,
+			Seeds:            r.seeds,
+			TLS:              r.options.tlsOptions(),
+			Values:           r.sharedValues(template),
 		})
 	}
 
 	if err != nil {
-		p.Drop(request.(*requests.BulkHTTPRequest).GetRequestCount())
-		gologger.Warningf("Could not create http client: %s\n", err)
+		p.Drop(requestCount)
+		gologger.Warningf("Could not create %s client: %s\n", requestType(request), err)
 
 		return false
 	}
 
-	var globalresult atomicboolean.AtomBool
+	var globalresult, failed atomicboolean.AtomBool
 
-	wg := sizedwaitgroup.New(r.options.BulkSize)
+	var wg sync.WaitGroup
 
+	key := resumeKey(template, request)
+	index := -1
 	scanner := bufio.NewScanner(strings.NewReader(r.input))
 	for scanner.Scan() {
 		URL := scanner.Text()
-		wg.Add()
-		go func(URL string) {
+		index++
+		// skip targets the template is not routed to or completed before resuming
+		if r.resume.skips(key, index) || r.draining() || !r.routes.allows(URL, template.Info, r.probes.tech(URL), r.labels.of(URL)) || !r.verification.allows(template.ID, URL) || r.blocked.isBlocked(URL) || r.templateErrors.isDisabled(template.ID) {
+			p.Drop(requestCount)
+			continue
+		}
+		// hold back new targets while the findings can't be written fast enough
+		r.output.WaitReady()
+
+		wg.Add(1)
+		// dos templates are run on one target at a time
+		submit := r.networkPool.Submit
+		if template.DoS() {
+			submit = func(task func()) { task() }
+		}
+		index := index
+		submit(func() {
 			defer wg.Done()
+			defer r.recoverTemplate(template.ID, URL)
+
+			// the host or the template may have been blocked while waiting for a slot
+			if r.draining() || r.blocked.isBlocked(URL) || r.templateErrors.isDisabled(template.ID) {
+				p.Drop(requestCount)
+				return
+			}
 
 			var result *executer.Result
 
+			start := time.Now()
+
 			if httpExecuter != nil {
 				result = httpExecuter.ExecuteHTTP(p, URL)
 				globalresult.Or(result.GotResults)
@@ -106,49 +289,171 @@ func (r *Runner) processTemplateWithList(p progress.IProgress, template *templat
 				globalresult.Or(result.GotResults)
 			}
 
+			if headlessExecuter != nil {
+				result = headlessExecuter.ExecuteHeadless(p, URL)
+				globalresult.Or(result.GotResults)
+			}
+
+			if networkExecuter != nil {
+				result = networkExecuter.ExecuteNetwork(p, URL)
+				globalresult.Or(result.GotResults)
+			}
+
+			if sslExecuter != nil {
+				result = sslExecuter.ExecuteSSL(p, URL)
+				globalresult.Or(result.GotResults)
+			}
+
+			if whoisExecuter != nil {
+				result = whoisExecuter.ExecuteWhois(p, URL)
+				globalresult.Or(result.GotResults)
+			}
+
+			if websocketExecuter != nil {
+				result = websocketExecuter.ExecuteWebsocket(p, URL)
+				globalresult.Or(result.GotResults)
+			}
+
+			if fileExecuter != nil {
+				result = fileExecuter.ExecuteFile(p, URL)
+				globalresult.Or(result.GotResults)
+			}
+
+			if grpcExecuter != nil {
+				result = grpcExecuter.ExecuteGRPC(p, URL)
+				globalresult.Or(result.GotResults)
+			}
+
 			if result.Error != nil {
 				gologger.Warningf("[%s] Could not execute step: %s\n", r.colorizer.Colorizer.BrightBlue(template.ID), result.Error)
 			}
-		}(URL)
+			if result.GotResults {
+				r.blocked.report(URL, template.ID, template.Info["severity"])
+			}
+			r.templateErrors.report(template.ID, result.Error)
+			r.summary.templateDone(template.ID, template.Info["severity"], time.Since(start), result.GotResults)
+			r.verification.report(template.ID, URL, result.GotResults, result.Error)
+			if result.Error == nil {
+				r.resume.complete(key, index)
+			} else {
+				failed.Set(true)
+			}
+		})
 	}
 
 	wg.Wait()
+	if !failed.Get() && !r.draining() {
+		r.resume.finish(key)
+	}
 
 	// See if we got any results from the executers
 	return globalresult.Get()
 }
 
+// requestType returns the protocol name of a template request
+func requestType(request interface{}) string {
+	switch request.(type) {
+	case *requests.NetworkRequest:
+		return "network"
+	case *requests.SSLRequest:
+		return "ssl"
+	case *requests.WebsocketRequest:
+		return "websocket"
+	case *requests.GRPCRequest:
+		return "grpc"
+	}
+
+	return "http"
+}
+
 // ProcessWorkflowWithList coming from stdin or list of targets
 func (r *Runner) processWorkflowWithList(p progress.IProgress, workflow *workflows.Workflow) bool {
-	result := false
+	defer r.recoverTemplate(workflow.ID, "")
 
 	workflowTemplatesList, err := r.preloadWorkflowTemplates(p, workflow)
 	if err != nil {
 		gologger.Warningf("Could not preload templates for workflow %s: %s\n", workflow.ID, err)
 
-		return result
+		return false
 	}
 
 	logicBytes := []byte(workflow.Logic)
 
-	wg := sizedwaitgroup.New(r.options.BulkSize)
+	concurrency := workflow.Concurrency
+	if concurrency <= 0 {
+		concurrency = r.options.WorkflowConcurrency
+	}
+
+	// workflows with dos templates are run on one target at a time, one template at a time
+	submit := r.networkPool.Submit
+	if hasDoS(*workflowTemplatesList) {
+		concurrency = 1
+		submit = func(task func()) { task() }
+	}
+
+	// in verification mode a workflow only runs on the targets of its members findings
+	var memberIDs []string
+	for _, workflowTemplate := range *workflowTemplatesList {
+		for _, template := range workflowTemplate.Templates {
+			memberIDs = append(memberIDs, template.ID())
+		}
+	}
+
+	var result atomicboolean.AtomBool
 
+	var wg sync.WaitGroup
+
+	index := -1
 	scanner := bufio.NewScanner(strings.NewReader(r.input))
 	for scanner.Scan() {
 		targetURL := scanner.Text()
-		wg.Add()
+		index++
+		if r.resume.skips(workflow.ID, index) || r.draining() || !r.routes.allows(targetURL, workflow.Info, r.probes.tech(targetURL), r.labels.of(targetURL)) || !r.verification.allowsAny(memberIDs, targetURL) || r.blocked.isBlocked(targetURL) {
+			continue
+		}
+		r.output.WaitReady()
 
-		go func(targetURL string) {
+		wg.Add(1)
+
+		index := index
+		submit(func() {
 			defer wg.Done()
+			defer r.recoverTemplate(workflow.ID, targetURL)
+
+			start := time.Now()
 
 			script := tengo.NewScript(logicBytes)
 			script.SetImports(stdlib.GetModuleMap(stdlib.AllModuleNames()...))
 
 			variables := make(map[string]*workflows.NucleiVar)
 
+			// the templates of a workflow run share a trace so the findings show the whole chain
+			var trace *executer.Trace
+			if r.options.TraceFindings {
+				trace = executer.NewTrace()
+			}
+
+			ctx := context.Background()
+			if workflow.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(workflow.Timeout)*time.Second)
+				defer cancel()
+			}
+
 			for _, workflowTemplate := range *workflowTemplatesList {
 				name := workflowTemplate.Name
-				variable := &workflows.NucleiVar{Templates: workflowTemplate.Templates, URL: targetURL}
+				variable := &workflows.NucleiVar{
+					Templates:   workflowTemplate.Templates,
+					URL:         targetURL,
+					Concurrency: concurrency,
+					StepTimeout: time.Duration(workflow.StepTimeout) * time.Second,
+					OnFailure:   workflow.OnFailure,
+					Context:     ctx,
+					Trace:       trace,
+					Disabled:    r.templateErrors.isDisabled,
+					Report:      r.workflowReporter(targetURL),
+					Recovered:   r.reportPanic,
+				}
 				err := script.Add(name, variable)
 				if err != nil {
 					gologger.Errorf("Could not initialize script for workflow '%s': %s\n", workflow.ID, err)
@@ -158,23 +463,46 @@ func (r *Runner) processWorkflowWithList(p progress.IProgress, workflow *workflo
 				variables[name] = variable
 			}
 
-			_, err := script.RunContext(context.Background())
-			if err != nil {
+			// the steps running at the deadline fail with it, wrapped by the script
+			_, err := script.RunContext(ctx)
+			if errors.Is(err, context.DeadlineExceeded) {
+				gologger.Warningf("Workflow '%s' timed out after %ds on %s\n", workflow.ID, workflow.Timeout, targetURL)
+			} else if err != nil {
 				gologger.Errorf("Could not execute workflow '%s': %s\n", workflow.ID, err)
 			}
 
+			matched := false
 			for _, variable := range variables {
-				result = !variable.IsFalsy()
-				if result {
+				if !variable.IsFalsy() {
+					matched = true
 					break
 				}
 			}
-		}(targetURL)
+			result.Or(matched)
+			r.summary.templateDone(workflow.ID, workflow.Info["severity"], time.Since(start), matched)
+			r.resume.complete(workflow.ID, index)
+		})
 	}
 
 	wg.Wait()
+	if !r.draining() {
+		r.resume.finish(workflow.ID)
+	}
 
-	return result
+	return result.Get()
+}
+
+// workflowReporter returns the function recording the outcomes of the workflow
+// templates on a target, the findings blocking the host with the severity of
+// the template which matched
+func (r *Runner) workflowReporter(target string) func(string, string, *executer.Result) {
+	return func(templateID, templateSeverity string, result *executer.Result) {
+		r.templateErrors.report(templateID, result.Error)
+		r.verification.report(templateID, target, result.GotResults, result.Error)
+		if result.GotResults {
+			r.blocked.report(target, templateID, templateSeverity)
+		}
+	}
 }
 
 func (r *Runner) preloadWorkflowTemplates(p progress.IProgress, workflow *workflows.Workflow) (*[]workflowTemplates, error) {
@@ -195,61 +523,19 @@ func (r *Runner) preloadWorkflowTemplates(p progress.IProgress, workflow *workfl
 	for name, value := range workflow.Variables {
 		// Check if the template is an absolute path or relative path.
 		// If the path is absolute, use it. Otherwise,
-		if isRelative(value) {
-			newPath, err := r.resolvePath(value)
-			if err != nil {
-				newPath, err = resolvePathWithBaseFolder(filepath.Dir(workflow.GetPath()), value)
-				if err != nil {
-					return nil, err
-				}
-			}
-
-			value = newPath
+		value, err := r.resolveWorkflowPath(workflow.GetPath(), value)
+		if err != nil {
+			return nil, err
 		}
 
 		var wtlst []*workflows.Template
 
 		if strings.HasSuffix(value, ".yaml") {
-			t, err := templates.Parse(value)
+			template, err := r.workflowTemplate(p, workflow, jar, value)
 			if err != nil {
 				return nil, err
 			}
-
-			template := &workflows.Template{Progress: p}
-			if len(t.BulkRequestsHTTP) > 0 {
-				template.HTTPOptions = &executer.HTTPOptions{
-					TraceLog:      r.traceLog,
-					Debug:         r.options.Debug,
-					Writer:        r.output,
-					Template:      t,
-					Timeout:       r.options.Timeout,
-					Retries:       r.options.Retries,
-					ProxyURL:      r.options.ProxyURL,
-					ProxySocksURL: r.options.ProxySocksURL,
-					CustomHeaders: r.options.CustomHeaders,
-					JSON:          r.options.JSON,
-					JSONRequests:  r.options.JSONRequests,
-					CookieJar:     jar,
-					ColoredOutput: !r.options.NoColor,
-					Colorizer:     &r.colorizer,
-					Decolorizer:   r.decolorizer,
-					PF:            r.pf,
-				}
-			} else if len(t.RequestsDNS) > 0 {
-				template.DNSOptions = &executer.DNSOptions{
-					TraceLog:      r.traceLog,
-					Debug:         r.options.Debug,
-					Template:      t,
-					Writer:        r.output,
-					JSON:          r.options.JSON,
-					JSONRequests:  r.options.JSONRequests,
-					ColoredOutput: !r.options.NoColor,
-					Colorizer:     r.colorizer,
-					Decolorizer:   r.decolorizer,
-				}
-			}
-
-			if template.DNSOptions != nil || template.HTTPOptions != nil {
+			if template != nil {
 				wtlst = append(wtlst, template)
 			}
 		} else {
@@ -278,32 +564,16 @@ func (r *Runner) preloadWorkflowTemplates(p progress.IProgress, workflow *workfl
 				return nil, fmt.Errorf("no match found in the directory %s", value)
 			}
 
+			if r.options.Deterministic {
+				sort.Strings(matches)
+			}
+
 			for _, match := range matches {
-				t, err := templates.Parse(match)
+				template, err := r.workflowTemplate(p, workflow, jar, match)
 				if err != nil {
 					return nil, err
 				}
-				template := &workflows.Template{Progress: p}
-				if len(t.BulkRequestsHTTP) > 0 {
-					template.HTTPOptions = &executer.HTTPOptions{
-						Debug:         r.options.Debug,
-						Writer:        r.output,
-						Template:      t,
-						Timeout:       r.options.Timeout,
-						Retries:       r.options.Retries,
-						ProxyURL:      r.options.ProxyURL,
-						ProxySocksURL: r.options.ProxySocksURL,
-						CustomHeaders: r.options.CustomHeaders,
-						CookieJar:     jar,
-					}
-				} else if len(t.RequestsDNS) > 0 {
-					template.DNSOptions = &executer.DNSOptions{
-						Debug:    r.options.Debug,
-						Template: t,
-						Writer:   r.output,
-					}
-				}
-				if template.DNSOptions != nil || template.HTTPOptions != nil {
+				if template != nil {
 					wtlst = append(wtlst, template)
 				}
 			}
@@ -315,6 +585,67 @@ func (r *Runner) preloadWorkflowTemplates(p progress.IProgress, workflow *workfl
 	return &wflTemplatesList, nil
 }
 
+// workflowTemplate loads a template referenced by a workflow with the
+// options of its executer, nil if it has no http or dns requests or its
+// safety class isn't allowed.
+func (r *Runner) workflowTemplate(p progress.IProgress, workflow *workflows.Workflow, jar *cookiejar.Jar, file string) (*workflows.Template, error) {
+	t, err := r.parseTemplate(file)
+	if err != nil {
+		return nil, err
+	}
+	r.filterProtocols(t)
+	t.Info = r.setProvenance(t.Info, file)
+	if r.options.Offline && requiresExternalServices(t) {
+		return nil, fmt.Errorf("template %s requires external services which are unavailable in offline mode", t.ID)
+	}
+	// the excluded templates were reported before the scan
+	if !r.allowsSafety(t) {
+		return nil, nil
+	}
+
+	template := &workflows.Template{Progress: p}
+	if len(t.BulkRequestsHTTP) > 0 {
+		template.HTTPOptions = &executer.HTTPOptions{
+			CommonOptions:    r.commonOptions(t),
+			Timeout:          r.options.Timeout,
+			Retries:          r.options.Retries,
+			ProxyURL:         r.options.ProxyURL,
+			ProxySocksURL:    r.options.ProxySocksURL,
+			Proxies:          r.proxies,
+			Proxy:            templateProxy(workflow.Proxy, t),
+			CustomHeaders:    r.options.CustomHeaders,
+			CookieJar:        templateCookieJar(jar, t),
+			PF:               r.pf,
+			Dialer:           &r.dialer,
+			MatcherPool:      r.matcherPool,
+			BypassForbidden:  r.options.BypassForbidden,
+			TraceFindings:    r.options.TraceFindings,
+			Screenshots:      r.screenshots,
+			XSSVerifier:      r.xssVerifier,
+			Seeds:            r.seeds,
+			Interactsh:       r.interactsh,
+			Throttle:         r.throttle,
+			InteractshWait:   time.Duration(r.options.InteractshWait) * time.Second,
+			RequestJitter:    time.Duration(r.options.RequestJitter) * time.Millisecond,
+			RequestDelay:     r.requestDelay(t),
+			TLSProfile:       r.options.TLSProfile,
+			RandomizeHeaders: r.options.RandomizeHeaders,
+			HTTP2:            r.options.HTTP2,
+			TLS:              r.options.tlsOptions(),
+			Values:           newSharedValues(t),
+		}
+	} else if len(t.RequestsDNS) > 0 {
+		template.DNSOptions = &executer.DNSOptions{
+			CommonOptions: r.commonOptions(t),
+			Resolvers:     r.options.Resolvers,
+		}
+	} else {
+		return nil, nil
+	}
+
+	return template, nil
+}
+
 func resolvePathWithBaseFolder(baseFolder, templateName string) (string, error) {
 	templatePath := path.Join(baseFolder, templateName)
 	if _, err := os.Stat(templatePath); !os.IsNotExist(err) {
@@ -0,0 +1,22 @@
+package runner
+
+import (
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// filterProtocols removes the requests of the disabled protocols from a
+// template, returning false if the template has no requests left.
+func (r *Runner) filterProtocols(template *templates.Template) bool {
+	if r.options.NoDNS && len(template.RequestsDNS) > 0 {
+		gologger.Verbosef("Removing dns requests of %s\n", "protocols", template.ID)
+		template.RequestsDNS = nil
+	}
+
+	if r.options.NoHeadless && len(template.RequestsHeadless) > 0 {
+		gologger.Verbosef("Removing headless requests of %s\n", "protocols", template.ID)
+		template.RequestsHeadless = nil
+	}
+
+	return len(template.BulkRequestsHTTP)+len(template.RequestsDNS)+len(template.RequestsHeadless)+len(template.RequestsNetwork)+len(template.RequestsSSL)+len(template.RequestsWhois)+len(template.RequestsWebsocket)+len(template.RequestsFile)+len(template.RequestsGRPC) > 0
+}
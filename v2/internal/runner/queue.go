@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/queue"
+)
+
+// RunQueue runs nuclei as a worker scanning the targets received from a
+// message queue, in batches, until it is stopped or the connection to the
+// queue is lost. The findings are published to the queue in addition to
+// the outputs of the flags. nuclei service install -queue ... runs the
+// worker as a daemon, started again if the connection is lost.
+func RunQueue(options *Options) error {
+	q, err := queue.New(&queue.Options{
+		URL:      options.Queue,
+		Targets:  options.QueueTargets,
+		Findings: options.QueueFindings,
+		Group:    options.QueueGroup,
+	})
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	// the scans drain on the signals themselves, the worker exits once they are done
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	gologger.Infof("Waiting for targets on %s of %s\n", options.QueueTargets, options.Queue)
+
+	linger := time.Duration(options.QueueLinger) * time.Second
+	for {
+		batch, open := receiveBatch(q.Targets(), options.QueueBatchSize, linger, stop)
+		if len(batch) > 0 {
+			if err := scanBatch(options, batch, q); err != nil {
+				return err
+			}
+		}
+		if !open {
+			return q.Err()
+		}
+	}
+}
+
+// receiveBatch waits for a target and returns it with the ones received
+// within the linger time after it, up to the size of a batch. It returns
+// false once the targets are closed or the worker is stopped.
+func receiveBatch(targets <-chan string, size int, linger time.Duration, stop <-chan os.Signal) ([]string, bool) {
+	var batch []string
+
+	select {
+	case target, ok := <-targets:
+		if !ok {
+			return nil, false
+		}
+		batch = append(batch, target)
+	case <-stop:
+		return nil, false
+	}
+
+	timer := time.NewTimer(linger)
+	defer timer.Stop()
+
+	for len(batch) < size {
+		select {
+		case target, ok := <-targets:
+			if !ok {
+				return batch, false
+			}
+			batch = append(batch, target)
+		case <-timer.C:
+			return batch, true
+		case <-stop:
+			// the worker stops without starting a new scan
+			return nil, false
+		}
+	}
+
+	return batch, true
+}
+
+// scanBatch scans a batch of targets with the settings of the worker,
+// publishing the findings to the queue
+func scanBatch(options *Options, batch []string, q queue.Queue) error {
+	directory, err := ioutil.TempDir("", "nuclei-queue-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(directory)
+
+	targetsFile := filepath.Join(directory, "targets.txt")
+	if err := ioutil.WriteFile(targetsFile, []byte(strings.Join(batch, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+
+	batchOptions := *options
+	batchOptions.Queue = ""
+	batchOptions.Target, batchOptions.Stdin, batchOptions.Targets = "", false, targetsFile
+	batchOptions.Resume, batchOptions.UpdateTemplates = "", false
+
+	gologger.Infof("Scanning %d targets received from the queue\n", len(batch))
+
+	nucleiRunner, err := New(&batchOptions)
+	if err != nil {
+		return err
+	}
+	if nucleiRunner.writers == nil {
+		nucleiRunner.writers = &output.Writers{}
+	}
+	nucleiRunner.writers.Add(&queueWriter{queue: q})
+
+	nucleiRunner.RunEnumeration()
+	nucleiRunner.Close()
+
+	return nil
+}
+
+// queueWriter publishes the findings of a scan to the queue as json
+type queueWriter struct {
+	queue queue.Queue
+}
+
+// Write publishes a finding to the queue
+func (w *queueWriter) Write(event output.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return w.queue.Publish(data)
+}
+
+// Close does nothing, the queue outlives the scans
+func (w *queueWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,241 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+const (
+	// gitPrefix marks the template definitions cloned from a git repository,
+	// eg. git+https://git.acme.local/security/templates.git@v1.2#http
+	gitPrefix = "git+"
+	// etagFile stores the etag of a downloaded template next to it
+	etagFile = ".etag"
+	// remoteTrust is the trust level of the templates loaded from remote definitions
+	remoteTrust = "untrusted"
+)
+
+// errNoCachedCopy is returned for the remote definitions never downloaded before with -remote-cached
+var errNoCachedCopy = errors.New("no cached copy, run once without -remote-cached to download it")
+
+// isRemoteDefinition returns true if a template definition is an url or a git repository
+func isRemoteDefinition(definition string) bool {
+	return strings.HasPrefix(definition, gitPrefix) || strings.HasPrefix(definition, "https://") || strings.HasPrefix(definition, "http://")
+}
+
+// resolveRemote downloads the templates of a remote definition to the cache
+// directory and returns their local path, registered as an untrusted source.
+func (r *Runner) resolveRemote(definition string) (string, error) {
+	directory, err := r.remoteDirectory(definition)
+	if err != nil {
+		return "", err
+	}
+
+	var local string
+	if strings.HasPrefix(definition, gitPrefix) {
+		local, err = r.cloneRemote(strings.TrimPrefix(definition, gitPrefix), directory)
+	} else {
+		local, err = r.downloadRemote(definition, directory)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	r.sources.add(redactURL(definition), directory, remoteTrust)
+
+	return local, nil
+}
+
+// remoteDirectory returns the cache directory of a remote definition, the
+// same for all the refs of a repository so they share the clone.
+func (r *Runner) remoteDirectory(definition string) (string, error) {
+	base := r.options.RemoteTemplatesDir
+	if base == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(cache, "nuclei", "remote-templates")
+	}
+
+	repository, _, _ := splitGitDefinition(strings.TrimPrefix(definition, gitPrefix))
+	if !strings.HasPrefix(definition, gitPrefix) {
+		repository = definition
+	}
+	hash := sha256.Sum256([]byte(repository))
+
+	directory := filepath.Join(base, hex.EncodeToString(hash[:8]))
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	return directory, nil
+}
+
+// downloadRemote downloads a template file, revalidating the cached copy
+// with its etag. The cached copy is used if the server can't be reached,
+// or as is with -remote-cached.
+func (r *Runner) downloadRemote(definition, directory string) (string, error) {
+	parsed, err := url.Parse(definition)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(parsed.Path)
+	if !strings.HasSuffix(name, ".yaml") {
+		return "", fmt.Errorf("remote templates must be yaml files or git repositories")
+	}
+	local := filepath.Join(directory, name)
+
+	if r.options.RemoteCached {
+		if _, err := os.Stat(local); err != nil {
+			return "", errNoCachedCopy
+		}
+		return local, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, definition, nil)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(local); err == nil {
+		if etag, err := ioutil.ReadFile(filepath.Join(directory, etagFile)); err == nil {
+			req.Header.Set("If-None-Match", string(etag))
+		}
+	}
+
+	resp, err := r.updateClient().Do(req)
+	if err != nil {
+		if _, statErr := os.Stat(local); statErr == nil {
+			gologger.Warningf("Could not download '%s', using the cached copy: %s\n", redactURL(definition), err)
+			return local, nil
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		gologger.Verbosef("Using cached copy of %s\n", "remote", redactURL(definition))
+		return local, nil
+	case http.StatusOK:
+	default:
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	file, err := os.Create(local)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(local)
+		return "", err
+	}
+	file.Close()
+
+	etag := filepath.Join(directory, etagFile)
+	if value := resp.Header.Get("ETag"); value != "" {
+		if err := ioutil.WriteFile(etag, []byte(value), 0644); err != nil {
+			return "", err
+		}
+	} else {
+		os.Remove(etag)
+	}
+
+	return local, nil
+}
+
+// cloneRemote clones or fetches a git repository and checks out the pinned
+// ref (branch, tag or commit), or the default branch if none is given. The
+// existing clone isn't fetched with -remote-cached.
+func (r *Runner) cloneRemote(definition, directory string) (string, error) {
+	repository, ref, subdirectory := splitGitDefinition(definition)
+	clone := filepath.Join(directory, "repository")
+
+	// a ref starting with a dash would be read as an option of the checkout
+	if strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid git ref '%s'", ref)
+	}
+
+	if _, err := os.Stat(filepath.Join(clone, ".git")); err == nil {
+		if !r.options.RemoteCached {
+			if err := runGit(clone, "fetch", "--quiet", "--tags", "--force", "origin"); err != nil {
+				return "", err
+			}
+		}
+	} else if r.options.RemoteCached {
+		return "", errNoCachedCopy
+	} else if err := runGit(directory, "clone", "--quiet", "--", repository, clone); err != nil {
+		return "", err
+	}
+
+	if ref == "" {
+		ref = "origin/HEAD"
+	}
+	// branches are checked out as fetched, tags and commits as they are
+	if err := runGit(clone, "checkout", "--quiet", "--detach", "origin/"+ref); err != nil {
+		if err := runGit(clone, "checkout", "--quiet", "--detach", ref); err != nil {
+			return "", err
+		}
+	}
+
+	if commit, err := exec.Command("git", "-C", clone, "rev-parse", "HEAD").Output(); err == nil {
+		gologger.Verbosef("Using %s at commit %s\n", "remote", redactURL(repository), strings.TrimSpace(string(commit)))
+	}
+
+	return filepath.Join(clone, filepath.FromSlash(subdirectory)), nil
+}
+
+// splitGitDefinition splits a git definition in its repository, ref and templates subdirectory
+func splitGitDefinition(definition string) (repository, ref, subdirectory string) {
+	repository = definition
+	if i := strings.LastIndex(repository, "#"); i >= 0 {
+		repository, subdirectory = repository[:i], repository[i+1:]
+	}
+	// the ref comes after the repository name, an @ before it is part of the host
+	if i := strings.LastIndex(repository, "@"); i > strings.LastIndex(repository, "/") {
+		repository, ref = repository[:i], repository[i+1:]
+	}
+
+	return repository, ref, subdirectory
+}
+
+// runGit runs a git command in a directory
+func runGit(directory string, args ...string) error {
+	cmd := exec.Command("git", append([]string{"-C", directory}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// redactURL removes the credentials of an url for the logs and the findings
+func redactURL(value string) string {
+	prefix := ""
+	if strings.HasPrefix(value, gitPrefix) {
+		prefix, value = gitPrefix, strings.TrimPrefix(value, gitPrefix)
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.User == nil {
+		return prefix + value
+	}
+	parsed.User = nil
+
+	return prefix + parsed.String()
+}
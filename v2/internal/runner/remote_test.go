@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineRemoteTemplates(t *testing.T) {
+	options := &Options{
+		Templates:         multiStringFlag{"https://templates.acme.local/cve.yaml"},
+		Target:            "http://127.0.0.1",
+		UnsignedTemplates: "warn",
+		Offline:           true,
+	}
+
+	err := options.validateOptions()
+	require.NotNil(t, err, "remote templates were allowed in offline mode")
+	require.True(t, strings.Contains(err.Error(), "offline mode"), "unexpected error: %s", err)
+
+	options.RemoteCached = true
+	if err := options.validateOptions(); err != nil {
+		require.False(t, strings.Contains(err.Error(), "remote templates"), "cached remote templates were refused in offline mode: %s", err)
+	}
+}
+
+func TestRemoteCachedCopy(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	directory, err := ioutil.TempDir("", "nuclei-remote-")
+	require.Nil(t, err, "could not create cache directory")
+	defer os.RemoveAll(directory)
+
+	r := &Runner{options: &Options{RemoteTemplatesDir: directory, RemoteCached: true}, sources: &templateSources{}}
+	definition := server.URL + "/cve.yaml"
+
+	_, err = r.resolveRemote(definition)
+	require.Equal(t, errNoCachedCopy, err, "a missing cached copy was not reported")
+
+	cache, err := r.remoteDirectory(definition)
+	require.Nil(t, err, "could not get the cache directory")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(cache, "cve.yaml"), []byte("id: cve\n"), 0644), "could not write the cached copy")
+
+	local, err := r.resolveRemote(definition)
+	require.Nil(t, err, "the cached copy was not used")
+	require.Equal(t, filepath.Join(cache, "cve.yaml"), local, "unexpected cached copy")
+	require.Zero(t, requests, "the remote template was downloaded with -remote-cached")
+
+	_, err = r.cloneRemote("https://git.acme.local/templates.git@--upload-pack=touch", directory)
+	require.NotNil(t, err, "a ref read as a git option was accepted")
+	require.True(t, strings.Contains(err.Error(), "invalid git ref"), "unexpected error: %s", err)
+}
@@ -0,0 +1,248 @@
+package runner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/logrusorgru/aurora"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/httpx/common/cache"
+	"github.com/projectdiscovery/nuclei/v2/internal/tracelog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// replayFinding contains the fields of a json output finding required for replaying it
+type replayFinding struct {
+	Matched  string `json:"matched"`
+	Target   string `json:"target"`
+	Template string `json:"template"`
+	Type     string `json:"type"`
+	Request  string `json:"request"`
+	// RequestBlock is the position of the http request block that produced the finding
+	RequestBlock *int `json:"request_block"`
+}
+
+// Replay re-sends the requests stored in a json output file and re-evaluates
+// the matchers of the templates that produced the findings.
+//
+// The findings must have been written with the -json-requests flag. Only the
+// request block that produced a finding is replayed, the findings depending
+// on the previous requests are replayed by sending the whole sequence again.
+func Replay(options *Options) error {
+	r := &Runner{options: options}
+	r.colorizer = *colorizer.NewNucleiColorizer(aurora.NewAurora(!options.NoColor))
+
+	if config, err := readConfiguration(); err == nil {
+		r.templatesConfig = config
+	}
+
+	keys, err := readTrustedKeys(options.TrustedKeys)
+	if err != nil {
+		return err
+	}
+	r.trustedKeys = keys
+
+	file, err := os.Open(options.Replay)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	dialer, err := newDialer(options, cache.DefaultOptions)
+	if err != nil {
+		return err
+	}
+
+	r.proxies, err = loadProxies(options)
+	if err != nil {
+		return err
+	}
+
+	lookup := newTemplateLookup(r)
+	reproduced, errored, total := 0, 0, 0
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxFindingSize)
+
+	for scanner.Scan() {
+		finding := &replayFinding{}
+		if err := jsoniter.Unmarshal(scanner.Bytes(), finding); err != nil {
+			gologger.Warningf("Could not unmarshal finding: %s\n", err)
+			continue
+		}
+
+		if finding.Type != "http" {
+			gologger.Warningf("[%s] Skipping finding for %s without a stored http request\n", finding.Template, finding.Matched)
+			continue
+		}
+
+		template, err := lookup.find(finding.Template)
+		if err != nil {
+			gologger.Warningf("[%s] Could not find template: %s\n", finding.Template, err)
+			continue
+		}
+
+		block, err := findingBlock(template, finding)
+		if err != nil {
+			gologger.Warningf("[%s] Skipping finding for %s: %s\n", finding.Template, finding.Matched, err)
+			continue
+		}
+
+		chained := executer.ChainedBlock(template, block)
+		if !chained && finding.Request == "" {
+			gologger.Warningf("[%s] Skipping finding for %s without a stored http request\n", finding.Template, finding.Matched)
+			continue
+		}
+
+		total++
+
+		httpOptions := &executer.HTTPOptions{
+			CommonOptions:   executer.CommonOptions{Template: template, TraceLog: &tracelog.NoopLogger{}},
+			BulkHTTPRequest: template.BulkRequestsHTTP[block],
+			Timeout:         options.Timeout,
+			Retries:         options.Retries,
+			ProxyURL:        options.ProxyURL,
+			ProxySocksURL:   options.ProxySocksURL,
+			Proxies:         r.proxies,
+			Proxy:           template.Proxy,
+			CookieJar:       templateCookieJar(nil, template),
+			Dialer:          &dialer,
+			HTTP2:           options.HTTP2,
+			TLS:             options.tlsOptions(),
+		}
+
+		var matched bool
+		if chained {
+			target := finding.Target
+			if target == "" {
+				target = finding.Matched
+			}
+			matched, err = executer.ReplayHTTPChain(httpOptions, block, target)
+		} else {
+			matched, err = executer.ReplayHTTP(httpOptions, finding.Request, finding.Matched)
+		}
+		failed := err != nil
+		if failed {
+			gologger.Warningf("[%s] Could not replay request to %s: %s\n", finding.Template, finding.Matched, err)
+		}
+
+		var status string
+		switch {
+		case matched:
+			status = r.colorizer.Colorizer.Red("reproducible").String()
+			reproduced++
+		case failed:
+			status = r.colorizer.Colorizer.Yellow("error").String()
+			errored++
+		default:
+			status = r.colorizer.Colorizer.Green("fixed").String()
+		}
+
+		gologger.Silentf("[%s] [%s] %s\n", r.colorizer.Colorizer.BrightBlue(finding.Template).String(), status, finding.Matched)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	gologger.Infof("Replayed %d findings, %d still reproducible, %d could not be replayed\n", total, reproduced, errored)
+
+	return nil
+}
+
+// findingBlock returns the http request block of a template that produced a
+// finding, the findings written before the blocks were recorded can only be
+// replayed on templates with a single one.
+func findingBlock(template *templates.Template, finding *replayFinding) (int, error) {
+	if finding.RequestBlock == nil {
+		if len(template.BulkRequestsHTTP) != 1 {
+			return 0, errors.New("the finding does not record its request block")
+		}
+		return 0, nil
+	}
+
+	block := *finding.RequestBlock
+	if block < 0 || block >= len(template.BulkRequestsHTTP) {
+		return 0, fmt.Errorf("no request block %d in the template", block)
+	}
+
+	return block, nil
+}
+
+// maxFindingSize is the maximum size of a single json output line
+const maxFindingSize = 10 * 1024 * 1024
+
+// templateLookup resolves template IDs to parsed templates
+type templateLookup struct {
+	paths []string
+	// parse parses a template file verifying its signature
+	parse  func(file string) (*templates.Template, error)
+	parsed map[string]*templates.Template
+	// scanned is true once all the templates have been parsed
+	scanned bool
+}
+
+// newTemplateLookup creates a lookup over the user provided templates,
+// or the installed nuclei-templates if none were provided.
+func newTemplateLookup(r *Runner) *templateLookup {
+	definitions := r.options.Templates
+	if len(definitions) == 0 && r.templatesConfig != nil {
+		definitions = []string{r.templatesConfig.TemplatesDirectory}
+	}
+
+	return &templateLookup{
+		paths:  r.getTemplatesFor(definitions),
+		parse:  r.parseTemplate,
+		parsed: make(map[string]*templates.Template),
+	}
+}
+
+// find returns the template with the given ID. Templates named after their
+// ID are tried first, then all the templates are parsed once.
+func (t *templateLookup) find(id string) (*templates.Template, error) {
+	if template, ok := t.parsed[id]; ok {
+		return template, nil
+	}
+
+	// the error of the template named after the ID is kept, eg. a rejected signature
+	var named error
+	for _, path := range t.paths {
+		if filepath.Base(path) != id+".yaml" {
+			continue
+		}
+
+		template, err := t.parse(path)
+		if err != nil {
+			named = err
+			continue
+		}
+		if template.ID == id {
+			t.parsed[id] = template
+			return template, nil
+		}
+	}
+
+	if !t.scanned {
+		for _, path := range t.paths {
+			if template, err := t.parse(path); err == nil {
+				t.parsed[template.ID] = template
+			}
+		}
+		t.scanned = true
+	}
+
+	if template, ok := t.parsed[id]; ok {
+		return template, nil
+	}
+	if named != nil {
+		return nil, named
+	}
+
+	return nil, fmt.Errorf("no template with id %s", id)
+}
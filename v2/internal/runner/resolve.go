@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/projectdiscovery/httpx/common/cache"
+)
+
+// readStaticHosts returns the addresses of the hosts mapped by a hosts file
+// and the -resolve host:ip flags, the flags overriding the file.
+func readStaticHosts(hostsFile string, resolve []string) (map[string]string, error) {
+	hosts := make(map[string]string)
+
+	if hostsFile != "" {
+		file, err := os.Open(hostsFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if comment := strings.Index(line, "#"); comment != -1 {
+				line = line[:comment]
+			}
+
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			if net.ParseIP(fields[0]) == nil {
+				return nil, fmt.Errorf("invalid address %s in %s", fields[0], hostsFile)
+			}
+			for _, host := range fields[1:] {
+				hosts[normalizeHost(host)] = fields[0]
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mapping := range resolve {
+		// the address may be an ipv6 one, the host is before the first colon
+		separator := strings.Index(mapping, ":")
+		if separator == -1 {
+			return nil, fmt.Errorf("invalid mapping %s, expected host:ip", mapping)
+		}
+
+		host, ip := mapping[:separator], strings.Trim(mapping[separator+1:], "[]")
+		if host == "" || net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("invalid mapping %s, expected host:ip", mapping)
+		}
+		hosts[normalizeHost(host)] = ip
+	}
+
+	return hosts, nil
+}
+
+// staticDialer returns a dialer connecting to the static address of the
+// mapped hosts, the other ones being resolved by dialer. The requests dialed
+// by the browser or written on raw connections resolve the hosts themselves.
+func staticDialer(dialer cache.DialerFunc, hosts map[string]string) cache.DialerFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(address); err == nil {
+			if ip, ok := hosts[normalizeHost(host)]; ok {
+				address = net.JoinHostPort(ip, port)
+			}
+		}
+
+		return dialer(ctx, network, address)
+	}
+}
+
+// newDialer creates the dialer of the runner, consulting the static hosts
+// of the options before dns and connecting from the source ports if set.
+func newDialer(options *Options, dialerOptions cache.Options) (cache.DialerFunc, error) {
+	var dialer cache.DialerFunc
+	if options.SourcePorts != "" {
+		ports, err := parsePortRange(options.SourcePorts)
+		if err != nil {
+			return nil, err
+		}
+		dnsCache, err := cache.New(dialerOptions)
+		if err != nil {
+			return nil, err
+		}
+		dialer = sourcePortDialer(dnsCache, ports)
+	} else {
+		var err error
+		if dialer, err = cache.NewDialer(dialerOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	hosts, err := readStaticHosts(options.HostsFile, options.Resolve)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return dialer, nil
+	}
+
+	return staticDialer(dialer, hosts), nil
+}
@@ -0,0 +1,254 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// checkpointInterval is the time between the saves of the resume file
+const checkpointInterval = 10 * time.Second
+
+// resumeState tracks the requests of the templates completed on each target and
+// periodically saves them, so that an interrupted scan can continue where it
+// stopped. The targets in flight when it stopped are scanned again.
+//
+// The targets are identified by their index in the scanned list, which is
+// saved along as probing and crawling may expand the supplied targets
+// differently on each run.
+type resumeState struct {
+	path  string
+	input string
+
+	mutex sync.Mutex
+	// targets is the scanned list of targets, empty until known
+	targets string
+	done    map[string]struct{}
+	partial map[string]map[int]struct{}
+	changed bool
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// resumeFile is the format of the resume file
+type resumeFile struct {
+	// Input is the hash of the supplied targets, a resume file is only used for the same targets
+	Input string `json:"input"`
+	// Targets is the scanned list of targets the indexes refer to, after probing and crawling
+	Targets []string `json:"targets"`
+	// Done are the template requests completed on all the targets
+	Done []string `json:"done"`
+	// Partial are the indexes of the targets completed by the other template requests
+	Partial map[string][]int `json:"partial"`
+}
+
+// newResumeState loads the resume file if it exists for the same supplied targets,
+// before they are expanded. The progress is saved once the state is started.
+func newResumeState(path, input string) (*resumeState, error) {
+	hash := sha256.Sum256([]byte(input))
+	state := &resumeState{
+		path:    path,
+		input:   hex.EncodeToString(hash[:]),
+		done:    make(map[string]struct{}),
+		partial: make(map[string]map[int]struct{}),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		saved := &resumeFile{}
+		if err := json.Unmarshal(data, saved); err != nil {
+			return nil, fmt.Errorf("could not read resume file: %s", err)
+		}
+
+		if saved.Input != state.input {
+			gologger.Warningf("The targets changed since the resume file %s was written, starting over\n", path)
+		} else {
+			if len(saved.Targets) > 0 {
+				state.targets = strings.Join(saved.Targets, "\n") + "\n"
+			}
+			for _, key := range saved.Done {
+				state.done[key] = struct{}{}
+			}
+			for key, indexes := range saved.Partial {
+				state.partial[key] = make(map[int]struct{}, len(indexes))
+				for _, index := range indexes {
+					state.partial[key][index] = struct{}{}
+				}
+			}
+			gologger.Infof("Resuming the scan from %s (%d template requests done, %d partially)\n", path, len(state.done), len(state.partial))
+		}
+	}
+
+	return state, nil
+}
+
+// savedTargets returns the scanned list of targets of the resumed scan, empty
+// if the scan is started over
+func (s *resumeState) savedTargets() string {
+	if s == nil {
+		return ""
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.targets
+}
+
+// start records the scanned list of targets and starts saving the progress periodically
+func (s *resumeState) start(targets string) {
+	s.mutex.Lock()
+	s.targets = targets
+	s.changed = true
+	s.mutex.Unlock()
+
+	go s.checkpoint()
+}
+
+// checkpoint saves the progress periodically until the state is closed
+func (s *resumeState) checkpoint() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.save(); err != nil {
+				gologger.Warningf("Could not save resume file: %s\n", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// skips returns true if a template request already completed on a target
+func (s *resumeState) skips(key string, index int) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.done[key]; ok {
+		return true
+	}
+	_, ok := s.partial[key][index]
+	return ok
+}
+
+// complete records a template request completed on a target
+func (s *resumeState) complete(key string, index int) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.partial[key] == nil {
+		s.partial[key] = make(map[int]struct{})
+	}
+	s.partial[key][index] = struct{}{}
+	s.changed = true
+}
+
+// finish records a template request completed on all the targets
+func (s *resumeState) finish(key string) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.done[key] = struct{}{}
+	delete(s.partial, key)
+	s.changed = true
+}
+
+// save writes the progress to the resume file if it changed
+func (s *resumeState) save() error {
+	s.mutex.Lock()
+	if !s.changed {
+		s.mutex.Unlock()
+		return nil
+	}
+
+	saved := &resumeFile{Input: s.input, Targets: strings.Split(strings.TrimSpace(s.targets), "\n"), Done: make([]string, 0, len(s.done)), Partial: make(map[string][]int, len(s.partial))}
+	for key := range s.done {
+		saved.Done = append(saved.Done, key)
+	}
+	sort.Strings(saved.Done)
+	for key, indexes := range s.partial {
+		for index := range indexes {
+			saved.Partial[key] = append(saved.Partial[key], index)
+		}
+		sort.Ints(saved.Partial[key])
+	}
+	s.changed = false
+	s.mutex.Unlock()
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+
+	// the file is replaced at once so that a crash can't leave it truncated
+	temp := s.path + ".tmp"
+	if err := ioutil.WriteFile(temp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temp, s.path)
+}
+
+// close stops the checkpoints, removing the resume file if the scan
+// completed or saving the progress otherwise.
+func (s *resumeState) close(completed bool) {
+	if s == nil {
+		return
+	}
+
+	close(s.stop)
+	<-s.stopped
+
+	if completed {
+		os.Remove(s.path)
+		return
+	}
+	if err := s.save(); err != nil {
+		gologger.Warningf("Could not save resume file: %s\n", err)
+		return
+	}
+	gologger.Infof("The scan can be resumed with -resume %s\n", s.path)
+}
+
+// resumeKey identifies a request of a template by its position, stable
+// across runs as long as the template isn't changed.
+func resumeKey(template *templates.Template, request interface{}) string {
+	for _, candidate := range template.Requests() {
+		if candidate.Request == request {
+			return fmt.Sprintf("%s/%s/%d", template.ID, candidate.Protocol, candidate.Index)
+		}
+	}
+
+	return template.ID
+}
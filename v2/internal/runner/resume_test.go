@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeExpandedTargets(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-resume-")
+	require.Nil(t, err, "could not create directory")
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "resume.json")
+	supplied := "https://acme.local\n"
+	// the crawled endpoints are appended to the supplied targets
+	expanded := "https://acme.local\nhttps://acme.local/login\n"
+
+	state, err := newResumeState(path, supplied)
+	require.Nil(t, err, "could not create resume state")
+	require.Empty(t, state.savedTargets(), "a new scan has saved targets")
+	state.start(expanded)
+	state.complete("exposed-logs/http/0", 1)
+	state.close(false)
+
+	resumed, err := newResumeState(path, supplied)
+	require.Nil(t, err, "could not read resume state")
+	require.Equal(t, expanded, resumed.savedTargets(), "the expanded targets were not saved")
+	require.True(t, resumed.skips("exposed-logs/http/0", 1), "the completed target was not skipped")
+	require.False(t, resumed.skips("exposed-logs/http/0", 0), "a pending target was skipped")
+
+	changed, err := newResumeState(path, "https://other.local\n")
+	require.Nil(t, err, "could not read resume state")
+	require.Empty(t, changed.savedTargets(), "the targets of another scan were resumed")
+	require.False(t, changed.skips("exposed-logs/http/0", 1), "the progress of another scan was resumed")
+}
@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// targetRoutes contains the rules used to select the templates
+// which are allowed to run against a target.
+type targetRoutes struct {
+	Routes []*targetRoute `yaml:"routes"`
+}
+
+// targetRoute maps a set of hostname patterns to template tag filters.
+type targetRoute struct {
+	// Hosts contains glob patterns matched against the target hostname (eg. *.internal.corp).
+	// Empty matches all the hosts.
+	Hosts []string `yaml:"hosts,omitempty"`
+	// Labels contains the input labels of the targets, the rule only matches targets having one of them.
+	Labels []string `yaml:"labels,omitempty"`
+	// Tags contains the template tags allowed on matching targets. Empty allows all tags.
+	Tags []string `yaml:"tags,omitempty"`
+	// ExcludeTags contains the template tags never run on matching targets.
+	ExcludeTags []string `yaml:"exclude-tags,omitempty"`
+	// MinTrust is the lowest trust level of the template sources allowed on matching targets.
+	MinTrust string `yaml:"min-trust,omitempty"`
+	// Tech contains technologies found by the probe, the rule only matches targets running one of them.
+	Tech []string `yaml:"tech,omitempty"`
+}
+
+// readTargetRoutes reads the target routing rules from a yaml file.
+func readTargetRoutes(file string) (*targetRoutes, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	routes := &targetRoutes{}
+	if err := yaml.NewDecoder(f).Decode(routes); err != nil {
+		return nil, err
+	}
+
+	for _, route := range routes.Routes {
+		if _, ok := trustLevels[route.MinTrust]; route.MinTrust != "" && !ok {
+			return nil, fmt.Errorf("invalid min-trust level '%s'", route.MinTrust)
+		}
+	}
+
+	return routes, nil
+}
+
+// allows returns true if a template with the provided info can be run on the target.
+//
+// Rules are evaluated in order and the first rule matching the target hostname,
+// labels and technologies decides. Targets which don't match any rule are
+// allowed all the templates.
+func (t *targetRoutes) allows(target string, info map[string]string, tech, labels []string) bool {
+	if t == nil {
+		return true
+	}
+
+	hostname := targetHostname(target)
+
+	for _, route := range t.Routes {
+		if !route.matchesHost(hostname) || !route.matchesLabels(labels) || !route.matchesTech(tech) {
+			continue
+		}
+
+		if route.MinTrust != "" && trustLevels[info["trust"]] < trustLevels[route.MinTrust] {
+			return false
+		}
+
+		tags := templateTags(info)
+		for _, tag := range route.ExcludeTags {
+			if _, ok := tags[strings.ToLower(tag)]; ok {
+				return false
+			}
+		}
+
+		if len(route.Tags) == 0 {
+			return true
+		}
+
+		for _, tag := range route.Tags {
+			if _, ok := tags[strings.ToLower(tag)]; ok {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// matchesHost returns true if the route has no patterns or one of them matches the hostname
+func (t *targetRoute) matchesHost(hostname string) bool {
+	if len(t.Hosts) == 0 {
+		return true
+	}
+
+	for _, pattern := range t.Hosts {
+		if matched, err := path.Match(strings.ToLower(pattern), hostname); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesLabels returns true if the route has no labels or the target has one of them
+func (t *targetRoute) matchesLabels(labels []string) bool {
+	if len(t.Labels) == 0 {
+		return true
+	}
+
+	for _, want := range t.Labels {
+		for _, label := range labels {
+			if strings.EqualFold(want, label) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchesTech returns true if the route has no technologies or one of them was found on the target
+func (t *targetRoute) matchesTech(tech []string) bool {
+	if len(t.Tech) == 0 {
+		return true
+	}
+
+	for _, want := range t.Tech {
+		for _, found := range tech {
+			if strings.EqualFold(want, found) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// targetLabels contains the labels given to the input targets by hostname,
+// so that they still apply once the targets are probed or crawled
+type targetLabels map[string][]string
+
+// add gives labels to the host of a target
+func (l targetLabels) add(target string, labels []string) {
+	hostname := targetHostname(target)
+
+	for _, label := range labels {
+		known := false
+		for _, existing := range l[hostname] {
+			if existing == label {
+				known = true
+				break
+			}
+		}
+		if !known {
+			l[hostname] = append(l[hostname], label)
+		}
+	}
+}
+
+// of returns the labels given to the host of a target
+func (l targetLabels) of(target string) []string {
+	return l[targetHostname(target)]
+}
+
+// templateTags returns the lowercased set of tags from template info
+func templateTags(info map[string]string) map[string]struct{} {
+	tags := make(map[string]struct{})
+
+	for _, tag := range strings.Split(info["tags"], ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags[strings.ToLower(tag)] = struct{}{}
+		}
+	}
+
+	return tags
+}
+
+// targetHostname returns the lowercased hostname of an input target
+// which can either be an URL, a host:port pair or a plain domain.
+func targetHostname(target string) string {
+	hostname := target
+
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil {
+			hostname = u.Host
+		}
+	} else if i := strings.IndexByte(hostname, '/'); i != -1 {
+		hostname = hostname[:i]
+	}
+
+	if host, _, err := net.SplitHostPort(hostname); err == nil {
+		hostname = host
+	}
+
+	return strings.ToLower(hostname)
+}
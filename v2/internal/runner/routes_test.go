@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInputLine(t *testing.T) {
+	target, labels := parseInputLine("  label=Internal,prod label=pci https://acme.local/app ")
+	require.Equal(t, "https://acme.local/app", target, "wrong target")
+	require.Equal(t, []string{"internal", "prod", "pci"}, labels, "wrong labels")
+
+	target, labels = parseInputLine("https://acme.local")
+	require.Equal(t, "https://acme.local", target, "wrong target without labels")
+	require.Empty(t, labels, "labels found on a plain target")
+
+	target, labels = parseInputLine("label=internal")
+	require.Equal(t, "label=internal", target, "a line without a target was dropped")
+	require.Empty(t, labels, "labels found without a target")
+}
+
+func TestTargetRoutesLabels(t *testing.T) {
+	routes := &targetRoutes{Routes: []*targetRoute{
+		{Labels: []string{"internal"}, ExcludeTags: []string{"intrusive"}},
+		{Hosts: []string{"*.acme.local"}, Labels: []string{"prod"}, Tags: []string{"cve"}},
+	}}
+
+	labels := make(targetLabels)
+	labels.add("https://app.acme.local/login", []string{"internal"})
+	labels.add("app.acme.local", []string{"internal"})
+	labels.add("https://shop.acme.local", []string{"prod"})
+	require.Equal(t, []string{"internal"}, labels.of("https://app.acme.local:8443"), "the labels are not kept by host")
+
+	intrusive := map[string]string{"tags": "intrusive,cve"}
+	exposure := map[string]string{"tags": "exposure"}
+
+	require.False(t, routes.allows("https://app.acme.local", intrusive, nil, labels.of("https://app.acme.local")), "the label rule was not applied")
+	require.True(t, routes.allows("https://app.acme.local", exposure, nil, labels.of("https://app.acme.local")), "the label rule excluded an allowed template")
+	require.False(t, routes.allows("https://shop.acme.local", exposure, nil, labels.of("https://shop.acme.local")), "the host and label rule was not applied")
+	require.True(t, routes.allows("https://blog.acme.local", exposure, nil, labels.of("https://blog.acme.local")), "a rule matched a target without its labels")
+}
@@ -2,12 +2,18 @@ package runner
 
 import (
 	"bufio"
+	"crypto/ed25519"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/logrusorgru/aurora"
 	"github.com/pkg/errors"
@@ -19,13 +25,28 @@ import (
 	"github.com/projectdiscovery/nuclei/v2/pkg/atomicboolean"
 	"github.com/projectdiscovery/nuclei/v2/pkg/collaborator"
 	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/crawler"
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
 	"github.com/projectdiscovery/nuclei/v2/pkg/globalratelimiter"
+	"github.com/projectdiscovery/nuclei/v2/pkg/headless"
+	"github.com/projectdiscovery/nuclei/v2/pkg/interactsh"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/projectfile"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting"
+	"github.com/projectdiscovery/nuclei/v2/pkg/saferegex"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
 	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workpool"
 	"github.com/remeh/sizedwaitgroup"
 )
 
+const (
+	// deterministicSeed is the random seed used by deterministic runs
+	deterministicSeed = 1
+	// headlessTimeoutFactor multiplies the request timeout for the browser to load and use a page
+	headlessTimeoutFactor = 3
+)
+
 // Runner is a client for running the enumeration process.
 type Runner struct {
 	input      string
@@ -52,6 +73,105 @@ type Runner struct {
 
 	// http dialer
 	dialer cache.DialerFunc
+
+	// routes selects the templates allowed per target
+	routes *targetRoutes
+
+	// sources contains the catalogue sources with their trust level
+	sources *templateSources
+
+	// console prints the findings, grouped at the end of the scan if requested
+	console *executer.Console
+
+	// summary collects the statistics of the scan
+	summary *scanSummary
+
+	// scan identifies the scan in the json findings
+	scan *executer.Scan
+
+	// limits caps the number of findings written
+	limits *executer.FindingLimits
+
+	// inventory writes the assets detected by the templates with product metadata
+	inventory *executer.Inventory
+	// writers are the output writers the findings are sent to besides the output file
+	writers *output.Writers
+
+	// interactsh provides the interactsh urls of the templates with blind payloads
+	interactsh *interactsh.Client
+
+	// proxies rotates the http requests over the proxy list if set
+	proxies *executer.ProxyRotator
+	// cluster shares the responses of the identical http requests of the templates
+	cluster *executer.Cluster
+	// throttle limits the global rate and the per host concurrency of the http requests
+	throttle *executer.Throttle
+	// values shares the extracted values between the http requests of the templates by id
+	values sync.Map
+	// jars shares the cookies between the http requests of the templates reusing them by id
+	jars sync.Map
+	// resume tracks the progress of the scan to continue it after an interruption
+	resume *resumeState
+	// loadReport records the templates failing to load if set
+	loadReport *loadReport
+	// trustedKeys are the public keys the templates must be signed with if set
+	trustedKeys []ed25519.PublicKey
+
+	// probes contains the title, status and technologies of the probed targets
+	probes *probeResults
+	// labels contains the labels given to the targets in the input
+	labels targetLabels
+
+	// blocked contains the hosts skipped after a blocking finding
+	blocked *blockedHosts
+	// drain is set once a termination signal is received, no new targets are scheduled then
+	drain *atomicboolean.AtomBool
+
+	// verification contains the findings checked in fix-verification mode
+	verification *verification
+
+	// templateCache contains the decoded templates of previous runs
+	templateCache *templates.Cache
+
+	// templateErrors disables the templates failing on too many targets
+	templateErrors *templateErrors
+	// panicked is set once a template panicked, the scan exits with an error then
+	panicked *atomicboolean.AtomBool
+
+	// networkPool executes the requests of all the templates on the targets
+	networkPool *workpool.Pool
+	// matcherPool evaluates the matchers on the http responses
+	matcherPool *workpool.Pool
+
+	// browserPath is the browser used by headless templates and screenshots
+	browserPath string
+	// headlessPool shares the browser between the headless templates
+	headlessPool *headless.Pool
+	// xssVerifier confirms the xss findings in the browser
+	xssVerifier *headless.XSSVerifier
+
+	// seeds contains the robots.txt and sitemap.xml paths of the hosts
+	seeds *crawler.Seeds
+	// screenshots captures the matched URLs with a headless browser
+	screenshots *headless.Screenshotter
+}
+
+// loadProxies reads the proxy list if set, dropping the proxies which can't be reached
+func loadProxies(options *Options) (*executer.ProxyRotator, error) {
+	if options.ProxyList == "" {
+		return nil, nil
+	}
+
+	list, err := executer.ReadProxyList(options.ProxyList)
+	if err != nil {
+		return nil, fmt.Errorf("could not read proxy list '%s': %s", options.ProxyList, err)
+	}
+	proxies := executer.NewProxyRotator(list, options.ProxyRotation == "host")
+	if proxies.Check(time.Duration(options.Timeout)*time.Second) == 0 {
+		return nil, fmt.Errorf("no proxy of the proxy list '%s' is reachable", options.ProxyList)
+	}
+
+	return proxies, nil
 }
 
 // New creates a new client for running enumeration process.
@@ -60,6 +180,19 @@ func New(options *Options) (*Runner, error) {
 		traceLog: &tracelog.NoopLogger{},
 		options:  options,
 	}
+
+	if options.OutputDir != "" {
+		if err := setupWorkspace(options); err != nil {
+			return nil, errors.Wrap(err, "could not create the scan workspace")
+		}
+	}
+
+	// Deterministic runs use a fixed seed and a single worker for templates and targets
+	if options.Deterministic {
+		rand.Seed(deterministicSeed)
+		options.TemplateThreads = 1
+		options.BulkSize = 1
+	}
 	if options.TraceLogFile != "" {
 		fileLog, err := tracelog.NewFileLogger(options.TraceLogFile)
 		if err != nil {
@@ -67,14 +200,45 @@ func New(options *Options) (*Runner, error) {
 		}
 		runner.traceLog = fileLog
 	}
+	runner.summary = newScanSummary(runner.traceLog)
+	runner.traceLog = runner.summary
 
-	if err := runner.updateTemplates(); err != nil {
+	if options.Offline {
+		// only read the configuration of the installed templates
+		if config, err := readConfiguration(); err == nil {
+			runner.templatesConfig = config
+		}
+	} else if err := runner.updateTemplates(); err != nil {
 		gologger.Labelf("Could not update templates: %s\n", err)
 	}
 
 	// output coloring
 	useColor := !options.NoColor
 	runner.colorizer = *colorizer.NewNucleiColorizer(aurora.NewAurora(useColor))
+	if options.SeverityIcons {
+		runner.colorizer.EnableIcons()
+	}
+
+	console, consoleErr := executer.NewConsole(options.GroupBy, runner.colorizer)
+	if consoleErr != nil {
+		gologger.Fatalf("Could not create console output: %s\n", consoleErr)
+	}
+	runner.console = console
+
+	var templatesVersion string
+	if runner.templatesConfig != nil {
+		templatesVersion = runner.templatesConfig.CurrentVersion
+	}
+	if options.Deterministic {
+		runner.scan = executer.NewDeterministicScan(Version, templatesVersion)
+	} else {
+		scan, scanErr := executer.NewScan(Version, templatesVersion)
+		if scanErr != nil {
+			gologger.Fatalf("Could not create scan id: %s\n", scanErr)
+		}
+		runner.scan = scan
+	}
+	runner.limits = executer.NewFindingLimits(options.MaxFindingsPerHost, options.MaxFindings)
 
 	if useColor {
 		// compile a decolorization regex to cleanup file output messages
@@ -121,12 +285,34 @@ func New(options *Options) (*Runner, error) {
 		tempInput.Close()
 	}
 
+	// In verification mode the targets are read from the previous findings
+	if options.Verify != "" {
+		verified, err := readVerification(options.Verify)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read findings to verify")
+		}
+		runner.verification = verified
+
+		tempInput, err := ioutil.TempFile("", "stdin-input-*")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, target := range verified.targets() {
+			fmt.Fprintf(tempInput, "%s\n", target)
+		}
+		runner.tempFile = tempInput.Name()
+		tempInput.Close()
+	}
+
 	// Setup input, handle a list of hosts as argument
 	var err error
 
 	var input *os.File
 
-	if options.Targets != "" {
+	if options.Verify != "" {
+		input, err = os.Open(runner.tempFile)
+	} else if options.Targets != "" {
 		input, err = os.Open(options.Targets)
 	} else if options.Stdin || options.Target != "" {
 		input, err = os.Open(runner.tempFile)
@@ -138,18 +324,26 @@ func New(options *Options) (*Runner, error) {
 
 	// Sanitize input and pre-compute total number of targets
 	var usedInput = make(map[string]struct{})
+	// the targets as supplied, to tell apart the duplicates only removed by normalization
+	var rawInput = make(map[string]struct{})
 
-	dupeCount := 0
+	dupeCount, normalizedCount := 0, 0
 	sb := strings.Builder{}
 	scanner := bufio.NewScanner(input)
 	runner.inputCount = 0
+	runner.labels = make(targetLabels)
 
 	for scanner.Scan() {
-		url := scanner.Text()
+		raw, labels := parseInputLine(scanner.Text())
 		// skip empty lines
-		if url == "" {
+		if raw == "" {
 			continue
 		}
+		_, exact := rawInput[raw]
+		rawInput[raw] = struct{}{}
+
+		url := normalizeInput(raw)
+		runner.labels.add(url, labels)
 		// deduplication
 		if _, ok := usedInput[url]; !ok {
 			usedInput[url] = struct{}{}
@@ -162,14 +356,121 @@ func New(options *Options) (*Runner, error) {
 			sb.WriteString("\n")
 		} else {
 			dupeCount++
+			if !exact {
+				normalizedCount++
+			}
 		}
 	}
 	input.Close()
 
 	runner.input = sb.String()
 
+	// the resume file is matched against the supplied targets, before they are expanded
+	if options.Resume != "" && !options.WhatIf {
+		runner.resume, err = newResumeState(options.Resume, runner.input)
+		if err != nil {
+			gologger.Fatalf("Could not resume the scan from '%s': %s\n", options.Resume, err)
+		}
+	}
+	resumedTargets := runner.resume.savedTargets()
+
+	// the seed paths are only fetched for the hosts of templates using them
+	runner.seeds = crawler.NewSeeds(runner.newCrawlClient())
+
+	if options.WhatIf && (options.Probe || options.CrawlDepth > 0) {
+		gologger.Warningf("Probing and crawling are skipped in what-if mode as they send traffic\n")
+	}
+
+	// the targets are still probed when resuming for the technologies they run
+	if options.Probe && !options.WhatIf {
+		runner.probeInput(usedInput)
+	}
+
+	if options.CrawlDepth > 0 && !options.WhatIf && resumedTargets == "" {
+		runner.crawlInput(usedInput)
+	}
+
+	// a resumed scan goes on with the targets it was started with, so that the
+	// completed indexes refer to the same targets
+	if resumedTargets != "" {
+		runner.input = resumedTargets
+		runner.inputCount = 0
+		for _, target := range strings.Split(strings.TrimSpace(resumedTargets), "\n") {
+			globalratelimiter.Add(target, options.RateLimit)
+			runner.inputCount++
+		}
+	}
+
 	if dupeCount > 0 {
-		gologger.Labelf("Supplied input was automatically deduplicated (%d removed).", dupeCount)
+		gologger.Labelf("Supplied input was automatically deduplicated (%d removed, %d of them after normalization).\n", dupeCount, normalizedCount)
+	}
+
+	// Read the template sources, the official templates are always known
+	runner.sources = &templateSources{}
+	if options.TemplateSources != "" {
+		runner.sources, err = readTemplateSources(options.TemplateSources)
+		if err != nil {
+			gologger.Fatalf("Could not read template sources file '%s': %s\n", options.TemplateSources, err)
+		}
+	}
+	if runner.templatesConfig != nil && runner.templatesConfig.TemplatesDirectory != "" {
+		runner.sources.add(officialSource, runner.templatesConfig.TemplatesDirectory, "official")
+	}
+
+	// Read the target routing rules if provided
+	if options.TargetRoutes != "" {
+		runner.routes, err = readTargetRoutes(options.TargetRoutes)
+		if err != nil {
+			gologger.Fatalf("Could not read target routes file '%s': %s\n", options.TargetRoutes, err)
+		}
+		gologger.Infof("Loaded %d target routing rules", len(runner.routes.Routes))
+	}
+
+	if options.StopAtSeverity != "" {
+		runner.blocked = newBlockedHosts(options.StopAtSeverity)
+	}
+
+	// the targets of all the templates share a single bounded pool of workers
+	networkWorkers := options.BulkSize * options.TemplateThreads
+	runner.networkPool = workpool.New("network", networkWorkers, networkWorkers)
+	runner.matcherPool = workpool.New("matcher", runtime.NumCPU(), runtime.NumCPU())
+
+	saferegex.MaxInputSize = options.RegexMaxInput
+
+	runner.panicked = atomicboolean.New()
+	if options.TemplateErrorLimit > 0 {
+		runner.templateErrors = newTemplateErrors(options.TemplateErrorLimit)
+	}
+
+	if options.TemplateCache != "" {
+		runner.templateCache, err = templates.NewCache(options.TemplateCache)
+		if err != nil {
+			gologger.Fatalf("Could not create template cache '%s': %s\n", options.TemplateCache, err)
+		}
+	}
+
+	if options.NoHeadless && (options.Headless || options.VerifyXSS || options.Screenshots != "") {
+		gologger.Warningf("Headless templates, xss verification and screenshots are disabled by -no-headless\n")
+		options.Headless, options.VerifyXSS, options.Screenshots = false, false, ""
+	}
+
+	if options.Headless || options.VerifyXSS || options.Screenshots != "" {
+		runner.browserPath, err = headless.FindBrowser(options.BrowserPath)
+		if err != nil {
+			gologger.Fatalf("Could not find headless browser: %s\n", err)
+		}
+		runner.headlessPool = headless.NewPool(runner.browserPath, options.HeadlessPages)
+	}
+
+	if options.VerifyXSS {
+		runner.xssVerifier = headless.NewXSSVerifier(runner.headlessPool, runner.headlessTimeout())
+	}
+
+	if options.Screenshots != "" {
+		runner.screenshots, err = headless.NewScreenshotter(runner.browserPath, options.Screenshots, runner.headlessTimeout())
+		if err != nil {
+			gologger.Fatalf("Could not create screenshot directory '%s': %s\n", options.Screenshots, err)
+		}
 	}
 
 	// Create the output file if asked
@@ -181,6 +482,61 @@ func New(options *Options) (*Runner, error) {
 		runner.output = output
 	}
 
+	// Create the inventory file if asked, exposures are written to assets.jsonl by default
+	if options.Exposures && options.InventoryOutput == "" {
+		options.InventoryOutput = "assets.jsonl"
+	}
+	if options.InventoryOutput != "" {
+		output, err := bufwriter.New(options.InventoryOutput)
+		if err != nil {
+			gologger.Fatalf("Could not create inventory file '%s': %s\n", options.InventoryOutput, err)
+		}
+		runner.inventory = executer.NewInventory(output, options.Exposures)
+	}
+
+	if options.LoadReport != "" {
+		report, err := newLoadReport(options.LoadReport)
+		if err != nil {
+			gologger.Fatalf("Could not create template load report '%s': %s\n", options.LoadReport, err)
+		}
+		runner.loadReport = report
+	}
+
+	if options.TrustedKeys != "" {
+		keys, err := readTrustedKeys(options.TrustedKeys)
+		if err != nil {
+			gologger.Fatalf("%s\n", err)
+		}
+		runner.trustedKeys = keys
+	}
+
+	runner.throttle = executer.NewThrottle(options.GlobalRateLimit, options.HostConcurrency, options.AdaptiveRate)
+
+	// Create the output writers if asked, eg. sarif=results.sarif
+	if options.OutputWriters != "" {
+		writers, err := output.NewWriters(options.OutputWriters)
+		if err != nil {
+			gologger.Fatalf("Could not create output writers: %s\n", err)
+		}
+		runner.writers = writers
+	}
+
+	// File the findings in the issue trackers of the reporting config if set
+	if options.ReportConfig != "" {
+		reportOptions, err := reporting.ReadOptions(options.ReportConfig)
+		if err != nil {
+			gologger.Fatalf("Could not read reporting config '%s': %s\n", options.ReportConfig, err)
+		}
+		reporter, err := reporting.New(reportOptions)
+		if err != nil {
+			gologger.Fatalf("Could not create reporter: %s\n", err)
+		}
+		if runner.writers == nil {
+			runner.writers = &output.Writers{}
+		}
+		runner.writers.Add(reporter)
+	}
+
 	// Creates the progress tracking object
 	runner.progress = progress.NewProgress(runner.colorizer.Colorizer, options.EnableProgressBar)
 
@@ -198,8 +554,31 @@ func New(options *Options) (*Runner, error) {
 		collaborator.DefaultCollaborator.Collab.AddBIID(options.BurpCollaboratorBiid)
 	}
 
-	// Create Dialer
-	runner.dialer, err = cache.NewDialer(cache.DefaultOptions)
+	runner.proxies, err = loadProxies(options)
+	if err != nil {
+		gologger.Fatalf("%s\n", err)
+	}
+
+	// the interactsh client only registers with the server when a template uses it
+	if !options.NoInteractsh && !options.Offline {
+		runner.interactsh, err = interactsh.New(&interactsh.Options{ServerURL: options.InteractshServer, HTTPClient: runner.updateClient()})
+		if err != nil {
+			gologger.Fatalf("Could not create interactsh client: %s\n", err)
+		}
+	}
+
+	// Create Dialer, resolving with the system nameservers in offline mode
+	dialerOptions := cache.DefaultOptions
+	if options.Offline {
+		if len(options.Resolvers) == 0 {
+			options.Resolvers, err = systemResolvers()
+			if err != nil {
+				gologger.Fatalf("Could not read the system resolvers: %s\n", err)
+			}
+		}
+		dialerOptions.BaseResolvers = options.Resolvers
+	}
+	runner.dialer, err = newDialer(options, dialerOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -212,17 +591,46 @@ func (r *Runner) Close() {
 	if r.output != nil {
 		r.output.Close()
 	}
+	r.inventory.Close()
+	r.loadReport.close()
+	if err := r.writers.Close(); err != nil {
+		gologger.Errorf("Could not close output writers: %s\n", err)
+	}
+	if r.interactsh != nil {
+		r.interactsh.Close()
+	}
 	os.Remove(r.tempFile)
 	if r.pf != nil {
 		r.pf.Close()
 	}
+	if r.networkPool != nil {
+		r.networkPool.Stop()
+	}
+	if r.matcherPool != nil {
+		r.matcherPool.Stop()
+	}
+	r.headlessPool.Close()
+}
+
+// logPoolMetrics logs the queue metrics of the worker pools
+func (r *Runner) logPoolMetrics() {
+	for _, pool := range []*workpool.Pool{r.networkPool, r.matcherPool} {
+		metrics := pool.Metrics()
+		gologger.Verbosef("Pool %s: %d workers, %d tasks completed, peak queue size %d\n", "pool", metrics.Name, metrics.Workers, metrics.Completed, metrics.PeakQueued)
+	}
 }
 
 // RunEnumeration sets up the input layer for giving input nuclei.
 // binary and runs the actual enumeration
 func (r *Runner) RunEnumeration() {
+	definitions := r.options.Templates
+	// verification defaults to looking up the findings templates in nuclei-templates
+	if r.verification != nil && len(definitions) == 0 && r.templatesConfig != nil {
+		definitions = []string{r.templatesConfig.TemplatesDirectory}
+	}
+
 	// resolves input templates definitions and any optional exclusion
-	includedTemplates := r.getTemplatesFor(r.options.Templates)
+	includedTemplates := r.getTemplatesFor(definitions)
 	excludedTemplates := r.getTemplatesFor(r.options.ExcludedTemplates)
 	// defaults to all templates
 	allTemplates := includedTemplates
@@ -244,8 +652,26 @@ func (r *Runner) RunEnumeration() {
 		}
 	}
 
+	if r.options.Deterministic {
+		sort.Strings(allTemplates)
+	}
+
 	// pre-parse all the templates, apply filters
-	availableTemplates, workflowCount := r.getParsedTemplatesFor(allTemplates, r.options.Severity)
+	availableTemplates, workflowCount := r.getParsedTemplatesFor(allTemplates, templates.NewFilter(r.options.Tags, r.options.ExcludeTags, r.options.Severity, r.options.Author))
+	if workflowCount > 0 || r.options.Graph != "" {
+		availableTemplates, workflowCount = r.validateWorkflows(availableTemplates)
+	}
+	if r.options.Graph != "" {
+		gologger.Infof("Workflow dependency graph written to %s\n", r.options.Graph)
+		return
+	}
+	if r.verification != nil {
+		availableTemplates, workflowCount = r.verification.filter(availableTemplates, r.workflowMembers)
+	}
+	availableTemplates = r.filterIntrusive(availableTemplates)
+	if !r.options.NoClustering {
+		r.cluster = clusterTemplates(availableTemplates)
+	}
 	templateCount := len(availableTemplates)
 	hasWorkflows := workflowCount > 0
 
@@ -265,14 +691,30 @@ func (r *Runner) RunEnumeration() {
 	for _, t := range availableTemplates {
 		switch av := t.(type) {
 		case *templates.Template:
-			totalRequests += (av.GetHTTPRequestCount() + av.GetDNSRequestCount()) * r.inputCount
+			totalRequests += (av.GetHTTPRequestCount() + av.GetDNSRequestCount() + av.GetNetworkRequestCount() + av.GetSSLRequestCount() + av.GetWhoisRequestCount() + av.GetWebsocketRequestCount() + av.GetFileRequestCount() + av.GetGRPCRequestCount()) * r.inputCount
+			if r.options.Headless {
+				totalRequests += av.GetHeadlessRequestCount() * r.inputCount
+			}
 		case *workflows.Workflow:
 			// workflows will dynamically adjust the totals while running, as
 			// it can't be know in advance which requests will be called
 		} // nolint:wsl // comment
 	}
 
+	if r.options.WhatIf {
+		r.printWhatIf(availableTemplates)
+		return
+	}
+
 	results := atomicboolean.New()
+	r.drain = atomicboolean.New()
+	defer r.drainOnSignal()()
+	if r.resume != nil {
+		r.resume.start(r.input)
+		defer func() {
+			r.resume.close(!r.draining())
+		}()
+	}
 	wgtemplates := sizedwaitgroup.New(r.options.TemplateThreads)
 	// Starts polling or ignore
 	collaborator.DefaultCollaborator.Poll()
@@ -296,6 +738,31 @@ func (r *Runner) RunEnumeration() {
 					for _, request := range tt.BulkRequestsHTTP {
 						results.Or(r.processTemplateWithList(p, tt, request))
 					}
+					r.values.Delete(tt.ID)
+					r.jars.Delete(tt.ID)
+					for _, request := range tt.RequestsNetwork {
+						results.Or(r.processTemplateWithList(p, tt, request))
+					}
+					for _, request := range tt.RequestsSSL {
+						results.Or(r.processTemplateWithList(p, tt, request))
+					}
+					for _, request := range tt.RequestsWhois {
+						results.Or(r.processTemplateWithList(p, tt, request))
+					}
+					for _, request := range tt.RequestsWebsocket {
+						results.Or(r.processTemplateWithList(p, tt, request))
+					}
+					for _, request := range tt.RequestsFile {
+						results.Or(r.processTemplateWithList(p, tt, request))
+					}
+					for _, request := range tt.RequestsGRPC {
+						results.Or(r.processTemplateWithList(p, tt, request))
+					}
+					if r.options.Headless {
+						for _, request := range tt.RequestsHeadless {
+							results.Or(r.processTemplateWithList(p, tt, request))
+						}
+					}
 				case *workflows.Workflow:
 					results.Or(r.processWorkflowWithList(p, template.(*workflows.Workflow)))
 				}
@@ -304,6 +771,29 @@ func (r *Runner) RunEnumeration() {
 
 		wgtemplates.Wait()
 		p.Wait()
+		r.console.Flush()
+		r.logPoolMetrics()
+	}
+
+	r.templateErrors.printSummary()
+	printLateInteractions(r.interactsh)
+
+	if suppressed := r.limits.Suppressed(); suppressed > 0 {
+		gologger.Infof("%d findings over the limits were not written\n", suppressed)
+	}
+
+	summary := r.summary.report()
+	summary.ScanID = r.scan.ID
+	summary.print()
+	if r.options.SummaryJSON != "" {
+		if err := summary.write(r.options.SummaryJSON); err != nil {
+			gologger.Errorf("Could not write summary file '%s': %s\n", r.options.SummaryJSON, err)
+		}
+	}
+	printSlowRegexes(availableTemplates)
+
+	if r.verification != nil {
+		r.verification.printReport(&r.colorizer)
 	}
 
 	if !results.Get() {
@@ -315,3 +805,8 @@ func (r *Runner) RunEnumeration() {
 		gologger.Infof("No results found. Happy hacking!")
 	}
 }
+
+// headlessTimeout returns the time the browser has to execute the steps of a page
+func (r *Runner) headlessTimeout() time.Duration {
+	return time.Duration(r.options.Timeout) * headlessTimeoutFactor * time.Second
+}
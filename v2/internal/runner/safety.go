@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+)
+
+// minDoSDelay is the lowest delay enforced before each request of the dos templates
+const minDoSDelay = time.Second
+
+// filterIntrusive removes the intrusive, destructive and dos templates unless
+// they are allowed, in which case they are listed before the scan starts. The
+// templates of the workflows are checked the same way, the excluded ones are
+// skipped when the workflows run.
+func (r *Runner) filterIntrusive(availableTemplates []interface{}) []interface{} {
+	var (
+		filtered  []interface{}
+		intrusive []string
+	)
+
+	for _, t := range availableTemplates {
+		switch template := t.(type) {
+		case *templates.Template:
+			allowed, label := r.checkSafety(template, template.ID)
+			if !allowed {
+				continue
+			}
+			if label != "" {
+				intrusive = append(intrusive, label)
+			}
+		case *workflows.Workflow:
+			for _, member := range r.workflowMembers(template) {
+				if _, label := r.checkSafety(member, template.ID+"/"+member.ID); label != "" {
+					intrusive = append(intrusive, label)
+				}
+			}
+		}
+		filtered = append(filtered, t)
+	}
+
+	if len(intrusive) > 0 {
+		gologger.Warningf("Running %d intrusive templates which may change the state of the targets: %s\n", len(intrusive), strings.Join(intrusive, ", "))
+	}
+
+	return filtered
+}
+
+// checkSafety returns true if the safety class of a template allows running
+// it, warning about it otherwise, with the label listing the allowed intrusive
+// templates, empty for the others.
+func (r *Runner) checkSafety(template *templates.Template, name string) (allowed bool, label string) {
+	if !template.Intrusive() && !template.DoS() {
+		return true, ""
+	}
+
+	if template.DoS() {
+		if !r.options.AllowDoS {
+			gologger.Warningf("Excluding dos template %s, use -allow-dos to run it\n", name)
+			return false, ""
+		}
+		return true, name + " (dos)"
+	}
+
+	if !r.options.AllowIntrusive {
+		gologger.Warningf("Excluding %s template %s, use -allow-intrusive to run it\n", template.Safety(), name)
+		return false, ""
+	}
+	return true, name + " (" + template.Safety() + ")"
+}
+
+// allowsSafety returns true if the safety class of a template allows running it
+func (r *Runner) allowsSafety(template *templates.Template) bool {
+	if template.DoS() {
+		return r.options.AllowDoS
+	}
+
+	return !template.Intrusive() || r.options.AllowIntrusive
+}
+
+// workflowMembers returns the templates referenced by the variables of a
+// workflow, the invalid references being reported by the workflow validation.
+func (r *Runner) workflowMembers(workflow *workflows.Workflow) []*templates.Template {
+	names := make([]string, 0, len(workflow.Variables))
+	for name := range workflow.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var members []*templates.Template
+	for _, name := range names {
+		files, err := r.workflowVariableFiles(workflow.GetPath(), workflow.Variables[name])
+		if err != nil {
+			continue
+		}
+
+		for _, file := range files {
+			if template, err := r.parseTemplateFile(file); err == nil {
+				if member, ok := template.(*templates.Template); ok {
+					members = append(members, member)
+				}
+			}
+		}
+	}
+
+	return members
+}
+
+// requestDelay returns the delay waited before each request of a template,
+// which is only mandatory for the dos templates.
+func (r *Runner) requestDelay(template *templates.Template) time.Duration {
+	if !template.DoS() {
+		return 0
+	}
+
+	if delay := time.Duration(r.options.DoSDelay) * time.Millisecond; delay > minDoSDelay {
+		return delay
+	}
+	return minDoSDelay
+}
+
+// hasDoS returns true if a workflow runs dos templates
+func hasDoS(list []workflowTemplates) bool {
+	for _, variable := range list {
+		for _, template := range variable.Templates {
+			if template.HTTPOptions != nil && template.HTTPOptions.Template.DoS() || template.DNSOptions != nil && template.DNSOptions.Template.DoS() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,235 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+)
+
+const (
+	selfTestDomain  = "nuclei-self-test.local"
+	selfTestVersion = "1.2.3"
+)
+
+// selfTestTemplates contains the canary templates run by the self-test.
+var selfTestTemplates = map[string]string{
+	"self-test-http.yaml": `id: self-test-http
+info:
+  name: Self-test HTTP canary
+  author: nuclei
+  severity: info
+  tags: self-test-http
+
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}/canary"
+    matchers-condition: and
+    matchers:
+      - type: status
+        status:
+          - 200
+      - type: word
+        part: header
+        words:
+          - "X-Canary: nuclei"
+      - type: regex
+        regex:
+          - "nuclei-self-test-[a-z]+"
+    extractors:
+      - type: regex
+        group: 1
+        regex:
+          - "canary-version: ([0-9.]+)"
+`,
+	"self-test-dns.yaml": `id: self-test-dns
+info:
+  name: Self-test DNS canary
+  author: nuclei
+  severity: info
+  tags: self-test-dns
+
+dns:
+  - name: "{{FQDN}}"
+    type: A
+    class: inet
+    recursion: true
+    retries: 2
+    matchers:
+      - type: word
+        words:
+          - "127.0.0.1"
+`,
+}
+
+// selfTestRoutes routes each canary template to the target of its protocol
+const selfTestRoutes = `routes:
+  - hosts: ["127.0.0.1"]
+    tags: [self-test-http]
+  - hosts: ["` + selfTestDomain + `"]
+    tags: [self-test-dns]
+`
+
+// SelfTest runs the bundled canary templates end-to-end against internal
+// mock servers and checks that the expected findings were written.
+func SelfTest(options *Options) error {
+	directory, err := ioutil.TempDir("", "nuclei-self-test-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(directory)
+
+	templatesDirectory := filepath.Join(directory, "templates")
+	if err := os.Mkdir(templatesDirectory, 0755); err != nil {
+		return err
+	}
+
+	for name, data := range selfTestTemplates {
+		if err := ioutil.WriteFile(filepath.Join(templatesDirectory, name), []byte(data), 0644); err != nil {
+			return err
+		}
+	}
+
+	routes := filepath.Join(directory, "routes.yaml")
+	if err := ioutil.WriteFile(routes, []byte(selfTestRoutes), 0644); err != nil {
+		return err
+	}
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/canary" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("X-Canary", "nuclei")
+		fmt.Fprintf(w, "nuclei-self-test-canary\ncanary-version: %s\n", selfTestVersion)
+	}))
+	defer httpServer.Close()
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return errors.Wrap(err, "could not start dns server")
+	}
+
+	dnsServer := &dns.Server{PacketConn: packetConn, Handler: dns.HandlerFunc(serveSelfTestDNS)}
+	go dnsServer.ActivateAndServe() //nolint:errcheck // the server is shut down when the test ends
+	defer dnsServer.Shutdown()      //nolint:errcheck // the server is shut down when the test ends
+
+	targets := filepath.Join(directory, "targets.txt")
+	if err := ioutil.WriteFile(targets, []byte(httpServer.URL+"\n"+selfTestDomain+"\n"), 0644); err != nil {
+		return err
+	}
+
+	output := filepath.Join(directory, "output.json")
+
+	// the scan runs with the defaults of the flags rather than the ones of
+	// the user, whose filters, outputs and network settings would hide the
+	// canaries or reach beyond the mock servers. Only the display is kept.
+	testOptions := defaultOptions()
+	testOptions.NoColor, testOptions.Verbose, testOptions.Silent = options.NoColor, options.Verbose, options.Silent
+	testOptions.Offline, testOptions.NoInteractsh = true, true
+	testOptions.JSON, testOptions.Output, testOptions.Targets = true, output, targets
+	testOptions.Templates = multiStringFlag{templatesDirectory}
+	testOptions.TargetRoutes, testOptions.Resolvers = routes, []string{packetConn.LocalAddr().String()}
+	testOptions.BulkSize, testOptions.TemplateThreads, testOptions.Timeout, testOptions.Retries = 2, 2, 5, 1
+
+	gologger.Infof("Running self-test against %s and %s\n", httpServer.URL, packetConn.LocalAddr())
+
+	nucleiRunner, err := New(testOptions)
+	if err != nil {
+		return errors.Wrap(err, "could not create runner")
+	}
+	nucleiRunner.RunEnumeration()
+	nucleiRunner.Close()
+
+	findings := readSelfTestFindings(output)
+
+	checks := []struct {
+		name string
+		ok   bool
+	}{
+		{"http request and matchers", findings["self-test-http"] != nil},
+		{"regex extractor", findings["self-test-http"] != nil && strings.Contains(strings.Join(findings["self-test-http"].ExtractedResults, ","), selfTestVersion)},
+		{"dns request and matchers", findings["self-test-dns"] != nil},
+		{"json output", len(findings) > 0},
+	}
+
+	var failed []string
+
+	for _, check := range checks {
+		status := "ok"
+		if !check.ok {
+			status = "failed"
+			failed = append(failed, check.name)
+		}
+		gologger.Infof("Self-test %s: %s\n", check.name, status)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed checks: %s", strings.Join(failed, ", "))
+	}
+
+	gologger.Infof("Self-test passed\n")
+
+	return nil
+}
+
+// serveSelfTestDNS answers every A question with a loopback address
+func serveSelfTestDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+
+	for _, question := range r.Question {
+		if question.Qtype == dns.TypeA {
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: question.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP("127.0.0.1"),
+			})
+		}
+	}
+
+	_ = w.WriteMsg(m)
+}
+
+// selfTestFinding contains the fields of a finding checked by the self-test
+type selfTestFinding struct {
+	Template         string   `json:"template"`
+	ExtractedResults []string `json:"extracted_results"`
+}
+
+// readSelfTestFindings reads the findings written by the self-test scan by template
+func readSelfTestFindings(output string) map[string]*selfTestFinding {
+	findings := make(map[string]*selfTestFinding)
+
+	file, err := os.Open(output)
+	if err != nil {
+		return findings
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		finding := &selfTestFinding{}
+		if err := jsoniter.Unmarshal(scanner.Bytes(), finding); err != nil {
+			continue
+		}
+
+		if previous, ok := findings[finding.Template]; ok {
+			previous.ExtractedResults = append(previous.ExtractedResults, finding.ExtractedResults...)
+			continue
+		}
+		findings[finding.Template] = finding
+	}
+
+	return findings
+}
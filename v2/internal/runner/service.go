@@ -0,0 +1,390 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+const (
+	// minScanRetry is the first delay before a failed scan of the daemon is started again
+	minScanRetry = time.Minute
+	// megabyte is the unit of the daemon log size
+	megabyte = 1024 * 1024
+)
+
+// RunService runs a command of the supervised daemon mode: install and
+// uninstall register the service with the service manager of the system,
+// start runs the daemon the service manager starts.
+func RunService(options *Options) error {
+	switch options.Service {
+	case "install":
+		args := options.ServiceArgs
+		// the service manager doesn't start the daemon in the directory the paths of the flags are relative to
+		if options.DaemonDirectory == "" {
+			directory, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			args = append([]string{"-daemon-dir", directory}, args...)
+		}
+		if err := installService(options.ServiceName, args); err != nil {
+			return err
+		}
+		gologger.Infof("Installed the %s service\n", options.ServiceName)
+	case "uninstall":
+		if err := uninstallService(options.ServiceName); err != nil {
+			return err
+		}
+		gologger.Infof("Uninstalled the %s service\n", options.ServiceName)
+	case "start":
+		if options.DaemonDirectory != "" {
+			if err := os.Chdir(options.DaemonDirectory); err != nil {
+				return err
+			}
+		}
+
+		log, err := newRotatingLog(options.DaemonLog, int64(options.DaemonLogSize)*megabyte, options.DaemonLogBackups)
+		if err != nil {
+			return err
+		}
+		defer log.Close()
+
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+
+		load := func() ([]string, error) {
+			return daemonArgs(options)
+		}
+		args, err := load()
+		if err != nil {
+			return err
+		}
+		interval := time.Duration(options.DaemonInterval) * time.Minute
+		s := newSupervisor(executable, args, interval, log)
+
+		if options.HealthAddr != "" {
+			server, err := serveHealth(options.HealthAddr, s)
+			if err != nil {
+				return err
+			}
+			defer server.Close()
+		}
+
+		return runService(options.ServiceName, s, load)
+	default:
+		return fmt.Errorf("unknown service command %s", options.Service)
+	}
+
+	return nil
+}
+
+// daemonArgs returns the flags the daemon runs the scans with, the ones of
+// the service command followed by the ones of the daemon config file. Each
+// line of the file is a flag followed by its value if any, the empty lines
+// and the ones starting with # are skipped.
+func daemonArgs(options *Options) ([]string, error) {
+	// the scans write to the log, where colors can't be shown
+	args := append([]string{"-nC"}, options.ServiceArgs...)
+	if options.DaemonConfig == "" {
+		return args, nil
+	}
+
+	data, err := ioutil.ReadFile(options.DaemonConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if separator := strings.IndexAny(line, " \t"); separator != -1 {
+			args = append(args, line[:separator], strings.TrimSpace(line[separator:]))
+			continue
+		}
+		args = append(args, line)
+	}
+
+	return args, nil
+}
+
+// supervisor runs the scan of the daemon in a child process every interval,
+// starting it again sooner if it fails, with its output written to the log.
+type supervisor struct {
+	executable string
+	args       []string
+	interval   time.Duration
+	log        io.Writer
+
+	mutex    sync.Mutex
+	child    *os.Process
+	stopped  chan struct{}
+	stopOnce sync.Once
+	reloaded chan struct{}
+}
+
+// newSupervisor creates a supervisor running an executable with arguments
+func newSupervisor(executable string, args []string, interval time.Duration, log io.Writer) *supervisor {
+	return &supervisor{
+		executable: executable,
+		args:       args,
+		interval:   interval,
+		log:        log,
+		stopped:    make(chan struct{}),
+		reloaded:   make(chan struct{}, 1),
+	}
+}
+
+// run runs the scans until the supervisor is stopped. A failed scan is
+// started again after a delay doubled on each failure, up to the interval.
+func (s *supervisor) run() error {
+	retry := minScanRetry
+	for {
+		start := time.Now()
+		fmt.Fprintf(s.log, "[daemon] %s starting the scan\n", start.Format(time.RFC3339))
+
+		var wait time.Duration
+		if err := s.scan(); err != nil {
+			fmt.Fprintf(s.log, "[daemon] %s the scan failed: %s\n", time.Now().Format(time.RFC3339), err)
+
+			wait = retry
+			if retry *= 2; retry > s.interval {
+				retry = s.interval
+			}
+		} else {
+			fmt.Fprintf(s.log, "[daemon] %s the scan completed\n", time.Now().Format(time.RFC3339))
+
+			retry = minScanRetry
+			wait = s.interval - time.Since(start)
+		}
+
+		select {
+		case <-s.stopped:
+			return nil
+		case <-s.reloaded:
+			fmt.Fprintf(s.log, "[daemon] %s reloaded the flags of the scans\n", time.Now().Format(time.RFC3339))
+			retry = minScanRetry
+		case <-time.After(wait):
+		}
+	}
+}
+
+// scan runs a scan in a child process, unless the supervisor is stopped.
+// The output is copied through a pipe of its own so that the processes
+// started by the scan, eg. a browser, don't delay its end.
+func (s *supervisor) scan() error {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	s.mutex.Lock()
+	select {
+	case <-s.stopped:
+		s.mutex.Unlock()
+		reader.Close()
+		return nil
+	default:
+	}
+
+	cmd := exec.Command(s.executable, s.args...)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	if err := cmd.Start(); err != nil {
+		s.mutex.Unlock()
+		reader.Close()
+		return err
+	}
+	s.child = cmd.Process
+	s.mutex.Unlock()
+
+	go func() {
+		//nolint:errcheck // the output is lost if the log can't be written
+		io.Copy(s.log, reader)
+		reader.Close()
+	}()
+
+	err = cmd.Wait()
+
+	s.mutex.Lock()
+	s.child = nil
+	s.mutex.Unlock()
+
+	return err
+}
+
+// stop stops the scans, the running one is asked to drain its requests
+// in flight. run returns once it has exited.
+func (s *supervisor) stop() {
+	s.stopOnce.Do(func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		close(s.stopped)
+		if s.child != nil {
+			if err := terminate(s.child); err != nil {
+				gologger.Warningf("Could not stop the running scan: %s\n", err)
+			}
+		}
+	})
+}
+
+// reload replaces the flags of the scans. The running scan is asked to
+// drain its requests in flight, the next one starts with the new flags
+// once it has exited, or right away if no scan is running.
+func (s *supervisor) reload(args []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.args = args
+	if s.child != nil {
+		if err := terminate(s.child); err != nil {
+			gologger.Warningf("Could not stop the running scan: %s\n", err)
+		}
+	}
+
+	select {
+	case s.reloaded <- struct{}{}:
+	default:
+	}
+}
+
+// draining returns true once the supervisor is stopping
+func (s *supervisor) draining() bool {
+	select {
+	case <-s.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// rotatingLog is a log file renamed with a .1 suffix once it reaches its
+// maximum size, the previous ones being shifted to .2, .3... and the oldest
+// of them removed.
+type rotatingLog struct {
+	mutex   sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// newRotatingLog opens a rotating log appending to the file of a path
+func newRotatingLog(path string, maxSize int64, backups int) (*rotatingLog, error) {
+	log := &rotatingLog{path: path, maxSize: maxSize, backups: backups}
+	if err := log.open(); err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+// open opens the file of the log for appending
+func (l *rotatingLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file, l.size = file, info.Size()
+
+	return nil
+}
+
+// Write writes to the log, rotating it first if the data doesn't fit
+func (l *rotatingLog) Write(data []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(data)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+
+	return n, err
+}
+
+// rotate shifts the files of the log and opens a new one
+func (l *rotatingLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+
+	if l.backups <= 0 {
+		if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return l.open()
+	}
+
+	for i := l.backups - 1; i > 0; i-- {
+		if err := os.Rename(l.backupPath(i), l.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(l.path, l.backupPath(1)); err != nil {
+		return err
+	}
+
+	return l.open()
+}
+
+// backupPath returns the path of a rotated file of the log
+func (l *rotatingLog) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", l.path, i)
+}
+
+// Close closes the file of the log
+func (l *rotatingLog) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.file.Close()
+}
+
+// systemdUnit returns the systemd unit of the service running the daemon
+// with the flags of the scan
+func systemdUnit(name, executable string, args []string) string {
+	command := []string{systemdQuote(executable), "service", "start"}
+	for _, arg := range args {
+		command = append(command, systemdQuote(arg))
+	}
+
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "[Unit]\nDescription=%s scheduled scans\nWants=network-online.target\nAfter=network-online.target\n\n", name)
+	fmt.Fprintf(builder, "[Service]\nExecStart=%s\nExecReload=/bin/kill -HUP $MAINPID\nRestart=on-failure\nKillSignal=SIGTERM\nTimeoutStopSec=5min\n\n", strings.Join(command, " "))
+	builder.WriteString("[Install]\nWantedBy=multi-user.target\n")
+
+	return builder.String()
+}
+
+// systemdQuote quotes an argument of a systemd command line, escaping the
+// specifiers and the variables expanded by systemd
+func systemdQuote(arg string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$")
+	return `"` + replacer.Replace(arg) + `"`
+}
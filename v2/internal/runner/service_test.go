@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingLog(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-daemon-")
+	require.Nil(t, err, "could not create directory")
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "daemon.log")
+	log, err := newRotatingLog(path, 10, 2)
+	require.Nil(t, err, "could not open log")
+
+	for _, line := range []string{"scan one\n", "scan two\n", "scan three\n", "scan four\n"} {
+		_, err := log.Write([]byte(line))
+		require.Nil(t, err, "could not write log")
+	}
+	require.Nil(t, log.Close(), "could not close log")
+
+	for file, expected := range map[string]string{path: "scan four\n", path + ".1": "scan three\n", path + ".2": "scan two\n"} {
+		data, err := ioutil.ReadFile(file)
+		require.Nil(t, err, "could not read rotated log")
+		require.Equal(t, expected, string(data), "wrong rotated log content")
+	}
+	_, err = os.Stat(path + ".3")
+	require.True(t, os.IsNotExist(err), "more rotated logs than the backups were kept")
+}
+
+func TestSystemdUnit(t *testing.T) {
+	unit := systemdUnit("nuclei", "/usr/local/bin/nuclei", []string{"-daemon-dir", "/srv/scans", "-H", `X-Token: "50%$off"`})
+
+	require.Contains(t, unit, `ExecStart="/usr/local/bin/nuclei" service start "-daemon-dir" "/srv/scans" "-H" "X-Token: \"50%%$$off\""`, "wrong quoted command")
+	require.True(t, strings.HasSuffix(unit, "WantedBy=multi-user.target\n"), "the unit is not installable")
+}
+
+func TestDaemonArgs(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-daemon-")
+	require.Nil(t, err, "could not create directory")
+	defer os.RemoveAll(directory)
+
+	config := filepath.Join(directory, "daemon.conf")
+	err = ioutil.WriteFile(config, []byte("# scanned targets\n-l targets.txt\n\n-H X-Token: secret\n-silent\n"), 0600)
+	require.Nil(t, err, "could not write daemon config")
+
+	args, err := daemonArgs(&Options{ServiceArgs: []string{"-t", "cves/"}, DaemonConfig: config})
+	require.Nil(t, err, "could not read daemon config")
+	require.Equal(t, []string{"-nC", "-t", "cves/", "-l", "targets.txt", "-H", "X-Token: secret", "-silent"}, args, "wrong daemon flags")
+}
+
+func TestSupervisorReload(t *testing.T) {
+	s := newSupervisor("nuclei", []string{"-nC"}, time.Hour, ioutil.Discard)
+
+	s.reload([]string{"-nC", "-silent"})
+	s.reload([]string{"-nC", "-debug"})
+
+	require.Equal(t, []string{"-nC", "-debug"}, s.args, "the last flags were not kept")
+	require.Len(t, s.reloaded, 1, "the reloads were not merged into a single restart")
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	s := newSupervisor("nuclei", nil, time.Hour, ioutil.Discard)
+	handler := healthHandler(s)
+
+	status := func(path string) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusOK, status("/healthz"), "the daemon is not live")
+	require.Equal(t, http.StatusOK, status("/readyz"), "the daemon is not ready")
+
+	s.stop()
+	require.Equal(t, http.StatusOK, status("/healthz"), "the draining daemon is not live")
+	require.Equal(t, http.StatusServiceUnavailable, status("/readyz"), "the draining daemon is still ready")
+}
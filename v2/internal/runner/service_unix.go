@@ -0,0 +1,114 @@
+//go:build !windows
+// +build !windows
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// systemdUnitDirectory is the directory the units of the system services are installed in
+const systemdUnitDirectory = "/etc/systemd/system"
+
+// installService installs the daemon as a systemd service enabled at boot
+func installService(name string, args []string) error {
+	if runtime.GOOS != "linux" {
+		return errors.New("the service can only be installed with systemd on linux")
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(systemdUnitDirectory, name+".service")
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("service %s already exists", name)
+	}
+	if err := ioutil.WriteFile(path, []byte(systemdUnit(name, executable, args)), 0644); err != nil {
+		return err
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+
+	return systemctl("enable", name+".service")
+}
+
+// uninstallService stops and removes the systemd service of the daemon
+func uninstallService(name string) error {
+	if runtime.GOOS != "linux" {
+		return errors.New("the service can only be uninstalled with systemd on linux")
+	}
+
+	path := filepath.Join(systemdUnitDirectory, name+".service")
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("service %s is not installed", name)
+	}
+	if err := systemctl("disable", "--now", name+".service"); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	return systemctl("daemon-reload")
+}
+
+// systemctl runs a systemctl command
+func systemctl(args ...string) error {
+	output, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s failed: %s: %s", args[0], err, output)
+	}
+
+	return nil
+}
+
+// runService runs the daemon until SIGINT or SIGTERM, loading the flags of
+// the scans again and restarting them on SIGHUP
+func runService(name string, s *supervisor, load func() ([]string, error)) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	reloads := make(chan os.Signal, 1)
+	signal.Notify(reloads, syscall.SIGHUP)
+	defer signal.Stop(reloads)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				s.stop()
+				return
+			case <-reloads:
+				args, err := load()
+				if err != nil {
+					gologger.Warningf("Could not reload the flags of the scans, keeping the previous ones: %s\n", err)
+					continue
+				}
+				gologger.Infof("Reloaded the flags of the scans\n")
+				s.reload(args)
+			}
+		}
+	}()
+
+	return s.run()
+}
+
+// terminate asks a scan to drain its requests in flight and exit
+func terminate(process *os.Process) error {
+	return process.Signal(syscall.SIGTERM)
+}
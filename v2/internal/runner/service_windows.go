@@ -0,0 +1,123 @@
+//go:build windows
+// +build windows
+
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// installService installs the daemon as a windows service started at boot
+func installService(name string, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	manager, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer manager.Disconnect()
+
+	if service, err := manager.OpenService(name); err == nil {
+		service.Close()
+		return fmt.Errorf("service %s already exists", name)
+	}
+
+	config := mgr.Config{DisplayName: name + " scheduled scans", StartType: mgr.StartAutomatic}
+	service, err := manager.CreateService(name, executable, config, append([]string{"service", "start"}, args...)...)
+	if err != nil {
+		return err
+	}
+
+	return service.Close()
+}
+
+// uninstallService removes the windows service of the daemon, which stops
+// once the service manager has stopped it
+func uninstallService(name string) error {
+	manager, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer manager.Disconnect()
+
+	service, err := manager.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", name)
+	}
+	defer service.Close()
+
+	return service.Delete()
+}
+
+// runService runs the daemon under the service manager, or until interrupted
+// if started from a console. Windows has no SIGHUP, the flags of the scans
+// are only loaded when the daemon starts.
+func runService(name string, s *supervisor, _ func() ([]string, error)) error {
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil {
+		return err
+	}
+	if !interactive {
+		return svc.Run(name, &windowsService{supervisor: s})
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	go func() {
+		<-signals
+		s.stop()
+	}()
+
+	return s.run()
+}
+
+// windowsService runs the supervisor of the daemon for the service manager
+type windowsService struct {
+	supervisor *supervisor
+}
+
+// Execute runs the daemon until the service manager stops it
+func (w *windowsService) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.supervisor.run()
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return false, 1
+			}
+			return false, 0
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				w.supervisor.stop()
+				<-done
+				return false, 0
+			}
+		}
+	}
+}
+
+// terminate stops a scan, windows processes can't be asked to drain
+func terminate(process *os.Process) error {
+	return process.Kill()
+}
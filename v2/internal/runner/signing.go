@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// readTrustedKeys reads the comma separated public key files templates must be signed with
+func readTrustedKeys(files string) ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for _, file := range strings.Split(files, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+
+		key, err := templates.ReadPublicKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read trusted key %s: %s", file, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// readTemplateFile reads a template or workflow file verifying it was signed
+// by a trusted key, so that the content parsed is the one verified. Only the
+// unsigned or modified ones are warned about in warn mode. Every template is
+// accepted when no trusted keys are configured.
+//
+// All the templates and workflows must be loaded through it.
+func (r *Runner) readTemplateFile(file string) ([]byte, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.trustedKeys) == 0 {
+		return data, nil
+	}
+
+	err = templates.Verify(data, r.trustedKeys)
+	if err == nil {
+		return data, nil
+	}
+	if r.options.UnsignedTemplates == "warn" {
+		gologger.Warningf("Running template %s anyway: %s\n", file, err)
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("rejected template %s: %w", file, err)
+}
+
+// parseTemplate parses a request template file read with readTemplateFile
+func (r *Runner) parseTemplate(file string) (*templates.Template, error) {
+	data, err := r.readTemplateFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.templateCache.ParseBytes(file, data)
+}
+
+// SignTemplates adds a digest trailer signed with the -sign-key private key
+// to the templates and workflows selected with -t, rewriting them in place.
+func SignTemplates(options *Options) error {
+	key, err := templates.ReadPrivateKey(options.SignKey)
+	if err != nil {
+		return fmt.Errorf("could not read signing key: %s", err)
+	}
+
+	r := &Runner{options: options}
+	if config, err := readConfiguration(); err == nil {
+		r.templatesConfig = config
+	}
+
+	signed := 0
+	for _, file := range r.getTemplatesFor(options.Templates) {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(file, templates.Sign(data, key), info.Mode()); err != nil {
+			return err
+		}
+		gologger.Verbosef("Signed %s\n", "sign", file)
+		signed++
+	}
+
+	gologger.Infof("Signed %d templates\n", signed)
+
+	return nil
+}
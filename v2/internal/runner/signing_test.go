@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedTemplateLoading(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.Nil(t, err, "could not generate key")
+
+	signed := templates.Sign([]byte(workflowStepTemplate), private)
+	directory := writeWorkflowFiles(t, map[string]string{
+		"step.yaml":     string(signed),
+		"tampered.yaml": strings.Replace(string(signed), "/step", "/tampered", 1),
+		"workflow.yaml": string(templates.Sign([]byte(workflowDefinition), private)),
+	})
+	defer os.RemoveAll(directory)
+
+	r := &Runner{options: &Options{}, trustedKeys: []ed25519.PublicKey{public}}
+
+	template, err := r.parseTemplate(filepath.Join(directory, "step.yaml"))
+	require.Nil(t, err, "the signed template was rejected")
+	require.Equal(t, "workflow-step", template.ID, "unexpected template")
+
+	_, err = r.parseTemplate(filepath.Join(directory, "tampered.yaml"))
+	require.NotNil(t, err, "the tampered template was accepted")
+
+	parsed, err := r.parseTemplateFile(filepath.Join(directory, "workflow.yaml"))
+	require.Nil(t, err, "the signed workflow was rejected")
+	require.IsType(t, &workflows.Workflow{}, parsed, "the workflow was not parsed")
+
+	lookup := &templateLookup{paths: []string{filepath.Join(directory, "tampered.yaml")}, parse: r.parseTemplate, parsed: make(map[string]*templates.Template)}
+	_, err = lookup.find("tampered")
+	require.NotNil(t, err, "the replayed template skipped the signature")
+
+	r.trustedKeys = nil
+	_, err = r.parseTemplate(filepath.Join(directory, "tampered.yaml"))
+	require.Nil(t, err, "the template was rejected without trusted keys")
+}
@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/projectdiscovery/httpx/common/cache"
+)
+
+// sourcePortAttempts is the number of random local ports tried for a
+// connection, a port in use by another connection being skipped
+const sourcePortAttempts = 5
+
+// portRange is an inclusive range of local ports
+type portRange struct {
+	first, last int
+}
+
+// parsePortRange parses a range of ports as first-last
+func parsePortRange(value string) (portRange, error) {
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) != 2 {
+		return portRange{}, fmt.Errorf("invalid source port range %s, expected first-last", value)
+	}
+
+	first, firstErr := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	last, lastErr := strconv.Atoi(strings.TrimSpace(bounds[1]))
+	if firstErr != nil || lastErr != nil || first < 1 || last > 65535 || first > last {
+		return portRange{}, fmt.Errorf("invalid source port range %s, expected first-last between 1 and 65535", value)
+	}
+
+	return portRange{first: first, last: last}, nil
+}
+
+// random returns a random port of the range
+func (r portRange) random() int {
+	return r.first + rand.Intn(r.last-r.first+1)
+}
+
+// sourcePortDialer returns a dialer resolving the hosts with a dns cache as
+// the default one does, connecting from a random local port of a range so
+// that the connections to a host don't come from consecutive ports.
+func sourcePortDialer(dnsCache *cache.Cache, ports portRange) cache.DialerFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		ips, err := dnsCache.Lookup(host)
+		if err != nil {
+			return nil, &cache.NoAddressFoundError{}
+		}
+
+		var conn net.Conn
+		err = &cache.NoAddressFoundError{}
+		for _, ip := range ips {
+			if ip == "" {
+				continue
+			}
+			if conn, err = dialFromRandomPort(ctx, network, net.JoinHostPort(ip, port), ports); err == nil {
+				return conn, nil
+			}
+		}
+
+		return nil, err
+	}
+}
+
+// dialFromRandomPort connects to an address from a random port of a range,
+// trying another one if the port is in use
+func dialFromRandomPort(ctx context.Context, network, address string, ports portRange) (net.Conn, error) {
+	var err error
+	for i := 0; i < sourcePortAttempts; i++ {
+		dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 10 * time.Second}
+		if strings.HasPrefix(network, "udp") {
+			dialer.LocalAddr = &net.UDPAddr{Port: ports.random()}
+		} else {
+			dialer.LocalAddr = &net.TCPAddr{Port: ports.random()}
+		}
+
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, network, address)
+		if err == nil {
+			return conn, nil
+		}
+		if !errors.Is(err, syscall.EADDRINUSE) && !errors.Is(err, syscall.EADDRNOTAVAIL) {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
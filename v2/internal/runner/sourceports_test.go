@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePortRange(t *testing.T) {
+	ports, err := parsePortRange("40000-40100")
+	require.Nil(t, err, "could not parse range")
+	require.Equal(t, portRange{first: 40000, last: 40100}, ports, "wrong range")
+
+	for _, value := range []string{"40000", "40100-40000", "0-10", "1-65536", "a-b"} {
+		_, err := parsePortRange(value)
+		require.NotNil(t, err, "invalid range %s was accepted", value)
+	}
+}
+
+func TestDialFromRandomPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen")
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	ports := portRange{first: 41000, last: 41999}
+	for i := 0; i < 4; i++ {
+		conn, err := dialFromRandomPort(context.Background(), "tcp", listener.Addr().String(), ports)
+		require.Nil(t, err, "could not dial")
+		port := conn.LocalAddr().(*net.TCPAddr).Port
+		conn.Close()
+		require.True(t, port >= ports.first && port <= ports.last, "source port %d is out of the range", port)
+	}
+}
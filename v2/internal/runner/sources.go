@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// officialSource is the source name of the templates installed by -update-templates
+	officialSource = "official"
+	// localSource is the source name of the templates outside of any known source
+	localSource = "local"
+)
+
+// trustLevels orders the trust levels of the template sources, higher is more trusted
+var trustLevels = map[string]int{
+	"untrusted": 1,
+	"trusted":   2,
+	"official":  3,
+}
+
+// templateSources contains the catalogue sources the templates are loaded from.
+type templateSources struct {
+	Sources []*templateSource `yaml:"sources"`
+}
+
+// templateSource is a directory of templates with its trust level.
+type templateSource struct {
+	// Name identifies the source in the findings (eg. private-acme)
+	Name string `yaml:"name"`
+	// Path is the directory containing the templates of the source
+	Path string `yaml:"path"`
+	// Trust is the trust level of the source, one of untrusted, trusted or official
+	Trust string `yaml:"trust"`
+}
+
+// readTemplateSources reads the template sources from a yaml file.
+func readTemplateSources(file string) (*templateSources, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sources := &templateSources{}
+	if err := yaml.NewDecoder(f).Decode(sources); err != nil {
+		return nil, err
+	}
+
+	for _, source := range sources.Sources {
+		if source.Name == "" || source.Path == "" {
+			return nil, fmt.Errorf("sources need a name and a path")
+		}
+		if _, ok := trustLevels[source.Trust]; !ok {
+			return nil, fmt.Errorf("invalid trust level '%s' for source %s", source.Trust, source.Name)
+		}
+		if source.Path, err = filepath.Abs(source.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	return sources, nil
+}
+
+// add registers a source unless its directory is already known
+func (t *templateSources) add(name, path, trust string) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+
+	for _, source := range t.Sources {
+		if source.Path == path {
+			return
+		}
+	}
+
+	t.Sources = append(t.Sources, &templateSource{Name: name, Path: path, Trust: trust})
+}
+
+// lookup returns the source containing a template file.
+//
+// The most specific directory wins so a source can be nested in another one.
+// Files outside of the known sources are untrusted local templates.
+func (t *templateSources) lookup(file string) *templateSource {
+	found := &templateSource{Name: localSource, Trust: "untrusted"}
+
+	file, err := filepath.Abs(file)
+	if err != nil || t == nil {
+		return found
+	}
+
+	for _, source := range t.Sources {
+		if file != source.Path && !strings.HasPrefix(file, source.Path+string(filepath.Separator)) {
+			continue
+		}
+		if len(source.Path) > len(found.Path) {
+			found = source
+		}
+	}
+
+	return found
+}
+
+// setProvenance records the source of a template in its info so that it
+// is reported in the findings and usable by the target routing rules.
+//
+// Values set by the template itself are overwritten as they can't be trusted.
+func (r *Runner) setProvenance(info map[string]string, file string) map[string]string {
+	if info == nil {
+		info = make(map[string]string)
+	}
+
+	source := r.sources.lookup(file)
+	info["source"] = source.Name
+	info["trust"] = source.Trust
+
+	return info
+}
@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/internal/tracelog"
+)
+
+// slowestTemplates is the number of templates listed by runtime in the summary
+const slowestTemplates = 10
+
+// scanSummary collects the statistics of the scan printed once it's done.
+//
+// It wraps the trace log as every request sent by the executers is
+// reported to it along with its error.
+type scanSummary struct {
+	tracelog.Log
+
+	start    time.Time
+	requests int64
+
+	mutex    sync.Mutex
+	errors   map[string]int64
+	runtimes map[string]time.Duration
+	findings map[string]int64
+}
+
+// summaryReport is the summary written to the json summary file
+type summaryReport struct {
+	ScanID            string             `json:"scan_id"`
+	Duration          float64            `json:"duration"`
+	Requests          int64              `json:"requests"`
+	RequestsPerSecond float64            `json:"requests_per_second"`
+	Errors            map[string]int64   `json:"errors"`
+	SlowestTemplates  []*templateRuntime `json:"slowest_templates"`
+	Findings          map[string]int64   `json:"findings"`
+}
+
+// templateRuntime is the time spent running a template on all the targets
+type templateRuntime struct {
+	ID      string  `json:"id"`
+	Runtime float64 `json:"runtime"`
+}
+
+// newScanSummary starts the statistics of a scan, reporting the requests to the trace log
+func newScanSummary(traceLog tracelog.Log) *scanSummary {
+	return &scanSummary{
+		Log:      traceLog,
+		start:    time.Now(),
+		errors:   make(map[string]int64),
+		runtimes: make(map[string]time.Duration),
+		findings: make(map[string]int64),
+	}
+}
+
+// Request counts a request and its error before writing it to the trace log
+func (s *scanSummary) Request(templateID, url, requestType string, err error) {
+	s.Log.Request(templateID, url, requestType, err)
+
+	if requestType != "panic" {
+		atomic.AddInt64(&s.requests, 1)
+	}
+	if err == nil {
+		return
+	}
+
+	kind := errorKind(err)
+	if requestType == "panic" {
+		kind = "panic"
+	}
+
+	s.mutex.Lock()
+	s.errors[kind]++
+	s.mutex.Unlock()
+}
+
+// templateDone records the runtime of a template on a target and whether it matched
+func (s *scanSummary) templateDone(templateID, severity string, runtime time.Duration, matched bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.runtimes[templateID] += runtime
+	if matched {
+		if severity == "" {
+			severity = "unknown"
+		}
+		s.findings[strings.ToLower(severity)]++
+	}
+}
+
+// errorKind returns the category of a request error
+func errorKind(err error) string {
+	message := strings.ToLower(err.Error())
+
+	switch {
+	case strings.Contains(message, "timeout"), strings.Contains(message, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(message, "connection refused"):
+		return "connection-refused"
+	case strings.Contains(message, "connection reset"), strings.Contains(message, "broken pipe"):
+		return "connection-reset"
+	case strings.Contains(message, "no such host"), strings.Contains(message, "server misbehaving"):
+		return "dns"
+	case strings.Contains(message, "tls"), strings.Contains(message, "x509"), strings.Contains(message, "certificate"):
+		return "tls"
+	case strings.Contains(message, "eof"):
+		return "eof"
+	}
+
+	return "other"
+}
+
+// report returns the summary of the scan so far
+func (s *scanSummary) report() *summaryReport {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	duration := time.Since(s.start)
+	requests := atomic.LoadInt64(&s.requests)
+
+	report := &summaryReport{
+		Duration: duration.Seconds(),
+		Requests: requests,
+		Errors:   make(map[string]int64, len(s.errors)),
+		Findings: make(map[string]int64, len(s.findings)),
+	}
+	if duration > 0 {
+		report.RequestsPerSecond = float64(requests) / duration.Seconds()
+	}
+	for kind, count := range s.errors {
+		report.Errors[kind] = count
+	}
+	for severity, count := range s.findings {
+		report.Findings[severity] = count
+	}
+
+	for id, runtime := range s.runtimes {
+		report.SlowestTemplates = append(report.SlowestTemplates, &templateRuntime{ID: id, Runtime: runtime.Seconds()})
+	}
+	sort.Slice(report.SlowestTemplates, func(i, j int) bool {
+		if report.SlowestTemplates[i].Runtime == report.SlowestTemplates[j].Runtime {
+			return report.SlowestTemplates[i].ID < report.SlowestTemplates[j].ID
+		}
+		return report.SlowestTemplates[i].Runtime > report.SlowestTemplates[j].Runtime
+	})
+	if len(report.SlowestTemplates) > slowestTemplates {
+		report.SlowestTemplates = report.SlowestTemplates[:slowestTemplates]
+	}
+
+	return report
+}
+
+// print logs the summary of the scan on the console
+func (r *summaryReport) print() {
+	gologger.Infof("Scan finished in %s: %d requests (%.1f/s)\n", time.Duration(r.Duration*float64(time.Second)).Round(time.Millisecond), r.Requests, r.RequestsPerSecond)
+
+	if len(r.Errors) > 0 {
+		gologger.Infof("Errors: %s\n", formatCounts(r.Errors))
+	}
+	if len(r.Findings) > 0 {
+		gologger.Infof("Findings: %s\n", formatCounts(r.Findings))
+	}
+
+	for i, template := range r.SlowestTemplates {
+		if i == 0 {
+			gologger.Infof("Slowest templates:\n")
+		}
+		gologger.Infof("  %s (%.1fs)\n", template.ID, template.Runtime)
+	}
+}
+
+// write writes the summary of the scan to a json file
+func (r *summaryReport) write(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return jsoniter.NewEncoder(f).Encode(r)
+}
+
+// formatCounts formats counts by name sorted by decreasing count
+func formatCounts(counts map[string]int64) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] == counts[names[j]] {
+			return names[i] < names[j]
+		}
+		return counts[names[i]] > counts[names[j]]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + strconv.FormatInt(counts[name], 10)
+	}
+
+	return strings.Join(parts, ", ")
+}
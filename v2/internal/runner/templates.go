@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/karrick/godirwalk"
 	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/saferegex"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
 	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
 )
@@ -25,6 +28,16 @@ func (r *Runner) getTemplatesFor(definitions []string) []string {
 
 		var err error
 
+		// remote definitions are resolved to their cached local copy
+		if isRemoteDefinition(t) {
+			local, err := r.resolveRemote(t)
+			if err != nil {
+				gologger.Errorf("Could not load remote templates '%s': %s\n", redactURL(t), err)
+				continue
+			}
+			t = local
+		}
+
 		if strings.Contains(t, "*") {
 			dirs := strings.Split(t, "/")
 			priorDir := strings.Join(dirs[:len(dirs)-1], "/")
@@ -122,33 +135,57 @@ func (r *Runner) getTemplatesFor(definitions []string) []string {
 }
 
 // getParsedTemplatesFor parse the specified templates and returns a slice of the parsable ones, optionally filtered
-// by severity, along with a flag indicating if workflows are present.
-func (r *Runner) getParsedTemplatesFor(templatePaths []string, severities string) (parsedTemplates []interface{}, workflowCount int) {
+// by tags, severity and author, along with a flag indicating if workflows are present.
+func (r *Runner) getParsedTemplatesFor(templatePaths []string, filter *templates.Filter) (parsedTemplates []interface{}, workflowCount int) {
 	workflowCount = 0
-	severities = strings.ToLower(severities)
-	allSeverities := strings.Split(severities, ",")
-	filterBySeverity := len(severities) > 0
 
 	gologger.Infof("Loading templates...")
 
 	for _, match := range templatePaths {
+		// templates are filtered on their info before being compiled
+		if filter != nil {
+			if id, info, workflow, err := templates.ReadInfo(match); err == nil && !workflow {
+				if ok, reason := filter.Match(info); !ok {
+					gologger.Warningf("Excluding template %s due to filters (%s)", id, reason)
+					continue
+				}
+			}
+		}
+
 		t, err := r.parseTemplateFile(match)
 		switch tp := t.(type) {
 		case *templates.Template:
-			// only include if severity matches or no severity filtering
-			sev := strings.ToLower(tp.Info["severity"])
-			if !filterBySeverity || hasMatchingSeverity(sev, allSeverities) {
-				parsedTemplates = append(parsedTemplates, tp)
-				gologger.Infof("%s\n", r.templateLogMsg(tp.ID, tp.Info["name"], tp.Info["author"], tp.Info["severity"]))
-			} else {
-				gologger.Warningf("Excluding template %s due to severity filter (%s not in [%s])", tp.ID, sev, severities)
+			if !r.filterProtocols(tp) {
+				gologger.Warningf("Excluding template %s as all its protocols are disabled", tp.ID)
+				continue
 			}
+			tp.Info = r.setProvenance(tp.Info, match)
+
+			if r.options.Offline && requiresExternalServices(tp) {
+				gologger.Fatalf("Template %s requires external services which are unavailable in offline mode\n", tp.ID)
+			}
+
+			// parallel requests are sent serially in deterministic mode
+			if r.options.Deterministic {
+				for _, request := range tp.BulkRequestsHTTP {
+					request.Threads = 0
+				}
+			}
+
+			if len(tp.RequestsHeadless) > 0 && !r.options.Headless {
+				gologger.Warningf("Skipping headless requests of %s, use -headless to execute them", tp.ID)
+			}
+
+			parsedTemplates = append(parsedTemplates, tp)
+			gologger.Infof("%s\n", r.templateLogMsg(tp.ID, tp.Info["name"], tp.Info["author"], tp.Info["severity"]))
 		case *workflows.Workflow:
+			tp.Info = r.setProvenance(tp.Info, match)
 			parsedTemplates = append(parsedTemplates, tp)
 			gologger.Infof("%s\n", r.templateLogMsg(tp.ID, tp.Info["name"], tp.Info["author"], tp.Info["severity"]))
 			workflowCount++
 		default:
 			gologger.Errorf("Could not parse file '%s': %s\n", match, err)
+			r.loadReport.report(match, err)
 		}
 	}
 
@@ -156,14 +193,19 @@ func (r *Runner) getParsedTemplatesFor(templatePaths []string, severities string
 }
 
 func (r *Runner) parseTemplateFile(file string) (interface{}, error) {
+	data, err := r.readTemplateFile(file)
+	if err != nil {
+		return nil, err
+	}
+
 	// check if it's a template
-	template, errTemplate := templates.Parse(file)
+	template, errTemplate := r.templateCache.ParseBytes(file, data)
 	if errTemplate == nil {
 		return template, nil
 	}
 
 	// check if it's a workflow
-	workflow, errWorkflow := workflows.Parse(file)
+	workflow, errWorkflow := workflows.ParseBytes(file, data)
 	if errWorkflow == nil {
 		return workflow, nil
 	}
@@ -256,16 +298,6 @@ func (r *Runner) resolvePathIfRelative(filePath string) (string, error) {
 	return filePath, nil
 }
 
-func hasMatchingSeverity(templateSeverity string, allowedSeverities []string) bool {
-	for _, s := range allowedSeverities {
-		if s != "" && strings.HasPrefix(templateSeverity, s) {
-			return true
-		}
-	}
-
-	return false
-}
-
 func directoryWalker(fsPath string, callback func(fsPath string, d *godirwalk.Dirent) error) error {
 	err := godirwalk.Walk(fsPath, &godirwalk.Options{
 		Callback: callback,
@@ -300,3 +332,66 @@ func isNewPath(filePath string, pathMap map[string]bool) bool {
 
 	return true
 }
+
+// printSlowRegexes warns about the regexes which were slow to evaluate
+// along with the templates using them.
+func printSlowRegexes(parsed []interface{}) {
+	slow := saferegex.Slow()
+	if len(slow) == 0 {
+		return
+	}
+
+	users := make(map[string][]string)
+
+	for _, t := range parsed {
+		template, ok := t.(*templates.Template)
+		if !ok {
+			continue
+		}
+
+		var patterns []string
+
+		for _, request := range template.Requests() {
+			for _, matcher := range request.Operators.Matchers {
+				patterns = append(patterns, matcher.Regex...)
+			}
+			for _, extractor := range request.Operators.Extractors {
+				patterns = append(patterns, extractor.Regex...)
+			}
+		}
+
+		for _, pattern := range patterns {
+			if _, ok := slow[pattern]; ok {
+				users[pattern] = append(users[pattern], template.ID)
+			}
+		}
+	}
+
+	patterns := make([]string, 0, len(slow))
+	for pattern := range slow {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		gologger.Warningf("Slow regex '%s' took up to %s in templates [%s]\n", pattern, slow[pattern], strings.Join(users[pattern], ","))
+	}
+}
+
+// clusterTemplates groups the identical http requests of the templates so
+// they are sent once per target, returning nil if there are none.
+func clusterTemplates(availableTemplates []interface{}) *executer.Cluster {
+	var templateList []*templates.Template
+	for _, t := range availableTemplates {
+		if template, ok := t.(*templates.Template); ok {
+			templateList = append(templateList, template)
+		}
+	}
+
+	cluster := executer.NewCluster(templateList)
+	if cluster != nil {
+		gologger.Verbosef("Clustered %d http requests shared by several templates\n", "cluster", cluster.Len())
+	}
+
+	return cluster
+}
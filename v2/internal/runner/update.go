@@ -18,6 +18,7 @@ import (
 	"github.com/blang/semver"
 	"github.com/google/go-github/v32/github"
 	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
 )
 
 const (
@@ -151,9 +152,25 @@ func (r *Runner) updateTemplates() error {
 	return nil
 }
 
+// updateClient returns the http client used to download the templates,
+// trusting the custom ca so updates work behind tls interception.
+func (r *Runner) updateClient() *http.Client {
+	if r.options.CACert == "" {
+		return http.DefaultClient
+	}
+
+	verify := false
+	tlsConfig, err := tlsconfig.New(&tlsconfig.Options{CACert: r.options.CACert, InsecureSkipVerify: &verify})
+	if err != nil {
+		return http.DefaultClient
+	}
+
+	return &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}}
+}
+
 // getLatestReleaseFromGithub returns the latest release from github
 func (r *Runner) getLatestReleaseFromGithub() (semver.Version, *github.RepositoryRelease, error) {
-	client := github.NewClient(nil)
+	client := github.NewClient(r.updateClient())
 
 	rels, _, err := client.Repositories.ListReleases(context.Background(), userName, repoName, nil)
 	if err != nil {
@@ -202,7 +219,7 @@ func (r *Runner) downloadReleaseAndUnzip(ctx context.Context, downloadURL string
 		return fmt.Errorf("failed to create HTTP request to %s: %s", downloadURL, err)
 	}
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := r.updateClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to download a release file from %s: %s", downloadURL, err)
 	}
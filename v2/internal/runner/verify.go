@@ -0,0 +1,220 @@
+package runner
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+)
+
+// verifyState is the remediation status of a verified finding
+type verifyState int
+
+// The states are ordered, a pair only moves to a higher one while verified
+const (
+	// verifyPending is the state of a pair which was not run, eg. skipped
+	verifyPending verifyState = iota
+	// verifyFixed is the state of a pair run without reproducing the finding
+	verifyFixed
+	// verifyErrored is the state of a pair which could not be run completely
+	verifyErrored
+	// verifyReproduced is the state of a pair which reproduced the finding
+	verifyReproduced
+)
+
+// verification contains the template/target pairs of a previous run
+// which are checked again in fix-verification mode.
+type verification struct {
+	sync.Mutex
+	// pairs maps template IDs to targets and their remediation status
+	pairs map[string]map[string]verifyState
+}
+
+// readVerification reads the template/target pairs from a json output file
+func readVerification(file string) (*verification, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	v := &verification{pairs: make(map[string]map[string]verifyState)}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxFindingSize)
+
+	for scanner.Scan() {
+		finding := &replayFinding{}
+		if err := jsoniter.Unmarshal(scanner.Bytes(), finding); err != nil {
+			gologger.Warningf("Could not unmarshal finding: %s\n", err)
+			continue
+		}
+
+		if finding.Template == "" || finding.Matched == "" {
+			continue
+		}
+
+		if _, ok := v.pairs[finding.Template]; !ok {
+			v.pairs[finding.Template] = make(map[string]verifyState)
+		}
+		target := finding.Target
+		if target == "" {
+			target = findingTarget(finding.Matched)
+		}
+		// the targets are matched against the normalized input of the scan
+		v.pairs[finding.Template][normalizeInput(target)] = verifyPending
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// findingTarget returns the input target a matched URL was likely produced
+// from, for the findings written before their target was recorded
+func findingTarget(matched string) string {
+	u, err := url.Parse(matched)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return matched
+	}
+
+	return u.Scheme + "://" + u.Host
+}
+
+// targets returns the unique targets of the verified findings
+func (v *verification) targets() []string {
+	unique := make(map[string]struct{})
+
+	for _, targets := range v.pairs {
+		for target := range targets {
+			unique[target] = struct{}{}
+		}
+	}
+
+	list := make([]string, 0, len(unique))
+	for target := range unique {
+		list = append(list, target)
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+// filter returns the parsed templates which produced one of the verified
+// findings, and the workflows with such a template among their members
+func (v *verification) filter(parsed []interface{}, members func(*workflows.Workflow) []*templates.Template) (filtered []interface{}, workflowCount int) {
+	for _, t := range parsed {
+		switch template := t.(type) {
+		case *templates.Template:
+			if _, found := v.pairs[template.ID]; found {
+				filtered = append(filtered, template)
+			}
+		case *workflows.Workflow:
+			for _, member := range members(template) {
+				if _, found := v.pairs[member.ID]; found {
+					filtered = append(filtered, template)
+					workflowCount++
+					break
+				}
+			}
+		}
+	}
+
+	return filtered, workflowCount
+}
+
+// allows returns true if the template has to be run on the target
+func (v *verification) allows(templateID, target string) bool {
+	if v == nil {
+		return true
+	}
+
+	v.Lock()
+	defer v.Unlock()
+
+	_, ok := v.pairs[templateID][target]
+
+	return ok
+}
+
+// allowsAny returns true if one of the templates has to be run on the target
+func (v *verification) allowsAny(templateIDs []string, target string) bool {
+	for _, templateID := range templateIDs {
+		if v.allows(templateID, target) {
+			return true
+		}
+	}
+
+	return v == nil
+}
+
+// report records whether a verified finding was reproduced, a finding
+// is only fixed if the template could be run without errors
+func (v *verification) report(templateID, target string, reproduced bool, err error) {
+	if v == nil {
+		return
+	}
+
+	state := verifyFixed
+	if reproduced {
+		state = verifyReproduced
+	} else if err != nil {
+		state = verifyErrored
+	}
+
+	v.Lock()
+	defer v.Unlock()
+
+	if previous, ok := v.pairs[templateID][target]; ok && state > previous {
+		v.pairs[templateID][target] = state
+	}
+}
+
+// printReport writes the remediation status of every verified finding
+func (v *verification) printReport(colorizer *colorizer.NucleiColorizer) {
+	ids := make([]string, 0, len(v.pairs))
+	for id := range v.pairs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	reproduced, fixed, total := 0, 0, 0
+
+	for _, id := range ids {
+		targets := make([]string, 0, len(v.pairs[id]))
+		for target := range v.pairs[id] {
+			targets = append(targets, target)
+		}
+		sort.Strings(targets)
+
+		for _, target := range targets {
+			total++
+
+			// the pairs skipped or failing can't tell whether the finding was fixed
+			var status string
+			switch v.pairs[id][target] {
+			case verifyReproduced:
+				status = colorizer.Colorizer.Red("reproducible").String()
+				reproduced++
+			case verifyFixed:
+				status = colorizer.Colorizer.Green("fixed").String()
+				fixed++
+			default:
+				status = colorizer.Colorizer.Yellow("unverified").String()
+			}
+
+			gologger.Silentf("[%s] [%s] %s\n", colorizer.Colorizer.BrightBlue(id).String(), status, target)
+		}
+	}
+
+	gologger.Infof("Verified %d findings: %d still reproducible, %d fixed, %d unverified\n", total, reproduced, fixed, total-reproduced-fixed)
+}
@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationTargets(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-verify-")
+	require.Nil(t, err, "could not create directory")
+	defer os.RemoveAll(directory)
+
+	findings := `{"template":"exposed-logs","matched":"https://acme.local/app/logs","target":"https://acme.local/app/"}
+{"template":"exposed-config","matched":"https://acme.local:8443/config.json"}
+{"template":"exposed-admin","matched":"https://ACME.local:443/admin","target":"HTTPS://ACME.local:443/"}
+`
+	file := filepath.Join(directory, "findings.json")
+	require.Nil(t, ioutil.WriteFile(file, []byte(findings), 0644), "could not write findings")
+
+	v, err := readVerification(file)
+	require.Nil(t, err, "could not read verification")
+
+	require.True(t, v.allows("exposed-logs", "https://acme.local/app"), "the recorded input target was not kept")
+	require.False(t, v.allows("exposed-logs", "https://acme.local"), "the recorded target was reduced to its host")
+	require.True(t, v.allows("exposed-config", "https://acme.local:8443"), "the target of an older finding was not derived from its url")
+	require.True(t, v.allows("exposed-admin", "https://acme.local"), "the recorded input target was not normalized")
+	require.Equal(t, []string{"https://acme.local", "https://acme.local/app", "https://acme.local:8443"}, v.targets(), "unexpected verified targets")
+}
+
+func TestVerificationStates(t *testing.T) {
+	v := &verification{pairs: map[string]map[string]verifyState{
+		"exposed-logs": {"https://fixed.local": verifyPending, "https://failing.local": verifyPending, "https://skipped.local": verifyPending, "https://vulnerable.local": verifyPending},
+	}}
+
+	v.report("exposed-logs", "https://fixed.local", false, nil)
+	v.report("exposed-logs", "https://failing.local", false, nil)
+	v.report("exposed-logs", "https://failing.local", false, errors.New("connection refused"))
+	v.report("exposed-logs", "https://vulnerable.local", false, errors.New("connection refused"))
+	v.report("exposed-logs", "https://vulnerable.local", true, nil)
+	v.report("exposed-logs", "https://unknown.local", true, nil)
+
+	require.Equal(t, map[string]verifyState{
+		"https://fixed.local":      verifyFixed,
+		"https://failing.local":    verifyErrored,
+		"https://skipped.local":    verifyPending,
+		"https://vulnerable.local": verifyReproduced,
+	}, v.pairs["exposed-logs"], "unexpected remediation states")
+}
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+)
+
+// whatIfTarget is the plan of the scan of a target
+type whatIfTarget struct {
+	Target    string   `json:"target"`
+	Templates []string `json:"templates,omitempty"`
+	Workflows []string `json:"workflows,omitempty"`
+	Requests  int64    `json:"requests"`
+}
+
+// printWhatIf prints the templates and workflows which would run
+// on each target with the number of requests, without sending any.
+//
+// The routes and verification filters are applied as they would be
+// during the scan. Workflows decide which requests are sent while
+// running, so they are listed without counting their requests.
+func (r *Runner) printWhatIf(availableTemplates []interface{}) {
+	var total int64
+	targets := 0
+
+	// the workflows are verified on the targets of their members findings
+	members := make(map[string][]string)
+	if r.verification != nil {
+		for _, t := range availableTemplates {
+			if workflow, ok := t.(*workflows.Workflow); ok {
+				for _, member := range r.workflowMembers(workflow) {
+					members[workflow.ID] = append(members[workflow.ID], member.ID)
+				}
+			}
+		}
+	}
+
+	for _, target := range strings.Split(strings.TrimSpace(r.input), "\n") {
+		if target == "" {
+			continue
+		}
+		plan := &whatIfTarget{Target: target}
+
+		for _, t := range availableTemplates {
+			switch template := t.(type) {
+			case *templates.Template:
+				if !r.routes.allows(target, template.Info, nil, r.labels.of(target)) || !r.verification.allows(template.ID, target) {
+					continue
+				}
+				requests := template.GetHTTPRequestCount() + template.GetDNSRequestCount() + template.GetNetworkRequestCount() + template.GetSSLRequestCount() + template.GetWhoisRequestCount() + template.GetWebsocketRequestCount() + template.GetFileRequestCount() + template.GetGRPCRequestCount()
+				if r.options.Headless {
+					requests += template.GetHeadlessRequestCount()
+				}
+				if requests == 0 {
+					continue
+				}
+				plan.Templates = append(plan.Templates, template.ID)
+				plan.Requests += requests
+			case *workflows.Workflow:
+				if r.routes.allows(target, template.Info, nil, r.labels.of(target)) && r.verification.allowsAny(members[template.ID], target) {
+					plan.Workflows = append(plan.Workflows, template.ID)
+				}
+			}
+		}
+
+		if len(plan.Templates) == 0 && len(plan.Workflows) == 0 {
+			continue
+		}
+		targets++
+		total += plan.Requests
+		plan.print(r.options.JSON)
+	}
+
+	gologger.Infof("What-if: %d targets would be scanned with %d requests, no traffic was sent\n", targets, total)
+}
+
+// print writes the plan of a target to the standard output
+func (w *whatIfTarget) print(json bool) {
+	if json {
+		data, err := jsoniter.Marshal(w)
+		if err != nil {
+			gologger.Warningf("Could not marshal json output: %s\n", err)
+			return
+		}
+		gologger.Silentf("%s\n", string(data))
+		return
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString(w.Target)
+	builder.WriteString(" [")
+	builder.WriteString(strconv.FormatInt(w.Requests, 10))
+	builder.WriteString(" requests]")
+	if len(w.Templates) > 0 {
+		builder.WriteString(" [templates=")
+		builder.WriteString(strings.Join(w.Templates, ","))
+		builder.WriteString("]")
+	}
+	if len(w.Workflows) > 0 {
+		builder.WriteString(" [workflows=")
+		builder.WriteString(strings.Join(w.Workflows, ","))
+		builder.WriteString("]")
+	}
+
+	gologger.Silentf("%s\n", builder.String())
+}
@@ -0,0 +1,309 @@
+package runner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tengo "github.com/d5/tengo/v2"
+	"github.com/projectdiscovery/nuclei/v2/internal/tracelog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/atomicboolean"
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+	"github.com/stretchr/testify/require"
+)
+
+const workflowStepTemplate = `id: workflow-step
+info:
+  name: Workflow step
+  author: nuclei
+  severity: info
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}/step"
+    matchers:
+      - type: word
+        words:
+          - "step reached"
+`
+
+const workflowDefinition = `id: step-workflow
+info:
+  name: Step workflow
+  author: nuclei
+variables:
+  step: step.yaml
+logic: step()
+`
+
+// workflowTestTimeout bounds the waits of the tests, far beyond the
+// workflow timeouts and below the timeout of the requests
+const workflowTestTimeout = 30 * time.Second
+
+// writeWorkflowFiles writes the files of a test workflow to a temporary
+// directory removed at the end of the test
+func writeWorkflowFiles(t *testing.T, files map[string]string) string {
+	directory, err := ioutil.TempDir("", "nuclei-workflow-")
+	require.Nil(t, err, "could not create workflow directory")
+	t.Cleanup(func() { os.RemoveAll(directory) })
+
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(directory, name), []byte(content), 0644)
+		require.Nil(t, err, "could not write workflow file")
+	}
+
+	return directory
+}
+
+// workflowOptions writes the step template and the workflow, replaced by
+// the given files if any, and returns the options running the workflow
+// on a target one request at a time, with the directory of the files.
+func workflowOptions(t *testing.T, target string, files map[string]string) (*Options, string) {
+	all := map[string]string{"step.yaml": workflowStepTemplate, "workflow.yaml": workflowDefinition}
+	for name, content := range files {
+		all[name] = content
+	}
+	directory := writeWorkflowFiles(t, all)
+
+	return &Options{
+		Target:              target,
+		Templates:           []string{filepath.Join(directory, "workflow.yaml")},
+		Output:              filepath.Join(directory, "output.txt"),
+		NoInteractsh:        true,
+		Timeout:             5,
+		Threads:             1,
+		BulkSize:            1,
+		TemplateThreads:     1,
+		WorkflowConcurrency: 1,
+		RateLimit:           150,
+	}, directory
+}
+
+// runWorkflow runs a scan with the options, returning once it is closed
+func runWorkflow(t *testing.T, options *Options) {
+	nucleiRunner, err := New(options)
+	require.Nil(t, err, "could not create runner")
+	nucleiRunner.RunEnumeration()
+	nucleiRunner.Close()
+}
+
+// receive waits for a signal, failing the test if it isn't sent in time
+func receive(t *testing.T, signal <-chan struct{}, what string) {
+	select {
+	case <-signal:
+	case <-time.After(workflowTestTimeout):
+		t.Fatalf("%s", what)
+	}
+}
+
+func TestWorkflowHTTPStep(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/step" {
+			fmt.Fprint(w, "step reached")
+		}
+	}))
+	defer server.Close()
+
+	options, _ := workflowOptions(t, server.URL, nil)
+	runWorkflow(t, options)
+
+	require.NotZero(t, atomic.LoadInt32(&requests), "the workflow step sent no request")
+
+	data, err := ioutil.ReadFile(options.Output)
+	require.Nil(t, err, "could not read the findings")
+	require.True(t, strings.Contains(string(data), "[workflow-step]"), "the workflow step did not match: %s", data)
+}
+
+func TestWorkflowStepPanic(t *testing.T) {
+	r := &Runner{
+		traceLog:       &tracelog.NoopLogger{},
+		templateErrors: newTemplateErrors(1),
+		panicked:       atomicboolean.New(),
+	}
+
+	// the missing progress makes the execution of the template panic
+	template := &workflows.Template{HTTPOptions: &executer.HTTPOptions{CommonOptions: executer.CommonOptions{Template: &templates.Template{ID: "panicking-step"}}}}
+	variable := &workflows.NucleiVar{
+		Templates: []*workflows.Template{template},
+		URL:       "http://127.0.0.1",
+		Disabled:  r.templateErrors.isDisabled,
+		Report:    r.workflowReporter("http://127.0.0.1"),
+		Recovered: r.reportPanic,
+	}
+
+	result, err := variable.Call()
+	require.Nil(t, err, "the panic was not recovered")
+	require.Equal(t, tengo.FalseValue, result, "the panicking step matched")
+	require.True(t, r.Failed(), "the panic did not fail the scan")
+	require.True(t, r.templateErrors.isDisabled("panicking-step"), "the panic was not counted as a template error")
+}
+
+func TestWorkflowReporterBlocksHost(t *testing.T) {
+	r := &Runner{
+		templateErrors: newTemplateErrors(1),
+		blocked:        newBlockedHosts("high"),
+	}
+	report := r.workflowReporter("http://acme.local/app")
+
+	report("low-step", "low", &executer.Result{GotResults: true})
+	require.False(t, r.blocked.isBlocked("http://acme.local"), "a low finding of a step blocked the host")
+
+	report("critical-step", "critical", &executer.Result{})
+	require.False(t, r.blocked.isBlocked("http://acme.local"), "a critical step without finding blocked the host")
+
+	report("critical-step", "critical", &executer.Result{GotResults: true})
+	require.True(t, r.blocked.isBlocked("http://acme.local"), "the critical finding of a step did not block the host")
+}
+
+func TestWorkflowIntrusiveStep(t *testing.T) {
+	intrusiveStep := strings.Replace(workflowStepTemplate, "  severity: info\n", "  severity: info\n  safety: intrusive\n", 1)
+
+	for _, allowed := range []bool{false, true} {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+		}))
+
+		options, _ := workflowOptions(t, server.URL, map[string]string{"step.yaml": intrusiveStep})
+		options.AllowIntrusive = allowed
+		runWorkflow(t, options)
+		server.Close()
+
+		if allowed {
+			require.NotZero(t, atomic.LoadInt32(&requests), "the allowed intrusive step sent no request")
+		} else {
+			require.Zero(t, atomic.LoadInt32(&requests), "the intrusive step ran without -allow-intrusive")
+		}
+	}
+}
+
+func TestWorkflowDoSStep(t *testing.T) {
+	dosStep := strings.Replace(workflowStepTemplate, "  severity: info\n", "  severity: info\n  tags: dos\n", 1)
+
+	for _, allowed := range []bool{false, true} {
+		var (
+			mutex             sync.Mutex
+			inFlight, maximum int
+			times             []time.Time
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			inFlight++
+			if inFlight > maximum {
+				maximum = inFlight
+			}
+			times = append(times, time.Now())
+			mutex.Unlock()
+
+			time.Sleep(50 * time.Millisecond)
+
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+		}))
+
+		options, directory := workflowOptions(t, "", map[string]string{
+			"step.yaml":   dosStep,
+			"targets.txt": server.URL + "/first\n" + server.URL + "/second\n",
+		})
+		options.Targets = filepath.Join(directory, "targets.txt")
+		options.AllowDoS = allowed
+		options.Threads, options.BulkSize, options.TemplateThreads, options.WorkflowConcurrency = 2, 2, 2, 2
+		runWorkflow(t, options)
+		server.Close()
+
+		if !allowed {
+			require.Empty(t, times, "the dos step ran without -allow-dos")
+			continue
+		}
+		require.Len(t, times, 2, "the dos step did not run on both targets")
+		require.Equal(t, 1, maximum, "the dos step ran on several targets at a time")
+		require.True(t, times[1].Sub(times[0]) >= minDoSDelay, "the dos step requests were not delayed")
+	}
+}
+
+// cancellableServer starts a server whose requests hang until cancelled
+// by the client, recording their paths and signalling their cancellation
+func cancellableServer(t *testing.T) (server *httptest.Server, paths func() []string, cancelled <-chan struct{}) {
+	var mutex sync.Mutex
+	var received []string
+	signal := make(chan struct{}, 16)
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		received = append(received, r.URL.Path)
+		mutex.Unlock()
+
+		<-r.Context().Done()
+		signal <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+
+	paths = func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return append([]string(nil), received...)
+	}
+
+	return server, paths, signal
+}
+
+// runTimedWorkflow runs the step in a workflow timing out after a second,
+// with requests timing out long after the test would fail, returning a
+// channel closed once the scan is done.
+func runTimedWorkflow(t *testing.T, target, step string) <-chan struct{} {
+	timedWorkflow := strings.Replace(workflowDefinition, "logic:", "timeout: 1\nlogic:", 1)
+	options, _ := workflowOptions(t, target, map[string]string{"step.yaml": step, "workflow.yaml": timedWorkflow})
+	options.Timeout = 2 * int(workflowTestTimeout/time.Second)
+
+	nucleiRunner, err := New(options)
+	require.Nil(t, err, "could not create runner")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		nucleiRunner.RunEnumeration()
+		nucleiRunner.Close()
+	}()
+
+	return done
+}
+
+func TestWorkflowTimeoutInterruptsStep(t *testing.T) {
+	server, paths, cancelled := cancellableServer(t)
+
+	done := runTimedWorkflow(t, server.URL, workflowStepTemplate)
+
+	receive(t, done, "the workflow timeout did not interrupt the hanging step")
+	receive(t, cancelled, "the request of the interrupted step was not cancelled")
+	require.Equal(t, []string{"/step"}, paths(), "unexpected requests of the step")
+}
+
+func TestWorkflowTimeoutCancelsRequests(t *testing.T) {
+	server, paths, cancelled := cancellableServer(t)
+
+	slowStep := strings.Replace(workflowStepTemplate, `      - "{{BaseURL}}/step"`, `      - "{{BaseURL}}/first"
+      - "{{BaseURL}}/second"
+      - "{{BaseURL}}/third"`, 1)
+	done := runTimedWorkflow(t, server.URL, slowStep)
+
+	receive(t, done, "the workflow timeout did not interrupt the step")
+	receive(t, cancelled, "the request in flight at the deadline was not cancelled")
+
+	// the server waits for the requests in flight, none is left hanging
+	server.Close()
+	require.Equal(t, []string{"/first"}, paths(), "requests were sent after the workflow deadline")
+}
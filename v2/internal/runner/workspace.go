@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// The files of a scan workspace
+const (
+	workspaceFindings     = "findings.txt"
+	workspaceJSONFindings = "findings.json"
+	workspaceResponses    = "responses"
+	workspaceTraceLog     = "trace.log"
+	workspaceResume       = "resume.json"
+	workspaceSummary      = "summary.json"
+	workspaceLoadReport   = "load-report.jsonl"
+	workspaceInventory    = "inventory.jsonl"
+)
+
+// workspaceLayout is the format of the timestamped directory of a scan
+const workspaceLayout = "20060102-150405"
+
+// setupWorkspace creates the workspace of a scan in the output directory and
+// points the file options the user didn't set to it: the findings, the
+// responses stored by -project, the trace log, the resume file, the summary,
+// the load report and the inventory. A directory holding the resume file of
+// an interrupted scan is reused as is, to continue it.
+func setupWorkspace(options *Options) error {
+	workspace := options.OutputDir
+	if _, err := os.Stat(filepath.Join(workspace, workspaceResume)); err != nil {
+		workspace = filepath.Join(options.OutputDir, time.Now().Format(workspaceLayout))
+	}
+	if err := os.MkdirAll(workspace, 0755); err != nil {
+		return err
+	}
+
+	findings := workspaceFindings
+	if options.JSON {
+		findings = workspaceJSONFindings
+	}
+	setWorkspaceFile(&options.Output, workspace, findings)
+	if options.Project {
+		setWorkspaceFile(&options.ProjectPath, workspace, workspaceResponses)
+	}
+	setWorkspaceFile(&options.TraceLogFile, workspace, workspaceTraceLog)
+	setWorkspaceFile(&options.Resume, workspace, workspaceResume)
+	setWorkspaceFile(&options.SummaryJSON, workspace, workspaceSummary)
+	setWorkspaceFile(&options.LoadReport, workspace, workspaceLoadReport)
+	setWorkspaceFile(&options.InventoryOutput, workspace, workspaceInventory)
+
+	gologger.Infof("Writing the files of the scan to %s\n", workspace)
+
+	return nil
+}
+
+// setWorkspaceFile sets a file option to its path in the workspace unless the user set it
+func setWorkspaceFile(option *string, workspace, name string) {
+	if *option == "" {
+		*option = filepath.Join(workspace, name)
+	}
+}
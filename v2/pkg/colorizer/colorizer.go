@@ -1,6 +1,7 @@
 package colorizer
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/logrusorgru/aurora"
@@ -31,6 +32,31 @@ func NewNucleiColorizer(colorizer aurora.Aurora) *NucleiColorizer {
 	}
 }
 
+// severityIcons are the symbols shown before the severities with EnableIcons
+var severityIcons = map[string]string{
+	"info":     "ℹ",
+	"low":      "●",
+	"medium":   "◆",
+	"high":     "▲",
+	"critical": "✖",
+}
+
+// EnableIcons prefixes the severities with an icon and pads them
+// to the same width so the columns after them are aligned.
+func (r *NucleiColorizer) EnableIcons() {
+	colors := map[string]func(arg interface{}) aurora.Value{
+		"info":     r.Colorizer.Blue,
+		"low":      r.Colorizer.Green,
+		"medium":   r.Colorizer.Yellow,
+		"high":     func(arg interface{}) aurora.Value { return r.Colorizer.Index(fgOrange, arg) },
+		"critical": r.Colorizer.Red,
+	}
+
+	for severity, color := range colors {
+		r.SeverityMap[severity] = color(fmt.Sprintf("%s %-8s", severityIcons[severity], severity)).String()
+	}
+}
+
 // GetColorizedSeverity returns the colorized severity string
 func (r *NucleiColorizer) GetColorizedSeverity(severity string) string {
 	sev := r.SeverityMap[strings.ToLower(severity)]
@@ -0,0 +1,296 @@
+package crawler
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxBodySize is the maximum number of bytes of a page parsed for links
+const maxBodySize = 2 * 1024 * 1024
+
+// Crawler spiders the pages of a host, following the links and
+// recording the forms found up to a maximum depth.
+type Crawler struct {
+	client   *http.Client
+	seeds    *Seeds
+	depth    int
+	maxPages int
+}
+
+// Form is a html form found while crawling
+type Form struct {
+	// Action is the absolute URL the form is submitted to
+	Action string
+	// Method is the uppercase method of the form
+	Method string
+	// Fields contains the names of the form inputs
+	Fields []string
+}
+
+// Result contains the endpoints discovered on a host
+type Result struct {
+	// URLs contains the crawled pages and the linked resources of the host
+	URLs []string
+	// Forms contains the forms of the crawled pages
+	Forms []Form
+}
+
+// New creates a crawler following links up to depth and fetching at most
+// maxPages pages per host. The paths of the seeds, if not nil, are crawled
+// as if they were linked from the start page. The redirects of the client
+// are only followed to the scheme and host of the crawled page.
+func New(client *http.Client, seeds *Seeds, depth, maxPages int) *Crawler {
+	scoped := *client
+	scoped.CheckRedirect = sameHostRedirect
+
+	return &Crawler{client: &scoped, seeds: seeds, depth: depth, maxPages: maxPages}
+}
+
+// maxRedirects is the maximum number of redirects followed for a page
+const maxRedirects = 10
+
+// sameHostRedirect stops the redirects leaving the scheme and host of the
+// first request, the redirect response being returned as the page then.
+func sameHostRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects || !sameHost(req.URL, via[0].URL) {
+		return http.ErrUseLastResponse
+	}
+
+	return nil
+}
+
+// sameHost returns true if two URLs have the same scheme and host
+func sameHost(a, b *url.URL) bool {
+	return a.Scheme == b.Scheme && a.Host == b.Host
+}
+
+// Crawl spiders the host of the start URL, only following the links and
+// keeping the forms of the same scheme and host.
+func (c *Crawler) Crawl(start string) (*Result, error) {
+	base, err := url.Parse(start)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+
+	seen := map[string]struct{}{normalize(base): {}}
+	forms := make(map[string]struct{})
+
+	type page struct {
+		URL   *url.URL
+		depth int
+	}
+	queue := []page{{URL: base}}
+	fetched := 0
+
+	if c.seeds != nil {
+		for _, path := range c.seeds.Paths(start) {
+			seed := resolve(base, path)
+			if seed == nil {
+				continue
+			}
+
+			key := normalize(seed)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			result.URLs = append(result.URLs, key)
+			if c.depth > 1 {
+				queue = append(queue, page{URL: seed, depth: 1})
+			}
+		}
+	}
+
+	for len(queue) > 0 && fetched < c.maxPages {
+		current := queue[0]
+		queue = queue[1:]
+
+		links, pageForms, err := c.fetch(current.URL)
+		fetched++
+		if err != nil {
+			continue
+		}
+
+		for _, form := range pageForms {
+			// forms submitted to other hosts are out of scope like their links
+			action, err := url.Parse(form.Action)
+			if err != nil || !sameHost(action, base) {
+				continue
+			}
+
+			key := form.Method + " " + form.Action + " " + strings.Join(form.Fields, ",")
+			if _, ok := forms[key]; !ok {
+				forms[key] = struct{}{}
+				result.Forms = append(result.Forms, form)
+			}
+		}
+
+		for _, link := range links {
+			if !sameHost(link, base) {
+				continue
+			}
+
+			key := normalize(link)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			result.URLs = append(result.URLs, key)
+			if current.depth+1 < c.depth {
+				queue = append(queue, page{URL: link, depth: current.depth + 1})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// fetch returns the links and forms of a html page
+func (c *Crawler) fetch(pageURL *url.URL) ([]*url.URL, []Form, error) {
+	resp, err := c.client.Get(pageURL.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return nil, nil, nil
+	}
+
+	// redirects are followed so relative links resolve against the final URL
+	base := resp.Request.URL
+
+	var (
+		links []*url.URL
+		forms []Form
+		form  *Form
+	)
+
+	tokenizer := html.NewTokenizer(io.LimitReader(resp.Body, maxBodySize))
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+		if tokenType == html.EndTagToken {
+			if token.Data == "form" && form != nil {
+				forms = append(forms, *form)
+				form = nil
+			}
+			continue
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		switch token.Data {
+		case "a", "link", "area":
+			links = appendLink(links, base, attribute(token, "href"))
+		case "script", "iframe", "frame", "img":
+			links = appendLink(links, base, attribute(token, "src"))
+		case "form":
+			action := base
+			if resolved := resolve(base, attribute(token, "action")); resolved != nil {
+				action = resolved
+			}
+			method := strings.ToUpper(attribute(token, "method"))
+			if method == "" {
+				method = http.MethodGet
+			}
+			form = &Form{Action: action.String(), Method: method}
+		case "input", "select", "textarea", "button":
+			if name := attribute(token, "name"); name != "" && form != nil {
+				form.Fields = append(form.Fields, name)
+			}
+		}
+	}
+
+	// unterminated forms end with the document
+	if form != nil {
+		forms = append(forms, *form)
+	}
+
+	return links, forms, nil
+}
+
+// attribute returns the value of an attribute of a tag
+func attribute(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+
+	return ""
+}
+
+// appendLink adds a link resolved against the page URL if it's valid
+func appendLink(links []*url.URL, base *url.URL, link string) []*url.URL {
+	if resolved := resolve(base, link); resolved != nil {
+		links = append(links, resolved)
+	}
+
+	return links
+}
+
+// resolve returns the absolute URL of a link, or nil for links which
+// can't be crawled such as javascript or mailto links.
+func resolve(base *url.URL, link string) *url.URL {
+	if link == "" || strings.HasPrefix(link, "#") {
+		return nil
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return nil
+	}
+
+	resolved := base.ResolveReference(parsed)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return nil
+	}
+
+	return resolved
+}
+
+// normalize returns the URL without its fragment for deduplication
+func normalize(u *url.URL) string {
+	normalized := *u
+	normalized.Fragment = ""
+
+	return normalized.String()
+}
+
+// SubmitURL returns the URL of a GET form submitted with placeholder
+// values, or an empty string if the form isn't submitted with GET.
+func (f *Form) SubmitURL() string {
+	if f.Method != http.MethodGet {
+		return ""
+	}
+
+	action, err := url.Parse(f.Action)
+	if err != nil {
+		return ""
+	}
+
+	query := action.Query()
+	for _, field := range f.Fields {
+		if query.Get(field) == "" {
+			query.Set(field, "1")
+		}
+	}
+	action.RawQuery = query.Encode()
+
+	return action.String()
+}
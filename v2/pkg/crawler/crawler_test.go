@@ -0,0 +1,40 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrawlScope(t *testing.T) {
+	var offHostRequests int
+	offHost := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offHostRequests++
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/off-host-page">page</a>`)
+	}))
+	defer offHost.Close()
+
+	start := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, offHost.URL+"/landing", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<a href="/moved">moved</a>
+<form action="/search"><input name="q"></form>
+<form action="%s/login" method="post"><input name="user"></form>`, offHost.URL)
+	}))
+	defer start.Close()
+
+	result, err := New(start.Client(), nil, 3, 10).Crawl(start.URL)
+	require.Nil(t, err, "could not crawl")
+
+	require.Equal(t, 0, offHostRequests, "a redirect off the start host was followed")
+	require.Len(t, result.Forms, 1, "a form submitted off the start host was kept")
+	require.Equal(t, start.URL+"/search", result.Forms[0].Action, "the form of the start host was not kept")
+	require.Equal(t, []string{start.URL + "/moved"}, result.URLs, "unexpected crawled urls")
+}
@@ -0,0 +1,197 @@
+package crawler
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxSeedPaths is the maximum number of paths seeded per host
+	maxSeedPaths = 1000
+	// maxSitemaps is the maximum number of sitemaps read per host, including nested ones
+	maxSitemaps = 10
+)
+
+// errNotFound is returned for the files missing on a host
+var errNotFound = errors.New("file not found")
+
+// Seeds fetches the paths listed in the robots.txt and sitemap.xml of
+// hosts, caching them so each host is only fetched once.
+type Seeds struct {
+	client *http.Client
+
+	mutex sync.Mutex
+	hosts map[string]*seedsEntry
+}
+
+// seedsEntry contains the paths of a host, fetched once
+type seedsEntry struct {
+	once  sync.Once
+	paths []string
+}
+
+// NewSeeds creates a seeds cache fetching the files with a client
+func NewSeeds(client *http.Client) *Seeds {
+	return &Seeds{client: client, hosts: make(map[string]*seedsEntry)}
+}
+
+// Paths returns the absolute paths listed for the host of a target URL
+func (s *Seeds) Paths(target string) []string {
+	if s == nil {
+		return nil
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	base := parsed.Scheme + "://" + parsed.Host
+
+	s.mutex.Lock()
+	entry, ok := s.hosts[base]
+	if !ok {
+		entry = &seedsEntry{}
+		s.hosts[base] = entry
+	}
+	s.mutex.Unlock()
+
+	entry.once.Do(func() {
+		entry.paths = s.fetch(base)
+	})
+
+	return entry.paths
+}
+
+// fetch reads the robots.txt of a host followed by its sitemaps
+func (s *Seeds) fetch(base string) []string {
+	seen := make(map[string]struct{})
+	var paths []string
+
+	add := func(path string) {
+		if path == "" || path == "/" || len(paths) >= maxSeedPaths {
+			return
+		}
+		if _, ok := seen[path]; !ok {
+			seen[path] = struct{}{}
+			paths = append(paths, path)
+		}
+	}
+
+	sitemaps := []string{base + "/sitemap.xml"}
+
+	if body, err := s.get(base + "/robots.txt"); err == nil {
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if comment := strings.IndexByte(line, '#'); comment != -1 {
+				line = line[:comment]
+			}
+
+			separator := strings.IndexByte(line, ':')
+			if separator == -1 {
+				continue
+			}
+			directive := strings.ToLower(strings.TrimSpace(line[:separator]))
+			value := strings.TrimSpace(line[separator+1:])
+
+			switch directive {
+			case "allow", "disallow":
+				add(robotsPath(value))
+			case "sitemap":
+				sitemaps = append(sitemaps, value)
+			}
+		}
+		body.Close()
+	}
+
+	read := make(map[string]struct{})
+
+	for len(sitemaps) > 0 && len(read) < maxSitemaps {
+		sitemap := sitemaps[0]
+		sitemaps = sitemaps[1:]
+
+		if _, ok := read[sitemap]; ok {
+			continue
+		}
+		read[sitemap] = struct{}{}
+
+		pages, nested, err := s.readSitemap(sitemap)
+		if err != nil {
+			continue
+		}
+		sitemaps = append(sitemaps, nested...)
+
+		for _, page := range pages {
+			if parsed, err := url.Parse(page); err == nil && parsed.Scheme+"://"+parsed.Host == base {
+				add(parsed.RequestURI())
+			}
+		}
+	}
+
+	return paths
+}
+
+// readSitemap returns the page locations of a sitemap, or
+// the nested sitemaps if it's a sitemap index.
+func (s *Seeds) readSitemap(sitemapURL string) (pages, sitemaps []string, err error) {
+	body, err := s.get(sitemapURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	var document struct {
+		URLs []struct {
+			Loc string `xml:"loc"`
+		} `xml:"url"`
+		Sitemaps []struct {
+			Loc string `xml:"loc"`
+		} `xml:"sitemap"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(body, maxBodySize)).Decode(&document); err != nil {
+		return nil, nil, err
+	}
+
+	for _, page := range document.URLs {
+		pages = append(pages, strings.TrimSpace(page.Loc))
+	}
+	for _, sitemap := range document.Sitemaps {
+		sitemaps = append(sitemaps, strings.TrimSpace(sitemap.Loc))
+	}
+
+	return pages, sitemaps, nil
+}
+
+// get returns the body of a successful response
+func (s *Seeds) get(fileURL string) (io.ReadCloser, error) {
+	resp, err := s.client.Get(fileURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errNotFound
+	}
+
+	return resp.Body, nil
+}
+
+// robotsPath returns the literal prefix of a robots.txt path rule,
+// the wildcards matching any path can't be requested.
+func robotsPath(rule string) string {
+	if wildcard := strings.IndexAny(rule, "*$"); wildcard != -1 {
+		rule = rule[:wildcard]
+	}
+	if !strings.HasPrefix(rule, "/") {
+		return ""
+	}
+
+	return rule
+}
@@ -0,0 +1,209 @@
+package executer
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/similarity"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+const (
+	// bypassPathCharset contains the characters of the missing path of the baseline
+	bypassPathCharset = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// bypassPathLength is the length of the missing path of the baseline
+	bypassPathLength = 16
+)
+
+// bypassMutation is a change of a request known to bypass access controls
+// of some servers. apply returns false if the mutation doesn't apply.
+type bypassMutation struct {
+	name  string
+	apply func(req *http.Request, path string) bool
+}
+
+// bypassMutations contains the mutations attempted on forbidden responses
+var bypassMutations = []bypassMutation{
+	{"trailing-slash", func(req *http.Request, path string) bool {
+		return !strings.HasSuffix(path, "/") && setLiteralPath(req, path+"/")
+	}},
+	{"trailing-dot", func(req *http.Request, path string) bool { return setLiteralPath(req, path+"/.") }},
+	{"double-slash", func(req *http.Request, path string) bool {
+		// an opaque path starting with // would be sent as an absolute URL
+		if req.URL.Opaque != "" {
+			return false
+		}
+		req.URL.Path = "/" + req.URL.Path
+		if req.URL.RawPath != "" {
+			req.URL.RawPath = "/" + req.URL.RawPath
+		}
+		return true
+	}},
+	{"dot-semicolon", func(req *http.Request, path string) bool { return setLiteralPath(req, path+"..;/") }},
+	{"encoded-dot", func(req *http.Request, path string) bool { return setLiteralPath(req, "/%2e"+path) }},
+	{"encoded-space", func(req *http.Request, path string) bool { return setLiteralPath(req, path+"%20") }},
+	{"uppercase", func(req *http.Request, path string) bool {
+		return strings.ToUpper(path) != path && setLiteralPath(req, strings.ToUpper(path))
+	}},
+	{"x-original-url", func(req *http.Request, path string) bool {
+		req.Header.Set("X-Original-URL", path)
+		return setLiteralPath(req, "/")
+	}},
+	{"x-rewrite-url", func(req *http.Request, path string) bool {
+		req.Header.Set("X-Rewrite-URL", path)
+		return setLiteralPath(req, "/")
+	}},
+	{"x-forwarded-for", func(req *http.Request, path string) bool {
+		req.Header.Set("X-Forwarded-For", "127.0.0.1")
+		return true
+	}},
+	{"x-real-ip", func(req *http.Request, path string) bool {
+		req.Header.Set("X-Real-IP", "127.0.0.1")
+		return true
+	}},
+	{"x-custom-ip-authorization", func(req *http.Request, path string) bool {
+		req.Header.Set("X-Custom-IP-Authorization", "127.0.0.1")
+		return true
+	}},
+}
+
+// setLiteralPath sets the path of the request sent exactly as written,
+// it always applies and returns true.
+func setLiteralPath(req *http.Request, path string) bool {
+	req.URL.Opaque = path
+	return true
+}
+
+// isForbidden returns true if the status code denies access to the resource
+func isForbidden(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
+
+// bypassBaselineSimilarity is the similarity above which the response to a
+// mutation is considered the same as the response of its baseline
+const bypassBaselineSimilarity = 0.9
+
+// bypassResponse is a response received for a mutated request
+type bypassResponse struct {
+	request  *retryablehttp.Request
+	resp     *http.Response
+	body     string
+	rawBody  string
+	duration time.Duration
+}
+
+// tryBypasses resends a request which got a forbidden response with the
+// bypass mutations, writing a finding for each one that was allowed, matched
+// the template and didn't get the same response as the mutation applied to
+// a path which doesn't exist. The baseline rules out the servers answering
+// every path, like the rewrite headers ignored in favour of the root page.
+func (e *HTTPExecuter) tryBypasses(reqURL string, request *requests.HTTPRequest, result *Result) {
+	original := request.Request.Request
+
+	// requests with a body can't be sent again
+	if original.Body != nil && original.Body != http.NoBody {
+		return
+	}
+
+	path := original.URL.Opaque
+	if path == "" {
+		path = original.URL.EscapedPath()
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	// the baseline is the same request on a random path, the header mutations keep it
+	missingPath := "/" + generators.RandSeq(bypassPathCharset, bypassPathLength)
+	missing := original.Clone(original.Context())
+	missing.URL.Opaque = ""
+	missing.URL.RawPath = ""
+	missing.URL.Path = missingPath
+
+	for _, mutation := range bypassMutations {
+		bypass, ok := e.sendBypass(reqURL, original, mutation, path)
+		if !ok || bypass.resp.StatusCode < http.StatusOK || bypass.resp.StatusCode >= http.StatusMultipleChoices {
+			continue
+		}
+
+		matcher, matched := e.bypassMatches(bypass)
+		if !matched {
+			continue
+		}
+
+		// a server answering the missing path the same way ignored the mutation
+		baseline, ok := e.sendBypass(reqURL, missing, mutation, missingPath)
+		if !ok || baseline.resp.StatusCode == bypass.resp.StatusCode && similarity.Levenshtein(baseline.body, bypass.body) >= bypassBaselineSimilarity {
+			continue
+		}
+
+		bypassRequest := &requests.HTTPRequest{Request: bypass.request, Meta: map[string]interface{}{"bypass": mutation.name}, Target: request.Target}
+
+		result.Lock()
+		result.GotResults = true
+		result.Unlock()
+
+		e.writeOutputHTTP(bypassRequest, bypass.resp, bypass.body, matcher, nil, bypassRequest.Meta, nil, nil, e.capture(bypassRequest))
+	}
+}
+
+// sendBypass sends a request with a mutation applied to its path,
+// returning false if the mutation doesn't apply or the request failed.
+func (e *HTTPExecuter) sendBypass(reqURL string, request *http.Request, mutation bypassMutation, path string) (*bypassResponse, bool) {
+	mutated := request.Clone(request.Context())
+	if !mutation.apply(mutated, path) {
+		return nil, false
+	}
+
+	retryableRequest, err := retryablehttp.FromRequest(mutated)
+	if err != nil {
+		return nil, false
+	}
+
+	resp, data, duration, err := e.send(reqURL, retryableRequest)
+	if err != nil {
+		return nil, false
+	}
+
+	return &bypassResponse{
+		request:  retryableRequest,
+		resp:     resp,
+		body:     unsafeToString(requests.DecodeBody(resp, data)),
+		rawBody:  unsafeToString(data),
+		duration: duration,
+	}, true
+}
+
+// bypassMatches evaluates the matchers of the request on the response to a
+// mutation, returning the first matcher matched with the or condition. A
+// request without matchers matches any allowed response.
+func (e *HTTPExecuter) bypassMatches(bypass *bypassResponse) (*matchers.Matcher, bool) {
+	headers := headersToString(bypass.resp.Header)
+
+	var fields map[string]interface{}
+	if e.needsFields {
+		fields = httpToMap(bypass.resp, bypass.body, headers, bypass.duration, "")
+	}
+
+	var data map[string]interface{}
+	if e.needsRawBody {
+		data = map[string]interface{}{matchers.RawBodyVariable: bypass.rawBody}
+	}
+
+	matcherCondition := e.bulkHTTPRequest.GetMatchersCondition()
+	for _, matcher := range e.bulkHTTPRequest.Matchers {
+		matched := matcher.Match(bypass.resp, bypass.body, headers, fields, data)
+		if !matched && matcherCondition == matchers.ANDCondition {
+			return nil, false
+		}
+		if matched && matcherCondition == matchers.ORCondition {
+			return matcher, true
+		}
+	}
+
+	return nil, len(e.bulkHTTPRequest.Matchers) == 0 || matcherCondition == matchers.ANDCondition
+}
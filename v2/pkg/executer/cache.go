@@ -0,0 +1,67 @@
+package executer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// cacheBusterVariable is replaced with a random value, the same for all the
+// requests of a template on a target so a poisoned entry can be requested again.
+const cacheBusterVariable = "CacheBuster"
+
+// newDynamicValues returns the initial values of the requests of a template on a target
+func newDynamicValues() map[string]interface{} {
+	return map[string]interface{}{cacheBusterVariable: newCacheBuster()}
+}
+
+// newCacheBuster returns a random value unlikely to be cached already
+func newCacheBuster() string {
+	data := make([]byte, 6)
+	//nolint:errcheck // reading from crypto/rand doesn't fail on the supported platforms
+	rand.Read(data)
+
+	return hex.EncodeToString(data)
+}
+
+// verifyCache requests the URL of a poisoning request again without its
+// headers and body, returning what the cache serves to the other clients,
+// its body decoded and as received.
+func (e *HTTPExecuter) verifyCache(reqURL string, request *requests.HTTPRequest) (*http.Response, string, string, time.Duration, error) {
+	var (
+		target *url.URL
+		host   string
+		err    error
+	)
+
+	if request.Request != nil {
+		copied := *request.Request.URL
+		target, host = &copied, request.Request.Host
+	} else if target, err = url.Parse(request.RawRequest.FullURL); err != nil {
+		return nil, "", "", 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, "", "", 0, err
+	}
+	req.URL, req.Host = target, host
+
+	retryableRequest, err := retryablehttp.FromRequest(req)
+	if err != nil {
+		return nil, "", "", 0, err
+	}
+	e.setCustomHeaders(&requests.HTTPRequest{Request: retryableRequest})
+
+	resp, data, duration, err := e.send(reqURL, retryableRequest)
+	if err != nil {
+		return nil, "", "", 0, err
+	}
+
+	return resp, unsafeToString(requests.DecodeBody(resp, data)), unsafeToString(data), duration, nil
+}
@@ -0,0 +1,182 @@
+package executer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// maxClusterSize is the total size of the response bodies kept for the clustered requests
+const maxClusterSize = 128 * 1024 * 1024
+
+// Cluster shares the responses of the identical requests of different templates,
+// so that they are sent once per target and matched by each template.
+type Cluster struct {
+	shared  map[string]struct{}
+	mutex   sync.Mutex
+	entries map[string]*clusterEntry
+	size    int64
+}
+
+// clusterEntry is the response of a clustered request, complete once done is closed
+type clusterEntry struct {
+	done     chan struct{}
+	response *http.Response
+	body     []byte
+}
+
+// NewCluster groups the http requests of the templates with the same signature,
+// keeping the ones sent by more than one template. It returns nil if none are.
+func NewCluster(templateList []*templates.Template) *Cluster {
+	counts := make(map[string]int)
+	for _, template := range templateList {
+		seen := make(map[string]struct{})
+		for _, request := range template.BulkRequestsHTTP {
+			for _, signature := range clusterSignatures(request) {
+				if _, ok := seen[signature]; !ok {
+					seen[signature] = struct{}{}
+					counts[signature]++
+				}
+			}
+		}
+	}
+
+	shared := make(map[string]struct{})
+	for signature, count := range counts {
+		if count > 1 {
+			shared[signature] = struct{}{}
+		}
+	}
+	if len(shared) == 0 {
+		return nil
+	}
+
+	return &Cluster{shared: shared, entries: make(map[string]*clusterEntry)}
+}
+
+// Len returns the number of distinct requests shared by several templates
+func (c *Cluster) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	return len(c.shared)
+}
+
+// clusters returns true if a request of the templates is shared with another template
+func (c *Cluster) clusters(request *requests.BulkHTTPRequest) bool {
+	if c == nil {
+		return false
+	}
+
+	for _, signature := range clusterSignatures(request) {
+		if _, ok := c.shared[signature]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// acquire returns the response of an identical request, waiting for it while
+// it is in flight. If there is none the caller has to send the request and
+// pass its outcome to release.
+func (c *Cluster) acquire(key string) (response *http.Response, leader bool) {
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		c.entries[key] = &clusterEntry{done: make(chan struct{})}
+		c.mutex.Unlock()
+
+		return nil, true
+	}
+	c.mutex.Unlock()
+
+	<-entry.done
+	if entry.response == nil {
+		return nil, false
+	}
+
+	return entry.replay(), false
+}
+
+// release stores the response of a clustered request for the identical ones,
+// or forgets the request if it failed or the cluster is full.
+func (c *Cluster) release(key string, response *http.Response, body []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := c.entries[key]
+	if response != nil && c.size+int64(len(body)) <= maxClusterSize {
+		entry.response = response
+		entry.body = body
+		c.size += int64(len(body))
+	} else {
+		delete(c.entries, key)
+	}
+	close(entry.done)
+}
+
+// replay returns a copy of the stored response with its own body reader
+func (entry *clusterEntry) replay() *http.Response {
+	response := *entry.response
+	response.Header = entry.response.Header.Clone()
+	response.Body = ioutil.NopCloser(bytes.NewReader(entry.body))
+
+	return &response
+}
+
+// clusterSignatures returns the signatures of the paths of a request which
+// can be clustered, that is sent as is without state or payloads.
+func clusterSignatures(request *requests.BulkHTTPRequest) []string {
+	if len(request.Raw) > 0 || len(request.Payloads) > 0 || len(request.Multipart) > 0 || request.Body != "" ||
+		request.CookieReuse || request.Race || request.Pipeline || request.Unsafe || request.FreshConnection ||
+		request.CacheVerify || request.CSRF || request.TLS != nil || request.Protocol != "" || len(request.Fallback) > 0 {
+		return nil
+	}
+
+	method := strings.ToUpper(request.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	headers := make([]string, 0, len(request.Headers))
+	for name, value := range request.Headers {
+		headers = append(headers, strings.ToLower(name)+":"+value)
+	}
+	sort.Strings(headers)
+
+	var signatures []string
+	for _, path := range request.Path {
+		signatures = append(signatures, strings.Join([]string{method, path, strings.Join(headers, "\n"), redirectPolicy(request)}, "\x00"))
+	}
+
+	return signatures
+}
+
+// requestClusterKey returns the key of a built request of a clustered template on a target
+func requestClusterKey(request *retryablehttp.Request, policy string) string {
+	headers := make([]string, 0, len(request.Header))
+	for name, values := range request.Header {
+		headers = append(headers, strings.ToLower(name)+":"+strings.Join(values, ","))
+	}
+	sort.Strings(headers)
+
+	return strings.Join([]string{request.Method, request.URL.String(), request.Host, strings.Join(headers, "\n"), policy}, "\x00")
+}
+
+// redirectPolicy describes how the client of a request follows redirects
+func redirectPolicy(request *requests.BulkHTTPRequest) string {
+	if !request.Redirects {
+		return "0"
+	}
+
+	return strconv.Itoa(request.MaxRedirects) + "+"
+}
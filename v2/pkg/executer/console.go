@@ -0,0 +1,99 @@
+package executer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
+)
+
+const (
+	// GroupByHost groups the console findings by target host
+	GroupByHost = "host"
+	// GroupByTemplate groups the console findings by template
+	GroupByTemplate = "template"
+)
+
+// Console prints the findings on the console as they are found, or
+// grouped by host or template once the scan is done with Flush.
+type Console struct {
+	groupBy   string
+	colorizer colorizer.NucleiColorizer
+
+	mutex  sync.Mutex
+	order  []string
+	groups map[string][]string
+}
+
+// NewConsole creates a console grouping the findings, an empty
+// groupBy prints them as they are found.
+func NewConsole(groupBy string, colorizer colorizer.NucleiColorizer) (*Console, error) {
+	switch groupBy {
+	case "", GroupByHost, GroupByTemplate:
+	default:
+		return nil, fmt.Errorf("invalid grouping %s, use %s or %s", groupBy, GroupByHost, GroupByTemplate)
+	}
+
+	return &Console{groupBy: groupBy, colorizer: colorizer, groups: make(map[string][]string)}, nil
+}
+
+// Print prints the console line of a finding of a template on a target
+func (c *Console) Print(templateID, target, line string) {
+	if c == nil || c.groupBy == "" {
+		gologger.Silentf("%s", line)
+		return
+	}
+
+	key := templateID
+	if c.groupBy == GroupByHost {
+		key = findingHost(target)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.groups[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.groups[key] = append(c.groups[key], line)
+}
+
+// Flush prints the grouped findings in the order their groups were first seen
+func (c *Console) Flush() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, key := range c.order {
+		lines := c.groups[key]
+		header := fmt.Sprintf("%s (%d)", key, len(lines))
+		gologger.Silentf("\n%s\n", c.colorizer.Colorizer.Bold(c.colorizer.Colorizer.BrightWhite(header)).String())
+
+		for _, line := range lines {
+			gologger.Silentf("  %s", line)
+		}
+	}
+
+	c.order = nil
+	c.groups = make(map[string][]string)
+}
+
+// findingHost returns the host of the target of a finding, which is
+// an URL for the http and headless findings and a domain for dns.
+func findingHost(target string) string {
+	if parsed, err := url.Parse(target); err == nil && parsed.Host != "" {
+		target = parsed.Host
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		target = host
+	}
+
+	return strings.ToLower(target)
+}
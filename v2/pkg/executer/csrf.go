@@ -0,0 +1,100 @@
+package executer
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+var (
+	reInputTag   = regexp.MustCompile(`(?i)<input\b[^>]*>`)
+	reMetaTag    = regexp.MustCompile(`(?i)<meta\b[^>]*>`)
+	reAttribute  = regexp.MustCompile(`(?i)\b(name|value|content)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	reCSRFName   = regexp.MustCompile(`(?i)csrf|xsrf|authenticity_token|__requestverificationtoken|^_token$|^token$`)
+	reCSRFHeader = regexp.MustCompile(`(?i)^(csrf-token|_csrf|csrf_token|xsrf-token)$`)
+)
+
+// csrfCookies maps the names of the cookies holding csrf tokens to the
+// form field and header the frameworks expect them in. Tokens only
+// accepted in a header have no form field.
+var csrfCookies = map[string][2]string{
+	"xsrf-token": {"", "X-XSRF-TOKEN"},
+	"csrftoken":  {"csrfmiddlewaretoken", "X-CSRFToken"},
+	"csrf_token": {"csrf_token", "X-CSRF-Token"},
+	"_csrf":      {"_csrf", "X-CSRF-Token"},
+}
+
+// updateCSRF stores the csrf token of a response in the dynamic values,
+// so that the next requests of the template send it. Tokens rotate so a
+// token found in a response replaces the previous one.
+//
+// Hidden form inputs are preferred over meta tags, which are preferred
+// over cookies. A token set in a cookie is also sent back in a header.
+func updateCSRF(resp *http.Response, body string, dynamicvalues map[string]interface{}) {
+	name, token := "", ""
+
+	for _, tag := range reInputTag.FindAllString(body, -1) {
+		attributes := tagAttributes(tag)
+		if reCSRFName.MatchString(attributes["name"]) && attributes["value"] != "" {
+			name, token = attributes["name"], attributes["value"]
+			break
+		}
+	}
+
+	if token == "" {
+		param := ""
+		for _, tag := range reMetaTag.FindAllString(body, -1) {
+			attributes := tagAttributes(tag)
+			switch {
+			case strings.EqualFold(attributes["name"], "csrf-param"):
+				param = attributes["content"]
+			case reCSRFHeader.MatchString(attributes["name"]) && token == "":
+				token = attributes["content"]
+			}
+		}
+		if token != "" {
+			name = param
+		}
+	}
+
+	header := ""
+	for _, cookie := range resp.Cookies() {
+		names, ok := csrfCookies[strings.ToLower(cookie.Name)]
+		if !ok || cookie.Value == "" {
+			continue
+		}
+		value, err := url.QueryUnescape(cookie.Value)
+		if err != nil {
+			value = cookie.Value
+		}
+		if token == "" {
+			name, token = names[0], value
+		}
+		if value == token {
+			header = names[1]
+		}
+		break
+	}
+
+	if token == "" {
+		return
+	}
+
+	dynamicvalues[requests.CSRFTokenVariable] = token
+	dynamicvalues[requests.CSRFNameVariable] = name
+	dynamicvalues[requests.CSRFHeaderVariable] = header
+}
+
+// tagAttributes returns the name, value and content attributes of a html tag by lowercased name
+func tagAttributes(tag string) map[string]string {
+	attributes := make(map[string]string)
+
+	for _, match := range reAttribute.FindAllStringSubmatch(tag, -1) {
+		attributes[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+	}
+
+	return attributes
+}
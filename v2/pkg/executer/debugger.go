@@ -0,0 +1,245 @@
+package executer
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// Debugger sends the requests of a http template to a target one at a time
+// for the -debug-template mode. The values extracted by a step are used to
+// build the next ones like in a scan, and a step can be edited and sent again.
+type Debugger struct {
+	executer *HTTPExecuter
+	request  *requests.BulkHTTPRequest
+	target   string
+	// Variables are the values the requests of the template are built with
+	Variables map[string]interface{}
+	// history contains the responses of the steps for the dsl matchers
+	history map[string]interface{}
+
+	position int
+	data     string
+	payloads map[string]interface{}
+	// built is true once the payloads of the step are read from the generator
+	built    bool
+	rendered *requests.HTTPRequest
+	dump     string
+}
+
+// DebugStep is a request sent by the debugger with the evaluation of the
+// matchers and the extractors of the template on its response
+type DebugStep struct {
+	Request  string
+	Response string
+	Duration time.Duration
+	Matchers []DebugMatcher
+	// Matched is the result of the matchers with the condition of the request
+	Matched bool
+	// Extracted contains the values of each extractor, by name
+	Extracted map[string][]string
+}
+
+// DebugMatcher is the result of a matcher on the response of a step
+type DebugMatcher struct {
+	Name    string
+	Type    string
+	Matched bool
+}
+
+// NewDebugger creates a debugger for a http request of a template and a target
+func NewDebugger(options *HTTPOptions, target string) (*Debugger, error) {
+	executer, err := NewHTTPExecuter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	debugger := &Debugger{
+		executer:  executer,
+		request:   options.BulkHTTPRequest,
+		target:    target,
+		Variables: executer.templateValues(target),
+		history:   make(map[string]interface{}),
+	}
+	debugger.request.CreateGenerator(target)
+
+	return debugger, nil
+}
+
+// Next moves to the next request of the template, returning false once
+// they are all sent. A request with payloads is a step per payload value.
+func (d *Debugger) Next() bool {
+	if d.position > 0 {
+		d.request.Increment(d.target)
+	}
+	if !d.request.Next(d.target) {
+		d.executer.values.Set(d.target, d.Variables)
+		return false
+	}
+
+	d.position++
+	d.data = d.request.Current(d.target)
+	d.payloads, d.built, d.rendered = nil, false, nil
+
+	return true
+}
+
+// Position returns the number of the current step
+func (d *Debugger) Position() int {
+	return d.position
+}
+
+// Data returns the path or the raw request of the current step
+func (d *Debugger) Data() string {
+	return d.data
+}
+
+// SetData replaces the path or the raw request of the current step,
+// keeping its payload values
+func (d *Debugger) SetData(data string) {
+	d.data = data
+	d.rendered = nil
+}
+
+// Payloads returns the payload values of the current step once rendered
+func (d *Debugger) Payloads() map[string]interface{} {
+	return d.payloads
+}
+
+// Render builds the request of the current step with the variables and
+// returns its dump
+func (d *Debugger) Render() (string, error) {
+	var (
+		request *requests.HTTPRequest
+		err     error
+	)
+
+	if d.built {
+		request, err = d.request.MakeHTTPRequestWithPayloads(d.target, d.Variables, d.data, d.payloads)
+	} else {
+		request, err = d.request.MakeHTTPRequest(d.target, d.Variables, d.data)
+	}
+	if err != nil {
+		return "", &TemplateError{Err: err}
+	}
+	if !d.built {
+		d.payloads, d.built = request.Meta, true
+	}
+	d.executer.setCustomHeaders(request)
+
+	dump, err := requests.Dump(request, d.target)
+	if err != nil {
+		return "", err
+	}
+	d.rendered, d.dump = request, string(dump)
+
+	return d.dump, nil
+}
+
+// Send sends the request of the current step and evaluates the matchers and
+// the extractors on its response. The extracted values replace the ones of
+// the previous sends of the step.
+func (d *Debugger) Send() (*DebugStep, error) {
+	if d.rendered == nil {
+		if _, err := d.Render(); err != nil {
+			return nil, err
+		}
+	}
+	// the body of a request is consumed once sent
+	request := d.rendered
+	d.rendered = nil
+
+	timeStart := time.Now()
+
+	resp, err := d.do(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not send request")
+	}
+
+	duration := time.Since(timeStart)
+
+	dumpedResponse, err := httputil.DumpResponse(resp, false)
+	if err != nil {
+		resp.Body.Close()
+		return nil, errors.Wrap(err, "could not dump http response")
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read http body")
+	}
+
+	rawBody := unsafeToString(data)
+	body := unsafeToString(requests.DecodeBody(resp, data))
+	headers := headersToString(resp.Header)
+
+	if d.request.CSRF {
+		updateCSRF(resp, body, d.Variables)
+	}
+
+	d.history = generators.MergeMaps(d.history, httpToMap(resp, body, headers, duration, "%s_"+strconv.Itoa(d.position)))
+	fields := httpToMap(resp, body, headers, duration, "")
+
+	step := &DebugStep{
+		Request:   d.dump,
+		Response:  string(dumpedResponse) + body,
+		Duration:  duration,
+		Extracted: make(map[string][]string),
+	}
+
+	condition := d.request.GetMatchersCondition()
+	step.Matched = condition == matchers.ANDCondition && len(d.request.Matchers) > 0
+
+	history := generators.MergeMaps(d.history, map[string]interface{}{matchers.RawBodyVariable: rawBody})
+	for _, matcher := range d.request.Matchers {
+		matched := matcher.Match(resp, body, headers, fields, history)
+		step.Matchers = append(step.Matchers, DebugMatcher{Name: matcher.Name, Type: matcher.Type, Matched: matched})
+
+		if matched && condition == matchers.ORCondition {
+			step.Matched = true
+		}
+		if !matched && condition == matchers.ANDCondition {
+			step.Matched = false
+		}
+	}
+
+	for _, extractor := range d.request.Extractors {
+		results := sortedResults(extractor.Extract(resp, body, headers, fields))
+		step.Extracted[extractor.Name] = results
+
+		if len(results) > 0 {
+			d.Variables[extractor.Name] = results[0]
+		}
+		for name, value := range extractor.Variables(body, headers, fields) {
+			d.Variables[name] = value
+		}
+	}
+
+	return step, nil
+}
+
+// do sends a request with the raw client if unsafe, the http one otherwise
+func (d *Debugger) do(request *requests.HTTPRequest) (*http.Response, error) {
+	if !request.Unsafe {
+		return d.executer.httpClient.Do(request.Request)
+	}
+
+	// burp uses "\r\n" as new line character
+	data := strings.ReplaceAll(request.RawRequest.Data, "\n", "\r\n")
+	options := d.executer.rawHTTPClient.Options
+	options.AutomaticContentLength = request.AutomaticContentLengthHeader
+	options.AutomaticHostHeader = request.AutomaticHostHeader
+	options.FollowRedirects = request.FollowRedirects
+
+	return d.executer.rawHTTPClient.DoRawWithOptions(request.RawRequest.Method, d.target, request.RawRequest.Path, requests.ExpandMapValues(request.RawRequest.Headers), ioutil.NopCloser(strings.NewReader(data)), options)
+}
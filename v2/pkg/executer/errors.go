@@ -0,0 +1,39 @@
+package executer
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// TemplateError is an error caused by the template itself rather than
+// by the target, such as a request which can't be built.
+type TemplateError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error
+func (e *TemplateError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// recoverRequest converts a panic of a request goroutine into
+// a template error of the result. It must be deferred.
+func recoverRequest(result *Result) {
+	if recovered := recover(); recovered != nil {
+		result.Lock()
+		result.Error = &TemplateError{Err: fmt.Errorf("panic: %v", recovered)}
+		result.Unlock()
+	}
+}
+
+// IsTemplateError returns true if the error was caused by the template
+func IsTemplateError(err error) bool {
+	var templateErr *TemplateError
+	return errors.As(err, &templateErr)
+}
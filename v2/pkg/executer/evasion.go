@@ -0,0 +1,296 @@
+package executer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// pace waits for the delay and a random time up to the jitter before a
+// request so that the requests of a scan don't arrive at a fixed rate.
+func (e *HTTPExecuter) pace() {
+	if e.delay > 0 {
+		time.Sleep(e.delay)
+	}
+	if e.jitter <= 0 {
+		return
+	}
+
+	time.Sleep(time.Duration(rand.Int63n(int64(e.jitter))))
+}
+
+// RandomTLSProfile picks one of the browser profiles for each template
+const RandomTLSProfile = "random"
+
+// TLSProfiles are the browser client hellos the https requests can mimic
+var TLSProfiles = map[string]utls.ClientHelloID{
+	"chrome":  utls.HelloChrome_Auto,
+	"firefox": utls.HelloFirefox_Auto,
+	"ios":     utls.HelloIOS_Auto,
+}
+
+// tlsProfile returns the client hello of a profile, a random browser one
+// for the random profile
+func tlsProfile(name string) (utls.ClientHelloID, error) {
+	if name == RandomTLSProfile {
+		names := make([]string, 0, len(TLSProfiles))
+		for profile := range TLSProfiles {
+			names = append(names, profile)
+		}
+		name = names[rand.Intn(len(names))]
+	}
+
+	profile, ok := TLSProfiles[name]
+	if !ok {
+		return utls.ClientHelloID{}, fmt.Errorf("unknown tls profile %s", name)
+	}
+
+	return profile, nil
+}
+
+// profileDialer returns the function dialing the tls connections of a
+// transport with the client hello of a browser profile, the certificate
+// checks and versions of the config being kept. The profile replaces the
+// cipher suites of the config.
+//
+// The transport can't tell the protocol negotiated on the connection, only
+// http/1.1 is offered with alpn.
+func profileDialer(dial func(ctx context.Context, network, address string) (net.Conn, error), config *tls.Config, profile utls.ClientHelloID) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+
+		serverName := config.ServerName
+		if serverName == "" {
+			serverName, _, _ = net.SplitHostPort(address)
+		}
+		client := utls.UClient(conn, &utls.Config{
+			ServerName:         serverName,
+			InsecureSkipVerify: config.InsecureSkipVerify,
+			RootCAs:            config.RootCAs,
+			MinVersion:         config.MinVersion,
+			MaxVersion:         config.MaxVersion,
+		}, profile)
+
+		if err := offerHTTP11(client); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline) //nolint:errcheck // the handshake fails on a broken connection
+		}
+		if err := client.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn.SetDeadline(time.Time{}) //nolint:errcheck // the reads fail on a broken connection
+
+		return client, nil
+	}
+}
+
+// offerHTTP11 builds the client hello of a connection with http/1.1 as the
+// only protocol of its alpn extension, if it has one
+func offerHTTP11(client *utls.UConn) error {
+	if err := client.BuildHandshakeState(); err != nil {
+		return err
+	}
+	for _, extension := range client.Extensions {
+		if alpn, ok := extension.(*utls.ALPNExtension); ok {
+			alpn.AlpnProtocols = []string{"http/1.1"}
+		}
+	}
+
+	// the extensions are written to the client hello again
+	return client.BuildHandshakeState()
+}
+
+// headerShuffler sends the http/1.1 requests itself, writing their headers
+// in a random order after the host, on a new connection dialed by the
+// transport. net/http writes them sorted, which singles out its requests.
+type headerShuffler struct {
+	transport *http.Transport
+}
+
+// RoundTrip sends a request with its headers shuffled
+func (s *headerShuffler) RoundTrip(req *http.Request) (*http.Response, error) {
+	conn, err := s.dial(req)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	// the connection is closed once the request is cancelled or its body is
+	// closed, the reads and writes on it failing
+	body := &connBody{conn: conn, done: make(chan struct{})}
+	go func() {
+		select {
+		case <-req.Context().Done():
+			conn.Close()
+		case <-body.done:
+		}
+	}()
+
+	if err := writeShuffledRequest(conn, req); err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	body.ReadCloser = resp.Body
+	resp.Body = body
+
+	return resp, nil
+}
+
+// dial connects to the host of a request, over tls for the https ones
+func (s *headerShuffler) dial(req *http.Request) (net.Conn, error) {
+	ctx := req.Context()
+	address := canonicalAddress(req.URL.Scheme, req.URL.Host)
+
+	if req.URL.Scheme != "https" {
+		return s.transport.DialContext(ctx, "tcp", address)
+	}
+	if s.transport.DialTLSContext != nil {
+		return s.transport.DialTLSContext(ctx, "tcp", address)
+	}
+
+	conn, err := s.transport.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{}
+	if s.transport.TLSClientConfig != nil {
+		config = s.transport.TLSClientConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = req.URL.Hostname()
+	}
+	config.NextProtos = []string{"http/1.1"}
+
+	client := tls.Client(conn, config)
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline) //nolint:errcheck // the handshake fails on a broken connection
+	}
+	if err := client.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{}) //nolint:errcheck // the reads fail on a broken connection
+
+	return client, nil
+}
+
+// canonicalAddress returns the host of a URL with the default port of its
+// scheme if it has none
+func canonicalAddress(scheme, host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+
+	port := "80"
+	if scheme == "https" {
+		port = "443"
+	}
+
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
+
+// connBody is the body of a response closing its connection once closed
+type connBody struct {
+	io.ReadCloser
+	conn net.Conn
+	once sync.Once
+	done chan struct{}
+}
+
+// Close closes the body and the connection of the response
+func (b *connBody) Close() error {
+	var err error
+	b.once.Do(func() {
+		close(b.done)
+		if b.ReadCloser != nil {
+			b.ReadCloser.Close()
+		}
+		err = b.conn.Close()
+	})
+
+	return err
+}
+
+// writeShuffledRequest writes a http/1.1 request with its host first and
+// its other headers in a random order. The user agent and content length
+// are added as net/http does.
+func writeShuffledRequest(w io.Writer, req *http.Request) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	var headers []string
+	for name, values := range req.Header {
+		if strings.EqualFold(name, "Host") || strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+		for _, value := range values {
+			headers = append(headers, name+": "+value)
+		}
+	}
+	if _, ok := req.Header["User-Agent"]; !ok {
+		headers = append(headers, "User-Agent: Go-http-client/1.1")
+	}
+	if len(body) > 0 || req.Method == http.MethodPost || req.Method == http.MethodPut || req.Method == http.MethodPatch {
+		headers = append(headers, fmt.Sprintf("Content-Length: %d", len(body)))
+	}
+	rand.Shuffle(len(headers), func(i, j int) {
+		headers[i], headers[j] = headers[j], headers[i]
+	})
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	buffer := &bytes.Buffer{}
+	fmt.Fprintf(buffer, "%s %s HTTP/1.1\r\nHost: %s\r\n", method, req.URL.RequestURI(), host)
+	for _, header := range headers {
+		buffer.WriteString(header + "\r\n")
+	}
+	buffer.WriteString("\r\n")
+	buffer.Write(body)
+
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
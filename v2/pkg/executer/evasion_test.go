@@ -0,0 +1,132 @@
+package executer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteShuffledRequest(t *testing.T) {
+	orders := make(map[string]struct{})
+
+	for i := 0; i < 32; i++ {
+		req, err := http.NewRequest(http.MethodPost, "http://acme.local/login?next=/", strings.NewReader("user=admin"))
+		require.Nil(t, err, "could not create request")
+		for _, name := range []string{"Accept", "Accept-Language", "Cookie", "Referer", "X-Requested-With"} {
+			req.Header.Set(name, "nuclei")
+		}
+
+		buffer := &bytes.Buffer{}
+		require.Nil(t, writeShuffledRequest(buffer, req), "could not write request")
+		raw := buffer.String()
+		require.True(t, strings.HasPrefix(raw, "POST /login?next=/ HTTP/1.1\r\nHost: acme.local\r\n"), "the request line or host was moved: %s", raw)
+
+		written, err := http.ReadRequest(bufio.NewReader(buffer))
+		require.Nil(t, err, "the written request is invalid")
+		require.Equal(t, "nuclei", written.Header.Get("X-Requested-With"), "a header was lost")
+		require.Equal(t, "Go-http-client/1.1", written.Header.Get("User-Agent"), "the default user agent was not added")
+		require.Equal(t, int64(len("user=admin")), written.ContentLength, "wrong content length")
+		body, err := ioutil.ReadAll(written.Body)
+		require.Nil(t, err, "could not read the written body")
+		require.Equal(t, "user=admin", string(body), "wrong body")
+
+		var order []string
+		for _, line := range strings.Split(raw, "\r\n") {
+			order = append(order, strings.SplitN(line, ":", 2)[0])
+		}
+		orders[strings.Join(order, ",")] = struct{}{}
+	}
+
+	require.True(t, len(orders) > 1, "the order of the headers did not vary")
+}
+
+func TestHeaderShuffler(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %s", r.Proto, r.URL.Path, r.Header.Get("X-Token"))
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		DialContext:     (&net.Dialer{}).DialContext,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	client := &http.Client{Transport: &headerShuffler{transport: transport}}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/admin", nil)
+	require.Nil(t, err, "could not create request")
+	req.Header.Set("X-Token", "secret")
+
+	resp, err := client.Do(req)
+	require.Nil(t, err, "could not send request")
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err, "could not read response")
+	require.Nil(t, resp.Body.Close(), "could not close response")
+	require.Equal(t, "HTTP/1.1 /admin secret", string(body), "wrong request received")
+}
+
+func TestProfileDialer(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	}))
+	// the server prefers http2, only http/1.1 must be offered
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	for name := range TLSProfiles {
+		profile, err := tlsProfile(name)
+		require.Nil(t, err, "could not get profile")
+
+		transport := &http.Transport{DialTLSContext: profileDialer((&net.Dialer{}).DialContext, &tls.Config{InsecureSkipVerify: true}, profile)}
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		require.Nil(t, err, "could not send request with the %s profile", name)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.Nil(t, err, "could not read response")
+		require.Equal(t, "HTTP/1.1", string(body), "wrong protocol with the %s profile", name)
+	}
+}
+
+func TestTLSProfile(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		_, err := tlsProfile(RandomTLSProfile)
+		require.Nil(t, err, "could not pick a random profile")
+	}
+
+	_, err := tlsProfile("netscape")
+	require.NotNil(t, err, "an unknown profile was accepted")
+}
+
+func TestProfileDialerCancelled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen")
+	defer listener.Close()
+
+	// the server never answers the client hello
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			ioutil.ReadAll(conn) //nolint:errcheck // the connection is closed by the client
+		}
+	}()
+
+	profile, err := tlsProfile("chrome")
+	require.Nil(t, err, "could not get profile")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = profileDialer((&net.Dialer{}).DialContext, &tls.Config{}, profile)(ctx, "tcp", listener.Addr().String())
+	require.NotNil(t, err, "the handshake ignored the deadline")
+}
@@ -0,0 +1,63 @@
+package executer
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+)
+
+// Evidence is the proof attached to a finding. Every protocol fills the parts
+// it has and writes them with the same keys in its json output.
+type Evidence struct {
+	// Request is the request sent to the target
+	Request string
+	// Response is the response of the target
+	Response string
+	// Screenshot is the path of the screenshot of the matched page
+	Screenshot string
+	// Extracted are the values extracted from the response
+	Extracted []string
+	// Snippets are the parts of the response that matched
+	Snippets []string
+	// Interactions are the raw out-of-band interactions with the interactsh server
+	Interactions []string
+	// Trace are the requests sent up to the finding
+	Trace []TraceStep
+}
+
+// write adds the evidence to a json output. The screenshot is always written,
+// the rest only with the metadata, and the request and the response or the
+// screenshot data only if the raw exchange is requested.
+func (ev *Evidence) write(output jsonOutput, noMeta, raw bool) {
+	if ev.Screenshot != "" {
+		output["screenshot"] = ev.Screenshot
+		if raw {
+			if data, err := ioutil.ReadFile(ev.Screenshot); err == nil {
+				output["screenshot_base64"] = base64.StdEncoding.EncodeToString(data)
+			}
+		}
+	}
+	if noMeta {
+		return
+	}
+
+	if len(ev.Snippets) > 0 {
+		output["snippets"] = ev.Snippets
+	}
+	if len(ev.Extracted) > 0 {
+		output["extracted_results"] = ev.Extracted
+	}
+	if len(ev.Interactions) > 0 {
+		output["interactions"] = ev.Interactions
+	}
+	if len(ev.Trace) > 0 {
+		output["trace"] = ev.Trace
+	}
+	if raw {
+		if ev.Request != "" {
+			output["request"] = ev.Request
+		}
+		if ev.Response != "" {
+			output["response"] = ev.Response
+		}
+	}
+}
@@ -2,41 +2,34 @@ package executer
 
 import (
 	"fmt"
+	"net"
 	"os"
-	"regexp"
+	"strings"
+	"time"
 
+	"github.com/miekg/dns"
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/gologger"
-	"github.com/projectdiscovery/nuclei/v2/internal/bufwriter"
 	"github.com/projectdiscovery/nuclei/v2/internal/progress"
-	"github.com/projectdiscovery/nuclei/v2/internal/tracelog"
-	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
 	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
 	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
-	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
 	retryabledns "github.com/projectdiscovery/retryabledns"
 )
 
 // DNSExecuter is a client for performing a DNS request
 // for a template.
 type DNSExecuter struct {
-	// hm            *hybrid.HybridMap // Unused
-	coloredOutput bool
-	debug         bool
-	jsonOutput    bool
-	jsonRequest   bool
-	noMeta        bool
-	Results       bool
-	traceLog      tracelog.Log
-	dnsClient     *retryabledns.Client
-	template      *templates.Template
-	dnsRequest    *requests.DNSRequest
-	writer        *bufwriter.Writer
-
-	colorizer   colorizer.NucleiColorizer
-	decolorizer *regexp.Regexp
+	findingWriter
+
+	Results    bool
+	dnsClient  *retryabledns.Client
+	resolvers  []string
+	dnsRequest *requests.DNSRequest
 }
 
+// dnsTCPTimeout is the timeout of the tcp queries sent for truncated responses
+const dnsTCPTimeout = 5 * time.Second
+
 // DefaultResolvers contains the list of resolvers known to be trusted.
 var DefaultResolvers = []string{
 	"1.1.1.1:53", // Cloudflare
@@ -47,38 +40,27 @@ var DefaultResolvers = []string{
 
 // DNSOptions contains configuration options for the DNS executer.
 type DNSOptions struct {
-	ColoredOutput bool
-	Debug         bool
-	JSON          bool
-	JSONRequests  bool
-	NoMeta        bool
-	TraceLog      tracelog.Log
-	Template      *templates.Template
-	DNSRequest    *requests.DNSRequest
-	Writer        *bufwriter.Writer
-
-	Colorizer   colorizer.NucleiColorizer
-	Decolorizer *regexp.Regexp
+	CommonOptions
+
+	DNSRequest *requests.DNSRequest
+	// Resolvers overrides the default resolvers if not empty
+	Resolvers []string
 }
 
 // NewDNSExecuter creates a new DNS executer from a template
 // and a DNS request query.
 func NewDNSExecuter(options *DNSOptions) *DNSExecuter {
-	dnsClient := retryabledns.New(DefaultResolvers, options.DNSRequest.Retries)
+	resolvers := DefaultResolvers
+	if len(options.Resolvers) > 0 {
+		resolvers = options.Resolvers
+	}
+	dnsClient := retryabledns.New(resolvers, options.DNSRequest.Retries)
 
 	executer := &DNSExecuter{
-		debug:         options.Debug,
-		noMeta:        options.NoMeta,
-		jsonOutput:    options.JSON,
-		traceLog:      options.TraceLog,
-		jsonRequest:   options.JSONRequests,
+		findingWriter: newFindingWriter(&options.CommonOptions),
 		dnsClient:     dnsClient,
-		template:      options.Template,
+		resolvers:     resolvers,
 		dnsRequest:    options.DNSRequest,
-		writer:        options.Writer,
-		coloredOutput: options.ColoredOutput,
-		colorizer:     options.Colorizer,
-		decolorizer:   options.Decolorizer,
 	}
 
 	return executer
@@ -100,7 +82,7 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) *Result {
 	compiledRequest, err := e.dnsRequest.MakeDNSRequest(domain)
 	if err != nil {
 		e.traceLog.Request(e.template.ID, domain, "dns", err)
-		result.Error = errors.Wrap(err, "could not make dns request")
+		result.Error = &TemplateError{Err: errors.Wrap(err, "could not make dns request")}
 
 		p.Drop(1)
 
@@ -113,8 +95,13 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) *Result {
 		fmt.Fprintf(os.Stderr, "%s\n", compiledRequest.String())
 	}
 
-	// Send the request to the target servers
-	resp, err := e.dnsClient.Do(compiledRequest)
+	// Send the request to the target servers, or to the nameservers of the zone for a transfer
+	var resp *dns.Msg
+	if compiledRequest.Question[0].Qtype == dns.TypeAXFR {
+		resp, err = e.transferZone(compiledRequest)
+	} else {
+		resp, err = e.dnsClient.Do(compiledRequest)
+	}
 	if err != nil {
 		result.Error = errors.Wrap(err, "could not send dns request")
 
@@ -123,6 +110,16 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) *Result {
 		return result
 	}
 
+	// truncated answers are partial, so the query is sent again over tcp
+	if resp.Truncated {
+		tcpResp, tcpErr := e.exchangeTCP(compiledRequest)
+		if tcpErr != nil {
+			gologger.Verbosef("Could not retry truncated response for %s over tcp: %s\n", "dns-request", reqURL, tcpErr)
+		} else {
+			resp = tcpResp
+		}
+	}
+
 	p.Update()
 
 	gologger.Verbosef("Sent for [%s] to %s\n", "dns-request", e.template.ID, reqURL)
@@ -132,11 +129,16 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) *Result {
 		fmt.Fprintf(os.Stderr, "%s\n", resp.String())
 	}
 
+	var fields map[string]interface{}
+	if e.dnsRequest.NeedsFields() {
+		fields = dnsToMap(resp, "")
+	}
+
 	matcherCondition := e.dnsRequest.GetMatchersCondition()
 
 	for _, matcher := range e.dnsRequest.Matchers {
 		// Check if the matcher matched
-		if !matcher.MatchDNS(resp) {
+		if !matcher.MatchDNS(resp, fields) {
 			// If the condition is AND we haven't matched, return.
 			if matcherCondition == matchers.ANDCondition {
 				return result
@@ -156,7 +158,7 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) *Result {
 	var extractorResults []string
 
 	for _, extractor := range e.dnsRequest.Extractors {
-		for match := range extractor.ExtractDNS(resp) {
+		for _, match := range sortedResults(extractor.ExtractDNS(resp, fields)) {
 			if !extractor.Internal {
 				extractorResults = append(extractorResults, match)
 			}
@@ -176,3 +178,86 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) *Result {
 
 // Close closes the dns executer for a template.
 func (e *DNSExecuter) Close() {}
+
+// exchangeTCP sends a request over tcp to the resolvers, trying
+// the next resolver on failure up to the request retries.
+func (e *DNSExecuter) exchangeTCP(msg *dns.Msg) (*dns.Msg, error) {
+	client := &dns.Client{Net: "tcp", Timeout: dnsTCPTimeout}
+
+	var err error
+
+	for i := 0; i <= e.dnsRequest.Retries; i++ {
+		resp, _, exchangeErr := client.Exchange(msg, e.resolvers[i%len(e.resolvers)])
+		if exchangeErr == nil {
+			return resp, nil
+		}
+
+		err = exchangeErr
+	}
+
+	return nil, err
+}
+
+// transferZone attempts a zone transfer from each nameserver of the zone of
+// a request. The response has the records of the first nameserver allowing
+// it, or is refused, and all the nameservers in its authority section.
+func (e *DNSExecuter) transferZone(request *dns.Msg) (*dns.Msg, error) {
+	zone := request.Question[0].Name
+
+	query := new(dns.Msg)
+	query.SetQuestion(zone, dns.TypeNS)
+	nameservers, err := e.dnsClient.Do(query)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(dns.Msg)
+	resp.SetReply(request)
+	resp.Rcode = dns.RcodeRefused
+
+	for _, record := range nameservers.Answer {
+		nameserver, ok := record.(*dns.NS)
+		if !ok {
+			continue
+		}
+		resp.Ns = append(resp.Ns, nameserver)
+		if len(resp.Answer) > 0 {
+			continue
+		}
+
+		records, err := transferFrom(zone, net.JoinHostPort(strings.TrimSuffix(nameserver.Ns, "."), "53"))
+		if err != nil {
+			gologger.Verbosef("Zone transfer of %s refused by %s: %s\n", "dns-request", zone, nameserver.Ns, err)
+			continue
+		}
+		resp.Answer = records
+		resp.Rcode = dns.RcodeSuccess
+	}
+
+	return resp, nil
+}
+
+// transferFrom returns the records of a zone transferred from a nameserver
+func transferFrom(zone, nameserver string) ([]dns.RR, error) {
+	transfer := &dns.Transfer{DialTimeout: dnsTCPTimeout, ReadTimeout: dnsTCPTimeout}
+
+	request := new(dns.Msg)
+	request.SetAxfr(zone)
+	envelopes, err := transfer.In(request, nameserver)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []dns.RR
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, envelope.Error
+		}
+		records = append(records, envelope.RR...)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty zone")
+	}
+
+	return records, nil
+}
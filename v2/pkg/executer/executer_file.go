@@ -0,0 +1,291 @@
+package executer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// fileScheme is the prefix of the targets given as file URLs
+const fileScheme = "file://"
+
+// FileExecuter is a client for scanning the local files of a template.
+type FileExecuter struct {
+	findingWriter
+
+	fileRequest *requests.FileRequest
+}
+
+// FileOptions contains configuration options for the file executer.
+type FileOptions struct {
+	CommonOptions
+
+	FileRequest *requests.FileRequest
+}
+
+// NewFileExecuter creates a new file executer from a template
+// and a file request.
+func NewFileExecuter(options *FileOptions) *FileExecuter {
+	executer := &FileExecuter{
+		findingWriter: newFindingWriter(&options.CommonOptions),
+		fileRequest:   options.FileRequest,
+	}
+
+	return executer
+}
+
+// ExecuteFile scans the files of a target path, walking the directories.
+// The network targets are skipped.
+func (e *FileExecuter) ExecuteFile(p progress.IProgress, target string) *Result {
+	result := &Result{}
+
+	if strings.Contains(target, "://") && !strings.HasPrefix(target, fileScheme) {
+		p.Drop(1)
+		return result
+	}
+	root := strings.TrimPrefix(target, fileScheme)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// unreadable files deep in a tree don't stop the scan of the others
+			if path != root {
+				gologger.Verbosef("Could not read %s: %s\n", "file-request", path, err)
+				return nil
+			}
+			return err
+		}
+		if path != root && e.fileRequest.Denies(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// the archives are opened whatever the extensions, their files being filtered
+		allowed := path == root || e.fileRequest.Allows(path) || (e.fileRequest.Archive && isArchive(path))
+		if !info.Mode().IsRegular() || !allowed {
+			return nil
+		}
+
+		if e.scanFile(path, info) {
+			result.GotResults = true
+		}
+		return nil
+	})
+	e.traceLog.Request(e.template.ID, root, "file", err)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not scan files")
+		p.Drop(1)
+
+		return result
+	}
+	p.Update()
+
+	gologger.Verbosef("Sent for [%s] to %s\n", "file-request", e.template.ID, root)
+
+	return result
+}
+
+// scanFile matches a file, or the files of an archive if enabled
+func (e *FileExecuter) scanFile(path string, info os.FileInfo) bool {
+	if e.fileRequest.Archive {
+		if matched, ok := e.scanArchive(path); ok {
+			return matched
+		}
+	}
+
+	if info.Size() > e.fileRequest.GetMaxSize() {
+		gologger.Verbosef("Skipped %s larger than %d bytes\n", "file-request", path, e.fileRequest.GetMaxSize())
+		return false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		gologger.Verbosef("Could not read %s: %s\n", "file-request", path, err)
+		return false
+	}
+
+	return e.match(path, string(data))
+}
+
+// isArchive returns true if a file is a zip, tar or gzip archive
+func isArchive(path string) bool {
+	name := strings.ToLower(path)
+	for _, extension := range []string{".zip", ".jar", ".war", ".tar", ".tgz", ".gz"} {
+		if strings.HasSuffix(name, extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scanArchive matches the files of a zip, tar or gzip archive, each one
+// named after the archive. It returns false if the file isn't an archive.
+func (e *FileExecuter) scanArchive(path string) (matched, ok bool) {
+	name := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(name, ".zip"), strings.HasSuffix(name, ".jar"), strings.HasSuffix(name, ".war"):
+		archive, err := zip.OpenReader(path)
+		if err != nil {
+			gologger.Verbosef("Could not open archive %s: %s\n", "file-request", path, err)
+			return false, true
+		}
+		defer archive.Close()
+
+		for _, file := range archive.File {
+			if file.FileInfo().IsDir() || !e.fileRequest.Allows(file.Name) {
+				continue
+			}
+			reader, err := file.Open()
+			if err != nil {
+				continue
+			}
+			if e.matchEntry(path+"/"+file.Name, reader) {
+				matched = true
+			}
+			reader.Close()
+		}
+		return matched, true
+	case strings.HasSuffix(name, ".tar"), strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		file, err := os.Open(path)
+		if err != nil {
+			return false, true
+		}
+		defer file.Close()
+
+		var reader io.Reader = file
+		if !strings.HasSuffix(name, ".tar") {
+			gzipReader, err := gzip.NewReader(file)
+			if err != nil {
+				gologger.Verbosef("Could not open archive %s: %s\n", "file-request", path, err)
+				return false, true
+			}
+			defer gzipReader.Close()
+			reader = gzipReader
+		}
+
+		archive := tar.NewReader(reader)
+		for {
+			header, err := archive.Next()
+			if err != nil {
+				if err != io.EOF {
+					gologger.Verbosef("Could not read archive %s: %s\n", "file-request", path, err)
+				}
+				break
+			}
+			if header.Typeflag != tar.TypeReg || !e.fileRequest.Allows(header.Name) {
+				continue
+			}
+			if e.matchEntry(path+"/"+header.Name, archive) {
+				matched = true
+			}
+		}
+		return matched, true
+	case strings.HasSuffix(name, ".gz"):
+		file, err := os.Open(path)
+		if err != nil {
+			return false, true
+		}
+		defer file.Close()
+
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			gologger.Verbosef("Could not open archive %s: %s\n", "file-request", path, err)
+			return false, true
+		}
+		defer reader.Close()
+
+		decompressed := strings.TrimSuffix(path, filepath.Ext(path))
+		if !e.fileRequest.Allows(decompressed) && !e.fileRequest.Allows(path) {
+			return false, true
+		}
+		return e.matchEntry(decompressed, reader), true
+	}
+
+	return false, false
+}
+
+// matchEntry reads a file of an archive and matches it, skipping the ones
+// larger than the max size once decompressed
+func (e *FileExecuter) matchEntry(path string, reader io.Reader) bool {
+	maxSize := e.fileRequest.GetMaxSize()
+	data, err := ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		gologger.Verbosef("Could not read %s: %s\n", "file-request", path, err)
+		return false
+	}
+	if int64(len(data)) > maxSize {
+		gologger.Verbosef("Skipped %s larger than %d bytes\n", "file-request", path, maxSize)
+		return false
+	}
+
+	return e.match(path, string(data))
+}
+
+// match runs the matchers and extractors on the content of a file
+func (e *FileExecuter) match(path, data string) bool {
+	if e.debug {
+		gologger.Infof("Dumped file %s (%s)\n\n", path, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n", data)
+	}
+
+	var fields map[string]interface{}
+	if e.fileRequest.NeedsFields() {
+		fields = fileToMap(path, data, "")
+	}
+
+	matcherCondition := e.fileRequest.GetMatchersCondition()
+	matched := false
+
+	for _, matcher := range e.fileRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchFile(data, fields) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return false
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.fileRequest.Extractors) == 0 {
+				e.writeOutputFile(path, data, matcher, nil)
+				matched = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	for _, extractor := range e.fileRequest.Extractors {
+		for _, match := range sortedResults(extractor.ExtractFile(data, fields)) {
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+	}
+
+	// Write a final string of output if matcher type is AND or if the
+	// extractors found something, the files without secrets not being reported.
+	if len(extractorResults) > 0 || (matcherCondition == matchers.ANDCondition && len(e.fileRequest.Matchers) > 0) {
+		e.writeOutputFile(path, data, nil, extractorResults)
+		matched = true
+	}
+
+	return matched
+}
+
+// Close closes the file executer for a template.
+func (e *FileExecuter) Close() {}
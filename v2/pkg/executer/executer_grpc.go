@@ -0,0 +1,182 @@
+package executer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/httpx/common/cache"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/grpc"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
+)
+
+// GRPCExecuter is a client for performing the grpc calls of a template.
+type GRPCExecuter struct {
+	findingWriter
+
+	timeout       time.Duration
+	client        *http.Client
+	customHeaders requests.CustomHeaders
+	grpcRequest   *requests.GRPCRequest
+}
+
+// GRPCOptions contains configuration options for the grpc executer.
+type GRPCOptions struct {
+	CommonOptions
+
+	Timeout       int
+	CustomHeaders requests.CustomHeaders
+	GRPCRequest   *requests.GRPCRequest
+	// Dialer is the shared dialer of the runner, the system one is used if nil
+	Dialer *cache.DialerFunc
+	TLS    *tlsconfig.Options
+}
+
+// NewGRPCExecuter creates a new grpc executer from a template
+// and a grpc request.
+func NewGRPCExecuter(options *GRPCOptions) (*GRPCExecuter, error) {
+	tlsConfig, err := tlsconfig.New(options.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := (&net.Dialer{}).DialContext
+	if options.Dialer != nil {
+		dialer = *options.Dialer
+	}
+
+	// the calls are always made over http2
+	transport := &http.Transport{
+		DialContext:       dialer,
+		TLSClientConfig:   tlsConfig,
+		DisableKeepAlives: true,
+	}
+
+	executer := &GRPCExecuter{
+		findingWriter: newFindingWriter(&options.CommonOptions),
+		timeout:       time.Duration(options.Timeout) * time.Second,
+		client: &http.Client{
+			Transport: withProtocol(transport, transport, requests.HTTP2Protocol, false),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		customHeaders: options.CustomHeaders,
+		grpcRequest:   options.GRPCRequest,
+	}
+
+	return executer, nil
+}
+
+// ExecuteGRPC makes the grpc call of the request for a target
+func (e *GRPCExecuter) ExecuteGRPC(p progress.IProgress, reqURL string) *Result {
+	result := &Result{}
+
+	call, err := e.grpcRequest.MakeGRPCRequest(reqURL)
+	if err != nil {
+		result.Error = &TemplateError{Err: errors.Wrap(err, "could not make grpc request")}
+		p.Drop(1)
+
+		return result
+	}
+
+	ctx := context.Background()
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	response, err := grpc.Invoke(ctx, e.client, call.Address, call.Method, e.metadata(call), call.Message)
+	e.traceLog.Request(e.template.ID, call.Address, "grpc", err)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not send grpc request")
+		p.Drop(1)
+
+		return result
+	}
+	p.Update()
+
+	gologger.Verbosef("Sent for [%s] to %s\n", "grpc-request", e.template.ID, call.Address)
+
+	if e.debug {
+		gologger.Infof("Dumped grpc call for %s (%s)\n\n", call.Address, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n", grpcPart(response, matchers.AllPart))
+	}
+
+	var fields map[string]interface{}
+	if e.grpcRequest.NeedsFields() {
+		fields = grpcToMap(response, "")
+	}
+
+	matcherCondition := e.grpcRequest.GetMatchersCondition()
+
+	for _, matcher := range e.grpcRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchGRPC(response.Status, grpcPart(response, matcher.GetPart()), fields) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return result
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.grpcRequest.Extractors) == 0 {
+				e.writeOutputGRPC(call, response, matcher, nil)
+				result.GotResults = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	for _, extractor := range e.grpcRequest.Extractors {
+		for _, match := range sortedResults(extractor.ExtractGRPC(grpcPart(response, matchers.BodyPart), grpcHeaders(response), grpcMetadata(response), fields)) {
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(e.grpcRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
+		e.writeOutputGRPC(call, response, nil, extractorResults)
+		result.GotResults = true
+	}
+
+	return result
+}
+
+// metadata returns the metadata of the call, the custom headers of the
+// runner replacing the ones of the template.
+func (e *GRPCExecuter) metadata(call *requests.GRPCCall) http.Header {
+	header := make(http.Header, len(call.Metadata)+len(e.customHeaders))
+	for name, value := range call.Metadata {
+		header.Set(name, value)
+	}
+
+	for _, customHeader := range e.customHeaders {
+		tokens := strings.SplitN(customHeader, ":", two)
+		if len(tokens) < two {
+			continue
+		}
+		header.Set(strings.TrimSpace(tokens[0]), strings.TrimSpace(tokens[1]))
+	}
+
+	return header
+}
+
+// Close closes the grpc executer for a template.
+func (e *GRPCExecuter) Close() {}
@@ -0,0 +1,156 @@
+package executer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/headless"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// HeadlessExecuter is a client for executing the browser actions
+// of a template.
+type HeadlessExecuter struct {
+	findingWriter
+
+	Results         bool
+	pool            *headless.Pool
+	timeout         time.Duration
+	headlessRequest *requests.HeadlessRequest
+}
+
+// HeadlessOptions contains configuration options for the headless executer.
+type HeadlessOptions struct {
+	CommonOptions
+
+	HeadlessRequest *requests.HeadlessRequest
+	// Pool provides the browser pages the steps are executed in
+	Pool *headless.Pool
+	// Timeout is the maximum time to execute all the steps on a target
+	Timeout time.Duration
+}
+
+// NewHeadlessExecuter creates a new headless executer from a template
+// and a headless request.
+func NewHeadlessExecuter(options *HeadlessOptions) *HeadlessExecuter {
+	return &HeadlessExecuter{
+		findingWriter:   newFindingWriter(&options.CommonOptions),
+		pool:            options.Pool,
+		timeout:         options.Timeout,
+		headlessRequest: options.HeadlessRequest,
+	}
+}
+
+// ExecuteHeadless executes the browser actions on a URL
+func (e *HeadlessExecuter) ExecuteHeadless(p progress.IProgress, reqURL string) *Result {
+	result := &Result{}
+
+	steps, err := e.headlessRequest.MakeSteps(reqURL)
+	if err != nil {
+		e.traceLog.Request(e.template.ID, reqURL, "headless", err)
+		result.Error = &TemplateError{Err: errors.Wrap(err, "could not make headless steps")}
+
+		p.Drop(1)
+
+		return result
+	}
+
+	page, err := e.execute(reqURL, steps)
+	e.traceLog.Request(e.template.ID, reqURL, "headless", err)
+
+	if err != nil {
+		result.Error = err
+
+		p.Drop(1)
+
+		return result
+	}
+
+	p.Update()
+
+	gologger.Verbosef("Sent for [%s] to %s\n", "headless-request", e.template.ID, reqURL)
+
+	if e.debug {
+		gologger.Infof("Dumped headless page for %s (%s)\n\n", reqURL, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n", page.Body)
+	}
+
+	var fields map[string]interface{}
+	if e.headlessRequest.NeedsFields() {
+		fields = headlessToMap(page, "")
+	}
+
+	matcherCondition := e.headlessRequest.GetMatchersCondition()
+
+	for _, matcher := range e.headlessRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchHeadless(page.StatusCode, page.Body, headlessPart(page, matcher.GetPart()), fields) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return result
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.headlessRequest.Extractors) == 0 {
+				e.writeOutputHeadless(reqURL, page, matcher, nil)
+				result.GotResults = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	for _, extractor := range e.headlessRequest.Extractors {
+		for _, match := range sortedResults(extractor.ExtractHeadless(page.Body, headlessPart(page, matchers.ConsolePart), headlessPart(page, matchers.NetworkPart), fields)) {
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(e.headlessRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
+		e.writeOutputHeadless(reqURL, page, nil, extractorResults)
+
+		result.GotResults = true
+	}
+
+	return result
+}
+
+// execute runs the steps in a new isolated page and returns its resulting state
+func (e *HeadlessExecuter) execute(reqURL string, steps []*headless.Action) (*headless.PageData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	page, err := e.pool.Page(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open page")
+	}
+	defer page.Close()
+
+	outputs, err := page.Run(ctx, steps)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not execute headless steps")
+	}
+
+	data, err := page.Data(ctx, outputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read page")
+	}
+
+	return data, nil
+}
+
+// Close closes the headless executer for a template.
+func (e *HeadlessExecuter) Close() {}
@@ -2,7 +2,6 @@ package executer
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -12,7 +11,6 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,16 +19,18 @@ import (
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/httpx/common/cache"
-	"github.com/projectdiscovery/nuclei/v2/internal/bufwriter"
 	"github.com/projectdiscovery/nuclei/v2/internal/progress"
-	"github.com/projectdiscovery/nuclei/v2/internal/tracelog"
-	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/crawler"
 	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/globalratelimiter"
+	"github.com/projectdiscovery/nuclei/v2/pkg/headless"
+	"github.com/projectdiscovery/nuclei/v2/pkg/interactsh"
 	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
 	projetctfile "github.com/projectdiscovery/nuclei/v2/pkg/projectfile"
 	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workpool"
 	"github.com/projectdiscovery/rawhttp"
 	"github.com/projectdiscovery/retryablehttp-go"
 	"github.com/remeh/sizedwaitgroup"
@@ -47,49 +47,98 @@ const (
 // HTTPExecuter is client for performing HTTP requests
 // for a template.
 type HTTPExecuter struct {
+	findingWriter
+
 	pf               *projetctfile.ProjectFile
 	customHeaders    requests.CustomHeaders
-	colorizer        colorizer.NucleiColorizer
 	httpClient       *retryablehttp.Client
 	rawHTTPClient    *rawhttp.Client
-	template         *templates.Template
 	bulkHTTPRequest  *requests.BulkHTTPRequest
-	writer           *bufwriter.Writer
 	CookieJar        *cookiejar.Jar
-	traceLog         tracelog.Log
-	decolorizer      *regexp.Regexp
-	coloredOutput    bool
-	debug            bool
 	Results          bool
-	jsonOutput       bool
-	jsonRequest      bool
-	noMeta           bool
 	stopAtFirstMatch bool
+	// hasDSLMatchers is true if the response history is needed by the matchers
+	hasDSLMatchers bool
+	// matcherPool runs the matchers apart from the network workers if set
+	matcherPool *workpool.Pool
+	// bypassForbidden retries the forbidden responses with bypass mutations
+	bypassForbidden bool
+	// traceFindings attaches the requests leading to each finding to its output
+	traceFindings bool
+	// screenshots captures the matched URLs if set
+	screenshots *headless.Screenshotter
+	// xssVerifier confirms the findings of xss templates if set
+	xssVerifier *headless.XSSVerifier
+	// seeds provides the robots.txt and sitemap.xml paths of the hosts
+	seeds *crawler.Seeds
+	// replaying is true when the requests are replayed, the findings are not written
+	replaying bool
+	// interactsh provides the interactsh urls and their interactions if set
+	interactsh *interactsh.Client
+	// interactshWait is the time waited for the interactions of a request
+	interactshWait time.Duration
+	// needsInteractions is true if the matchers match on the interactions
+	needsInteractions bool
+	// needsRawBody is true if the matchers match on the bodies as received
+	needsRawBody bool
+	// needsFields is true if the matchers or the extractors read the map of the responses
+	needsFields bool
+	// jitter is the maximum random delay added before each request
+	jitter time.Duration
+	// delay is the fixed delay waited before each request
+	delay time.Duration
+	// cluster shares the responses of the requests identical to other templates'
+	cluster *Cluster
+	// clustered is true if the requests are shared with other templates
+	clustered bool
+	// throttle limits the rate and the concurrency of the requests to the hosts
+	throttle *Throttle
+	// values passes the extracted values on to the next requests of the template
+	values *SharedValues
 }
 
 // HTTPOptions contains configuration options for the HTTP executer.
 type HTTPOptions struct {
-	CustomHeaders    requests.CustomHeaders
-	ProxyURL         string
-	ProxySocksURL    string
-	Template         *templates.Template
-	BulkHTTPRequest  *requests.BulkHTTPRequest
-	Writer           *bufwriter.Writer
-	Timeout          int
-	Retries          int
-	CookieJar        *cookiejar.Jar
-	Colorizer        *colorizer.NucleiColorizer
-	Decolorizer      *regexp.Regexp
-	TraceLog         tracelog.Log
-	Debug            bool
-	JSON             bool
-	JSONRequests     bool
-	NoMeta           bool
+	CommonOptions
+
+	CustomHeaders requests.CustomHeaders
+	ProxyURL      string
+	ProxySocksURL string
+	// Proxy is the proxy of a template or workflow, replacing the ones of the runner
+	Proxy string
+	// Proxies rotates the requests over a proxy list if set
+	Proxies         *ProxyRotator
+	Cluster         *Cluster
+	Throttle        *Throttle
+	BulkHTTPRequest *requests.BulkHTTPRequest
+	Timeout         int
+	Retries         int
+	CookieJar       *cookiejar.Jar
+	Interactsh      *interactsh.Client
+	InteractshWait  time.Duration
+	// RequestJitter is the maximum random delay added before each request
+	RequestJitter time.Duration
+	// RequestDelay is the fixed delay waited before each request
+	RequestDelay time.Duration
+	// TLSProfile is the browser whose client hello the https requests mimic, see TLSProfiles
+	TLSProfile string
+	// RandomizeHeaders writes the headers of the http/1.1 requests in a random order
+	RandomizeHeaders bool
+	// HTTP2 negotiates http2 with the servers for the requests forcing no protocol
+	HTTP2            bool
 	CookieReuse      bool
-	ColoredOutput    bool
 	StopAtFirstMatch bool
 	PF               *projetctfile.ProjectFile
 	Dialer           *cache.DialerFunc
+	MatcherPool      *workpool.Pool
+	BypassForbidden  bool
+	TraceFindings    bool
+	Screenshots      *headless.Screenshotter
+	XSSVerifier      *headless.XSSVerifier
+	Seeds            *crawler.Seeds
+	TLS              *tlsconfig.Options
+	// Values shares the extracted values between the http requests of a template
+	Values *SharedValues
 }
 
 // NewHTTPExecuter creates a new HTTP executer from a template
@@ -100,6 +149,8 @@ func NewHTTPExecuter(options *HTTPOptions) (*HTTPExecuter, error) {
 		err      error
 	)
 
+	options = overrideProxy(options)
+
 	if options.ProxyURL != "" {
 		proxyURL, err = url.Parse(options.ProxyURL)
 	}
@@ -130,35 +181,57 @@ func NewHTTPExecuter(options *HTTPOptions) (*HTTPExecuter, error) {
 	rawClient := rawhttp.NewClient(rawhttp.DefaultOptions)
 
 	executer := &HTTPExecuter{
-		debug:            options.Debug,
-		jsonOutput:       options.JSON,
-		jsonRequest:      options.JSONRequests,
-		noMeta:           options.NoMeta,
+		findingWriter:    newFindingWriter(&options.CommonOptions),
+		interactsh:       options.Interactsh,
+		interactshWait:   options.InteractshWait,
+		jitter:           options.RequestJitter,
+		delay:            options.RequestDelay,
+		cluster:          options.Cluster,
+		clustered:        options.Proxy == "" && options.Cluster.clusters(options.BulkHTTPRequest),
+		throttle:         options.Throttle,
 		httpClient:       client,
 		rawHTTPClient:    rawClient,
-		traceLog:         options.TraceLog,
-		template:         options.Template,
 		bulkHTTPRequest:  options.BulkHTTPRequest,
-		writer:           options.Writer,
 		customHeaders:    options.CustomHeaders,
 		CookieJar:        options.CookieJar,
-		coloredOutput:    options.ColoredOutput,
-		colorizer:        *options.Colorizer,
-		decolorizer:      options.Decolorizer,
 		stopAtFirstMatch: options.StopAtFirstMatch,
 		pf:               options.PF,
+		matcherPool:      options.MatcherPool,
+		bypassForbidden:  options.BypassForbidden,
+		traceFindings:    options.TraceFindings,
+		screenshots:      options.Screenshots,
+		seeds:            options.Seeds,
+		values:           options.Values,
+	}
+
+	// only the findings of xss templates are verified in a browser
+	if options.XSSVerifier != nil && isXSSTemplate(options.Template) {
+		executer.xssVerifier = options.XSSVerifier
 	}
 
+	for _, matcher := range options.BulkHTTPRequest.Matchers {
+		if matcher.NeedsHistory() {
+			executer.hasDSLMatchers = true
+		}
+		if matcher.NeedsInteractions() {
+			executer.needsInteractions = true
+		}
+		if matcher.NeedsRawBody() {
+			executer.needsRawBody = true
+		}
+	}
+	executer.needsFields = options.BulkHTTPRequest.NeedsFields()
+
 	return executer, nil
 }
 
-func (e *HTTPExecuter) ExecuteRaceRequest(reqURL string) *Result {
+func (e *HTTPExecuter) ExecuteRaceRequest(ctx context.Context, reqURL string) *Result {
 	result := &Result{
 		Matches:     make(map[string]interface{}),
 		Extractions: make(map[string]interface{}),
 	}
 
-	dynamicvalues := make(map[string]interface{})
+	dynamicvalues := e.templateValues(reqURL)
 
 	// verify if the URL is already being processed
 	if e.bulkHTTPRequest.HasGenerator(reqURL) {
@@ -170,16 +243,17 @@ func (e *HTTPExecuter) ExecuteRaceRequest(reqURL string) *Result {
 	// Workers that keeps enqueuing new requests
 	maxWorkers := e.bulkHTTPRequest.RaceNumberRequests
 	swg := sizedwaitgroup.New(maxWorkers)
-	for i := 0; i < e.bulkHTTPRequest.RaceNumberRequests; i++ {
+	for i := 0; i < e.bulkHTTPRequest.RaceNumberRequests && ctx.Err() == nil; i++ {
 		swg.Add()
 		// base request
-		request, err := e.bulkHTTPRequest.MakeHTTPRequest(reqURL, dynamicvalues, e.bulkHTTPRequest.Current(reqURL))
+		request, err := e.bulkHTTPRequest.MakeHTTPRequestContext(ctx, reqURL, dynamicvalues, e.bulkHTTPRequest.Current(reqURL))
 		if err != nil {
-			result.Error = err
+			result.Error = &TemplateError{Err: err}
 			return result
 		}
 		go func(httpRequest *requests.HTTPRequest) {
 			defer swg.Done()
+			defer recoverRequest(result)
 
 			// If the request was built correctly then execute it
 			err = e.handleHTTP(reqURL, httpRequest, dynamicvalues, result, "")
@@ -190,17 +264,18 @@ func (e *HTTPExecuter) ExecuteRaceRequest(reqURL string) *Result {
 	}
 
 	swg.Wait()
+	e.values.Set(reqURL, dynamicvalues)
 
 	return result
 }
 
-func (e *HTTPExecuter) ExecuteParallelHTTP(p progress.IProgress, reqURL string) *Result {
+func (e *HTTPExecuter) ExecuteParallelHTTP(ctx context.Context, p progress.IProgress, reqURL string) *Result {
 	result := &Result{
 		Matches:     make(map[string]interface{}),
 		Extractions: make(map[string]interface{}),
 	}
 
-	dynamicvalues := make(map[string]interface{})
+	dynamicvalues := e.templateValues(reqURL)
 
 	// verify if the URL is already being processed
 	if e.bulkHTTPRequest.HasGenerator(reqURL) {
@@ -213,26 +288,24 @@ func (e *HTTPExecuter) ExecuteParallelHTTP(p progress.IProgress, reqURL string)
 	// Workers that keeps enqueuing new requests
 	maxWorkers := e.bulkHTTPRequest.Threads
 	swg := sizedwaitgroup.New(maxWorkers)
-	for e.bulkHTTPRequest.Next(reqURL) && !result.Done {
-		request, err := e.bulkHTTPRequest.MakeHTTPRequest(reqURL, dynamicvalues, e.bulkHTTPRequest.Current(reqURL))
+	for e.bulkHTTPRequest.Next(reqURL) && !result.Done && ctx.Err() == nil {
+		request, err := e.bulkHTTPRequest.MakeHTTPRequestContext(ctx, reqURL, dynamicvalues, e.bulkHTTPRequest.Current(reqURL))
 		if err != nil {
-			result.Error = err
+			result.Error = &TemplateError{Err: err}
 			p.Drop(remaining)
 		} else {
 			swg.Add()
 			go func(httpRequest *requests.HTTPRequest) {
 				defer swg.Done()
+				defer recoverRequest(result)
 
 				globalratelimiter.Take(reqURL)
 
 				// If the request was built correctly then execute it
 				err = e.handleHTTP(reqURL, httpRequest, dynamicvalues, result, "")
 				if err != nil {
-					e.traceLog.Request(e.template.ID, reqURL, "http", err)
 					result.Error = errors.Wrap(err, "could not handle http request")
 					p.Drop(remaining)
-				} else {
-					e.traceLog.Request(e.template.ID, reqURL, "http", nil)
 				}
 			}(request)
 		}
@@ -240,17 +313,18 @@ func (e *HTTPExecuter) ExecuteParallelHTTP(p progress.IProgress, reqURL string)
 	}
 
 	swg.Wait()
+	e.values.Set(reqURL, dynamicvalues)
 
 	return result
 }
 
-func (e *HTTPExecuter) ExecuteTurboHTTP(reqURL string) *Result {
+func (e *HTTPExecuter) ExecuteTurboHTTP(ctx context.Context, reqURL string) *Result {
 	result := &Result{
 		Matches:     make(map[string]interface{}),
 		Extractions: make(map[string]interface{}),
 	}
 
-	dynamicvalues := make(map[string]interface{})
+	dynamicvalues := e.templateValues(reqURL)
 
 	// verify if the URL is already being processed
 	if e.bulkHTTPRequest.HasGenerator(reqURL) {
@@ -283,14 +357,15 @@ func (e *HTTPExecuter) ExecuteTurboHTTP(reqURL string) *Result {
 		maxWorkers = pipeOptions.MaxPendingRequests
 	}
 	swg := sizedwaitgroup.New(maxWorkers)
-	for e.bulkHTTPRequest.Next(reqURL) && !result.Done {
-		request, err := e.bulkHTTPRequest.MakeHTTPRequest(reqURL, dynamicvalues, e.bulkHTTPRequest.Current(reqURL))
+	for e.bulkHTTPRequest.Next(reqURL) && !result.Done && ctx.Err() == nil {
+		request, err := e.bulkHTTPRequest.MakeHTTPRequestContext(ctx, reqURL, dynamicvalues, e.bulkHTTPRequest.Current(reqURL))
 		if err != nil {
-			result.Error = err
+			result.Error = &TemplateError{Err: err}
 		} else {
 			swg.Add()
 			go func(httpRequest *requests.HTTPRequest) {
 				defer swg.Done()
+				defer recoverRequest(result)
 
 				// HTTP pipelining ignores rate limit
 				// If the request was built correctly then execute it
@@ -298,10 +373,7 @@ func (e *HTTPExecuter) ExecuteTurboHTTP(reqURL string) *Result {
 				request.PipelineClient = pipeclient
 				err = e.handleHTTP(reqURL, httpRequest, dynamicvalues, result, "")
 				if err != nil {
-					e.traceLog.Request(e.template.ID, reqURL, "http", err)
 					result.Error = errors.Wrap(err, "could not handle http request")
-				} else {
-					e.traceLog.Request(e.template.ID, reqURL, "http", nil)
 				}
 				request.PipelineClient = nil
 			}(request)
@@ -311,25 +383,38 @@ func (e *HTTPExecuter) ExecuteTurboHTTP(reqURL string) *Result {
 	}
 
 	swg.Wait()
+	e.values.Set(reqURL, dynamicvalues)
 
 	return result
 }
 
 // ExecuteHTTP executes the HTTP request on a URL
 func (e *HTTPExecuter) ExecuteHTTP(p progress.IProgress, reqURL string) *Result {
+	var trace *Trace
+	if e.traceFindings {
+		trace = NewTrace()
+	}
+
+	return e.ExecuteHTTPWithTrace(context.Background(), p, reqURL, trace)
+}
+
+// ExecuteHTTPWithTrace executes the HTTP request on a URL, recording the
+// sequential requests into a trace, eg. the one of a workflow run. No request
+// is sent anymore once the context is done and the ones in flight are cancelled.
+func (e *HTTPExecuter) ExecuteHTTPWithTrace(ctx context.Context, p progress.IProgress, reqURL string, trace *Trace) *Result {
 	// verify if pipeline was requested
 	if e.bulkHTTPRequest.Pipeline {
-		return e.ExecuteTurboHTTP(reqURL)
+		return e.ExecuteTurboHTTP(ctx, reqURL)
 	}
 
 	// verify if a basic race condition was requested
 	if e.bulkHTTPRequest.Race && e.bulkHTTPRequest.RaceNumberRequests > 0 {
-		return e.ExecuteRaceRequest(reqURL)
+		return e.ExecuteRaceRequest(ctx, reqURL)
 	}
 
 	// verify if parallel elaboration was requested
 	if e.bulkHTTPRequest.Threads > 0 {
-		return e.ExecuteParallelHTTP(p, reqURL)
+		return e.ExecuteParallelHTTP(ctx, p, reqURL)
 	}
 
 	var requestNumber int
@@ -338,9 +423,10 @@ func (e *HTTPExecuter) ExecuteHTTP(p progress.IProgress, reqURL string) *Result
 		Matches:     make(map[string]interface{}),
 		Extractions: make(map[string]interface{}),
 		historyData: make(map[string]interface{}),
+		trace:       trace,
 	}
 
-	dynamicvalues := make(map[string]interface{})
+	dynamicvalues := e.templateValues(reqURL)
 
 	// verify if the URL is already being processed
 	if e.bulkHTTPRequest.HasGenerator(reqURL) {
@@ -350,24 +436,25 @@ func (e *HTTPExecuter) ExecuteHTTP(p progress.IProgress, reqURL string) *Result
 	remaining := e.bulkHTTPRequest.GetRequestCount()
 	e.bulkHTTPRequest.CreateGenerator(reqURL)
 
-	for e.bulkHTTPRequest.Next(reqURL) && !result.Done {
-		requestNumber++
-		httpRequest, err := e.bulkHTTPRequest.MakeHTTPRequest(reqURL, dynamicvalues, e.bulkHTTPRequest.Current(reqURL))
-		if err != nil {
-			result.Error = err
-			p.Drop(remaining)
-		} else {
-			globalratelimiter.Take(reqURL)
-			// If the request was built correctly then execute it
-			format := "%s_" + strconv.Itoa(requestNumber)
-			err = e.handleHTTP(reqURL, httpRequest, dynamicvalues, result, format)
-			if err != nil {
-				result.Error = errors.Wrap(err, "could not handle http request")
-				p.Drop(remaining)
-				e.traceLog.Request(e.template.ID, reqURL, "http", err)
-			} else {
-				e.traceLog.Request(e.template.ID, reqURL, "http", nil)
+	for e.bulkHTTPRequest.Next(reqURL) && !result.Done && ctx.Err() == nil {
+		data := e.bulkHTTPRequest.Current(reqURL)
+
+		// a request using the seed variables is sent once per seed path of the host
+		if usesSeedPaths(data) {
+			for _, seedPath := range e.seeds.Paths(reqURL) {
+				dynamicvalues[seedPathVariable] = seedPath
+				dynamicvalues[seedURLVariable] = seedURL(reqURL, seedPath)
+
+				requestNumber++
+				e.sendRequest(ctx, p, reqURL, data, dynamicvalues, result, requestNumber, remaining)
+
+				if result.Done || ctx.Err() != nil || (e.stopAtFirstMatch && result.GotResults) {
+					break
+				}
 			}
+		} else {
+			requestNumber++
+			e.sendRequest(ctx, p, reqURL, data, dynamicvalues, result, requestNumber, remaining)
 		}
 
 		// Check if has to stop processing at first valid result
@@ -381,20 +468,62 @@ func (e *HTTPExecuter) ExecuteHTTP(p progress.IProgress, reqURL string) *Result
 		p.Update()
 		remaining--
 	}
+	e.values.Set(reqURL, dynamicvalues)
 
 	gologger.Verbosef("Sent for [%s] to %s\n", "http-request", e.template.ID, reqURL)
 
 	return result
 }
 
+// sendRequest builds and sends a request of the template for a path or raw data
+func (e *HTTPExecuter) sendRequest(ctx context.Context, p progress.IProgress, reqURL, data string, dynamicvalues map[string]interface{}, result *Result, requestNumber int, remaining int64) {
+	if requestNumber > 1 {
+		select {
+		case <-time.After(e.bulkHTTPRequest.NextDelay()):
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	correlation := e.correlate(reqURL, data, dynamicvalues, requestNumber)
+
+	httpRequest, err := e.bulkHTTPRequest.MakeHTTPRequestContext(ctx, reqURL, dynamicvalues, data)
+	if err != nil {
+		result.Error = &TemplateError{Err: err}
+		p.Drop(remaining)
+		return
+	}
+	if correlation != nil {
+		correlation.Payloads = httpRequest.Meta
+	}
+
+	globalratelimiter.Take(reqURL)
+	// If the request was built correctly then execute it
+	format := "%s_" + strconv.Itoa(requestNumber)
+	err = e.handleHTTP(reqURL, httpRequest, dynamicvalues, result, format)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not handle http request")
+		p.Drop(remaining)
+	}
+}
+
 func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest, dynamicvalues map[string]interface{}, result *Result, format string) error {
+	request.Target = reqURL
 	e.setCustomHeaders(request)
+	e.pace()
+
+	// the host slot is only taken by the requests sent, and released once the
+	// response is read so that matching doesn't hold it
+	release := func() {}
+	defer func() { release() }()
 
 	var (
 		resp          *http.Response
 		err           error
 		dumpedRequest []byte
 		fromcache     bool
+		// clusterRelease shares the response with the identical requests of other templates
+		clusterRelease func(*http.Response, []byte)
 	)
 
 	if e.debug || e.pf != nil {
@@ -412,12 +541,14 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 	timeStart := time.Now()
 
 	if request.Pipeline {
+		release = e.throttle.acquire(reqURL)
 		resp, err = request.PipelineClient.DoRaw(request.RawRequest.Method, reqURL, request.RawRequest.Path, requests.ExpandMapValues(request.RawRequest.Headers), ioutil.NopCloser(strings.NewReader(request.RawRequest.Data)))
 		if err != nil {
 			if resp != nil {
 				resp.Body.Close()
 			}
 			e.traceLog.Request(e.template.ID, reqURL, "http", err)
+			e.throttle.report(reqURL, nil)
 			return err
 		}
 		e.traceLog.Request(e.template.ID, reqURL, "http", nil)
@@ -429,18 +560,21 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 		options.AutomaticContentLength = request.AutomaticContentLengthHeader
 		options.AutomaticHostHeader = request.AutomaticHostHeader
 		options.FollowRedirects = request.FollowRedirects
+		release = e.throttle.acquire(reqURL)
 		resp, err = e.rawHTTPClient.DoRawWithOptions(request.RawRequest.Method, reqURL, request.RawRequest.Path, requests.ExpandMapValues(request.RawRequest.Headers), ioutil.NopCloser(strings.NewReader(request.RawRequest.Data)), options)
 		if err != nil {
 			if resp != nil {
 				resp.Body.Close()
 			}
 			e.traceLog.Request(e.template.ID, reqURL, "http", err)
+			e.throttle.report(reqURL, nil)
 			return err
 		}
 		e.traceLog.Request(e.template.ID, reqURL, "http", nil)
 	} else {
-		// if nuclei-project is available check if the request was already sent previously
-		if e.pf != nil {
+		// if nuclei-project is available check if the request was already sent previously,
+		// except for fresh connections as the response depends on the connection state
+		if e.pf != nil && !e.bulkHTTPRequest.FreshConnection {
 			// if unavailable fail silently
 			fromcache = true
 			// nolint:bodyclose // false positive the response is generated at runtime
@@ -450,14 +584,32 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 			}
 		}
 
+		// identical requests of other templates are sent once per target
+		if resp == nil && e.clustered {
+			key := requestClusterKey(request.Request, redirectPolicy(e.bulkHTTPRequest))
+			var leader bool
+			if resp, leader = e.cluster.acquire(key); leader {
+				clusterRelease = func(response *http.Response, body []byte) {
+					e.cluster.release(key, response, body)
+				}
+				defer func() {
+					if clusterRelease != nil {
+						clusterRelease(nil, nil)
+					}
+				}()
+			}
+		}
+
 		// retryablehttp
 		if resp == nil {
+			release = e.throttle.acquire(reqURL)
 			resp, err = e.httpClient.Do(request.Request)
 			if err != nil {
 				if resp != nil {
 					resp.Body.Close()
 				}
 				e.traceLog.Request(e.template.ID, reqURL, "http", err)
+				e.throttle.report(reqURL, nil)
 				return err
 			}
 			e.traceLog.Request(e.template.ID, reqURL, "http", nil)
@@ -465,6 +617,8 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 	}
 
 	duration := time.Since(timeStart)
+	e.throttle.report(reqURL, resp)
+	result.trace.add(e.template.ID, request, resp.StatusCode, duration, dynamicvalues)
 
 	if e.debug {
 		dumpedResponse, dumpErr := httputil.DumpResponse(resp, true)
@@ -490,12 +644,11 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 	}
 
 	resp.Body.Close()
+	release()
 
-	// net/http doesn't automatically decompress the response body if an encoding has been specified by the user in the request
-	// so in case we have to manually do it
-	data, err = requests.HandleDecompression(request, data)
-	if err != nil {
-		return errors.Wrap(err, "could not decompress http body")
+	if clusterRelease != nil {
+		clusterRelease(resp, data)
+		clusterRelease = nil
 	}
 
 	// if nuclei-project is enabled store the response if not previously done
@@ -506,23 +659,87 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 		}
 	}
 
+	// the matchers run on the decompressed utf-8 body, the raw-body ones on the received bytes
+	rawBody := unsafeToString(data)
+	data = requests.DecodeBody(resp, data)
+
 	// Convert response body from []byte to string with zero copy
 	body := unsafeToString(data)
 
+	// the token is stored before the next request of the template is built
+	if e.bulkHTTPRequest.CSRF {
+		updateCSRF(resp, body, dynamicvalues)
+	}
+
+	// the matchers of a cache poisoning request run on a clean request of the same URL
+	if e.bulkHTTPRequest.CacheVerify {
+		resp, body, rawBody, duration, err = e.verifyCache(reqURL, request)
+		if err != nil {
+			return errors.Wrap(err, "could not verify cache poisoning")
+		}
+	}
+
+	// the interactions are polled for on the network worker, the matcher pool only evaluates them
+	var interactionData map[string]interface{}
+	var interactions []string
+	if e.needsInteractions && e.interactsh != nil {
+		interactionData, interactions = e.interactionsData(dynamicvalues)
+	}
+
+	var findings []*httpFinding
+	e.matcherPool.Run(func() {
+		findings = e.matchResponse(request, resp, body, rawBody, duration, dynamicvalues, interactionData, result, format)
+	})
+	e.writeFindings(request, resp, body, findings, interactions, result)
+
+	if e.bypassForbidden && request.Request != nil && isForbidden(resp.StatusCode) {
+		e.tryBypasses(reqURL, request, result)
+	}
+
+	return nil
+}
+
+// httpFinding is a match of a response, written once confirmed outside the matcher pool
+type httpFinding struct {
+	// matcher is the matched matcher with the or condition, nil for the final output
+	matcher          *matchers.Matcher
+	extractorResults []string
+	meta             map[string]interface{}
+}
+
+// matchResponse evaluates the matchers and extractors of the request on a
+// response, along with the interactions received for it, and returns the
+// findings to write.
+func (e *HTTPExecuter) matchResponse(request *requests.HTTPRequest, resp *http.Response, body, rawBody string, duration time.Duration, dynamicvalues, interactionData map[string]interface{}, result *Result, format string) []*httpFinding {
 	headers := headersToString(resp.Header)
 
 	// store for internal purposes the DSL matcher data
 	// hardcode stopping storing data after defaultMaxHistorydata items
-	if len(result.historyData) < defaultMaxHistorydata {
+	if e.hasDSLMatchers && len(result.historyData) < defaultMaxHistorydata {
 		result.Lock()
-		result.historyData = generators.MergeMaps(result.historyData, matchers.HTTPToMap(resp, body, headers, duration, format))
+		result.historyData = generators.MergeMaps(result.historyData, httpToMap(resp, body, headers, duration, format))
 		result.Unlock()
 	}
 
+	var fields map[string]interface{}
+	if e.needsFields {
+		fields = httpToMap(resp, body, headers, duration, "")
+	}
+
+	var findings []*httpFinding
+
+	data := result.historyData
+	if interactionData != nil {
+		data = generators.MergeMaps(data, interactionData)
+	}
+	if e.needsRawBody {
+		data = generators.MergeMaps(data, map[string]interface{}{matchers.RawBodyVariable: rawBody})
+	}
+
 	matcherCondition := e.bulkHTTPRequest.GetMatchersCondition()
 	for _, matcher := range e.bulkHTTPRequest.Matchers {
 		// Check if the matcher matched
-		if !matcher.Match(resp, body, headers, duration, result.historyData) {
+		if !matcher.Match(resp, body, headers, fields, data) {
 			// If the condition is AND we haven't matched, try next request.
 			if matcherCondition == matchers.ANDCondition {
 				return nil
@@ -531,13 +748,7 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 			// If the matcher has matched, and its an OR
 			// write the first output then move to next matcher.
 			if matcherCondition == matchers.ORCondition {
-				result.Lock()
-				result.Matches[matcher.Name] = nil
-				// probably redundant but ensures we snapshot current payload values when matchers are valid
-				result.Meta = request.Meta
-				result.GotResults = true
-				result.Unlock()
-				e.writeOutputHTTP(request, resp, body, matcher, nil, result.Meta)
+				findings = append(findings, &httpFinding{matcher: matcher, meta: request.Meta})
 			}
 		}
 	}
@@ -545,9 +756,10 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 	// All matchers have successfully completed so now start with the
 	// next task which is extraction of input from matchers.
 	var extractorResults, outputExtractorResults []string
+	var variables map[string]interface{}
 
 	for _, extractor := range e.bulkHTTPRequest.Extractors {
-		for match := range extractor.Extract(resp, body, headers) {
+		for _, match := range sortedResults(extractor.Extract(resp, body, headers, fields)) {
 			if _, ok := dynamicvalues[extractor.Name]; !ok {
 				dynamicvalues[extractor.Name] = match
 			}
@@ -558,6 +770,16 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 				outputExtractorResults = append(outputExtractorResults, match)
 			}
 		}
+		// named groups are bound to variables of the same name for the next requests and the output
+		for name, value := range extractor.Variables(body, headers, fields) {
+			if _, ok := dynamicvalues[name]; !ok {
+				dynamicvalues[name] = value
+			}
+			if variables == nil {
+				variables = make(map[string]interface{})
+			}
+			variables[name] = value
+		}
 		// probably redundant but ensures we snapshot current payload values when extractors are valid
 		result.Lock()
 		result.Meta = request.Meta
@@ -565,16 +787,87 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 		result.Unlock()
 	}
 
+	meta := result.Meta
+	if len(variables) > 0 {
+		meta = make(map[string]interface{}, len(result.Meta)+len(variables))
+		for k, v := range result.Meta {
+			meta[k] = v
+		}
+		for k, v := range variables {
+			meta[k] = v
+		}
+	}
+
 	// Write a final string of output if matcher type is
 	// AND or if we have extractors for the mechanism too.
 	if len(outputExtractorResults) > 0 || matcherCondition == matchers.ANDCondition {
-		e.writeOutputHTTP(request, resp, body, nil, outputExtractorResults, result.Meta)
+		findings = append(findings, &httpFinding{extractorResults: outputExtractorResults, meta: meta})
+	}
+
+	return findings
+}
+
+// writeFindings writes the findings of a response. It runs on the network
+// worker as the xss findings are first confirmed and the screenshots taken
+// in a browser, once per response.
+func (e *HTTPExecuter) writeFindings(request *requests.HTTPRequest, resp *http.Response, body string, findings []*httpFinding, interactions []string, result *Result) {
+	// the findings of a request cancelled with its workflow step aren't written
+	if len(findings) == 0 || request.Cancelled() || !e.confirmXSS(request) {
+		return
+	}
+	screenshot := e.capture(request)
+
+	for _, finding := range findings {
 		result.Lock()
+		if finding.matcher != nil {
+			result.Matches[finding.matcher.Name] = nil
+			// probably redundant but ensures we snapshot current payload values when matchers are valid
+			result.Meta = request.Meta
+		}
 		result.GotResults = true
 		result.Unlock()
+
+		e.writeOutputHTTP(request, resp, body, finding.matcher, finding.extractorResults, finding.meta, interactions, result.trace.Steps(), screenshot)
 	}
+}
 
-	return nil
+// confirmXSS returns false if the xss finding of a request didn't execute
+// javascript in a browser. Findings which can't be loaded by URL are kept.
+func (e *HTTPExecuter) confirmXSS(request *requests.HTTPRequest) bool {
+	if e.xssVerifier == nil {
+		return true
+	}
+
+	var URL string
+	if request.Request != nil && request.Request.Method == http.MethodGet && request.Request.URL.Opaque == "" {
+		URL = request.Request.URL.String()
+	} else if request.RawRequest != nil && request.RawRequest.Method == http.MethodGet {
+		URL = request.RawRequest.FullURL
+	} else {
+		return true
+	}
+
+	confirmed, err := e.xssVerifier.Confirm(URL)
+	if err != nil {
+		gologger.Warningf("[%s] Could not verify xss on %s: %s\n", e.template.ID, URL, err)
+		return true
+	}
+	if !confirmed {
+		gologger.Verbosef("Discarding unconfirmed xss on %s\n", e.template.ID, URL)
+	}
+
+	return confirmed
+}
+
+// isXSSTemplate returns true if the template is tagged as cross-site scripting
+func isXSSTemplate(template *templates.Template) bool {
+	for _, tag := range strings.Split(template.Info["tags"], ",") {
+		if strings.EqualFold(strings.TrimSpace(tag), "xss") {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Close closes the http executer for a template.
@@ -597,21 +890,30 @@ func makeHTTPClient(proxyURL *url.URL, options *HTTPOptions) (*retryablehttp.Cli
 		maxConnsPerHost = 500
 	}
 
+	// the transport sends connection: close and never reuses a connection
+	if options.BulkHTTPRequest.FreshConnection {
+		disableKeepAlives = true
+		maxIdleConns = 0
+		maxIdleConnsPerHost = -1
+	}
+
 	retryablehttpOptions.RetryWaitMax = 10 * time.Second
 	retryablehttpOptions.RetryMax = options.Retries
 	followRedirects := options.BulkHTTPRequest.Redirects
 	maxRedirects := options.BulkHTTPRequest.MaxRedirects
 
+	tlsConfig, err := tlsconfig.New(options.TLS.Merge(options.BulkHTTPRequest.TLS))
+	if err != nil {
+		return nil, err
+	}
+
 	transport := &http.Transport{
 		DialContext:         *options.Dialer,
 		MaxIdleConns:        maxIdleConns,
 		MaxIdleConnsPerHost: maxIdleConnsPerHost,
 		MaxConnsPerHost:     maxConnsPerHost,
-		TLSClientConfig: &tls.Config{
-			Renegotiation:      tls.RenegotiateOnceAsClient,
-			InsecureSkipVerify: true,
-		},
-		DisableKeepAlives: disableKeepAlives,
+		TLSClientConfig:     tlsConfig,
+		DisableKeepAlives:   disableKeepAlives,
 	}
 
 	// Attempts to overwrite the dial function with the socks proxied version
@@ -640,8 +942,32 @@ func makeHTTPClient(proxyURL *url.URL, options *HTTPOptions) (*retryablehttp.Cli
 		transport.Proxy = http.ProxyURL(proxyURL)
 	}
 
+	// the connections through the http proxies are set up by the transport
+	// itself, which only dials the proxies
+	direct := proxyURL == nil && options.Proxies == nil
+	if options.TLSProfile != "" && direct {
+		profile, err := tlsProfile(options.TLSProfile)
+		if err != nil {
+			return nil, err
+		}
+		transport.DialTLSContext = profileDialer(transport.DialContext, tlsConfig, profile)
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if options.RandomizeHeaders && direct {
+		roundTripper = &headerShuffler{transport: transport}
+	}
+	if options.Proxies != nil {
+		roundTripper = options.Proxies.wrap(transport)
+	}
+	if len(options.BulkHTTPRequest.Fallback) > 0 {
+		roundTripper = withFallback(transport, roundTripper, options.BulkHTTPRequest.Fallback, options.HTTP2)
+	} else {
+		roundTripper = withProtocol(transport, roundTripper, options.BulkHTTPRequest.Protocol, options.HTTP2)
+	}
+
 	return retryablehttp.NewWithHTTPClient(&http.Client{
-		Transport:     transport,
+		Transport:     roundTripper,
 		Timeout:       time.Duration(options.Timeout) * time.Second,
 		CheckRedirect: makeCheckRedirectFunc(followRedirects, maxRedirects),
 	}, retryablehttpOptions), nil
@@ -702,4 +1028,6 @@ type Result struct {
 	Extractions map[string]interface{}
 	historyData map[string]interface{}
 	Error       error
+	// trace records the requests leading to the findings if set
+	trace *Trace
 }
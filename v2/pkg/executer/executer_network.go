@@ -0,0 +1,221 @@
+package executer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/httpx/common/cache"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
+)
+
+// NetworkExecuter is a client for performing the raw tcp
+// requests of a template.
+type NetworkExecuter struct {
+	findingWriter
+
+	timeout        time.Duration
+	dialer         func(ctx context.Context, network, address string) (net.Conn, error)
+	tlsConfig      *tls.Config
+	networkRequest *requests.NetworkRequest
+}
+
+// NetworkOptions contains configuration options for the network executer.
+type NetworkOptions struct {
+	CommonOptions
+
+	Timeout        int
+	NetworkRequest *requests.NetworkRequest
+	// Dialer is the shared dialer of the runner, the system one is used if nil
+	Dialer *cache.DialerFunc
+	TLS    *tlsconfig.Options
+}
+
+// NewNetworkExecuter creates a new network executer from a template
+// and a network request.
+func NewNetworkExecuter(options *NetworkOptions) (*NetworkExecuter, error) {
+	tlsConfig, err := tlsconfig.New(options.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := (&net.Dialer{}).DialContext
+	if options.Dialer != nil {
+		dialer = *options.Dialer
+	}
+
+	executer := &NetworkExecuter{
+		findingWriter:  newFindingWriter(&options.CommonOptions),
+		timeout:        time.Duration(options.Timeout) * time.Second,
+		dialer:         dialer,
+		tlsConfig:      tlsConfig,
+		networkRequest: options.NetworkRequest,
+	}
+
+	return executer, nil
+}
+
+// ExecuteNetwork connects to the addresses of the request for a target
+func (e *NetworkExecuter) ExecuteNetwork(p progress.IProgress, reqURL string) *Result {
+	result := &Result{}
+
+	addresses, err := e.networkRequest.MakeNetworkRequests(reqURL)
+	if err != nil {
+		result.Error = &TemplateError{Err: errors.Wrap(err, "could not make network request")}
+		p.Drop(e.networkRequest.GetRequestCount())
+
+		return result
+	}
+
+	for _, address := range addresses {
+		data, err := e.exchange(address)
+		e.traceLog.Request(e.template.ID, address.Address, "network", err)
+		if err != nil {
+			result.Error = errors.Wrap(err, "could not send network request")
+			p.Drop(1)
+
+			continue
+		}
+		p.Update()
+
+		if e.debug {
+			gologger.Infof("Dumped network response for %s (%s)\n\n", address.Address, e.template.ID)
+			fmt.Fprintf(os.Stderr, "%s\n", data)
+		}
+
+		if e.match(address, data) {
+			result.GotResults = true
+		}
+	}
+
+	gologger.Verbosef("Sent for [%s] to %s\n", "network-request", e.template.ID, reqURL)
+
+	return result
+}
+
+// exchange connects to an address, sends the inputs and returns all the data read
+func (e *NetworkExecuter) exchange(address *requests.NetworkAddress) (string, error) {
+	ctx := context.Background()
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	conn, err := e.dialer(ctx, "tcp", address.Address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if address.TLS {
+		config := e.tlsConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(address.Address)
+		}
+		conn = tls.Client(conn, config)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		//nolint:errcheck // the deadline only fails on closed connections
+		conn.SetDeadline(deadline)
+	}
+
+	var response []byte
+	for _, input := range address.Inputs {
+		if _, err := conn.Write(input.Data); err != nil {
+			return string(response), err
+		}
+		if input.Read > 0 {
+			data, err := readAtMost(conn, input.Read)
+			response = append(response, data...)
+			if err != nil {
+				return string(response), err
+			}
+		}
+	}
+
+	data, err := readAtMost(conn, e.networkRequest.GetReadSize())
+	response = append(response, data...)
+	if err != nil && len(response) == 0 {
+		return "", err
+	}
+
+	return string(response), nil
+}
+
+// readAtMost reads up to size bytes, returning what was read before the
+// connection was closed or timed out as servers don't announce the size.
+func readAtMost(conn net.Conn, size int) ([]byte, error) {
+	buffer := make([]byte, size)
+	n, err := conn.Read(buffer)
+	if err == io.EOF {
+		err = nil
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() && n > 0 {
+		err = nil
+	}
+
+	return buffer[:n], err
+}
+
+// match runs the matchers and extractors on the data read from an address
+func (e *NetworkExecuter) match(address *requests.NetworkAddress, data string) bool {
+	var fields map[string]interface{}
+	if e.networkRequest.NeedsFields() {
+		fields = networkToMap(data, "")
+	}
+
+	matcherCondition := e.networkRequest.GetMatchersCondition()
+	matched := false
+
+	for _, matcher := range e.networkRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchNetwork(data, fields) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return false
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.networkRequest.Extractors) == 0 {
+				e.writeOutputNetwork(address, data, matcher, nil)
+				matched = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	for _, extractor := range e.networkRequest.Extractors {
+		for _, match := range sortedResults(extractor.ExtractNetwork(data, fields)) {
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(e.networkRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
+		e.writeOutputNetwork(address, data, nil, extractorResults)
+		matched = true
+	}
+
+	return matched
+}
+
+// Close closes the network executer for a template.
+func (e *NetworkExecuter) Close() {}
@@ -0,0 +1,200 @@
+package executer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/httpx/common/cache"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
+)
+
+// SSLExecuter is a client for performing the tls handshakes
+// of a template.
+type SSLExecuter struct {
+	findingWriter
+
+	timeout    time.Duration
+	dialer     func(ctx context.Context, network, address string) (net.Conn, error)
+	tlsConfig  *tls.Config
+	sslRequest *requests.SSLRequest
+}
+
+// SSLOptions contains configuration options for the ssl executer.
+type SSLOptions struct {
+	CommonOptions
+
+	Timeout    int
+	SSLRequest *requests.SSLRequest
+	// Dialer is the shared dialer of the runner, the system one is used if nil
+	Dialer *cache.DialerFunc
+	TLS    *tlsconfig.Options
+}
+
+// NewSSLExecuter creates a new ssl executer from a template
+// and a ssl request.
+func NewSSLExecuter(options *SSLOptions) (*SSLExecuter, error) {
+	tlsConfig, err := tlsconfig.New(options.TLS.Merge(options.SSLRequest.TLS))
+	if err != nil {
+		return nil, err
+	}
+	// the certificates are checked by the matchers, an invalid one must not fail the handshake
+	tlsConfig.InsecureSkipVerify = true
+
+	dialer := (&net.Dialer{}).DialContext
+	if options.Dialer != nil {
+		dialer = *options.Dialer
+	}
+
+	executer := &SSLExecuter{
+		findingWriter: newFindingWriter(&options.CommonOptions),
+		timeout:       time.Duration(options.Timeout) * time.Second,
+		dialer:        dialer,
+		tlsConfig:     tlsConfig,
+		sslRequest:    options.SSLRequest,
+	}
+
+	return executer, nil
+}
+
+// ExecuteSSL handshakes with the addresses of the request for a target
+func (e *SSLExecuter) ExecuteSSL(p progress.IProgress, reqURL string) *Result {
+	result := &Result{}
+
+	addresses, err := e.sslRequest.MakeSSLRequests(reqURL)
+	if err != nil {
+		result.Error = &TemplateError{Err: errors.Wrap(err, "could not make ssl request")}
+		p.Drop(e.sslRequest.GetRequestCount())
+
+		return result
+	}
+
+	for _, address := range addresses {
+		state, err := e.handshake(address)
+		e.traceLog.Request(e.template.ID, address, "ssl", err)
+		if err != nil {
+			// a server refusing the offered versions or ciphers simply doesn't match
+			if _, ok := err.(handshakeError); ok {
+				gologger.Verbosef("Handshake with %s failed: %s\n", "ssl-request", address, err)
+			} else {
+				result.Error = errors.Wrap(err, "could not send ssl request")
+			}
+			p.Drop(1)
+
+			continue
+		}
+		p.Update()
+
+		if e.debug {
+			gologger.Infof("Dumped ssl handshake for %s (%s)\n\n", address, e.template.ID)
+			fmt.Fprintf(os.Stderr, "%s\n", sslText(state))
+		}
+
+		if e.match(address, state) {
+			result.GotResults = true
+		}
+	}
+
+	gologger.Verbosef("Sent for [%s] to %s\n", "ssl-request", e.template.ID, reqURL)
+
+	return result
+}
+
+// handshakeError is the failure of a handshake once connected
+type handshakeError struct {
+	error
+}
+
+// handshake connects to an address and returns the state of the tls handshake
+func (e *SSLExecuter) handshake(address string) (*tls.ConnectionState, error) {
+	ctx := context.Background()
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	conn, err := e.dialer(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		//nolint:errcheck // the deadline only fails on closed connections
+		conn.SetDeadline(deadline)
+	}
+
+	config := e.tlsConfig.Clone()
+	if config.ServerName == "" {
+		config.ServerName, _, _ = net.SplitHostPort(address)
+	}
+	client := tls.Client(conn, config)
+	if err := client.Handshake(); err != nil {
+		return nil, handshakeError{err}
+	}
+
+	state := client.ConnectionState()
+	return &state, nil
+}
+
+// match runs the matchers and extractors on a handshake with an address
+func (e *SSLExecuter) match(address string, state *tls.ConnectionState) bool {
+	text := sslText(state)
+	var fields map[string]interface{}
+	if e.sslRequest.NeedsFields() {
+		fields = sslToMap(state, address, "")
+	}
+
+	matcherCondition := e.sslRequest.GetMatchersCondition()
+	matched := false
+
+	for _, matcher := range e.sslRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchSSL(text, fields) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return false
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.sslRequest.Extractors) == 0 {
+				e.writeOutputSSL(address, state, matcher, nil)
+				matched = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	for _, extractor := range e.sslRequest.Extractors {
+		for _, match := range sortedResults(extractor.ExtractSSL(text, fields)) {
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(e.sslRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
+		e.writeOutputSSL(address, state, nil, extractorResults)
+		matched = true
+	}
+
+	return matched
+}
+
+// Close closes the ssl executer for a template.
+func (e *SSLExecuter) Close() {}
@@ -0,0 +1,257 @@
+package executer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/httpx/common/cache"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
+	"github.com/projectdiscovery/nuclei/v2/pkg/websocket"
+)
+
+// websocketIdleTimeout is the time without messages after which the server
+// is considered done once the inputs are sent
+const websocketIdleTimeout = 2 * time.Second
+
+// WebsocketExecuter is a client for performing the websocket connections
+// of a template.
+type WebsocketExecuter struct {
+	findingWriter
+
+	timeout          time.Duration
+	dialer           func(ctx context.Context, network, address string) (net.Conn, error)
+	tlsConfig        *tls.Config
+	customHeaders    requests.CustomHeaders
+	websocketRequest *requests.WebsocketRequest
+}
+
+// WebsocketOptions contains configuration options for the websocket executer.
+type WebsocketOptions struct {
+	CommonOptions
+
+	Timeout          int
+	CustomHeaders    requests.CustomHeaders
+	WebsocketRequest *requests.WebsocketRequest
+	// Dialer is the shared dialer of the runner, the system one is used if nil
+	Dialer *cache.DialerFunc
+	TLS    *tlsconfig.Options
+}
+
+// NewWebsocketExecuter creates a new websocket executer from a template
+// and a websocket request.
+func NewWebsocketExecuter(options *WebsocketOptions) (*WebsocketExecuter, error) {
+	tlsConfig, err := tlsconfig.New(options.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := (&net.Dialer{}).DialContext
+	if options.Dialer != nil {
+		dialer = *options.Dialer
+	}
+
+	executer := &WebsocketExecuter{
+		findingWriter:    newFindingWriter(&options.CommonOptions),
+		timeout:          time.Duration(options.Timeout) * time.Second,
+		dialer:           dialer,
+		tlsConfig:        tlsConfig,
+		customHeaders:    options.CustomHeaders,
+		websocketRequest: options.WebsocketRequest,
+	}
+
+	return executer, nil
+}
+
+// ExecuteWebsocket connects to the websocket of the request for a target
+func (e *WebsocketExecuter) ExecuteWebsocket(p progress.IProgress, reqURL string) *Result {
+	result := &Result{}
+
+	connection, err := e.websocketRequest.MakeWebsocketRequest(reqURL)
+	if err != nil {
+		result.Error = &TemplateError{Err: errors.Wrap(err, "could not make websocket request")}
+		p.Drop(1)
+
+		return result
+	}
+
+	response, err := e.connect(connection)
+	e.traceLog.Request(e.template.ID, connection.URL, "websocket", err)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not send websocket request")
+		p.Drop(1)
+
+		return result
+	}
+	p.Update()
+
+	gologger.Verbosef("Sent for [%s] to %s\n", "websocket-request", e.template.ID, connection.URL)
+
+	if e.debug {
+		gologger.Infof("Dumped websocket connection for %s (%s)\n\n", connection.URL, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n", websocketPart(response, matchers.AllPart))
+	}
+
+	var fields map[string]interface{}
+	if e.websocketRequest.NeedsFields() {
+		fields = websocketToMap(response, "")
+	}
+
+	matcherCondition := e.websocketRequest.GetMatchersCondition()
+
+	for _, matcher := range e.websocketRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchWebsocket(response.StatusCode, websocketPart(response, matcher.GetPart()), fields) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return result
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.websocketRequest.Extractors) == 0 {
+				e.writeOutputWebsocket(connection, response, matcher, nil)
+				result.GotResults = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	for _, extractor := range e.websocketRequest.Extractors {
+		for _, match := range sortedResults(extractor.ExtractWebsocket(websocketPart(response, matchers.BodyPart), websocketHeaders(response), response.Headers, fields)) {
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(e.websocketRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
+		e.writeOutputWebsocket(connection, response, nil, extractorResults)
+		result.GotResults = true
+	}
+
+	return result
+}
+
+// connect upgrades the connection, sends the inputs and reads the messages
+// of the server. A refused handshake is returned as a response to match,
+// eg. to check the origins allowed.
+func (e *WebsocketExecuter) connect(connection *requests.WebsocketConnection) (*websocket.Response, error) {
+	ctx := context.Background()
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	conn, handshake, err := websocket.Dial(ctx, e.dialer, e.tlsConfig, connection.URL, e.headers(connection))
+	if handshake == nil {
+		return nil, err
+	}
+	response := &websocket.Response{StatusCode: handshake.StatusCode, Headers: handshake.Header}
+	if err == websocket.ErrBadHandshake {
+		// the body is already read by the handshake
+		body, _ := ioutil.ReadAll(handshake.Body)
+		response.Body = string(body)
+
+		return response, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline, hasDeadline := ctx.Deadline()
+	for _, input := range connection.Inputs {
+		opcode := websocket.TextMessage
+		if input.Binary {
+			opcode = websocket.BinaryMessage
+		}
+		if err := conn.WriteMessage(opcode, input.Data); err != nil {
+			return nil, err
+		}
+
+		// the awaited messages are only bounded by the timeout of the connection
+		if input.Read > 0 && !e.read(conn, response, input.Read, func() time.Time { return deadline }) {
+			return response, nil
+		}
+	}
+
+	e.read(conn, response, e.websocketRequest.GetMaxMessages(), func() time.Time {
+		idle := time.Now().Add(websocketIdleTimeout)
+		if hasDeadline && deadline.Before(idle) {
+			return deadline
+		}
+		return idle
+	})
+
+	return response, nil
+}
+
+// read reads up to count messages into the response, each before the
+// deadline returned for it. It returns false once the connection is
+// closed or the maximum number of messages is read.
+func (e *WebsocketExecuter) read(conn *websocket.Conn, response *websocket.Response, count int, deadline func() time.Time) bool {
+	for i := 0; i < count; i++ {
+		if len(response.Messages) >= e.websocketRequest.GetMaxMessages() {
+			return false
+		}
+
+		//nolint:errcheck // the deadline only fails on closed connections
+		conn.SetReadDeadline(deadline())
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if closeErr, ok := err.(*websocket.CloseError); ok {
+				response.CloseCode = closeErr.Code
+				response.CloseReason = closeErr.Reason
+				return false
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return true
+			}
+			gologger.Verbosef("Could not read websocket message: %s\n", "websocket-request", err)
+			return false
+		}
+		response.Messages = append(response.Messages, string(data))
+	}
+
+	return true
+}
+
+// headers returns the headers of the handshake, the custom headers of the
+// runner replacing the ones of the template.
+func (e *WebsocketExecuter) headers(connection *requests.WebsocketConnection) http.Header {
+	header := make(http.Header, len(connection.Headers)+len(e.customHeaders))
+	for name, value := range connection.Headers {
+		header.Set(name, value)
+	}
+
+	for _, customHeader := range e.customHeaders {
+		tokens := strings.SplitN(customHeader, ":", two)
+		if len(tokens) < two {
+			continue
+		}
+		header.Set(strings.TrimSpace(tokens[0]), strings.TrimSpace(tokens[1]))
+	}
+
+	return header
+}
+
+// Close closes the websocket executer for a template.
+func (e *WebsocketExecuter) Close() {}
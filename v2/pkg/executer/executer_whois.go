@@ -0,0 +1,223 @@
+package executer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/httpx/common/cache"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+const (
+	// ianaWhoisServer refers to the whois server of each tld and network
+	ianaWhoisServer = "whois.iana.org"
+	// maxWhoisSize is the maximum size of a whois response read
+	maxWhoisSize = 1024 * 1024
+)
+
+// WhoisExecuter is a client for performing the whois lookups
+// of a template.
+type WhoisExecuter struct {
+	findingWriter
+
+	timeout      time.Duration
+	dialer       func(ctx context.Context, network, address string) (net.Conn, error)
+	whoisRequest *requests.WhoisRequest
+}
+
+// WhoisOptions contains configuration options for the whois executer.
+type WhoisOptions struct {
+	CommonOptions
+
+	Timeout      int
+	WhoisRequest *requests.WhoisRequest
+	// Dialer is the shared dialer of the runner, the system one is used if nil
+	Dialer *cache.DialerFunc
+}
+
+// NewWhoisExecuter creates a new whois executer from a template
+// and a whois request.
+func NewWhoisExecuter(options *WhoisOptions) *WhoisExecuter {
+	dialer := (&net.Dialer{}).DialContext
+	if options.Dialer != nil {
+		dialer = *options.Dialer
+	}
+
+	executer := &WhoisExecuter{
+		findingWriter: newFindingWriter(&options.CommonOptions),
+		timeout:       time.Duration(options.Timeout) * time.Second,
+		dialer:        dialer,
+		whoisRequest:  options.WhoisRequest,
+	}
+
+	return executer
+}
+
+// ExecuteWhois looks up the query of the request for a target
+func (e *WhoisExecuter) ExecuteWhois(p progress.IProgress, reqURL string) *Result {
+	result := &Result{}
+
+	query := e.whoisRequest.MakeWhoisQuery(reqURL)
+	server, response, err := e.lookup(query)
+	e.traceLog.Request(e.template.ID, query, "whois", err)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not send whois request")
+		p.Drop(1)
+
+		return result
+	}
+	p.Update()
+
+	gologger.Verbosef("Sent for [%s] to %s\n", "whois-request", e.template.ID, server)
+
+	if e.debug {
+		gologger.Infof("Dumped whois response for %s from %s (%s)\n\n", query, server, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n", response)
+	}
+
+	var fields map[string]interface{}
+	if e.whoisRequest.NeedsFields() {
+		fields = whoisToMap(response, "")
+	}
+
+	matcherCondition := e.whoisRequest.GetMatchersCondition()
+
+	for _, matcher := range e.whoisRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchWhois(response, fields) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return result
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.whoisRequest.Extractors) == 0 {
+				e.writeOutputWhois(query, response, matcher, nil)
+				result.GotResults = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	for _, extractor := range e.whoisRequest.Extractors {
+		for _, match := range sortedResults(extractor.ExtractWhois(response, fields)) {
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(e.whoisRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
+		e.writeOutputWhois(query, response, nil, extractorResults)
+		result.GotResults = true
+	}
+
+	return result
+}
+
+// lookup queries the whois server of the request, or the one iana refers to.
+// The registrar server the registry refers to for the thin registries is
+// queried too, its response following the one of the registry.
+func (e *WhoisExecuter) lookup(query string) (server, response string, err error) {
+	server = e.whoisRequest.Server
+	if server == "" {
+		referral, err := e.query(ianaWhoisServer, query)
+		if err != nil {
+			return ianaWhoisServer, "", err
+		}
+		server = referralServer(referral, "refer")
+		if server == "" {
+			return ianaWhoisServer, referral, nil
+		}
+	}
+
+	response, err = e.query(server, query)
+	if err != nil {
+		return server, "", err
+	}
+
+	if registrar := referralServer(response, "registrar whois server"); registrar != "" && !strings.EqualFold(registrar, server) {
+		if registrarResponse, err := e.query(registrar, query); err == nil {
+			response += "\n" + registrarResponse
+		} else {
+			gologger.Verbosef("Could not query registrar whois server %s: %s\n", "whois-request", registrar, err)
+		}
+	}
+
+	return server, response, nil
+}
+
+// query sends a query to a whois server and returns its whole response
+func (e *WhoisExecuter) query(server, query string) (string, error) {
+	ctx := context.Background()
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	address := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		address = net.JoinHostPort(server, "43")
+	}
+	conn, err := e.dialer(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		//nolint:errcheck // the deadline only fails on closed connections
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte(query + "\r\n")); err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(conn, maxWhoisSize))
+	if err != nil && len(data) == 0 {
+		return "", err
+	}
+
+	return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
+}
+
+// referralServer returns the server of a referral field of a whois response
+func referralServer(response, field string) string {
+	scanner := bufio.NewScanner(strings.NewReader(response))
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 2)
+		if len(parts) != 2 || !strings.EqualFold(strings.TrimSpace(parts[0]), field) {
+			continue
+		}
+
+		// some registries write the server as an url
+		value := strings.TrimSpace(parts[1])
+		value = strings.TrimPrefix(strings.TrimPrefix(value, "whois://"), "rwhois://")
+		if value != "" && !strings.Contains(value, "://") {
+			return strings.TrimSuffix(value, "/")
+		}
+	}
+
+	return ""
+}
+
+// Close closes the whois executer for a template.
+func (e *WhoisExecuter) Close() {}
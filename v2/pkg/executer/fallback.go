@@ -0,0 +1,66 @@
+package executer
+
+import (
+	"net/http"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"golang.org/x/net/http2"
+)
+
+// fallbackTransport sends a request over the transports of a fallback
+// ladder in turn, until one of them gets a response from the target.
+type fallbackTransport struct {
+	ladder []string
+	// roundTripper sends the https and http requests
+	roundTripper http.RoundTripper
+	h2c          *http2.Transport
+}
+
+// withFallback returns the round tripper trying the transports of a ladder,
+// http2 being negotiated over https if negotiate is true.
+func withFallback(transport *http.Transport, roundTripper http.RoundTripper, ladder []string, negotiate bool) http.RoundTripper {
+	transport.ForceAttemptHTTP2 = negotiate
+
+	return &fallbackTransport{ladder: ladder, roundTripper: roundTripper, h2c: newH2CTransport(transport)}
+}
+
+// RoundTrip sends a request over the first transport of the ladder which
+// connects, the error of the last one being returned if none does. The
+// redirects are followed as they are, over the transport of their URL.
+func (t *fallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Response != nil {
+		return t.roundTripper.RoundTrip(req)
+	}
+
+	var err error
+	for i, transport := range t.ladder {
+		attempt := req.Clone(req.Context())
+		if i > 0 && req.Body != nil && req.Body != http.NoBody {
+			// the body may have been consumed by the failed attempt
+			if req.GetBody == nil {
+				return nil, err
+			}
+			if attempt.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		var resp *http.Response
+		switch transport {
+		case requests.HTTPSTransport:
+			attempt.URL.Scheme = "https"
+			resp, err = t.roundTripper.RoundTrip(attempt)
+		case requests.HTTPTransport:
+			attempt.URL.Scheme = "http"
+			resp, err = t.roundTripper.RoundTrip(attempt)
+		case requests.H2CTransport:
+			attempt.URL.Scheme = "http"
+			resp, err = t.h2c.RoundTrip(attempt)
+		}
+		if err == nil {
+			return resp, nil
+		}
+	}
+
+	return nil, err
+}
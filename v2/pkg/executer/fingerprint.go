@@ -0,0 +1,60 @@
+package executer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// fingerprint returns a stable hash identifying a finding, so that the
+// same issue found by different scans can be deduplicated downstream.
+//
+// It covers the template, the normalized target and the evidence of the
+// finding: the matcher name and the extracted results.
+func fingerprint(templateID, target string, matcher *matchers.Matcher, extractorResults []string) string {
+	hash := sha256.New()
+	hash.Write([]byte(templateID + "\x00" + normalizeTarget(target) + "\x00"))
+	if matcher != nil {
+		hash.Write([]byte(matcher.Name))
+	}
+	hash.Write([]byte{0})
+
+	results := append([]string(nil), extractorResults...)
+	sort.Strings(results)
+	hash.Write([]byte(strings.Join(results, "\x00")))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// normalizeTarget lowercases the scheme and host of a target and drops
+// the default ports and fragments, which don't change what's requested.
+// Targets which aren't URLs, like dns domains, are only lowercased.
+func normalizeTarget(target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return strings.TrimSuffix(strings.ToLower(target), ".")
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	host := strings.ToLower(parsed.Host)
+	if (scheme == "http" && strings.HasSuffix(host, ":80")) || (scheme == "https" && strings.HasSuffix(host, ":443")) {
+		host = host[:strings.LastIndexByte(host, ':')]
+	}
+
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if parsed.Opaque != "" {
+		path = parsed.Opaque
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+
+	return scheme + "://" + host + path
+}
@@ -0,0 +1,70 @@
+package executer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"golang.org/x/net/http2"
+)
+
+// errNotHTTP2 is returned when a server doesn't negotiate http2 for a request forcing it
+var errNotHTTP2 = errors.New("the server did not negotiate http2")
+
+// withProtocol sets the http version of the requests sent by a transport and
+// returns the round tripper sending them. The version is negotiated with the
+// server if negotiate is true and the request forces none.
+func withProtocol(transport *http.Transport, roundTripper http.RoundTripper, protocol string, negotiate bool) http.RoundTripper {
+	switch protocol {
+	case requests.HTTP11Protocol:
+		// a non nil map disables the upgrade of the tls connections to http2
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+		return roundTripper
+	case requests.HTTP2Protocol:
+		transport.ForceAttemptHTTP2 = true
+		return &http2Transport{tls: roundTripper, h2c: newH2CTransport(transport)}
+	}
+
+	transport.ForceAttemptHTTP2 = negotiate
+	return roundTripper
+}
+
+// newH2CTransport returns a transport sending the requests over cleartext
+// http2 with prior knowledge, on the connections dialed by transport.
+func newH2CTransport(transport *http.Transport) *http2.Transport {
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return transport.DialContext(context.Background(), network, addr)
+		},
+	}
+}
+
+// http2Transport sends the requests over http2, negotiated with alpn for the
+// https URLs and with prior knowledge over cleartext (h2c) for the http ones.
+// The h2c connections are dialed directly, without the http proxies.
+type http2Transport struct {
+	tls http.RoundTripper
+	h2c *http2.Transport
+}
+
+// RoundTrip sends a request over http2, failing if the server downgrades it
+func (t *http2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" {
+		return t.h2c.RoundTrip(req)
+	}
+
+	resp, err := t.tls.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ProtoMajor != two {
+		resp.Body.Close()
+		return nil, errNotHTTP2
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,98 @@
+package executer
+
+import (
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/interactsh"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// interactshURLVariable is replaced with a unique host of the interactsh
+// server, the same for all the requests of a template on a target.
+const interactshURLVariable = "interactsh-url"
+
+// defaultInteractshWait is the time waited for the interactions of a request
+const defaultInteractshWait = 2 * interactsh.DefaultPollInterval
+
+// templateValues returns the initial values of the requests of a template on a target,
+// with the values extracted there by its previous requests and an interactsh url if
+// the template uses one.
+func (e *HTTPExecuter) templateValues(target string) map[string]interface{} {
+	values := newDynamicValues()
+	for name, value := range e.values.Get(target) {
+		values[name] = value
+	}
+	if e.interactsh != nil && (e.needsInteractions || usesInteractshURL(e.bulkHTTPRequest)) {
+		if host := e.interactsh.URLFor(&interactsh.Correlation{Template: e.template.ID, Target: target}); host != "" {
+			values[interactshURLVariable] = host
+		}
+	}
+
+	return values
+}
+
+// correlate gives a request sending the interactsh url a url of its own, so
+// that the interactions identify the request and its payload values, eg. the
+// parameter a blind injection was found in. The steps without the url keep
+// the one of the previous steps, which can be triggered by them. The
+// correlation of the request is returned to add its payload values to.
+func (e *HTTPExecuter) correlate(target, data string, dynamicvalues map[string]interface{}, requestNumber int) *interactsh.Correlation {
+	if _, ok := dynamicvalues[interactshURLVariable]; !ok {
+		return nil
+	}
+
+	// the paths share the headers and the body, the raw requests have their own
+	marker := "{{" + interactshURLVariable + "}}"
+	if !strings.Contains(data, marker) && (len(e.bulkHTTPRequest.Raw) > 0 || !usesInteractshURL(e.bulkHTTPRequest)) {
+		return nil
+	}
+
+	correlation := &interactsh.Correlation{Template: e.template.ID, Target: target, Request: requestNumber}
+	if host := e.interactsh.URLFor(correlation); host != "" {
+		dynamicvalues[interactshURLVariable] = host
+		return correlation
+	}
+
+	return nil
+}
+
+// interactionsData waits for the interactions with the interactsh url of a
+// template run and returns them as the variables of the interactsh parts,
+// along with the raw interactions.
+func (e *HTTPExecuter) interactionsData(dynamicvalues map[string]interface{}) (map[string]interface{}, []string) {
+	var protocols, rawRequests []string
+
+	if host, ok := dynamicvalues[interactshURLVariable].(string); ok {
+		wait := e.interactshWait
+		if wait <= 0 {
+			wait = defaultInteractshWait
+		}
+		for _, interaction := range e.interactsh.Wait(host, wait) {
+			protocols = append(protocols, interaction.Protocol)
+			rawRequests = append(rawRequests, interaction.RawRequest)
+		}
+	}
+
+	return map[string]interface{}{
+		matchers.InteractshProtocolVariable: strings.Join(protocols, "\n"),
+		matchers.InteractshRequestVariable:  strings.Join(rawRequests, "\n"),
+	}, rawRequests
+}
+
+// usesInteractshURL returns true if a request contains the interactsh url variable
+func usesInteractshURL(request *requests.BulkHTTPRequest) bool {
+	marker := "{{" + interactshURLVariable + "}}"
+
+	parts := append(append([]string{request.Body}, request.Path...), request.Raw...)
+	for _, value := range request.Headers {
+		parts = append(parts, value)
+	}
+	for _, part := range parts {
+		if strings.Contains(part, marker) {
+			return true
+		}
+	}
+
+	return false
+}
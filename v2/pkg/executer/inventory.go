@@ -0,0 +1,234 @@
+package executer
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/internal/bufwriter"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/versions"
+)
+
+// cpeFields is the number of fields of a cpe 2.3 formatted string, prefix included
+const cpeFields = 13
+
+// versionVariable is the named extractor group holding the detected version
+const versionVariable = "version"
+
+// exposureTags are the tags of the fingerprint templates written as assets in exposures mode
+var exposureTags = map[string]struct{}{
+	"tech":        {},
+	"fingerprint": {},
+	"detect":      {},
+}
+
+// InventoryRecord is an asset detected by a template declaring product metadata
+type InventoryRecord struct {
+	Host      string `json:"host"`
+	Port      string `json:"port,omitempty"`
+	Matched   string `json:"matched"`
+	Template  string `json:"template"`
+	Vendor    string `json:"vendor,omitempty"`
+	Product   string `json:"product,omitempty"`
+	Version   string `json:"version,omitempty"`
+	CPE       string `json:"cpe,omitempty"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Inventory writes the assets found by the templates with cpe, vendor or
+// product info to a separate stream of records, once per asset.
+//
+// In exposures mode the findings of the fingerprint templates are only
+// written as assets, the product defaulting to the matcher name.
+type Inventory struct {
+	writer    *bufwriter.Writer
+	exposures bool
+	mutex     sync.Mutex
+	seen      map[string]struct{}
+}
+
+// NewInventory creates an inventory writing json lines records to a writer
+func NewInventory(writer *bufwriter.Writer, exposures bool) *Inventory {
+	return &Inventory{writer: writer, exposures: exposures, seen: make(map[string]struct{})}
+}
+
+// record writes the inventory record of a finding if its template declares
+// product metadata. It returns true if the finding is an exposure which
+// must not be written with the vulnerability findings.
+func (i *Inventory) record(template *templates.Template, matched string, matcher *matchers.Matcher, extractorResults []string, meta map[string]interface{}) bool {
+	if i == nil {
+		return false
+	}
+	exposure := i.exposures && isExposure(template.Info)
+
+	vendor, product, cpe := normalizeProduct(template.Info)
+	if product == "" && exposure {
+		product = template.ID
+		if matcher != nil && matcher.Name != "" {
+			product = cpeValue(matcher.Name)
+		}
+	}
+	if vendor == "" && product == "" && cpe == "" {
+		return false
+	}
+
+	record := &InventoryRecord{
+		Matched:  matched,
+		Template: template.ID,
+		Vendor:   vendor,
+		Product:  product,
+		Version:  detectedVersion(extractorResults, meta),
+	}
+	record.Host, record.Port = hostPort(matched)
+	record.CPE = cpeWithVersion(cpe, record.Version)
+
+	key := strings.Join([]string{record.Host, record.Port, record.Template, record.Product, record.CPE, record.Version}, "\x00")
+	i.mutex.Lock()
+	if _, ok := i.seen[key]; ok {
+		i.mutex.Unlock()
+		return exposure
+	}
+	i.seen[key] = struct{}{}
+	i.mutex.Unlock()
+
+	record.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	data, err := jsonMarshaler.Marshal(record)
+	if err != nil {
+		gologger.Warningf("Could not marshal inventory record: %s\n", err)
+		return exposure
+	}
+	if err := i.writer.Write(data); err != nil {
+		gologger.Errorf("Could not write inventory record: %s\n", err)
+	}
+
+	return exposure
+}
+
+// isExposure returns true for the fingerprint templates, tagged as such or
+// informational templates declaring a product.
+func isExposure(info map[string]string) bool {
+	for _, tag := range strings.Split(info["tags"], ",") {
+		if _, ok := exposureTags[strings.ToLower(strings.TrimSpace(tag))]; ok {
+			return true
+		}
+	}
+
+	severity := strings.ToLower(info["severity"])
+	return (severity == "" || severity == "info") && (info["cpe"] != "" || info["product"] != "")
+}
+
+// normalizeProduct returns the vendor, product and cpe 2.3 string of a template info,
+// each one filling the missing others. The cpe may also use the 2.2 uri format.
+func normalizeProduct(info map[string]string) (vendor, product, cpe string) {
+	vendor = cpeValue(info["vendor"])
+	product = cpeValue(info["product"])
+
+	fields := cpeFieldsOf(strings.ToLower(strings.TrimSpace(info["cpe"])))
+	if fields == nil {
+		if product == "" {
+			return vendor, product, ""
+		}
+		fields = []string{"cpe", "2.3", "a", vendor, product}
+		if vendor == "" {
+			fields[3] = "*"
+		}
+	}
+	for len(fields) < cpeFields {
+		fields = append(fields, "*")
+	}
+
+	if vendor == "" && fields[3] != "*" {
+		vendor = fields[3]
+	}
+	if product == "" && fields[4] != "*" {
+		product = fields[4]
+	}
+
+	return vendor, product, strings.Join(fields[:cpeFields], ":")
+}
+
+// cpeFieldsOf splits a cpe 2.3 or 2.2 uri into the fields of the 2.3 format
+func cpeFieldsOf(cpe string) []string {
+	switch {
+	case strings.HasPrefix(cpe, "cpe:2.3:"):
+		return strings.Split(cpe, ":")
+	case strings.HasPrefix(cpe, "cpe:/"):
+		fields := []string{"cpe", "2.3"}
+		for _, field := range strings.Split(strings.TrimPrefix(cpe, "cpe:/"), ":") {
+			if field == "" {
+				field = "*"
+			}
+			fields = append(fields, field)
+		}
+		return fields
+	}
+
+	return nil
+}
+
+// cpeValue converts a name to a cpe field value
+func cpeValue(value string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value)), " ", "_")
+}
+
+// cpeWithVersion sets the version of a cpe string if it isn't set by the template
+func cpeWithVersion(cpe, version string) string {
+	if cpe == "" || version == "" {
+		return cpe
+	}
+
+	fields := strings.Split(cpe, ":")
+	if fields[5] == "*" {
+		fields[5] = cpeValue(version)
+	}
+	return strings.Join(fields, ":")
+}
+
+// detectedVersion returns the version extracted by a named group, or the
+// single extracted result if it looks like a version.
+func detectedVersion(extractorResults []string, meta map[string]interface{}) string {
+	if version, ok := meta[versionVariable].(string); ok && version != "" {
+		return version
+	}
+	if len(extractorResults) == 1 && versions.Valid(extractorResults[0]) {
+		return strings.TrimSpace(extractorResults[0])
+	}
+
+	return ""
+}
+
+// hostPort returns the host and port of a matched URL or address
+func hostPort(matched string) (host, port string) {
+	if u, err := url.Parse(matched); err == nil && u.Host != "" {
+		port = u.Port()
+		if port == "" {
+			port = defaultPorts[u.Scheme]
+		}
+		return u.Hostname(), port
+	}
+	if host, port, err := net.SplitHostPort(matched); err == nil {
+		return host, port
+	}
+
+	return matched, ""
+}
+
+// defaultPorts contains the ports of the schemes without an explicit one
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Close flushes the inventory records and closes their file
+func (i *Inventory) Close() error {
+	if i == nil {
+		return nil
+	}
+
+	return i.writer.Close()
+}
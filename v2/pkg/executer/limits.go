@@ -0,0 +1,77 @@
+package executer
+
+import (
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// FindingLimits caps the number of findings written, so a template matching
+// every URL of a host doesn't flood the output with identical findings.
+type FindingLimits struct {
+	perTemplateHost int
+	total           int
+
+	mutex      sync.Mutex
+	counts     map[string]int
+	written    int
+	suppressed int
+}
+
+// NewFindingLimits creates the limits of the findings of a template on a host
+// and of all the findings of the scan, 0 leaving them unlimited.
+//
+// A nil limiter is returned if both are unlimited.
+func NewFindingLimits(perTemplateHost, total int) *FindingLimits {
+	if perTemplateHost <= 0 && total <= 0 {
+		return nil
+	}
+
+	return &FindingLimits{perTemplateHost: perTemplateHost, total: total, counts: make(map[string]int)}
+}
+
+// Allow returns true if a finding of a template on a target is under the limits
+func (l *FindingLimits) Allow(templateID, target string) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.total > 0 && l.written >= l.total {
+		if l.suppressed == 0 {
+			gologger.Warningf("Reached the limit of %d findings, the next ones are not written\n", l.total)
+		}
+		l.suppressed++
+		return false
+	}
+
+	if l.perTemplateHost > 0 {
+		key := templateID + "\x00" + findingHost(target)
+		if l.counts[key] >= l.perTemplateHost {
+			if l.counts[key] == l.perTemplateHost {
+				gologger.Warningf("[%s] Reached the limit of %d findings on %s, the next ones are not written\n", templateID, l.perTemplateHost, findingHost(target))
+			}
+			l.counts[key]++
+			l.suppressed++
+			return false
+		}
+		l.counts[key]++
+	}
+
+	l.written++
+	return true
+}
+
+// Suppressed returns the number of findings over the limits which weren't written
+func (l *FindingLimits) Suppressed() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.suppressed
+}
@@ -0,0 +1,286 @@
+package executer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/nuclei/v2/pkg/grpc"
+	"github.com/projectdiscovery/nuclei/v2/pkg/headless"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
+	"github.com/projectdiscovery/nuclei/v2/pkg/websocket"
+)
+
+const defaultFormat = "%s"
+
+// httpMapFields is the number of fields of the http matcher map besides the headers
+const httpMapFields = 20
+
+// responseTransport returns the transport a response was received over,
+// https, http or h2c, empty if the request sending it is unknown.
+func responseTransport(resp *http.Response) string {
+	switch {
+	case resp.Request == nil || resp.Request.URL == nil:
+		return ""
+	case resp.Request.URL.Scheme == "https":
+		return "https"
+	case resp.ProtoMajor == 2:
+		return "h2c"
+	}
+
+	return "http"
+}
+
+// httpToMap Converts HTTP to Matcher Map
+func httpToMap(resp *http.Response, body, headers string, duration time.Duration, format string) (m map[string]interface{}) {
+	m = make(map[string]interface{}, len(resp.Header)+httpMapFields)
+
+	m[formatKey(format, "content_length")] = resp.ContentLength
+	m[formatKey(format, "status_code")] = resp.StatusCode
+
+	for k, v := range resp.Header {
+		k = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(k, "-", "_")))
+		if len(v) == 1 {
+			m[formatKey(format, k)] = v[0]
+		} else {
+			m[formatKey(format, k)] = strings.Join(v, " ")
+		}
+	}
+
+	m[formatKey(format, "all_headers")] = headers
+	m[formatKey(format, "cache_status")] = matchers.CacheStatus(resp.Header)
+	m[formatKey(format, "transport")] = responseTransport(resp)
+	m[formatKey(format, "body")] = body
+
+	if r, err := httputil.DumpResponse(resp, true); err == nil {
+		m[formatKey(format, "raw")] = string(r)
+	}
+
+	// Converts duration to seconds (floating point) for DSL syntax
+	m[formatKey(format, "duration")] = duration.Seconds()
+
+	certificateFields(m, resp.TLS, format)
+
+	return m
+}
+
+// formatKey formats a matcher map key, skipping the formatting for the default format
+func formatKey(format, key string) string {
+	if format == "" || format == defaultFormat {
+		return key
+	}
+
+	return fmt.Sprintf(format, key)
+}
+
+// certificateFields adds the negotiated tls parameters and the fields of
+// the server certificate of a https response to the matcher map. Plain
+// http responses have no such fields.
+func certificateFields(m map[string]interface{}, state *tls.ConnectionState, format string) {
+	if state == nil {
+		return
+	}
+
+	m[formatKey(format, "tls_version")] = tlsconfig.VersionName(state.Version)
+	m[formatKey(format, "tls_cipher")] = tls.CipherSuiteName(state.CipherSuite)
+	m[formatKey(format, "tls_alpn")] = state.NegotiatedProtocol
+	m[formatKey(format, "tls_weak_cipher")] = weakCipher(state.CipherSuite)
+
+	leaf := tlsconfig.Leaf(state)
+	if leaf == nil {
+		return
+	}
+
+	m[formatKey(format, "tls_subject")] = leaf.Subject.CommonName
+	m[formatKey(format, "tls_issuer")] = leaf.Issuer.CommonName
+	m[formatKey(format, "tls_dns_names")] = strings.Join(leaf.DNSNames, ",")
+	m[formatKey(format, "tls_not_before")] = leaf.NotBefore.Unix()
+	m[formatKey(format, "tls_not_after")] = leaf.NotAfter.Unix()
+	m[formatKey(format, "tls_expired")] = time.Now().After(leaf.NotAfter)
+	m[formatKey(format, "tls_self_signed")] = tlsconfig.SelfSigned(leaf)
+	m[formatKey(format, "tls_chain_length")] = len(state.PeerCertificates)
+	m[formatKey(format, matchers.CertificateVariable)] = tlsconfig.CertificateText(state)
+}
+
+// weakCipher returns true if a cipher suite has known security issues
+func weakCipher(id uint16) bool {
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dnsToMap Converts DNS to Matcher Map
+func dnsToMap(msg *dns.Msg, format string) (m map[string]interface{}) {
+	m = make(map[string]interface{})
+
+	if format == "" {
+		format = defaultFormat
+	}
+
+	m[fmt.Sprintf(format, "rcode")] = msg.Rcode
+
+	var qs string
+
+	for _, question := range msg.Question {
+		qs += fmt.Sprintln(question.String())
+	}
+
+	m[fmt.Sprintf(format, "question")] = qs
+
+	var exs string
+	for _, extra := range msg.Extra {
+		exs += fmt.Sprintln(extra.String())
+	}
+
+	m[fmt.Sprintf(format, "extra")] = exs
+
+	var ans string
+	for _, answer := range msg.Answer {
+		ans += fmt.Sprintln(answer.String())
+	}
+
+	m[fmt.Sprintf(format, "answer")] = ans
+
+	var nss string
+	for _, ns := range msg.Ns {
+		nss += fmt.Sprintln(ns.String())
+	}
+
+	m[fmt.Sprintf(format, "ns")] = nss
+	m[fmt.Sprintf(format, "raw")] = msg.String()
+
+	return m
+}
+
+// networkToMap Converts the data read from a network connection to Matcher Map
+func networkToMap(data, format string) (m map[string]interface{}) {
+	m = make(map[string]interface{})
+
+	m[formatKey(format, "data")] = data
+	m[formatKey(format, "raw")] = data
+
+	return m
+}
+
+// fileToMap Converts a local file to Matcher Map
+func fileToMap(path, data, format string) (m map[string]interface{}) {
+	m = make(map[string]interface{})
+
+	m[formatKey(format, "path")] = path
+	m[formatKey(format, "name")] = filepath.Base(path)
+	m[formatKey(format, "size")] = len(data)
+	m[formatKey(format, "data")] = data
+	m[formatKey(format, "raw")] = data
+
+	return m
+}
+
+// whoisToMap Converts a whois response to Matcher Map
+func whoisToMap(response, format string) (m map[string]interface{}) {
+	fields := matchers.WhoisFields(response)
+	m = make(map[string]interface{}, len(fields)+1)
+
+	for key, value := range fields {
+		m[formatKey(format, key)] = value
+	}
+	m[formatKey(format, "raw")] = response
+
+	return m
+}
+
+// sslToMap Converts a tls handshake with an address to Matcher Map. Besides
+// the fields of the https responses, the certificate is checked against the
+// system roots and the host of the address.
+func sslToMap(state *tls.ConnectionState, address, format string) (m map[string]interface{}) {
+	m = make(map[string]interface{}, httpMapFields)
+
+	host, _, _ := net.SplitHostPort(address)
+	m[formatKey(format, "host")] = host
+	m[formatKey(format, "address")] = address
+	m[formatKey(format, "raw")] = sslText(state)
+	certificateFields(m, state, format)
+
+	if leaf := tlsconfig.Leaf(state); leaf != nil {
+		intermediates := x509.NewCertPool()
+		for _, certificate := range state.PeerCertificates[1:] {
+			intermediates.AddCert(certificate)
+		}
+		_, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Intermediates: intermediates})
+		m[formatKey(format, "tls_verified")] = err == nil
+		m[formatKey(format, "tls_hostname_match")] = leaf.VerifyHostname(host) == nil
+	}
+
+	return m
+}
+
+// headlessToMap Converts a headless page to Matcher Map
+func headlessToMap(page *headless.PageData, format string) (m map[string]interface{}) {
+	m = make(map[string]interface{}, len(page.Outputs)+httpMapFields)
+
+	for name, output := range page.Outputs {
+		m[formatKey(format, name)] = output
+	}
+
+	m[formatKey(format, "url")] = page.URL
+	m[formatKey(format, "body")] = page.Body
+	m[formatKey(format, "status_code")] = page.StatusCode
+	m[formatKey(format, "console")] = strings.Join(page.Console, "\n")
+	m[formatKey(format, "network")] = strings.Join(page.Network, "\n")
+
+	return m
+}
+
+// websocketToMap Converts a websocket connection to Matcher Map
+func websocketToMap(response *websocket.Response, format string) (m map[string]interface{}) {
+	m = make(map[string]interface{}, len(response.Headers)+8)
+
+	m[formatKey(format, "status_code")] = response.StatusCode
+	for k, v := range response.Headers {
+		k = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(k, "-", "_")))
+		m[formatKey(format, k)] = strings.Join(v, " ")
+	}
+
+	m[formatKey(format, "all_headers")] = websocketHeaders(response)
+	m[formatKey(format, "body")] = websocketPart(response, matchers.BodyPart)
+	m[formatKey(format, "messages")] = len(response.Messages)
+	m[formatKey(format, "close_code")] = response.CloseCode
+	m[formatKey(format, "close_reason")] = response.CloseReason
+	m[formatKey(format, "raw")] = websocketPart(response, matchers.AllPart)
+
+	return m
+}
+
+// grpcToMap Converts a grpc call to Matcher Map
+func grpcToMap(response *grpc.Response, format string) (m map[string]interface{}) {
+	m = make(map[string]interface{}, len(response.Headers)+len(response.Trailers)+9)
+
+	for _, header := range []http.Header{response.Headers, response.Trailers} {
+		for k, v := range header {
+			k = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(k, "-", "_")))
+			m[formatKey(format, k)] = strings.Join(v, " ")
+		}
+	}
+
+	m[formatKey(format, "status_code")] = response.Status
+	m[formatKey(format, "status_name")] = grpc.StatusName(response.Status)
+	m[formatKey(format, "status_message")] = response.Message
+	m[formatKey(format, "http_status")] = response.HTTPStatus
+	m[formatKey(format, "all_headers")] = grpcHeaders(response)
+	m[formatKey(format, "body")] = grpcPart(response, matchers.BodyPart)
+	m[formatKey(format, "messages")] = len(response.Messages)
+	m[formatKey(format, "raw")] = grpcPart(response, matchers.AllPart)
+
+	return m
+}
@@ -0,0 +1,209 @@
+package executer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/internal/bufwriter"
+	"github.com/projectdiscovery/nuclei/v2/internal/tracelog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// CommonOptions contains the configuration options shared by all the
+// executers, mostly how their findings are written.
+type CommonOptions struct {
+	ColoredOutput bool
+	Debug         bool
+	JSON          bool
+	JSONRequests  bool
+	NoMeta        bool
+	// ShowMatch adds the matched snippets with MatchContext bytes around them to the findings
+	ShowMatch    bool
+	MatchContext int
+	Console      *Console
+	Scan         *Scan
+	Limits       *FindingLimits
+	Inventory    *Inventory
+	Writers      *output.Writers
+	TraceLog     tracelog.Log
+	Template     *templates.Template
+	Writer       *bufwriter.Writer
+
+	Colorizer   colorizer.NucleiColorizer
+	Decolorizer *regexp.Regexp
+}
+
+// findingWriter writes the findings of a template, it is embedded by the executers
+type findingWriter struct {
+	coloredOutput bool
+	debug         bool
+	jsonOutput    bool
+	jsonRequest   bool
+	noMeta        bool
+	traceLog      tracelog.Log
+	template      *templates.Template
+	writer        *bufwriter.Writer
+
+	colorizer   colorizer.NucleiColorizer
+	decolorizer *regexp.Regexp
+	// showMatch adds the matched snippets with matchContext bytes around them to the findings
+	showMatch    bool
+	matchContext int
+	// console prints or groups the console lines of the findings
+	console *Console
+	// scan is stamped into the json findings
+	scan *Scan
+	// limits caps the number of findings written
+	limits *FindingLimits
+	// inventory records the assets detected by the templates with product metadata
+	inventory *Inventory
+	// writers are the output writers the findings are sent to
+	writers *output.Writers
+}
+
+// newFindingWriter creates the finding writer of an executer from its options
+func newFindingWriter(options *CommonOptions) findingWriter {
+	return findingWriter{
+		coloredOutput: options.ColoredOutput,
+		debug:         options.Debug,
+		jsonOutput:    options.JSON,
+		jsonRequest:   options.JSONRequests,
+		noMeta:        options.NoMeta,
+		traceLog:      options.TraceLog,
+		template:      options.Template,
+		writer:        options.Writer,
+		colorizer:     options.Colorizer,
+		decolorizer:   options.Decolorizer,
+		showMatch:     options.ShowMatch,
+		matchContext:  options.MatchContext,
+		console:       options.Console,
+		scan:          options.Scan,
+		limits:        options.Limits,
+		inventory:     options.Inventory,
+		writers:       options.Writers,
+	}
+}
+
+// finding is a match of a request of a template on a target
+type finding struct {
+	// kind is the protocol of the request, eg. ssl
+	kind             string
+	target           string
+	matcher          *matchers.Matcher
+	extractorResults []string
+	// evidence is the request and the response of the finding, the extracted
+	// values and the snippets are added to it
+	evidence *Evidence
+	// matchers are the matchers of the request, for the snippets of the AND condition
+	matchers []*matchers.Matcher
+	// corpus returns the part of the response a matcher was evaluated on
+	corpus func(m *matchers.Matcher) string
+	// fields are the protocol specific fields of the json metadata
+	fields jsonOutput
+}
+
+// writeOutput writes a finding to the console, the output file and the output writers
+func (w *findingWriter) writeOutput(f *finding) {
+	if w.inventory.record(w.template, f.target, f.matcher, f.extractorResults, nil) {
+		return
+	}
+
+	if !w.limits.Allow(w.template.ID, f.target) {
+		return
+	}
+
+	var snippets []string
+	if w.showMatch {
+		snippets = matchSnippets(f.matcher, f.matchers, f.corpus, w.matchContext)
+	}
+
+	if w.jsonOutput || w.writers != nil {
+		output := make(jsonOutput)
+		output["matched"] = f.target
+		output["fingerprint"] = fingerprint(w.template.ID, f.target, f.matcher, f.extractorResults)
+		w.scan.stamp(output)
+
+		if !w.noMeta {
+			output["template"] = w.template.ID
+			output["type"] = f.kind
+			for k, v := range w.template.Info {
+				output[k] = v
+			}
+			if f.matcher != nil && len(f.matcher.Name) > 0 {
+				output["matcher_name"] = f.matcher.Name
+			}
+			for k, v := range f.fields {
+				output[k] = v
+			}
+		}
+
+		f.evidence.Extracted = f.extractorResults
+		f.evidence.Snippets = snippets
+		f.evidence.write(output, w.noMeta, w.jsonRequest)
+
+		writeEvent(output, w.writers, w.jsonOutput, w.writer)
+		if w.jsonOutput {
+			return
+		}
+	}
+
+	builder := &strings.Builder{}
+	colorizer := w.colorizer
+
+	if !w.noMeta {
+		builder.WriteRune('[')
+		builder.WriteString(colorizer.Colorizer.BrightGreen(w.template.ID).String())
+
+		if f.matcher != nil && len(f.matcher.Name) > 0 {
+			builder.WriteString(":")
+			builder.WriteString(colorizer.Colorizer.BrightGreen(f.matcher.Name).Bold().String())
+		}
+
+		builder.WriteString("] [")
+		builder.WriteString(colorizer.Colorizer.BrightBlue(f.kind).String())
+		builder.WriteString("] ")
+
+		if w.template.Info["severity"] != "" {
+			builder.WriteString("[")
+			builder.WriteString(colorizer.GetColorizedSeverity(w.template.Info["severity"]))
+			builder.WriteString("] ")
+		}
+	}
+	builder.WriteString(f.target)
+
+	// If any extractors, write the results
+	if len(f.extractorResults) > 0 && !w.noMeta {
+		builder.WriteString(" [")
+
+		for i, result := range f.extractorResults {
+			builder.WriteString(colorizer.Colorizer.BrightCyan(result).String())
+
+			if i != len(f.extractorResults)-1 {
+				builder.WriteRune(',')
+			}
+		}
+		builder.WriteString("]")
+	}
+	writeSnippets(builder, colorizer, snippets)
+
+	builder.WriteRune('\n')
+
+	// Write output to screen as well as any output file
+	message := builder.String()
+	w.console.Print(w.template.ID, f.target, message)
+
+	if w.writer != nil {
+		if w.coloredOutput {
+			message = w.decolorizer.ReplaceAllString(message, "")
+		}
+
+		if err := w.writer.WriteString(message); err != nil {
+			gologger.Errorf("Could not write output data: %s\n", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package executer
+
+import (
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// writeOutputFile writes file output to streams
+func (e *FileExecuter) writeOutputFile(path, data string, matcher *matchers.Matcher, extractorResults []string) {
+	e.writeOutput(&finding{
+		kind:             "file",
+		target:           path,
+		matcher:          matcher,
+		extractorResults: extractorResults,
+		evidence:         &Evidence{Request: path},
+		matchers:         e.fileRequest.Matchers,
+		corpus: func(m *matchers.Matcher) string {
+			return data
+		},
+	})
+}
@@ -0,0 +1,22 @@
+package executer
+
+import (
+	"github.com/projectdiscovery/nuclei/v2/pkg/grpc"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// writeOutputGRPC writes grpc output to streams
+func (e *GRPCExecuter) writeOutputGRPC(call *requests.GRPCCall, response *grpc.Response, matcher *matchers.Matcher, extractorResults []string) {
+	e.writeOutput(&finding{
+		kind:             "grpc",
+		target:           call.Address,
+		matcher:          matcher,
+		extractorResults: extractorResults,
+		evidence:         &Evidence{Request: call.String(), Response: grpcPart(response, matchers.AllPart)},
+		matchers:         e.grpcRequest.Matchers,
+		corpus: func(m *matchers.Matcher) string {
+			return grpcPart(response, m.GetPart())
+		},
+	})
+}
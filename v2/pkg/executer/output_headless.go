@@ -0,0 +1,31 @@
+package executer
+
+import (
+	"github.com/projectdiscovery/nuclei/v2/pkg/headless"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// writeOutputHeadless writes headless output to streams
+func (e *HeadlessExecuter) writeOutputHeadless(reqURL string, page *headless.PageData, matcher *matchers.Matcher, extractorResults []string) {
+	fields := make(jsonOutput)
+	if page.URL != reqURL {
+		fields["url"] = page.URL
+	}
+	if e.jsonRequest {
+		fields["console"] = page.Console
+		fields["network"] = page.Network
+	}
+
+	e.writeOutput(&finding{
+		kind:             "headless",
+		target:           reqURL,
+		matcher:          matcher,
+		extractorResults: extractorResults,
+		evidence:         &Evidence{Response: page.Body},
+		matchers:         e.headlessRequest.Matchers,
+		corpus: func(m *matchers.Matcher) string {
+			return headlessPart(page, m.GetPart())
+		},
+		fields: fields,
+	})
+}
@@ -3,75 +3,123 @@ package executer
 import (
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"strings"
 
-	jsoniter "github.com/json-iterator/go"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
 	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
 )
 
-// writeOutputHTTP writes http output to streams
-func (e *HTTPExecuter) writeOutputHTTP(req *requests.HTTPRequest, resp *http.Response, body string, matcher *matchers.Matcher, extractorResults []string, meta map[string]interface{}) {
+// matchedURL returns the URL of the request of a finding
+func matchedURL(req *requests.HTTPRequest) string {
 	var URL string
 	if req.RawRequest != nil {
 		URL = req.RawRequest.FullURL
 	}
 	if req.Request != nil {
 		URL = req.Request.URL.String()
+		// literal paths are kept in the opaque part of the URL
+		if req.Request.URL.Opaque != "" {
+			URL = req.Request.URL.Scheme + "://" + req.Request.URL.Host + req.Request.URL.Opaque
+		}
+	}
+
+	return URL
+}
+
+// capture takes the screenshot of the matched URL of a request if enabled,
+// it loads the page in a browser and must not run on the matcher pool.
+func (e *HTTPExecuter) capture(req *requests.HTTPRequest) string {
+	screenshot, err := e.screenshots.Capture(matchedURL(req))
+	if err != nil {
+		gologger.Warningf("[%s] %s\n", e.template.ID, err)
+	}
+
+	return screenshot
+}
+
+// writeOutputHTTP writes http output to streams
+func (e *HTTPExecuter) writeOutputHTTP(req *requests.HTTPRequest, resp *http.Response, body string, matcher *matchers.Matcher, extractorResults []string, meta map[string]interface{}, interactions []string, trace []TraceStep, screenshot string) {
+	// the findings of a replayed sequence are only counted
+	if e.replaying {
+		return
+	}
+
+	URL := matchedURL(req)
+
+	if e.inventory.record(e.template, URL, matcher, extractorResults, meta) {
+		return
+	}
+
+	if !e.limits.Allow(e.template.ID, URL) {
+		return
+	}
+
+	host := virtualHost(req)
+
+	var snippets []string
+	if e.showMatch {
+		headers, certificate := headersToString(resp.Header), tlsconfig.CertificateText(resp.TLS)
+		snippets = matchSnippets(matcher, e.bulkHTTPRequest.Matchers, func(m *matchers.Matcher) string {
+			return m.HTTPCorpus(resp, body, headers, certificate)
+		}, e.matchContext)
 	}
 
-	if e.jsonOutput {
+	if e.jsonOutput || e.writers != nil {
 		output := make(jsonOutput)
 
 		output["matched"] = URL
+		output["fingerprint"] = fingerprint(e.template.ID, URL, matcher, extractorResults)
+		e.scan.stamp(output)
+		if host != "" {
+			output["host"] = host
+		}
+		if req.Target != "" {
+			output["target"] = req.Target
+		}
 		if !e.noMeta {
 			output["template"] = e.template.ID
 			output["type"] = "http"
 			if len(meta) > 0 {
 				output["meta"] = meta
 			}
+			if block := requestBlock(e.template, e.bulkHTTPRequest); block >= 0 {
+				output["request_block"] = block
+			}
 			for k, v := range e.template.Info {
 				output[k] = v
 			}
 			if matcher != nil && len(matcher.Name) > 0 {
 				output["matcher_name"] = matcher.Name
 			}
-			if len(extractorResults) > 0 {
-				output["extracted_results"] = extractorResults
-			}
+		}
 
-			// TODO: URL should be an argument
-			if e.jsonRequest {
-				dumpedRequest, err := requests.Dump(req, URL)
-				if err != nil {
-					gologger.Warningf("could not dump request: %s\n", err)
-				} else {
-					output["request"] = string(dumpedRequest)
-				}
-
-				dumpedResponse, err := httputil.DumpResponse(resp, false)
-				if err != nil {
-					gologger.Warningf("could not dump response: %s\n", err)
-				} else {
-					output["response"] = string(dumpedResponse) + body
-				}
+		evidence := &Evidence{Screenshot: screenshot, Extracted: extractorResults, Snippets: snippets, Interactions: interactions, Trace: trace}
+		// TODO: URL should be an argument
+		if e.jsonRequest && !e.noMeta {
+			dumpedRequest, err := requests.Dump(req, URL)
+			if err != nil {
+				gologger.Warningf("could not dump request: %s\n", err)
+			} else {
+				evidence.Request = string(dumpedRequest)
 			}
-		}
 
-		data, err := jsoniter.Marshal(output)
-		if err != nil {
-			gologger.Warningf("Could not marshal json output: %s\n", err)
+			dumpedResponse, err := httputil.DumpResponse(resp, false)
+			if err != nil {
+				gologger.Warningf("could not dump response: %s\n", err)
+			} else {
+				evidence.Response = string(dumpedResponse) + body
+			}
 		}
-		gologger.Silentf("%s", string(data))
+		evidence.write(output, e.noMeta, e.jsonRequest)
 
-		if e.writer != nil {
-			if err := e.writer.Write(data); err != nil {
-				gologger.Errorf("Could not write output data: %s\n", err)
-				return
-			}
+		writeEvent(output, e.writers, e.jsonOutput, e.writer)
+		if e.jsonOutput {
+			return
 		}
-		return
 	}
 
 	builder := &strings.Builder{}
@@ -98,6 +146,22 @@ func (e *HTTPExecuter) writeOutputHTTP(req *requests.HTTPRequest, resp *http.Res
 	}
 	builder.WriteString(URL)
 
+	if host != "" {
+		builder.WriteString(" [")
+		builder.WriteString(colorizer.Colorizer.BrightYellow("host").Bold().String())
+		builder.WriteString("=")
+		builder.WriteString(colorizer.Colorizer.BrightYellow(host).String())
+		builder.WriteString("]")
+	}
+
+	if screenshot != "" {
+		builder.WriteString(" [")
+		builder.WriteString(colorizer.Colorizer.BrightYellow("screenshot").Bold().String())
+		builder.WriteString("=")
+		builder.WriteString(colorizer.Colorizer.BrightYellow(screenshot).String())
+		builder.WriteString("]")
+	}
+
 	// If any extractors, write the results
 	if len(extractorResults) > 0 && !e.noMeta {
 		builder.WriteString(" [")
@@ -119,19 +183,21 @@ func (e *HTTPExecuter) writeOutputHTTP(req *requests.HTTPRequest, resp *http.Res
 
 		var metas []string
 
-		for name, value := range req.Meta {
-			metas = append(metas, colorizer.Colorizer.BrightYellow(name).Bold().String()+"="+colorizer.Colorizer.BrightYellow(value.(string)).String())
+		for _, name := range sortedMetaKeys(req.Meta) {
+			metas = append(metas, colorizer.Colorizer.BrightYellow(name).Bold().String()+"="+colorizer.Colorizer.BrightYellow(req.Meta[name].(string)).String())
 		}
 
 		builder.WriteString(strings.Join(metas, ","))
 		builder.WriteString("]")
 	}
 
+	writeSnippets(builder, colorizer, snippets)
+
 	builder.WriteRune('\n')
 
 	// Write output to screen as well as any output file
 	message := builder.String()
-	gologger.Silentf("%s", message)
+	e.console.Print(e.template.ID, URL, message)
 
 	if e.writer != nil {
 		if e.coloredOutput {
@@ -144,3 +210,33 @@ func (e *HTTPExecuter) writeOutputHTTP(req *requests.HTTPRequest, resp *http.Res
 		}
 	}
 }
+
+// requestBlock returns the position of a request block in the http requests
+// of a template, or -1 if it is not one of them
+func requestBlock(template *templates.Template, request *requests.BulkHTTPRequest) int {
+	for i, block := range template.BulkRequestsHTTP {
+		if block == request {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// virtualHost returns the Host header of the request if it differs from the URL host
+func virtualHost(req *requests.HTTPRequest) string {
+	if req.RawRequest != nil {
+		host := req.RawRequest.Headers["Host"]
+		if parsed, err := url.Parse(req.RawRequest.FullURL); err == nil && host != parsed.Host {
+			return host
+		}
+
+		return ""
+	}
+
+	if req.Request != nil && req.Request.Host != "" && req.Request.Host != req.Request.URL.Host {
+		return req.Request.Host
+	}
+
+	return ""
+}
@@ -0,0 +1,21 @@
+package executer
+
+import (
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// writeOutputNetwork writes network output to streams
+func (e *NetworkExecuter) writeOutputNetwork(address *requests.NetworkAddress, response string, matcher *matchers.Matcher, extractorResults []string) {
+	e.writeOutput(&finding{
+		kind:             "network",
+		target:           address.Address,
+		matcher:          matcher,
+		extractorResults: extractorResults,
+		evidence:         &Evidence{Request: address.String(), Response: response},
+		matchers:         e.networkRequest.Matchers,
+		corpus: func(m *matchers.Matcher) string {
+			return response
+		},
+	})
+}
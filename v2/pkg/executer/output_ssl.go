@@ -0,0 +1,24 @@
+package executer
+
+import (
+	"crypto/tls"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// writeOutputSSL writes ssl output to streams
+func (e *SSLExecuter) writeOutputSSL(target string, state *tls.ConnectionState, matcher *matchers.Matcher, extractorResults []string) {
+	response := sslText(state)
+
+	e.writeOutput(&finding{
+		kind:             "ssl",
+		target:           target,
+		matcher:          matcher,
+		extractorResults: extractorResults,
+		evidence:         &Evidence{Response: response},
+		matchers:         e.sslRequest.Matchers,
+		corpus: func(m *matchers.Matcher) string {
+			return response
+		},
+	})
+}
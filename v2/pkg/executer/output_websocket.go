@@ -0,0 +1,22 @@
+package executer
+
+import (
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/websocket"
+)
+
+// writeOutputWebsocket writes websocket output to streams
+func (e *WebsocketExecuter) writeOutputWebsocket(connection *requests.WebsocketConnection, response *websocket.Response, matcher *matchers.Matcher, extractorResults []string) {
+	e.writeOutput(&finding{
+		kind:             "websocket",
+		target:           connection.URL,
+		matcher:          matcher,
+		extractorResults: extractorResults,
+		evidence:         &Evidence{Request: connection.String(), Response: websocketPart(response, matchers.AllPart)},
+		matchers:         e.websocketRequest.Matchers,
+		corpus: func(m *matchers.Matcher) string {
+			return websocketPart(response, m.GetPart())
+		},
+	})
+}
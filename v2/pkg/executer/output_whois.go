@@ -0,0 +1,20 @@
+package executer
+
+import (
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// writeOutputWhois writes whois output to streams
+func (e *WhoisExecuter) writeOutputWhois(target, response string, matcher *matchers.Matcher, extractorResults []string) {
+	e.writeOutput(&finding{
+		kind:             "whois",
+		target:           target,
+		matcher:          matcher,
+		extractorResults: extractorResults,
+		evidence:         &Evidence{Request: target, Response: response},
+		matchers:         e.whoisRequest.Matchers,
+		corpus: func(m *matchers.Matcher) string {
+			return response
+		},
+	})
+}
@@ -0,0 +1,112 @@
+package executer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/grpc"
+	"github.com/projectdiscovery/nuclei/v2/pkg/headless"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
+	"github.com/projectdiscovery/nuclei/v2/pkg/websocket"
+)
+
+// sslText returns the negotiated tls version and cipher suite of a handshake
+// followed by its certificate chain as text, for the words and regex matchers
+// of the ssl requests.
+func sslText(state *tls.ConnectionState) string {
+	return "version: " + tlsconfig.VersionName(state.Version) + "\ncipher: " + tls.CipherSuiteName(state.CipherSuite) + "\n" + tlsconfig.CertificateText(state)
+}
+
+// headlessPart returns the part of a headless page to match, the page
+// having no headers the header part is its network requests.
+func headlessPart(page *headless.PageData, part matchers.Part) string {
+	switch part {
+	case matchers.ConsolePart:
+		return strings.Join(page.Console, "\n")
+	case matchers.HeaderPart, matchers.NetworkPart:
+		return strings.Join(page.Network, "\n")
+	case matchers.AllPart:
+		return page.Body + "\n" + strings.Join(page.Console, "\n") + "\n" + strings.Join(page.Network, "\n")
+	}
+
+	return page.Body
+}
+
+// websocketHeaders returns the headers of the handshake of a websocket connection
+func websocketHeaders(response *websocket.Response) string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "HTTP/1.1 %d %s\r\n", response.StatusCode, http.StatusText(response.StatusCode))
+	//nolint:errcheck // writing to a strings.Builder never fails
+	response.Headers.Write(builder)
+
+	return builder.String()
+}
+
+// websocketPart returns the part of a websocket connection to match, the
+// body being the messages received, or the body of a refused handshake.
+func websocketPart(response *websocket.Response, part matchers.Part) string {
+	body := strings.Join(response.Messages, "\n")
+	if response.Body != "" {
+		body = response.Body
+	}
+
+	switch part {
+	case matchers.HeaderPart:
+		return websocketHeaders(response)
+	case matchers.AllPart:
+		return websocketHeaders(response) + "\r\n" + body
+	}
+
+	return body
+}
+
+// grpcHeaders returns the headers and the trailers of a grpc call
+func grpcHeaders(response *grpc.Response) string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "HTTP/2.0 %d %s\r\n", response.HTTPStatus, http.StatusText(response.HTTPStatus))
+	//nolint:errcheck // writing to a strings.Builder never fails
+	response.Headers.Write(builder)
+	//nolint:errcheck // writing to a strings.Builder never fails
+	response.Trailers.Write(builder)
+
+	return builder.String()
+}
+
+// grpcMetadata returns the headers and the trailers of a grpc call as a
+// single header, for the kval extractors
+func grpcMetadata(response *grpc.Response) http.Header {
+	header := make(http.Header, len(response.Headers)+len(response.Trailers))
+	for _, source := range []http.Header{response.Headers, response.Trailers} {
+		for name, values := range source {
+			header[name] = append(header[name], values...)
+		}
+	}
+
+	return header
+}
+
+// grpcPart returns the part of a grpc call to match, the body being the
+// serialized messages of the response, or the body of a response which
+// isn't grpc.
+func grpcPart(response *grpc.Response, part matchers.Part) string {
+	body := response.Body
+	if len(response.Messages) > 0 {
+		builder := &strings.Builder{}
+		for _, message := range response.Messages {
+			builder.Write(message)
+		}
+		body = builder.String()
+	}
+
+	switch part {
+	case matchers.HeaderPart:
+		return grpcHeaders(response)
+	case matchers.AllPart:
+		return grpcHeaders(response) + "\r\n" + body
+	}
+
+	return body
+}
@@ -0,0 +1,215 @@
+package executer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// maxProxyFailures is the number of consecutive failures after which a proxy is removed
+const maxProxyFailures = 5
+
+// errNoProxies is returned when all the proxies of the list were removed
+var errNoProxies = errors.New("no working proxy left in the proxy list")
+
+// proxyContextKey stores the proxy chosen for a request in its context
+type proxyContextKey struct{}
+
+// rotatedProxy is a proxy of the list with its consecutive failures
+type rotatedProxy struct {
+	url      *url.URL
+	failures int
+}
+
+// ProxyRotator spreads the requests over a list of proxies, either one
+// after the other or sticking to the same proxy for a host, and removes
+// the proxies failing repeatedly.
+type ProxyRotator struct {
+	mutex   sync.Mutex
+	perHost bool
+	proxies []*rotatedProxy
+	next    int
+	hosts   map[string]*rotatedProxy
+}
+
+// ReadProxyList reads the proxies of a file, one per line. Proxies without
+// a scheme are http proxies, lines starting with # are ignored.
+func ReadProxyList(file string) ([]*url.URL, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var proxies []*url.URL
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.Contains(line, "://") {
+			line = "http://" + line
+		}
+
+		proxy, err := url.Parse(line)
+		if err != nil || proxy.Host == "" {
+			return nil, errors.New("invalid proxy " + line)
+		}
+		proxies = append(proxies, proxy)
+	}
+
+	return proxies, scanner.Err()
+}
+
+// overrideProxy returns the options with the proxy of a template or a
+// workflow in place of the proxy urls and the proxy list of the runner.
+func overrideProxy(options *HTTPOptions) *HTTPOptions {
+	if options.Proxy == "" {
+		return options
+	}
+
+	overridden := *options
+	overridden.ProxyURL, overridden.ProxySocksURL, overridden.Proxies = "", "", nil
+	if strings.HasPrefix(strings.ToLower(options.Proxy), "socks5://") {
+		overridden.ProxySocksURL = options.Proxy
+	} else {
+		overridden.ProxyURL = options.Proxy
+	}
+
+	return &overridden
+}
+
+// NewProxyRotator creates a rotator over proxies, per request or per host
+func NewProxyRotator(proxies []*url.URL, perHost bool) *ProxyRotator {
+	rotator := &ProxyRotator{perHost: perHost, hosts: make(map[string]*rotatedProxy)}
+	for _, proxy := range proxies {
+		rotator.proxies = append(rotator.proxies, &rotatedProxy{url: proxy})
+	}
+
+	return rotator
+}
+
+// Check removes the proxies which don't accept connections within timeout
+// and returns the number of proxies left.
+func (p *ProxyRotator) Check(timeout time.Duration) int {
+	var wg sync.WaitGroup
+	alive := make([]bool, len(p.proxies))
+
+	for i, proxy := range p.proxies {
+		wg.Add(1)
+		go func(i int, proxy *rotatedProxy) {
+			defer wg.Done()
+
+			conn, err := net.DialTimeout("tcp", proxy.url.Host, timeout)
+			if err != nil {
+				gologger.Warningf("Removing unreachable proxy %s: %s\n", proxy.url.Host, err)
+				return
+			}
+			conn.Close()
+			alive[i] = true
+		}(i, proxy)
+	}
+	wg.Wait()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var checked []*rotatedProxy
+	for i, proxy := range p.proxies {
+		if alive[i] {
+			checked = append(checked, proxy)
+		}
+	}
+	p.proxies = checked
+
+	return len(p.proxies)
+}
+
+// pick returns the proxy of the next request to a host
+func (p *ProxyRotator) pick(host string) *rotatedProxy {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.proxies) == 0 {
+		return nil
+	}
+	if p.perHost {
+		if proxy, ok := p.hosts[host]; ok && proxy.failures < maxProxyFailures {
+			return proxy
+		}
+	}
+
+	proxy := p.proxies[p.next%len(p.proxies)]
+	p.next++
+	if p.perHost {
+		p.hosts[host] = proxy
+	}
+
+	return proxy
+}
+
+// report records the result of a request sent through a proxy, removing it
+// from the list after too many consecutive failures.
+func (p *ProxyRotator) report(proxy *rotatedProxy, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err == nil {
+		proxy.failures = 0
+		return
+	}
+
+	proxy.failures++
+	if proxy.failures != maxProxyFailures {
+		return
+	}
+
+	for i, candidate := range p.proxies {
+		if candidate == proxy {
+			p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
+			gologger.Warningf("Removing proxy %s after %d consecutive failures (%d left)\n", proxy.url.Host, maxProxyFailures, len(p.proxies))
+			break
+		}
+	}
+}
+
+// wrap returns a round tripper sending the requests of a transport through the rotated proxies
+func (p *ProxyRotator) wrap(transport *http.Transport) http.RoundTripper {
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if proxy, ok := req.Context().Value(proxyContextKey{}).(*rotatedProxy); ok {
+			return proxy.url, nil
+		}
+		return nil, errNoProxies
+	}
+
+	return &rotatingTransport{rotator: p, transport: transport}
+}
+
+// rotatingTransport picks the proxy of each request before sending it
+type rotatingTransport struct {
+	rotator   *ProxyRotator
+	transport *http.Transport
+}
+
+// RoundTrip sends a request through the next proxy of the rotator
+func (t *rotatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxy := t.rotator.pick(req.URL.Host)
+	if proxy == nil {
+		return nil, errNoProxies
+	}
+
+	resp, err := t.transport.RoundTrip(req.WithContext(context.WithValue(req.Context(), proxyContextKey{}, proxy)))
+	t.rotator.report(proxy, err)
+
+	return resp, err
+}
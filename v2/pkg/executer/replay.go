@@ -0,0 +1,177 @@
+package executer
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// ReplayHTTP re-sends a request dumped in the json output of a finding to the
+// matched URL and reports whether the matchers of the request still match.
+func ReplayHTTP(options *HTTPOptions, dumpedRequest, matchedURL string) (bool, error) {
+	target, err := url.Parse(matchedURL)
+	if err != nil {
+		return false, errors.Wrap(err, "could not parse matched url")
+	}
+
+	req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(dumpedRequest)))
+	if err != nil {
+		return false, errors.Wrap(err, "could not parse stored request")
+	}
+
+	requestURI, err := url.ParseRequestURI(req.RequestURI)
+	if err != nil {
+		return false, errors.Wrap(err, "could not parse stored request uri")
+	}
+
+	req.URL = target.ResolveReference(requestURI)
+	req.RequestURI = ""
+
+	options = overrideProxy(options)
+
+	var proxyURL *url.URL
+	if options.ProxyURL != "" {
+		if proxyURL, err = url.Parse(options.ProxyURL); err != nil {
+			return false, err
+		}
+	}
+
+	client, err := makeHTTPClient(proxyURL, options)
+	if err != nil {
+		return false, err
+	}
+
+	request, err := retryablehttp.FromRequest(req)
+	if err != nil {
+		return false, err
+	}
+
+	timeStart := time.Now()
+
+	resp, err := client.Do(request)
+	if err != nil {
+		return false, errors.Wrap(err, "could not send request")
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return false, errors.Wrap(err, "could not read http body")
+	}
+
+	duration := time.Since(timeStart)
+
+	rawBody := unsafeToString(data)
+	body := unsafeToString(requests.DecodeBody(resp, data))
+	headers := headersToString(resp.Header)
+
+	return responseMatches(options.BulkHTTPRequest, resp, body, rawBody, headers, duration), nil
+}
+
+// ChainedBlock returns true if the finding of a request block of a template
+// depends on other requests, so that the stored request alone can't replay
+// it: its matchers read the responses of the previous requests, its requests
+// use the values extracted by the previous ones or the cookies they got.
+func ChainedBlock(template *templates.Template, block int) bool {
+	request := template.BulkRequestsHTTP[block]
+	for _, matcher := range request.Matchers {
+		if matcher.NeedsHistory() {
+			return true
+		}
+	}
+	if request.CSRF || (request.GetRequestCount() > 1 && len(request.Extractors) > 0) {
+		return true
+	}
+	if block == 0 {
+		return false
+	}
+	if template.CookieReuse {
+		return true
+	}
+	for _, previous := range template.BulkRequestsHTTP[:block] {
+		if len(previous.Extractors) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReplayHTTPChain sends the requests of the blocks of a template up to the
+// given one to a target like a scan, sharing the extracted values and the
+// cookies, and reports whether the given block matched again. The findings
+// of the sequence are not written.
+func ReplayHTTPChain(options *HTTPOptions, block int, target string) (bool, error) {
+	blockOptions := *options
+	if blockOptions.Values == nil {
+		blockOptions.Values = NewSharedValues()
+	}
+
+	for i, request := range options.Template.BulkRequestsHTTP[:block+1] {
+		blockOptions.BulkHTTPRequest = request
+
+		executer, err := NewHTTPExecuter(&blockOptions)
+		if err != nil {
+			return false, err
+		}
+		executer.replaying = true
+
+		// the generator of the target is left by a previous replay of the block
+		request.RemoveGenerator(target)
+		result := executer.ExecuteHTTP(&progress.NoOpProgress{}, target)
+		request.RemoveGenerator(target)
+
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if i == block {
+			return result.GotResults, nil
+		}
+	}
+
+	return false, nil
+}
+
+// responseMatches evaluates the matchers of a request on a response honoring
+// the matchers condition. Requests without matchers match if any extractor
+// returns a value.
+func responseMatches(request *requests.BulkHTTPRequest, resp *http.Response, body, rawBody, headers string, duration time.Duration) bool {
+	fields := httpToMap(resp, body, headers, duration, "")
+
+	if len(request.Matchers) == 0 {
+		for _, extractor := range request.Extractors {
+			if len(extractor.Extract(resp, body, headers, fields)) > 0 {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	data := map[string]interface{}{matchers.RawBodyVariable: rawBody}
+	condition := request.GetMatchersCondition()
+
+	for _, matcher := range request.Matchers {
+		matched := matcher.Match(resp, body, headers, fields, data)
+		if matched && condition == matchers.ORCondition {
+			return true
+		}
+
+		if !matched && condition == matchers.ANDCondition {
+			return false
+		}
+	}
+
+	return condition == matchers.ANDCondition
+}
@@ -0,0 +1,59 @@
+package executer
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// Scan identifies the scan a finding belongs to, so that the findings
+// of several runs can be told apart and correlated.
+type Scan struct {
+	ID               string
+	Start            time.Time
+	EngineVersion    string
+	TemplatesVersion string
+}
+
+// NewScan creates the metadata of a scan starting now with a random ID
+func NewScan(engineVersion, templatesVersion string) (*Scan, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	// uuid version 4, variant 10
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	return &Scan{
+		ID:               fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Start:            time.Now(),
+		EngineVersion:    engineVersion,
+		TemplatesVersion: templatesVersion,
+	}, nil
+}
+
+// deterministicScanID is the ID of the scans run in deterministic mode
+const deterministicScanID = "00000000-0000-0000-0000-000000000000"
+
+// NewDeterministicScan creates the metadata of a scan with a fixed ID and
+// no start time, so that the findings of deterministic runs are identical.
+func NewDeterministicScan(engineVersion, templatesVersion string) *Scan {
+	return &Scan{ID: deterministicScanID, EngineVersion: engineVersion, TemplatesVersion: templatesVersion}
+}
+
+// stamp adds the scan metadata to a json finding, the start time if known
+func (s *Scan) stamp(output jsonOutput) {
+	if s == nil {
+		return
+	}
+
+	output["scan_id"] = s.ID
+	if !s.Start.IsZero() {
+		output["scan_start"] = s.Start.UTC().Format(time.RFC3339)
+	}
+	output["engine_version"] = s.EngineVersion
+	if s.TemplatesVersion != "" {
+		output["templates_version"] = s.TemplatesVersion
+	}
+}
@@ -0,0 +1,23 @@
+package executer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministicScanStamp(t *testing.T) {
+	first, second := make(jsonOutput), make(jsonOutput)
+	NewDeterministicScan("2.2.0", "7.3.0").stamp(first)
+	NewDeterministicScan("2.2.0", "7.3.0").stamp(second)
+
+	require.Equal(t, first, second, "the deterministic scans stamped different metadata")
+	require.Equal(t, deterministicScanID, first["scan_id"], "unexpected deterministic scan id")
+	require.NotContains(t, first, "scan_start", "the deterministic scan stamped its start time")
+
+	scan, err := NewScan("2.2.0", "7.3.0")
+	require.Nil(t, err, "could not create scan")
+	random := make(jsonOutput)
+	scan.stamp(random)
+	require.Contains(t, random, "scan_start", "the scan did not stamp its start time")
+}
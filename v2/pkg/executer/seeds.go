@@ -0,0 +1,34 @@
+package executer
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	// seedPathVariable iterates over the robots.txt and sitemap.xml paths of the host
+	seedPathVariable = "SeedPath"
+	// seedURLVariable is the absolute URL of the current seed path
+	seedURLVariable = "SeedURL"
+)
+
+// usesSeedPaths returns true if a template path or raw request uses the seed variables
+func usesSeedPaths(data string) bool {
+	for _, variable := range []string{seedPathVariable, seedURLVariable} {
+		if strings.Contains(data, "{{"+variable+"}}") || strings.Contains(data, "§"+variable+"§") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// seedURL returns the URL of a seed path on the host of the target
+func seedURL(reqURL, seedPath string) string {
+	parsed, err := url.Parse(reqURL)
+	if err != nil {
+		return seedPath
+	}
+
+	return parsed.Scheme + "://" + parsed.Host + seedPath
+}
@@ -0,0 +1,36 @@
+package executer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// matchSnippets returns the matched parts of a response with context bytes
+// around them. Findings written for the AND condition have no single
+// matcher so the snippets of all the matchers of the request are returned.
+func matchSnippets(matcher *matchers.Matcher, all []*matchers.Matcher, corpus func(m *matchers.Matcher) string, context int) []string {
+	if matcher != nil {
+		all = []*matchers.Matcher{matcher}
+	}
+
+	var snippets []string
+	for _, m := range all {
+		snippets = append(snippets, m.Snippets(corpus(m), context)...)
+	}
+
+	return snippets
+}
+
+// writeSnippets appends the quoted snippets of a finding to its console line
+func writeSnippets(builder *strings.Builder, colorizer colorizer.NucleiColorizer, snippets []string) {
+	for _, snippet := range snippets {
+		builder.WriteString(" [")
+		builder.WriteString(colorizer.Colorizer.BrightMagenta("match").Bold().String())
+		builder.WriteString("=")
+		builder.WriteString(colorizer.Colorizer.BrightMagenta(strconv.Quote(snippet)).String())
+		builder.WriteString("]")
+	}
+}
@@ -0,0 +1,173 @@
+package executer
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/globalratelimiter"
+	"github.com/projectdiscovery/retryablehttp-go"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// minBackoff is the first delay applied to a host throttling the requests
+	minBackoff = 500 * time.Millisecond
+	// maxBackoff is the longest delay applied before each request to a host
+	maxBackoff = 30 * time.Second
+)
+
+// Throttle limits the http requests of all the templates with a global rate
+// and a number of concurrent requests per host. In adaptive mode the requests
+// to a host are delayed more each time it answers with 429 or 503 or the
+// connection fails, and less again once it answers normally.
+type Throttle struct {
+	global          ratelimit.Limiter
+	hostConcurrency int
+	adaptive        bool
+
+	mutex sync.Mutex
+	hosts map[string]*hostThrottle
+}
+
+// hostThrottle is the state of the requests to a host
+type hostThrottle struct {
+	slots   chan struct{}
+	backoff time.Duration
+}
+
+// NewThrottle creates a throttle with a global rate in requests per second and
+// a number of concurrent requests per host, zero being unlimited. It returns
+// nil if nothing is limited.
+func NewThrottle(globalRate, hostConcurrency int, adaptive bool) *Throttle {
+	if globalRate <= 0 && hostConcurrency <= 0 && !adaptive {
+		return nil
+	}
+
+	throttle := &Throttle{hostConcurrency: hostConcurrency, adaptive: adaptive, hosts: make(map[string]*hostThrottle)}
+	if globalRate > 0 {
+		throttle.global = ratelimit.New(globalRate)
+	}
+
+	return throttle
+}
+
+// host returns the state of the requests to a host
+func (t *Throttle) host(host string) *hostThrottle {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	state, ok := t.hosts[host]
+	if !ok {
+		state = &hostThrottle{}
+		if t.hostConcurrency > 0 {
+			state.slots = make(chan struct{}, t.hostConcurrency)
+		}
+		t.hosts[host] = state
+	}
+
+	return state
+}
+
+// acquire waits until a request can be sent to the host of a target and
+// returns the function releasing its slot once the response is read, which
+// can be called more than once.
+func (t *Throttle) acquire(target string) (release func()) {
+	if t == nil {
+		return func() {}
+	}
+
+	state := t.host(findingHost(target))
+	if state.slots != nil {
+		state.slots <- struct{}{}
+	}
+
+	t.mutex.Lock()
+	backoff := state.backoff
+	t.mutex.Unlock()
+	if backoff > 0 {
+		time.Sleep(backoff)
+	}
+
+	if t.global != nil {
+		t.global.Take()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if state.slots != nil {
+				<-state.slots
+			}
+		})
+	}
+}
+
+// report adapts the delay of the requests to the host of a target to the
+// outcome of a request, resp being nil if it failed.
+func (t *Throttle) report(target string, resp *http.Response) {
+	if t == nil || !t.adaptive {
+		return
+	}
+
+	host := findingHost(target)
+	state := t.host(host)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if resp != nil && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		state.backoff /= 2
+		if state.backoff < minBackoff {
+			state.backoff = 0
+		}
+		return
+	}
+
+	backoff := state.backoff * 2
+	if backoff < minBackoff {
+		backoff = minBackoff
+	}
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && time.Duration(seconds)*time.Second > backoff {
+			backoff = time.Duration(seconds) * time.Second
+		}
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	if backoff != state.backoff {
+		gologger.Verbosef("Delaying the requests to %s by %s\n", "throttle", host, backoff)
+	}
+	state.backoff = backoff
+}
+
+// send sends a request made apart from the ones of the template, eg. a bypass
+// mutation, throttled like them, and returns the response with its body read.
+func (e *HTTPExecuter) send(reqURL string, request *retryablehttp.Request) (*http.Response, []byte, time.Duration, error) {
+	globalratelimiter.Take(reqURL)
+	e.pace()
+	release := e.throttle.acquire(reqURL)
+	defer release()
+
+	start := time.Now()
+	resp, err := e.httpClient.Do(request)
+	e.traceLog.Request(e.template.ID, reqURL, "http", err)
+	if err != nil {
+		e.throttle.report(reqURL, nil)
+		return nil, nil, 0, err
+	}
+	duration := time.Since(start)
+	e.throttle.report(reqURL, resp)
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return resp, data, duration, nil
+}
@@ -0,0 +1,81 @@
+package executer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// TraceStep is a request sent while executing a template, with the variables
+// it was built with
+type TraceStep struct {
+	Template   string                 `json:"template"`
+	Request    string                 `json:"request"`
+	StatusCode int                    `json:"status_code,omitempty"`
+	Duration   float64                `json:"duration"`
+	Payloads   map[string]interface{} `json:"payloads,omitempty"`
+	Variables  map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Trace records in order the requests sent to a target by the sequential
+// requests of a template, or by the templates of a workflow, so the findings
+// can show the chain leading to them. A nil trace records nothing.
+type Trace struct {
+	mutex sync.Mutex
+	steps []TraceStep
+}
+
+// NewTrace creates a new empty trace
+func NewTrace() *Trace {
+	return &Trace{}
+}
+
+// add records a request of a template
+func (t *Trace) add(templateID string, request *requests.HTTPRequest, statusCode int, duration time.Duration, dynamicvalues map[string]interface{}) {
+	if t == nil {
+		return
+	}
+
+	step := TraceStep{
+		Template:   templateID,
+		Request:    traceRequestLine(request),
+		StatusCode: statusCode,
+		Duration:   duration.Seconds(),
+		Payloads:   request.Meta,
+	}
+	if len(dynamicvalues) > 0 {
+		step.Variables = make(map[string]interface{}, len(dynamicvalues))
+		for k, v := range dynamicvalues {
+			step.Variables[k] = v
+		}
+	}
+
+	t.mutex.Lock()
+	t.steps = append(t.steps, step)
+	t.mutex.Unlock()
+}
+
+// Steps returns a copy of the steps recorded so far
+func (t *Trace) Steps() []TraceStep {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return append([]TraceStep(nil), t.steps...)
+}
+
+// traceRequestLine returns the method and the URL of a request
+func traceRequestLine(request *requests.HTTPRequest) string {
+	if request.RawRequest != nil {
+		return request.RawRequest.Method + " " + request.RawRequest.FullURL
+	}
+	if request.Request != nil {
+		return request.Request.Method + " " + request.Request.URL.String()
+	}
+
+	return ""
+}
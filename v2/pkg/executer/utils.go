@@ -3,12 +3,43 @@ package executer
 import (
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"unsafe"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/internal/bufwriter"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 )
 
 type jsonOutput map[string]interface{}
 
+// jsonMarshaler marshals the json output with sorted keys for stable output
+var jsonMarshaler = jsoniter.Config{EscapeHTML: true, SortMapKeys: true}.Froze()
+
+// sortedResults returns the extracted results in a stable order
+func sortedResults(results map[string]struct{}) []string {
+	sorted := make([]string, 0, len(results))
+	for result := range results {
+		sorted = append(sorted, result)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// sortedMetaKeys returns the keys of the request meta in a stable order
+func sortedMetaKeys(meta map[string]interface{}) []string {
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 // unsafeToString converts byte slice to string with zero allocations
 func unsafeToString(bs []byte) string {
 	return *(*string)(unsafe.Pointer(&bs))
@@ -16,7 +47,15 @@ func unsafeToString(bs []byte) string {
 
 // headersToString converts http headers to string
 func headersToString(headers http.Header) string {
+	size := 0
+	for header, values := range headers {
+		for _, value := range values {
+			size += len(header) + len(value) + len(": \n")
+		}
+	}
+
 	builder := &strings.Builder{}
+	builder.Grow(size)
 
 	for header, values := range headers {
 		builder.WriteString(header)
@@ -57,3 +96,26 @@ func extractDomain(theURL string) string {
 	}
 	return u.Hostname()
 }
+
+// writeEvent sends a finding to the output writers and, for the json output,
+// prints it and writes it to the output file.
+func writeEvent(event jsonOutput, writers *output.Writers, json bool, writer *bufwriter.Writer) {
+	if err := writers.Write(output.Event(event)); err != nil {
+		gologger.Errorf("Could not write output data: %s\n", err)
+	}
+	if !json {
+		return
+	}
+
+	data, err := jsonMarshaler.Marshal(event)
+	if err != nil {
+		gologger.Warningf("Could not marshal json output: %s\n", err)
+	}
+	gologger.Silentf("%s", string(data))
+
+	if writer != nil {
+		if err := writer.Write(data); err != nil {
+			gologger.Errorf("Could not write output data: %s\n", err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package executer
+
+import (
+	"sync"
+)
+
+// runVariables are set for each run of a request, they aren't passed on
+// to the next requests of a template.
+var runVariables = map[string]struct{}{
+	cacheBusterVariable:   {},
+	interactshURLVariable: {},
+	seedPathVariable:      {},
+	seedURLVariable:       {},
+}
+
+// SharedValues holds the values extracted by the http requests of a template
+// on each target, for the next requests of the template on the same target,
+// eg. a csrf token extracted from a login form before posting it.
+type SharedValues struct {
+	sync.RWMutex
+	values map[string]map[string]interface{}
+}
+
+// NewSharedValues creates an empty store of extracted values
+func NewSharedValues() *SharedValues {
+	return &SharedValues{values: make(map[string]map[string]interface{})}
+}
+
+// Get returns a copy of the values extracted on a target
+func (s *SharedValues) Get(target string) map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	values := make(map[string]interface{}, len(s.values[target]))
+	for name, value := range s.values[target] {
+		values[name] = value
+	}
+
+	return values
+}
+
+// Set stores the values of a run of a request on a target, replacing the
+// ones with the same names extracted by the previous requests.
+func (s *SharedValues) Set(target string, values map[string]interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	stored, ok := s.values[target]
+	if !ok {
+		stored = make(map[string]interface{}, len(values))
+		s.values[target] = stored
+	}
+	for name, value := range values {
+		if _, ok := runVariables[name]; !ok {
+			stored[name] = value
+		}
+	}
+}
@@ -2,7 +2,10 @@ package extractors
 
 import (
 	"fmt"
-	"regexp"
+
+	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/saferegex"
 )
 
 // CompileExtractors performs the initial setup operation on a extractor
@@ -16,12 +19,27 @@ func (e *Extractor) CompileExtractors() error {
 
 	// Compile the regexes
 	for _, regex := range e.Regex {
-		compiled, err := regexp.Compile(regex)
+		compiled, err := saferegex.Compile(regex)
 		if err != nil {
-			return fmt.Errorf("could not compile regex: %s", regex)
+			return err
 		}
 
 		e.regexCompiled = append(e.regexCompiled, compiled)
+		for _, name := range compiled.SubexpNames() {
+			if name != "" {
+				e.namedGroups = true
+			}
+		}
+	}
+
+	// Compile the dsl expressions
+	for _, dsl := range e.DSL {
+		compiled, err := govaluate.NewEvaluableExpressionWithFunctions(dsl, generators.HelperFunctions())
+		if err != nil {
+			return fmt.Errorf("could not compile dsl: %s", dsl)
+		}
+
+		e.dslCompiled = append(e.dslCompiled, compiled)
 	}
 
 	// Setup the part of the request to match, if any.
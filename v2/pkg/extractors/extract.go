@@ -1,19 +1,26 @@
 package extractors
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/miekg/dns"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/saferegex"
 )
 
-// Extract extracts response from the parts of request using a regex
-func (e *Extractor) Extract(resp *http.Response, body, headers string) map[string]struct{} {
+// Extract extracts response from the parts of request using a regex. The
+// fields are the map of the response built by the executer, for the
+// extractors needing them.
+func (e *Extractor) Extract(resp *http.Response, body, headers string, fields map[string]interface{}) map[string]struct{} {
 	switch e.extractorType {
 	case RegexExtractor:
 		if e.part == BodyPart {
 			return e.extractRegex(body)
 		} else if e.part == HeaderPart {
 			return e.extractRegex(headers)
+		} else if e.part == CertificatePart {
+			return e.extractRegex(certificateText(fields))
 		} else {
 			matches := e.extractRegex(headers)
 			if len(matches) > 0 {
@@ -33,6 +40,8 @@ func (e *Extractor) Extract(resp *http.Response, body, headers string) map[strin
 		}
 
 		return e.extractCookieKVal(resp)
+	case DSLExtractor:
+		return e.extractDSL(fields)
 	}
 
 	return nil
@@ -40,23 +49,165 @@ func (e *Extractor) Extract(resp *http.Response, body, headers string) map[strin
 
 // ExtractDNS extracts response from dns message using a regex
 // nolint:interfacer // dns.Msg is out of current scope
-func (e *Extractor) ExtractDNS(msg *dns.Msg) map[string]struct{} {
+func (e *Extractor) ExtractDNS(msg *dns.Msg, fields map[string]interface{}) map[string]struct{} {
 	switch e.extractorType {
 	case RegexExtractor:
 		return e.extractRegex(msg.String())
 	case KValExtractor:
+	case DSLExtractor:
+		return e.extractDSL(fields)
 	}
 
 	return nil
 }
 
+// ExtractNetwork extracts text from the data read from a network connection using a regex
+func (e *Extractor) ExtractNetwork(data string, fields map[string]interface{}) map[string]struct{} {
+	if e.extractorType == DSLExtractor {
+		return e.extractDSL(fields)
+	}
+	if e.extractorType != RegexExtractor {
+		return nil
+	}
+
+	return e.extractRegex(data)
+}
+
+// ExtractFile extracts text from the content of a local file using a regex
+func (e *Extractor) ExtractFile(data string, fields map[string]interface{}) map[string]struct{} {
+	switch e.extractorType {
+	case RegexExtractor:
+		return e.extractRegex(data)
+	case DSLExtractor:
+		return e.extractDSL(fields)
+	}
+
+	return nil
+}
+
+// ExtractWhois extracts text from a whois response using a regex, or
+// the values of its fields with kval
+func (e *Extractor) ExtractWhois(response string, fields map[string]interface{}) map[string]struct{} {
+	switch e.extractorType {
+	case RegexExtractor:
+		return e.extractRegex(response)
+	case KValExtractor:
+		results := make(map[string]struct{})
+		whois := matchers.WhoisFields(response)
+		for _, k := range e.KVal {
+			if value, ok := whois[k]; ok {
+				results[value] = struct{}{}
+			}
+		}
+		return results
+	case DSLExtractor:
+		return e.extractDSL(fields)
+	}
+
+	return nil
+}
+
+// ExtractSSL extracts text from the text of a tls handshake using a regex
+func (e *Extractor) ExtractSSL(text string, fields map[string]interface{}) map[string]struct{} {
+	switch e.extractorType {
+	case RegexExtractor:
+		return e.extractRegex(text)
+	case DSLExtractor:
+		return e.extractDSL(fields)
+	}
+
+	return nil
+}
+
+// ExtractWebsocket extracts text from the body and the handshake headers
+// of a websocket connection using a regex or a kval
+func (e *Extractor) ExtractWebsocket(body, headers string, header http.Header, fields map[string]interface{}) map[string]struct{} {
+	switch e.extractorType {
+	case RegexExtractor:
+		return e.extractRegex(e.messagePart(body, headers))
+	case KValExtractor:
+		return e.extractKVal(&http.Response{Header: header})
+	case DSLExtractor:
+		return e.extractDSL(fields)
+	}
+
+	return nil
+}
+
+// ExtractGRPC extracts text from the body and the headers of a grpc call
+// using a regex, or the values of its metadata with kval
+func (e *Extractor) ExtractGRPC(body, headers string, metadata http.Header, fields map[string]interface{}) map[string]struct{} {
+	switch e.extractorType {
+	case RegexExtractor:
+		return e.extractRegex(e.messagePart(body, headers))
+	case KValExtractor:
+		return e.extractKVal(&http.Response{Header: metadata})
+	case DSLExtractor:
+		return e.extractDSL(fields)
+	}
+
+	return nil
+}
+
+// messagePart returns the part of a websocket connection or a grpc call
+// the extractor reads, its headers being written before its body
+func (e *Extractor) messagePart(body, headers string) string {
+	switch e.part {
+	case HeaderPart:
+		return headers
+	case AllPart:
+		return headers + "\r\n" + body
+	}
+
+	return body
+}
+
+// ExtractHeadless extracts text from the body, the console messages and
+// the network requests of a headless page using a regex
+func (e *Extractor) ExtractHeadless(body, console, network string, fields map[string]interface{}) map[string]struct{} {
+	if e.extractorType == DSLExtractor {
+		return e.extractDSL(fields)
+	}
+	if e.extractorType != RegexExtractor {
+		return nil
+	}
+
+	switch e.part {
+	case ConsolePart:
+		return e.extractRegex(console)
+	case HeaderPart, NetworkPart:
+		return e.extractRegex(network)
+	case AllPart:
+		matches := e.extractRegex(body)
+		if len(matches) > 0 {
+			return matches
+		}
+		return e.extractRegex(console + "\n" + network)
+	}
+
+	return e.extractRegex(body)
+}
+
+// certificateText returns the certificate chain of the map of a response,
+// empty for the plain http responses
+func certificateText(fields map[string]interface{}) string {
+	text, _ := fields[matchers.CertificateVariable].(string)
+	return text
+}
+
+// NeedsFields returns true if the extractor reads the map of the responses,
+// in its dsl expressions or for the certificate chain of the https ones.
+func (e *Extractor) NeedsFields() bool {
+	return e.extractorType == DSLExtractor || e.part == CertificatePart
+}
+
 // extractRegex extracts text from a corpus and returns it
 func (e *Extractor) extractRegex(corpus string) map[string]struct{} {
 	results := make(map[string]struct{})
 
 	groupPlusOne := e.RegexGroup + 1
 	for _, regex := range e.regexCompiled {
-		matches := regex.FindAllStringSubmatch(corpus, -1)
+		matches := saferegex.FindAllStringSubmatch(regex, corpus)
 		for _, match := range matches {
 			if len(match) >= groupPlusOne {
 				results[match[e.RegexGroup]] = struct{}{}
@@ -66,6 +217,65 @@ func (e *Extractor) extractRegex(corpus string) map[string]struct{} {
 	return results
 }
 
+// extractDSL evaluates the dsl expressions on a generic map result and
+// returns their non-empty results
+func (e *Extractor) extractDSL(data map[string]interface{}) map[string]struct{} {
+	results := make(map[string]struct{})
+
+	for _, expression := range e.dslCompiled {
+		result, err := expression.Evaluate(data)
+		if err != nil || result == nil {
+			continue
+		}
+		if value := fmt.Sprint(result); value != "" {
+			results[value] = struct{}{}
+		}
+	}
+	return results
+}
+
+// Variables returns the values of the named capture groups of the regexes
+// in a http response, keyed by group name. The first match of a group wins.
+func (e *Extractor) Variables(body, headers string, fields map[string]interface{}) map[string]string {
+	if e.extractorType != RegexExtractor || !e.namedGroups {
+		return nil
+	}
+
+	switch e.part {
+	case BodyPart:
+		return e.regexVariables(body)
+	case HeaderPart:
+		return e.regexVariables(headers)
+	case CertificatePart:
+		return e.regexVariables(certificateText(fields))
+	}
+
+	if variables := e.regexVariables(headers); len(variables) > 0 {
+		return variables
+	}
+	return e.regexVariables(body)
+}
+
+// regexVariables maps the named capture groups of the regexes to their first match in a corpus
+func (e *Extractor) regexVariables(corpus string) map[string]string {
+	variables := make(map[string]string)
+
+	for _, regex := range e.regexCompiled {
+		names := regex.SubexpNames()
+		for _, match := range saferegex.FindAllStringSubmatch(regex, corpus) {
+			for i, name := range names {
+				if name == "" || i >= len(match) {
+					continue
+				}
+				if _, ok := variables[name]; !ok {
+					variables[name] = match[i]
+				}
+			}
+		}
+	}
+	return variables
+}
+
 // extractKVal extracts text from http response
 func (e *Extractor) extractKVal(r *http.Response) map[string]struct{} {
 	results := make(map[string]struct{})
@@ -1,6 +1,10 @@
 package extractors
 
-import "regexp"
+import (
+	"regexp"
+
+	"github.com/Knetic/govaluate"
+)
 
 // Extractor is used to extract part of response using a regex.
 type Extractor struct {
@@ -17,10 +21,17 @@ type Extractor struct {
 	RegexGroup int `yaml:"group"`
 	// regexCompiled is the compiled variant
 	regexCompiled []*regexp.Regexp
+	// namedGroups is true if any regex has named capture groups, eg. (?P<version>...)
+	namedGroups bool
 
 	// KVal are the kval to be present in the response headers/cookies
 	KVal []string `yaml:"kval,omitempty"`
 
+	// DSL are the dsl expressions whose results are extracted
+	DSL []string `yaml:"dsl,omitempty"`
+	// dslCompiled is the compiled variant
+	dslCompiled []*govaluate.EvaluableExpression
+
 	// Part is the part of the request to match
 	//
 	// By default, matching is performed in request body.
@@ -39,12 +50,15 @@ const (
 	RegexExtractor ExtractorType = iota + 1
 	// KValExtractor extracts responses with key:value
 	KValExtractor
+	// DSLExtractor extracts the results of dsl expressions
+	DSLExtractor
 )
 
 // ExtractorTypes is an table for conversion of extractor type from string.
 var ExtractorTypes = map[string]ExtractorType{
 	"regex": RegexExtractor,
 	"kval":  KValExtractor,
+	"dsl":   DSLExtractor,
 }
 
 // Part is the part of the request to match
@@ -57,13 +71,22 @@ const (
 	HeaderPart
 	// AllPart matches both response body and headers of the response.
 	AllPart
+	// ConsolePart matches the console messages of a headless page.
+	ConsolePart
+	// NetworkPart matches the URLs requested by a headless page.
+	NetworkPart
+	// CertificatePart matches the certificate chain of a https response.
+	CertificatePart
 )
 
 // PartTypes is an table for conversion of part type from string.
 var PartTypes = map[string]Part{
-	"body":   BodyPart,
-	"header": HeaderPart,
-	"all":    AllPart,
+	"body":        BodyPart,
+	"header":      HeaderPart,
+	"all":         AllPart,
+	"console":     ConsolePart,
+	"network":     NetworkPart,
+	"certificate": CertificatePart,
 }
 
 // GetPart returns the part of the matcher
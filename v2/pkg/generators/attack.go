@@ -10,11 +10,14 @@ const (
 	PitchFork
 	// ClusterBomb attack - Generate all possible combinations of values
 	ClusterBomb
+	// BatteringRam attack - All variables replaced with the same value at a time
+	BatteringRam
 )
 
 // AttackTypes is an table for conversion of attack type from string.
 var AttackTypes = map[string]Type{
-	"sniper":      Sniper,
-	"pitchfork":   PitchFork,
-	"clusterbomb": ClusterBomb,
+	"sniper":       Sniper,
+	"pitchfork":    PitchFork,
+	"clusterbomb":  ClusterBomb,
+	"batteringram": BatteringRam,
 }
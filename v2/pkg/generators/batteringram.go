@@ -0,0 +1,21 @@
+package generators
+
+// BatteringramGenerator Attack - Generate combinations with the same value in all the variables,
+// going through the values of each wordlist
+func BatteringramGenerator(payloads map[string][]string) (out chan map[string]interface{}) {
+	out = make(chan map[string]interface{})
+
+	// generator
+	go func() {
+		defer close(out)
+
+		for _, name := range SortedKeys(payloads) {
+			for _, value := range payloads[name] {
+				element := CopyMapWithDefaultValue(payloads, value)
+				out <- element
+			}
+		}
+	}()
+
+	return out
+}
@@ -13,9 +13,9 @@ func ClusterbombGenerator(payloads map[string][]string) (out chan map[string]int
 
 		var parts [][]string
 
-		for name, wordlist := range payloads {
+		for _, name := range SortedKeys(payloads) {
 			order = append(order, name)
-			parts = append(parts, wordlist)
+			parts = append(parts, payloads[name])
 		}
 
 		var n = 1
@@ -17,6 +17,8 @@ import (
 
 	"github.com/Knetic/govaluate"
 	"github.com/projectdiscovery/nuclei/v2/pkg/collaborator"
+	"github.com/projectdiscovery/nuclei/v2/pkg/similarity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/versions"
 	"github.com/spaolacci/murmur3"
 )
 
@@ -279,6 +281,28 @@ func HelperFunctions() (functions map[string]govaluate.ExpressionFunction) {
 		return rand.Intn(max-min) + min, nil
 	}
 
+	// similarity
+	functions["levenshtein_ratio"] = func(args ...interface{}) (interface{}, error) {
+		return similarity.Levenshtein(args[0].(string), args[1].(string)), nil
+	}
+
+	functions["simhash_similarity"] = func(args ...interface{}) (interface{}, error) {
+		return similarity.Simhash(args[0].(string), args[1].(string)), nil
+	}
+
+	// versions
+	functions["compare_versions"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 2 {
+			return nil, fmt.Errorf("compare_versions requires a version and at least one constraint")
+		}
+
+		constraints := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			constraints = append(constraints, fmt.Sprint(arg))
+		}
+		return versions.Satisfies(fmt.Sprint(args[0]), constraints...)
+	}
+
 	// Time Functions
 	functions["waitfor"] = func(args ...interface{}) (interface{}, error) {
 		seconds := args[0].(float64)
@@ -8,8 +8,8 @@ func SniperGenerator(payloads map[string][]string) (out chan map[string]interfac
 	go func() {
 		defer close(out)
 
-		for name, wordlist := range payloads {
-			for _, value := range wordlist {
+		for _, name := range SortedKeys(payloads) {
+			for _, value := range payloads[name] {
 				element := CopyMapWithDefaultValue(payloads, "")
 				element[name] = value
 				out <- element
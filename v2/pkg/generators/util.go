@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -186,6 +187,17 @@ func RandSeq(base string, n int) string {
 	return string(b)
 }
 
+// SortedKeys returns the keys of the payloads in a stable order
+func SortedKeys(payloads map[string][]string) []string {
+	keys := make([]string, 0, len(payloads))
+	for key := range payloads {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 func insertInto(s string, interval int, sep rune) string {
 	var buffer bytes.Buffer
 	before := interval - 1
@@ -0,0 +1,197 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the grpc calls
+const ContentType = "application/grpc"
+
+// MaxMessageSize is the maximum size of the messages read from a response
+const MaxMessageSize = 4 * 1024 * 1024
+
+// prefixLength is the size of the compression flag and of the length prefixing the messages
+const prefixLength = 5
+
+// The grpc status codes of the calls, the other ones being listed in statusNames
+const (
+	OK               = 0
+	Unknown          = 2
+	PermissionDenied = 7
+	Unimplemented    = 12
+	Internal         = 13
+	Unavailable      = 14
+	Unauthenticated  = 16
+)
+
+// statusNames are the names of the grpc status codes
+var statusNames = map[int]string{
+	0:  "OK",
+	1:  "CANCELLED",
+	2:  "UNKNOWN",
+	3:  "INVALID_ARGUMENT",
+	4:  "DEADLINE_EXCEEDED",
+	5:  "NOT_FOUND",
+	6:  "ALREADY_EXISTS",
+	7:  "PERMISSION_DENIED",
+	8:  "RESOURCE_EXHAUSTED",
+	9:  "FAILED_PRECONDITION",
+	10: "ABORTED",
+	11: "OUT_OF_RANGE",
+	12: "UNIMPLEMENTED",
+	13: "INTERNAL",
+	14: "UNAVAILABLE",
+	15: "DATA_LOSS",
+	16: "UNAUTHENTICATED",
+}
+
+// ErrCompressed is returned for the compressed messages, no encoding being
+// accepted by the calls
+var ErrCompressed = errors.New("compressed grpc messages are not supported")
+
+// Response is the outcome of a unary call
+type Response struct {
+	// HTTPStatus is the status code of the http2 response
+	HTTPStatus int
+	// Status is the grpc status code of the call
+	Status int
+	// Message is the status message of the call
+	Message  string
+	Headers  http.Header
+	Trailers http.Header
+	// Messages are the serialized protobuf messages of the response
+	Messages [][]byte
+	// Body is the body of a response which isn't grpc, eg. an error page
+	Body string
+}
+
+// StatusName returns the name of a grpc status code, eg. UNIMPLEMENTED
+func StatusName(code int) string {
+	if name, ok := statusNames[code]; ok {
+		return name
+	}
+
+	return strconv.Itoa(code)
+}
+
+// Encode prefixes a serialized message with its length, uncompressed
+func Encode(message []byte) []byte {
+	frame := make([]byte, prefixLength+len(message))
+	binary.BigEndian.PutUint32(frame[1:prefixLength], uint32(len(message)))
+	copy(frame[prefixLength:], message)
+
+	return frame
+}
+
+// Decode splits the body of a response into its length-prefixed messages
+func Decode(body []byte) ([][]byte, error) {
+	var messages [][]byte
+
+	for len(body) > 0 {
+		if len(body) < prefixLength {
+			return messages, io.ErrUnexpectedEOF
+		}
+		if body[0] != 0 {
+			return messages, ErrCompressed
+		}
+
+		length := binary.BigEndian.Uint32(body[1:prefixLength])
+		if length > MaxMessageSize {
+			return messages, fmt.Errorf("grpc message of %d bytes is too large", length)
+		}
+		if uint32(len(body)-prefixLength) < length {
+			return messages, io.ErrUnexpectedEOF
+		}
+
+		messages = append(messages, body[prefixLength:prefixLength+int(length)])
+		body = body[prefixLength+int(length):]
+	}
+
+	return messages, nil
+}
+
+// Invoke makes a unary call to a method of the server at an address, eg.
+// grpc.health.v1.Health/Check, with a serialized message. The client must
+// send the requests over http2.
+func Invoke(ctx context.Context, client *http.Client, address, method string, metadata http.Header, message []byte) (*Response, error) {
+	endpoint := strings.TrimSuffix(address, "/") + "/" + strings.TrimPrefix(method, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(Encode(message)))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range metadata {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("TE", "trailers")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxMessageSize+prefixLength))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response{HTTPStatus: resp.StatusCode, Headers: resp.Header, Trailers: resp.Trailer}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), ContentType) {
+		response.Body = string(body)
+		response.Status = httpStatusCode(resp.StatusCode)
+		return response, nil
+	}
+
+	if response.Messages, err = Decode(body); err != nil {
+		return nil, err
+	}
+
+	// the errors without messages are returned in the headers (trailers-only)
+	status := resp.Trailer.Get("grpc-status")
+	statusMessage := resp.Trailer.Get("grpc-message")
+	if status == "" {
+		status = resp.Header.Get("grpc-status")
+		statusMessage = resp.Header.Get("grpc-message")
+	}
+
+	response.Status = Unknown
+	if code, err := strconv.Atoi(status); err == nil {
+		response.Status = code
+	}
+	if response.Message, err = url.PathUnescape(statusMessage); err != nil {
+		response.Message = statusMessage
+	}
+
+	return response, nil
+}
+
+// httpStatusCode returns the grpc status of a response which isn't grpc, as
+// mapped by the grpc clients
+func httpStatusCode(status int) int {
+	switch status {
+	case http.StatusBadRequest:
+		return Internal
+	case http.StatusUnauthorized:
+		return Unauthenticated
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return Unavailable
+	}
+
+	return Unknown
+}
@@ -0,0 +1,121 @@
+package headless
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// defaultIdleTime is the time without network requests after which a page is idle
+const defaultIdleTime = 500 * time.Millisecond
+
+// Action is a step executed on the page of a headless template
+type Action struct {
+	// Action is the type of the action
+	Action string `yaml:"action"`
+	// Args contains the arguments of the action
+	Args map[string]string `yaml:"args,omitempty"`
+	// Name is the name the output of the action is available as to the matchers
+	Name string `yaml:"name,omitempty"`
+}
+
+// ActionArgs contains the required arguments of each action type
+var ActionArgs = map[string][]string{
+	// navigate loads the url and waits for the page load
+	"navigate": {"url"},
+	// click clicks on the element matching the selector
+	"click": {"selector"},
+	// fill sets the value of the form element matching the selector
+	"fill": {"selector", "value"},
+	// press sends a named key (Enter, Tab...) or types the keys text
+	"press": {"keys"},
+	// script executes javascript code, its result is the action output
+	"script": {"code"},
+	// waitload waits for the page load after a navigation
+	"waitload": nil,
+	// waitidle waits until no request was sent for idle milliseconds (500 by default)
+	"waitidle": nil,
+	// waitselector waits for an element to match the selector
+	"waitselector": {"selector"},
+	// sleep waits for the duration in seconds
+	"sleep": {"duration"},
+}
+
+// Validate checks the action type and its required arguments
+func (a *Action) Validate() error {
+	required, ok := ActionArgs[a.Action]
+	if !ok {
+		return fmt.Errorf("unknown headless action %s", a.Action)
+	}
+
+	for _, arg := range required {
+		if _, ok := a.Args[arg]; !ok {
+			return fmt.Errorf("headless action %s requires the %s argument", a.Action, arg)
+		}
+	}
+
+	return nil
+}
+
+// Run executes the actions on the page in order and returns the outputs
+// of the named actions.
+func (p *Page) Run(ctx context.Context, actions []*Action) (map[string]string, error) {
+	outputs := make(map[string]string)
+
+	for i, action := range actions {
+		output, err := p.run(ctx, action)
+		if err != nil {
+			return outputs, fmt.Errorf("step %d (%s): %s", i+1, action.Action, err)
+		}
+
+		if action.Name != "" {
+			outputs[action.Name] = output
+		}
+	}
+
+	return outputs, nil
+}
+
+// run executes an action on the page
+func (p *Page) run(ctx context.Context, action *Action) (string, error) {
+	switch action.Action {
+	case "navigate":
+		return "", p.Navigate(ctx, action.Args["url"])
+	case "click":
+		return "", p.Click(ctx, action.Args["selector"])
+	case "fill":
+		return "", p.Fill(ctx, action.Args["selector"], action.Args["value"])
+	case "press":
+		return "", p.Press(ctx, action.Args["keys"])
+	case "script":
+		return p.Evaluate(ctx, action.Args["code"])
+	case "waitload":
+		return "", p.WaitLoad(ctx)
+	case "waitidle":
+		idle := defaultIdleTime
+		if value, ok := action.Args["idle"]; ok {
+			milliseconds, err := strconv.Atoi(value)
+			if err != nil {
+				return "", fmt.Errorf("invalid idle time %s", value)
+			}
+			idle = time.Duration(milliseconds) * time.Millisecond
+		}
+		return "", p.WaitIdle(ctx, idle)
+	case "waitselector":
+		return "", p.WaitSelector(ctx, action.Args["selector"])
+	case "sleep":
+		seconds, err := strconv.ParseFloat(action.Args["duration"], 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid duration %s", action.Args["duration"])
+		}
+		select {
+		case <-time.After(time.Duration(seconds * float64(time.Second))):
+			return "", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	return "", fmt.Errorf("unknown headless action %s", action.Action)
+}
@@ -0,0 +1,288 @@
+package headless
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// browserNames are the executables of the chromium based browsers looked up in PATH
+var browserNames = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "chrome", "msedge"}
+
+// ErrNoBrowser is returned when no headless capable browser was found
+var ErrNoBrowser = errors.New("no chromium based browser found, use -browser-path to specify one")
+
+// errBrowserClosed is returned by the calls pending when the browser exits
+var errBrowserClosed = errors.New("browser was closed")
+
+// browserCloseTimeout is the time the browser is given to exit before being killed
+const browserCloseTimeout = 5 * time.Second
+
+// FindBrowser returns the path of the browser to use, looking up the
+// known chromium executables in PATH if none was provided.
+func FindBrowser(path string) (string, error) {
+	if path != "" {
+		return exec.LookPath(path)
+	}
+
+	for _, name := range browserNames {
+		if found, err := exec.LookPath(name); err == nil {
+			return found, nil
+		}
+	}
+
+	return "", ErrNoBrowser
+}
+
+// Browser is a headless browser controlled with the devtools protocol.
+//
+// The protocol messages are exchanged over the pipes of the browser process
+// (--remote-debugging-pipe), each message being terminated by a null byte.
+type Browser struct {
+	cmd     *exec.Cmd
+	dataDir string
+	writer  *os.File
+	reader  *os.File
+
+	writeMutex sync.Mutex
+
+	mutex    sync.Mutex
+	nextID   int64
+	pending  map[int64]chan *message
+	sessions map[string]func(method string, params json.RawMessage)
+	closed   chan struct{}
+}
+
+// request is a protocol command sent to the browser
+type request struct {
+	ID        int64       `json:"id"`
+	SessionID string      `json:"sessionId,omitempty"`
+	Method    string      `json:"method"`
+	Params    interface{} `json:"params,omitempty"`
+}
+
+// message is a command response or an event received from the browser
+type message struct {
+	ID        int64           `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Launch starts a new headless browser with an empty profile
+func Launch(path string) (*Browser, error) {
+	dataDir, err := ioutil.TempDir("", "nuclei-headless-")
+	if err != nil {
+		return nil, err
+	}
+
+	// the browser reads the commands from fd 3 and writes the messages to fd 4
+	commandsReader, commandsWriter, err := os.Pipe()
+	if err != nil {
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+	messagesReader, messagesWriter, err := os.Pipe()
+	if err != nil {
+		commandsReader.Close()
+		commandsWriter.Close()
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+
+	cmd := exec.Command(path,
+		"--headless",
+		"--disable-gpu",
+		"--no-sandbox",
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--disable-extensions",
+		"--disable-background-networking",
+		"--ignore-certificate-errors",
+		"--mute-audio",
+		"--hide-scrollbars",
+		"--remote-debugging-pipe",
+		"--user-data-dir="+dataDir,
+		"about:blank",
+	)
+	cmd.ExtraFiles = []*os.File{commandsReader, messagesWriter}
+
+	err = cmd.Start()
+
+	// the browser process holds its own copies of its pipe ends
+	commandsReader.Close()
+	messagesWriter.Close()
+
+	if err != nil {
+		commandsWriter.Close()
+		messagesReader.Close()
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+
+	b := &Browser{
+		cmd:      cmd,
+		dataDir:  dataDir,
+		writer:   commandsWriter,
+		reader:   messagesReader,
+		pending:  make(map[int64]chan *message),
+		sessions: make(map[string]func(method string, params json.RawMessage)),
+		closed:   make(chan struct{}),
+	}
+	go b.read()
+
+	return b, nil
+}
+
+// read dispatches the messages of the browser until its pipe is closed
+func (b *Browser) read() {
+	defer close(b.closed)
+
+	reader := bufio.NewReader(b.reader)
+
+	for {
+		data, err := reader.ReadBytes(0)
+		if err != nil {
+			return
+		}
+
+		msg := &message{}
+		if err := json.Unmarshal(data[:len(data)-1], msg); err != nil {
+			continue
+		}
+
+		b.mutex.Lock()
+		if msg.ID != 0 {
+			if pending, ok := b.pending[msg.ID]; ok {
+				delete(b.pending, msg.ID)
+				pending <- msg
+			}
+			b.mutex.Unlock()
+			continue
+		}
+		handler := b.sessions[msg.SessionID]
+		b.mutex.Unlock()
+
+		if handler != nil {
+			handler(msg.Method, msg.Params)
+		}
+	}
+}
+
+// call sends a command to the browser, or to a page if sessionID is set,
+// and decodes its result if result is not nil.
+//
+// It must not be called from an event handler as it waits for the read loop.
+func (b *Browser) call(ctx context.Context, sessionID, method string, params, result interface{}) error {
+	b.mutex.Lock()
+	b.nextID++
+	id := b.nextID
+	response := make(chan *message, 1)
+	b.pending[id] = response
+	b.mutex.Unlock()
+
+	data, err := json.Marshal(&request{ID: id, SessionID: sessionID, Method: method, Params: params})
+	if err != nil {
+		b.forget(id)
+		return err
+	}
+
+	b.writeMutex.Lock()
+	_, err = b.writer.Write(append(data, 0))
+	b.writeMutex.Unlock()
+
+	if err != nil {
+		b.forget(id)
+		return fmt.Errorf("could not send %s: %s", method, err)
+	}
+
+	select {
+	case msg := <-response:
+		if msg.Error != nil {
+			return fmt.Errorf("%s failed: %s", method, msg.Error.Message)
+		}
+		if result != nil {
+			return json.Unmarshal(msg.Result, result)
+		}
+		return nil
+	case <-ctx.Done():
+		b.forget(id)
+		return ctx.Err()
+	case <-b.closed:
+		return errBrowserClosed
+	}
+}
+
+// forget removes a call which won't wait for its response anymore
+func (b *Browser) forget(id int64) {
+	b.mutex.Lock()
+	delete(b.pending, id)
+	b.mutex.Unlock()
+}
+
+// handle registers the event handler of a page session, nil removes it
+func (b *Browser) handle(sessionID string, handler func(method string, params json.RawMessage)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if handler == nil {
+		delete(b.sessions, sessionID)
+		return
+	}
+
+	b.sessions[sessionID] = handler
+}
+
+// disposeContext removes a browser context with its pages and storage
+func (b *Browser) disposeContext(contextID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), browserCloseTimeout)
+	defer cancel()
+
+	//nolint:errcheck // the context is gone with the browser anyway
+	b.call(ctx, "", "Target.disposeBrowserContext", map[string]interface{}{"browserContextId": contextID}, nil)
+}
+
+// isClosed returns true if the browser exited
+func (b *Browser) isClosed() bool {
+	select {
+	case <-b.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close exits the browser and removes its profile
+func (b *Browser) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), browserCloseTimeout)
+	defer cancel()
+
+	//nolint:errcheck // the browser is killed if it doesn't exit
+	b.call(ctx, "", "Browser.close", nil, nil)
+	b.writer.Close()
+
+	select {
+	case <-b.closed:
+	case <-ctx.Done():
+		//nolint:errcheck // the process may have exited in the meantime
+		b.cmd.Process.Kill()
+	}
+
+	err := b.cmd.Wait()
+	b.reader.Close()
+	os.RemoveAll(b.dataDir)
+
+	return err
+}
@@ -0,0 +1,435 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pollInterval is the interval at which the page state is checked while waiting
+const pollInterval = 50 * time.Millisecond
+
+// Page is a browser tab recording the console messages and
+// the network requests made while executing actions.
+type Page struct {
+	browser   *Browser
+	contextID string
+	targetID  string
+	sessionID string
+	release   func()
+
+	mutex        sync.Mutex
+	loaded       chan struct{}
+	console      []string
+	dialogs      []string
+	network      []string
+	inflight     map[string]struct{}
+	lastActivity time.Time
+	statusCode   int
+}
+
+// PageData contains the state of a page after the execution of actions
+type PageData struct {
+	// URL is the current URL of the page
+	URL string
+	// Body is the serialized DOM of the page
+	Body string
+	// StatusCode is the status code of the last loaded document
+	StatusCode int
+	// Console contains the console messages, errors and dialogs of the page
+	Console []string
+	// Dialogs contains the messages of the javascript dialogs opened by the page
+	Dialogs []string
+	// Network contains the URLs requested by the page
+	Network []string
+	// Outputs contains the outputs of the named actions
+	Outputs map[string]string
+}
+
+// NewPage opens a new blank page in the browser, isolated from the other
+// pages in its own browser context with separate cookies and storage.
+func (b *Browser) NewPage(ctx context.Context) (*Page, error) {
+	var browserContext struct {
+		BrowserContextID string `json:"browserContextId"`
+	}
+	if err := b.call(ctx, "", "Target.createBrowserContext", map[string]interface{}{"disposeOnDetach": true}, &browserContext); err != nil {
+		return nil, err
+	}
+
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	targetParams := map[string]interface{}{"url": "about:blank", "browserContextId": browserContext.BrowserContextID}
+	if err := b.call(ctx, "", "Target.createTarget", targetParams, &target); err != nil {
+		b.disposeContext(browserContext.BrowserContextID)
+		return nil, err
+	}
+
+	var session struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := b.call(ctx, "", "Target.attachToTarget", map[string]interface{}{"targetId": target.TargetID, "flatten": true}, &session); err != nil {
+		b.disposeContext(browserContext.BrowserContextID)
+		return nil, err
+	}
+
+	p := &Page{
+		browser:      b,
+		contextID:    browserContext.BrowserContextID,
+		targetID:     target.TargetID,
+		sessionID:    session.SessionID,
+		loaded:       make(chan struct{}),
+		inflight:     make(map[string]struct{}),
+		lastActivity: time.Now(),
+	}
+	b.handle(p.sessionID, p.handleEvent)
+
+	for _, domain := range []string{"Page.enable", "Runtime.enable", "Network.enable"} {
+		if err := p.call(ctx, domain, nil, nil); err != nil {
+			p.Close()
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// call sends a command to the page
+func (p *Page) call(ctx context.Context, method string, params, result interface{}) error {
+	return p.browser.call(ctx, p.sessionID, method, params, result)
+}
+
+// handleEvent records the events of the page
+func (p *Page) handleEvent(method string, params json.RawMessage) {
+	var event struct {
+		RequestID string `json:"requestId"`
+		Type      string `json:"type"`
+		Message   string `json:"message"`
+		Args      []struct {
+			Value       json.RawMessage `json:"value"`
+			Description string          `json:"description"`
+		} `json:"args"`
+		ExceptionDetails struct {
+			Text      string `json:"text"`
+			Exception struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+		Request struct {
+			URL string `json:"url"`
+		} `json:"request"`
+		Response struct {
+			Status int `json:"status"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(params, &event); err != nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	switch method {
+	case "Page.loadEventFired":
+		select {
+		case <-p.loaded:
+		default:
+			close(p.loaded)
+		}
+	case "Page.javascriptDialogOpening":
+		p.console = append(p.console, "dialog: "+event.Message)
+		p.dialogs = append(p.dialogs, event.Message)
+		// dialogs block the page until they are closed
+		go p.call(context.Background(), "Page.handleJavaScriptDialog", map[string]interface{}{"accept": true}, nil) //nolint:errcheck // the page may be closed
+	case "Runtime.consoleAPICalled":
+		values := make([]string, 0, len(event.Args))
+		for _, arg := range event.Args {
+			values = append(values, remoteValue(arg.Value, arg.Description))
+		}
+		p.console = append(p.console, event.Type+": "+strings.Join(values, " "))
+	case "Runtime.exceptionThrown":
+		description := event.ExceptionDetails.Exception.Description
+		if description == "" {
+			description = event.ExceptionDetails.Text
+		}
+		p.console = append(p.console, "exception: "+description)
+	case "Network.requestWillBeSent":
+		p.network = append(p.network, event.Request.URL)
+		p.inflight[event.RequestID] = struct{}{}
+		p.lastActivity = time.Now()
+	case "Network.responseReceived":
+		if event.Type == "Document" {
+			p.statusCode = event.Response.Status
+		}
+	case "Network.loadingFinished", "Network.loadingFailed":
+		delete(p.inflight, event.RequestID)
+		p.lastActivity = time.Now()
+	}
+}
+
+// Navigate loads a URL in the page and waits for the load event
+func (p *Page) Navigate(ctx context.Context, URL string) error {
+	p.mutex.Lock()
+	p.loaded = make(chan struct{})
+	p.mutex.Unlock()
+
+	var result struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := p.call(ctx, "Page.navigate", map[string]interface{}{"url": URL}, &result); err != nil {
+		return err
+	}
+	if result.ErrorText != "" {
+		return fmt.Errorf("could not navigate to %s: %s", URL, result.ErrorText)
+	}
+
+	return p.WaitLoad(ctx)
+}
+
+// WaitLoad waits for the load event of the current document
+func (p *Page) WaitLoad(ctx context.Context) error {
+	p.mutex.Lock()
+	loaded := p.loaded
+	p.mutex.Unlock()
+
+	select {
+	case <-loaded:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("page did not load: %s", ctx.Err())
+	}
+}
+
+// WaitIdle waits until no network request was made for the idle duration
+func (p *Page) WaitIdle(ctx context.Context, idle time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.mutex.Lock()
+		isIdle := len(p.inflight) == 0 && time.Since(p.lastActivity) >= idle
+		p.mutex.Unlock()
+
+		if isIdle {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("network did not become idle: %s", ctx.Err())
+		}
+	}
+}
+
+// WaitSelector waits until an element matches the css selector
+func (p *Page) WaitSelector(ctx context.Context, selector string) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	script := fmt.Sprintf("document.querySelector(%s) !== null", quote(selector))
+
+	for {
+		found, err := p.Evaluate(ctx, script)
+		if err != nil {
+			return err
+		}
+		if found == "true" {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("no element matched %s: %s", selector, ctx.Err())
+		}
+	}
+}
+
+// Evaluate executes javascript in the page and returns its result
+// as a string, or as json if it isn't a string.
+func (p *Page) Evaluate(ctx context.Context, expression string) (string, error) {
+	var result struct {
+		Result struct {
+			Value       json.RawMessage `json:"value"`
+			Description string          `json:"description"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+
+	params := map[string]interface{}{"expression": expression, "returnByValue": true, "awaitPromise": true}
+	if err := p.call(ctx, "Runtime.evaluate", params, &result); err != nil {
+		return "", err
+	}
+
+	if result.ExceptionDetails != nil {
+		description := result.ExceptionDetails.Exception.Description
+		if description == "" {
+			description = result.ExceptionDetails.Text
+		}
+		return "", fmt.Errorf("script error: %s", description)
+	}
+
+	return remoteValue(result.Result.Value, result.Result.Description), nil
+}
+
+// Click clicks on the element matching the css selector
+func (p *Page) Click(ctx context.Context, selector string) error {
+	script := fmt.Sprintf(`(function(s) {
+	var e = document.querySelector(s);
+	if (!e) return false;
+	e.scrollIntoView();
+	e.click();
+	return true;
+})(%s)`, quote(selector))
+
+	return p.evaluateElement(ctx, selector, script)
+}
+
+// Fill sets the value of the form element matching the css selector,
+// firing the events of a user input.
+func (p *Page) Fill(ctx context.Context, selector, value string) error {
+	script := fmt.Sprintf(`(function(s, v) {
+	var e = document.querySelector(s);
+	if (!e) return false;
+	e.focus();
+	e.value = v;
+	e.dispatchEvent(new Event("input", {bubbles: true}));
+	e.dispatchEvent(new Event("change", {bubbles: true}));
+	return true;
+})(%s, %s)`, quote(selector), quote(value))
+
+	return p.evaluateElement(ctx, selector, script)
+}
+
+// evaluateElement executes a script returning false if the element wasn't found
+func (p *Page) evaluateElement(ctx context.Context, selector, script string) error {
+	found, err := p.Evaluate(ctx, script)
+	if err != nil {
+		return err
+	}
+	if found != "true" {
+		return fmt.Errorf("no element matched %s", selector)
+	}
+
+	return nil
+}
+
+// keyCodes are the virtual key codes of the named keys
+var keyCodes = map[string]int{
+	"Backspace":  8,
+	"Tab":        9,
+	"Enter":      13,
+	"Escape":     27,
+	"Space":      32,
+	"ArrowLeft":  37,
+	"ArrowUp":    38,
+	"ArrowRight": 39,
+	"ArrowDown":  40,
+	"Delete":     46,
+}
+
+// Press sends keys to the focused element of the page. Named keys such as
+// Enter or Tab are pressed, other text is typed as is.
+func (p *Page) Press(ctx context.Context, keys string) error {
+	code, ok := keyCodes[keys]
+	if !ok {
+		return p.call(ctx, "Input.insertText", map[string]interface{}{"text": keys}, nil)
+	}
+
+	key := keys
+	var text string
+	switch keys {
+	case "Enter":
+		text = "\r"
+	case "Space":
+		key, text = " ", " "
+	}
+
+	for _, eventType := range []string{"keyDown", "keyUp"} {
+		params := map[string]interface{}{
+			"type":                  eventType,
+			"key":                   key,
+			"code":                  keys,
+			"windowsVirtualKeyCode": code,
+		}
+		if eventType == "keyDown" && text != "" {
+			params["text"] = text
+		}
+		if err := p.call(ctx, "Input.dispatchKeyEvent", params, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Data returns the state of the page with the outputs of the actions
+func (p *Page) Data(ctx context.Context, outputs map[string]string) (*PageData, error) {
+	URL, err := p.Evaluate(ctx, "location.href")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.Evaluate(ctx, "document.documentElement ? document.documentElement.outerHTML : ''")
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return &PageData{
+		URL:        URL,
+		Body:       body,
+		StatusCode: p.statusCode,
+		Console:    append([]string(nil), p.console...),
+		Dialogs:    append([]string(nil), p.dialogs...),
+		Network:    append([]string(nil), p.network...),
+		Outputs:    outputs,
+	}, nil
+}
+
+// Close closes the page and disposes of its browser context
+func (p *Page) Close() {
+	p.browser.handle(p.sessionID, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), browserCloseTimeout)
+	defer cancel()
+
+	//nolint:errcheck // the page is gone with the browser anyway
+	p.browser.call(ctx, "", "Target.closeTarget", map[string]interface{}{"targetId": p.targetID}, nil)
+	p.browser.disposeContext(p.contextID)
+
+	if p.release != nil {
+		p.release()
+	}
+}
+
+// quote returns a string as a javascript string literal
+func quote(value string) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// remoteValue converts a javascript value returned by the browser to a string
+func remoteValue(value json.RawMessage, description string) string {
+	if len(value) == 0 {
+		return description
+	}
+
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		return text
+	}
+
+	return string(value)
+}
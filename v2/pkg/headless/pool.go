@@ -0,0 +1,96 @@
+package headless
+
+import (
+	"context"
+	"sync"
+)
+
+// Pool shares a browser between the headless templates, bounding the
+// number of pages open at the same time.
+//
+// The browser is launched on the first use and again if it crashes,
+// each page being isolated in its own browser context.
+type Pool struct {
+	path  string
+	slots chan struct{}
+
+	mutex   sync.Mutex
+	browser *Browser
+}
+
+// NewPool creates a pool of at most size pages of the browser at path
+func NewPool(path string, size int) *Pool {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &Pool{path: path, slots: make(chan struct{}, size)}
+}
+
+// Page opens a new isolated page, waiting for a slot if all are in use.
+// The slot is released when the page is closed.
+func (p *Pool) Page(ctx context.Context) (*Page, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	browser, err := p.getBrowser()
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+
+	page, err := browser.NewPage(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+
+	var once sync.Once
+	page.release = func() {
+		once.Do(func() { <-p.slots })
+	}
+
+	return page, nil
+}
+
+// getBrowser returns the running browser, launching it if needed
+func (p *Pool) getBrowser() (*Browser, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.browser != nil && !p.browser.isClosed() {
+		return p.browser, nil
+	}
+
+	if p.browser != nil {
+		//nolint:errcheck // the crashed browser is only cleaned up
+		p.browser.Close()
+	}
+
+	browser, err := Launch(p.path)
+	if err != nil {
+		return nil, err
+	}
+	p.browser = browser
+
+	return browser, nil
+}
+
+// Close exits the browser of the pool, a nil pool is a no-op
+func (p *Pool) Close() {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.browser != nil {
+		//nolint:errcheck // the browser is killed if it can't exit
+		p.browser.Close()
+		p.browser = nil
+	}
+}
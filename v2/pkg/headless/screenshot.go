@@ -0,0 +1,102 @@
+package headless
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// screenshotWindowSize is the size of the browser window captured
+const screenshotWindowSize = "1280,800"
+
+// Screenshotter captures screenshots of web pages with a headless browser.
+//
+// Each URL is captured once, later captures return the path of the first one.
+type Screenshotter struct {
+	browser   string
+	directory string
+	timeout   time.Duration
+
+	mutex    sync.Mutex
+	captured map[string]*screenshot
+}
+
+// screenshot is the result of a capture, done is closed when it completes
+type screenshot struct {
+	done chan struct{}
+	path string
+	err  error
+}
+
+// NewScreenshotter creates a screenshotter saving the images in a directory
+func NewScreenshotter(browser, directory string, timeout time.Duration) (*Screenshotter, error) {
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &Screenshotter{browser: browser, directory: directory, timeout: timeout, captured: make(map[string]*screenshot)}, nil
+}
+
+// Capture takes a screenshot of the URL and returns the path of the png image.
+// A nil screenshotter returns an empty path.
+func (s *Screenshotter) Capture(URL string) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+
+	s.mutex.Lock()
+	shot, ok := s.captured[URL]
+	if !ok {
+		shot = &screenshot{done: make(chan struct{})}
+		s.captured[URL] = shot
+	}
+	s.mutex.Unlock()
+
+	if ok {
+		<-shot.done
+		return shot.path, shot.err
+	}
+
+	shot.path, shot.err = s.capture(URL)
+	close(shot.done)
+
+	return shot.path, shot.err
+}
+
+// capture runs the browser to write the screenshot of the URL
+func (s *Screenshotter) capture(URL string) (string, error) {
+	hash := sha256.Sum256([]byte(URL))
+	path := filepath.Join(s.directory, hex.EncodeToString(hash[:10])+".png")
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.browser,
+		"--headless",
+		"--disable-gpu",
+		"--hide-scrollbars",
+		"--ignore-certificate-errors",
+		"--no-sandbox",
+		"--window-size="+screenshotWindowSize,
+		"--screenshot="+path,
+		URL,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("screenshot of %s timed out", URL)
+		}
+		return "", fmt.Errorf("could not capture screenshot of %s: %s: %s", URL, err, output)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("browser did not write the screenshot of %s", URL)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,43 @@
+package headless
+
+import (
+	"context"
+	"time"
+)
+
+// XSSVerifier confirms cross-site scripting findings by loading their
+// URL in a page and checking whether the payload opened a javascript dialog.
+type XSSVerifier struct {
+	pool    *Pool
+	timeout time.Duration
+}
+
+// NewXSSVerifier creates a verifier loading the URLs in the pages of the pool
+func NewXSSVerifier(pool *Pool, timeout time.Duration) *XSSVerifier {
+	return &XSSVerifier{pool: pool, timeout: timeout}
+}
+
+// Confirm returns true if loading the URL executed a dialog payload
+func (v *XSSVerifier) Confirm(URL string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+	defer cancel()
+
+	page, err := v.pool.Page(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer page.Close()
+
+	if err := page.Navigate(ctx, URL); err != nil {
+		return false, err
+	}
+
+	// payloads run by event handlers or timers may execute after the load
+	//nolint:errcheck // a busy page is checked as is
+	page.WaitIdle(ctx, defaultIdleTime)
+
+	page.mutex.Lock()
+	defer page.mutex.Unlock()
+
+	return len(page.dialogs) > 0, nil
+}
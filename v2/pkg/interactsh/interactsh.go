@@ -0,0 +1,412 @@
+// Package interactsh is a client of interactsh servers, receiving the dns,
+// http and smtp interactions triggered by the blind payloads of templates.
+package interactsh
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+const (
+	// DefaultServer is the public interactsh server
+	DefaultServer = "https://interact.sh"
+	// DefaultPollInterval is the interval between two polls of the interactions
+	DefaultPollInterval = 5 * time.Second
+
+	// correlationIDLength is the length of the id identifying the client on the server
+	correlationIDLength = 20
+	// nonceLength is the length of the random part appended to the id for each url
+	nonceLength = 13
+	// rsaKeySize is the size of the key the server encrypts the interactions for
+	rsaKeySize = 2048
+	// idAlphabet contains the characters of the ids, valid in a dns label
+	idAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// errClosed is the error of a client closed before it registered
+var errClosed = errors.New("client closed")
+
+// Options contains the configuration of an interactsh client
+type Options struct {
+	// ServerURL is the url of the interactsh server, DefaultServer if empty
+	ServerURL string
+	// PollInterval is the interval between two polls, DefaultPollInterval if 0
+	PollInterval time.Duration
+	// HTTPClient is the client talking to the server, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+// Interaction is a request received by the interactsh server
+type Interaction struct {
+	Protocol      string    `json:"protocol"`
+	UniqueID      string    `json:"unique-id"`
+	FullID        string    `json:"full-id"`
+	QType         string    `json:"q-type,omitempty"`
+	RawRequest    string    `json:"raw-request,omitempty"`
+	RawResponse   string    `json:"raw-response,omitempty"`
+	RemoteAddress string    `json:"remote-address"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// Correlation identifies the request a url was sent in, so that the
+// interactions with the url can be traced back to it.
+type Correlation struct {
+	Template string
+	Target   string
+	// Request is the number of the request of the template run on the target, 0 for all of them
+	Request int
+	// Payloads are the payload values the request was built with
+	Payloads map[string]interface{}
+}
+
+// LateInteractions are the interactions with a url received after its request was matched
+type LateInteractions struct {
+	Correlation  *Correlation
+	Interactions []*Interaction
+}
+
+// Client registers with an interactsh server on the first url requested
+// and polls the interactions of its urls until it's closed.
+type Client struct {
+	options *Options
+	server  *url.URL
+
+	once          sync.Once
+	err           error
+	correlationID string
+	secretKey     string
+	privateKey    *rsa.PrivateKey
+
+	mutex        sync.RWMutex
+	interactions map[string][]*Interaction
+	// correlations are the requests the urls were sent in by unique id
+	correlations map[string]*Correlation
+	// waited is the number of interactions of each unique id returned by Wait
+	waited map[string]int
+	stop   chan struct{}
+}
+
+// New creates an interactsh client, nothing is sent to the server until a url is requested
+func New(options *Options) (*Client, error) {
+	if options.ServerURL == "" {
+		options.ServerURL = DefaultServer
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+
+	server, err := url.Parse(options.ServerURL)
+	if err != nil || server.Host == "" {
+		return nil, fmt.Errorf("invalid interactsh server '%s'", options.ServerURL)
+	}
+
+	return &Client{
+		options:      options,
+		server:       server,
+		interactions: make(map[string][]*Interaction),
+		correlations: make(map[string]*Correlation),
+		waited:       make(map[string]int),
+		stop:         make(chan struct{}),
+	}, nil
+}
+
+// URL returns a new unique host name of the server, empty if the client couldn't register
+func (c *Client) URL() string {
+	c.once.Do(func() {
+		if c.err = c.register(); c.err != nil {
+			gologger.Errorf("Could not register with interactsh server %s: %s\n", c.server.Host, c.err)
+			return
+		}
+		go c.poll()
+	})
+	if c.err != nil {
+		return ""
+	}
+
+	nonce, err := randomID(nonceLength)
+	if err != nil {
+		return ""
+	}
+	return c.correlationID + nonce + "." + c.server.Hostname()
+}
+
+// URLFor returns a new unique host name of the server like URL, recording
+// the request it's sent in to trace the interactions with it back to it.
+func (c *Client) URLFor(correlation *Correlation) string {
+	host := c.URL()
+	if host == "" {
+		return ""
+	}
+
+	c.mutex.Lock()
+	c.correlations[uniqueID(host)] = correlation
+	c.mutex.Unlock()
+
+	return host
+}
+
+// Correlation returns the request a url was sent in, nil if it has none
+func (c *Client) Correlation(host string) *Correlation {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.correlations[uniqueID(host)]
+}
+
+// Wait returns the interactions received for a url, waiting up to timeout for the first one
+func (c *Client) Wait(host string, timeout time.Duration) []*Interaction {
+	id := uniqueID(host)
+	deadline := time.Now().Add(timeout)
+
+	for {
+		c.mutex.RLock()
+		interactions := c.interactions[id]
+		c.mutex.RUnlock()
+
+		if len(interactions) > 0 || !time.Now().Before(deadline) {
+			c.mutex.Lock()
+			if len(interactions) > c.waited[id] {
+				c.waited[id] = len(interactions)
+			}
+			c.mutex.Unlock()
+
+			return interactions
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// Late returns the interactions with the correlated urls which were received
+// after the responses of their requests were matched, eg. from a payload run
+// by a scheduled job of the target, ordered by template and target.
+func (c *Client) Late() []*LateInteractions {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	var late []*LateInteractions
+	for id, correlation := range c.correlations {
+		if interactions := c.interactions[id]; len(interactions) > c.waited[id] {
+			late = append(late, &LateInteractions{Correlation: correlation, Interactions: interactions[c.waited[id]:]})
+		}
+	}
+	sort.Slice(late, func(i, j int) bool {
+		a, b := late[i].Correlation, late[j].Correlation
+		if a.Template != b.Template {
+			return a.Template < b.Template
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Request < b.Request
+	})
+
+	return late
+}
+
+// Close stops polling and deregisters the client from the server. A client
+// closed before its first url never registers.
+func (c *Client) Close() {
+	// waits for a registration in progress, the fields are set once done
+	c.once.Do(func() {
+		c.err = errClosed
+	})
+	if c.err != nil {
+		return
+	}
+	close(c.stop)
+
+	body := map[string]string{"correlation-id": c.correlationID, "secret-key": c.secretKey}
+	if err := c.post("/deregister", body); err != nil {
+		gologger.Warningf("Could not deregister from interactsh server: %s\n", err)
+	}
+}
+
+// register generates the keys of the client and registers them with the server
+func (c *Client) register() error {
+	privateKey, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return err
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return err
+	}
+	encoded := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: publicKey})
+
+	c.privateKey = privateKey
+	if c.secretKey, err = randomID(correlationIDLength); err != nil {
+		return err
+	}
+	if c.correlationID, err = randomID(correlationIDLength); err != nil {
+		return err
+	}
+
+	return c.post("/register", map[string]string{
+		"public-key":     base64.StdEncoding.EncodeToString(encoded),
+		"secret-key":     c.secretKey,
+		"correlation-id": c.correlationID,
+	})
+}
+
+// poll fetches the interactions of the client at each interval until it's closed
+func (c *Client) poll() {
+	ticker := time.NewTicker(c.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if err := c.fetch(); err != nil {
+				gologger.Warningf("Could not poll interactsh server: %s\n", err)
+			}
+		}
+	}
+}
+
+// fetch retrieves and decrypts the interactions received since the last poll
+func (c *Client) fetch() error {
+	endpoint := *c.server
+	endpoint.Path = "/poll"
+	endpoint.RawQuery = url.Values{"id": {c.correlationID}, "secret": {c.secretKey}}.Encode()
+
+	resp, err := c.options.HTTPClient.Get(endpoint.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	polled := &struct {
+		Data   []string `json:"data"`
+		AESKey string   `json:"aes_key"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(polled); err != nil {
+		return err
+	}
+	if len(polled.Data) == 0 {
+		return nil
+	}
+
+	encryptedKey, err := base64.StdEncoding.DecodeString(polled.AESKey)
+	if err != nil {
+		return err
+	}
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, c.privateKey, encryptedKey, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, data := range polled.Data {
+		interaction, err := decrypt(key, data)
+		if err != nil {
+			gologger.Warningf("Could not decrypt interaction: %s\n", err)
+			continue
+		}
+
+		id := strings.ToLower(interaction.UniqueID)
+		c.mutex.Lock()
+		c.interactions[id] = append(c.interactions[id], interaction)
+		c.mutex.Unlock()
+	}
+
+	return nil
+}
+
+// decrypt decodes an interaction encrypted with aes-cfb, the iv preceding the data
+func decrypt(key []byte, data string) (*Interaction, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aes.BlockSize {
+		return nil, fmt.Errorf("interaction too short")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv, ciphertext := ciphertext[:aes.BlockSize], ciphertext[aes.BlockSize:]
+	cipher.NewCFBDecrypter(block, iv).XORKeyStream(ciphertext, ciphertext)
+
+	interaction := &Interaction{}
+	if err := json.Unmarshal(ciphertext, interaction); err != nil {
+		return nil, err
+	}
+
+	return interaction, nil
+}
+
+// post sends a json body to an endpoint of the server
+func (c *Client) post(path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	endpoint := *c.server
+	endpoint.Path = path
+	resp, err := c.options.HTTPClient.Post(endpoint.String(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// uniqueID returns the id the server reports the interactions of a url with
+func uniqueID(host string) string {
+	id := strings.ToLower(strings.SplitN(host, ".", 2)[0])
+	if len(id) > correlationIDLength+nonceLength {
+		id = id[:correlationIDLength+nonceLength]
+	}
+
+	return id
+}
+
+// randomID returns a random id of the given length
+func randomID(length int) (string, error) {
+	id := make([]byte, length)
+	max := big.NewInt(int64(len(idAlphabet)))
+	for i := range id {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		id[i] = idAlphabet[n.Int64()]
+	}
+
+	return string(id), nil
+}
@@ -0,0 +1,167 @@
+package interactsh
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// server is an interactsh server encrypting the interactions queued for
+// the registered client like the real one
+type server struct {
+	t *testing.T
+
+	mutex         sync.Mutex
+	publicKey     *rsa.PublicKey
+	correlationID string
+	secretKey     string
+	deregistered  bool
+	queued        []*Interaction
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch r.URL.Path {
+	case "/register":
+		body := make(map[string]string)
+		require.Nil(s.t, json.NewDecoder(r.Body).Decode(&body), "could not decode registration")
+		encoded, err := base64.StdEncoding.DecodeString(body["public-key"])
+		require.Nil(s.t, err, "could not decode public key")
+		block, _ := pem.Decode(encoded)
+		require.NotNil(s.t, block, "the public key is not pem encoded")
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		require.Nil(s.t, err, "could not parse public key")
+
+		s.publicKey = key.(*rsa.PublicKey)
+		s.correlationID = body["correlation-id"]
+		s.secretKey = body["secret-key"]
+	case "/poll":
+		if r.URL.Query().Get("id") != s.correlationID || r.URL.Query().Get("secret") != s.secretKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(s.encrypt()) //nolint:errcheck // the client fails on a partial body
+	case "/deregister":
+		body := make(map[string]string)
+		require.Nil(s.t, json.NewDecoder(r.Body).Decode(&body), "could not decode deregistration")
+		s.deregistered = body["correlation-id"] == s.correlationID && body["secret-key"] == s.secretKey
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// encrypt returns the queued interactions encrypted with a new aes key,
+// itself encrypted with the public key of the client
+func (s *server) encrypt() map[string]interface{} {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.Nil(s.t, err, "could not generate aes key")
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, s.publicKey, key, nil)
+	require.Nil(s.t, err, "could not encrypt aes key")
+
+	block, err := aes.NewCipher(key)
+	require.Nil(s.t, err, "could not create cipher")
+
+	data := []string{}
+	for _, interaction := range s.queued {
+		plaintext, err := json.Marshal(interaction)
+		require.Nil(s.t, err, "could not marshal interaction")
+
+		ciphertext := make([]byte, aes.BlockSize+len(plaintext))
+		_, err = rand.Read(ciphertext[:aes.BlockSize])
+		require.Nil(s.t, err, "could not generate iv")
+		cipher.NewCFBEncrypter(block, ciphertext[:aes.BlockSize]).XORKeyStream(ciphertext[aes.BlockSize:], plaintext)
+		data = append(data, base64.StdEncoding.EncodeToString(ciphertext))
+	}
+	s.queued = nil
+
+	return map[string]interface{}{"data": data, "aes_key": base64.StdEncoding.EncodeToString(encryptedKey)}
+}
+
+// queue adds an interaction with a url for the next poll
+func (s *server) queue(host, protocol string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.queued = append(s.queued, &Interaction{Protocol: protocol, UniqueID: strings.ToUpper(uniqueID(host)), FullID: host, Timestamp: time.Now()})
+}
+
+func newTestClient(t *testing.T) (*Client, *server, func()) {
+	s := &server{t: t}
+	ts := httptest.NewServer(s)
+
+	client, err := New(&Options{ServerURL: ts.URL, PollInterval: 50 * time.Millisecond, HTTPClient: ts.Client()})
+	require.Nil(t, err, "could not create client")
+
+	return client, s, ts.Close
+}
+
+func TestClient(t *testing.T) {
+	client, s, closeServer := newTestClient(t)
+	defer closeServer()
+
+	correlation := &Correlation{Template: "blind-ssrf", Target: "https://acme.local", Request: 1}
+	host := client.URLFor(correlation)
+	require.True(t, strings.HasSuffix(host, ".127.0.0.1"), "wrong host %s", host)
+	require.Len(t, uniqueID(host), correlationIDLength+nonceLength, "wrong unique id")
+	require.Equal(t, correlation, client.Correlation(host), "the url is not correlated to its request")
+
+	s.queue(host, "dns")
+	interactions := client.Wait(host, 5*time.Second)
+	require.Len(t, interactions, 1, "the interaction was not polled")
+	require.Equal(t, "dns", interactions[0].Protocol, "the interaction was not decrypted")
+	require.Empty(t, client.Late(), "a waited interaction is reported as late")
+
+	s.queue(host, "http")
+	require.Eventually(t, func() bool { return len(client.Late()) == 1 }, 5*time.Second, 50*time.Millisecond, "the late interaction was not polled")
+	late := client.Late()[0]
+	require.Equal(t, correlation, late.Correlation, "wrong correlation of the late interaction")
+	require.Len(t, late.Interactions, 1, "the waited interaction is reported as late")
+	require.Equal(t, "http", late.Interactions[0].Protocol, "wrong late interaction")
+
+	client.Close()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	require.True(t, s.deregistered, "the client was not deregistered")
+}
+
+func TestCloseBeforeURL(t *testing.T) {
+	client, s, closeServer := newTestClient(t)
+	defer closeServer()
+
+	client.Close()
+	require.Empty(t, client.URL(), "a closed client registered")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	require.Empty(t, s.correlationID, "a closed client registered")
+}
+
+func TestCloseWhileRegistering(t *testing.T) {
+	client, _, closeServer := newTestClient(t)
+	defer closeServer()
+
+	done := make(chan struct{})
+	go func() {
+		client.URL()
+		close(done)
+	}()
+	client.Close()
+	<-done
+}
@@ -0,0 +1,58 @@
+package matchers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// cacheHeaders are the headers set by caches and cdns with the cache status of a response
+var cacheHeaders = []string{
+	"X-Cache",
+	"X-Cache-Status",
+	"X-Cache-Lookup",
+	"Cf-Cache-Status",
+	"Akamai-Cache-Status",
+	"Cdn-Cache",
+	"X-Drupal-Cache",
+	"X-Varnish-Cache",
+	"X-Proxy-Cache",
+	"X-Rack-Cache",
+	"X-Litespeed-Cache",
+	"X-Vercel-Cache",
+	"X-Nextjs-Cache",
+}
+
+// CacheStatus returns hit or miss depending on the response headers of
+// the caches, or an empty string if no cache is visible in the response.
+func CacheStatus(header http.Header) string {
+	status := ""
+
+	for _, name := range cacheHeaders {
+		value := strings.ToLower(strings.Join(header.Values(name), " "))
+		if strings.Contains(value, "hit") {
+			return "hit"
+		}
+		if value != "" {
+			status = "miss"
+		}
+	}
+
+	// a cached response has a non zero age
+	if age, err := strconv.Atoi(header.Get("Age")); err == nil {
+		if age > 0 {
+			return "hit"
+		}
+		status = "miss"
+	}
+
+	// varnish adds the id of the request which cached the response
+	if varnish := header.Get("X-Varnish"); varnish != "" {
+		if len(strings.Fields(varnish)) > 1 {
+			return "hit"
+		}
+		status = "miss"
+	}
+
+	return status
+}
@@ -0,0 +1,18 @@
+package matchers
+
+// CertificateVariable contains the certificate chain of a https response as
+// text, set in the map of the response by the executer
+const CertificateVariable = "tls_certificate"
+
+// certificateText returns the certificate chain of the map of a response,
+// empty for the plain http responses
+func certificateText(fields map[string]interface{}) string {
+	text, _ := fields[CertificateVariable].(string)
+	return text
+}
+
+// NeedsFields returns true if the matcher reads the map of the responses,
+// in its dsl expressions or for the certificate chain of the https ones.
+func (m *Matcher) NeedsFields() bool {
+	return m.matcherType == DSLMatcher || m.part == CertificatePart
+}
@@ -1,11 +1,12 @@
 package matchers
 
 import (
+	"encoding/hex"
 	"fmt"
-	"regexp"
 
 	"github.com/Knetic/govaluate"
 	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/saferegex"
 )
 
 // CompileMatchers performs the initial setup operation on a matcher
@@ -20,14 +21,20 @@ func (m *Matcher) CompileMatchers() error {
 
 	// Compile the regexes
 	for _, regex := range m.Regex {
-		compiled, err := regexp.Compile(regex)
+		compiled, err := saferegex.Compile(regex)
 		if err != nil {
-			return fmt.Errorf("could not compile regex: %s", regex)
+			return err
 		}
 
 		m.regexCompiled = append(m.regexCompiled, compiled)
 	}
 
+	// Decode the binary characters once instead of on every response
+	for _, binary := range m.Binary {
+		decoded, _ := hex.DecodeString(binary)
+		m.binaryDecoded = append(m.binaryDecoded, string(decoded))
+	}
+
 	// Compile the dsl expressions
 	for _, dsl := range m.DSL {
 		compiled, err := govaluate.NewEvaluableExpressionWithFunctions(dsl, generators.HelperFunctions())
@@ -38,6 +45,20 @@ func (m *Matcher) CompileMatchers() error {
 		m.dslCompiled = append(m.dslCompiled, compiled)
 	}
 
+	if m.matcherType == SimilarityMatcher {
+		switch m.Algorithm {
+		case "", "levenshtein", "simhash":
+		default:
+			return fmt.Errorf("unknown similarity algorithm specified: %s", m.Algorithm)
+		}
+		if m.Threshold <= 0 || m.Threshold > 1 {
+			return fmt.Errorf("similarity threshold must be between 0 and 1")
+		}
+		if m.Request < 0 || (m.Request == 0 && m.Reference == "") {
+			return fmt.Errorf("similarity matcher needs a reference or a request to compare to")
+		}
+	}
+
 	// Setup the condition type, if any.
 	if m.Condition != "" {
 		m.condition, ok = ConditionTypes[m.Condition]
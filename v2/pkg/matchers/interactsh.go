@@ -0,0 +1,49 @@
+package matchers
+
+import "strings"
+
+const (
+	// InteractshProtocolVariable contains the protocols of the interactions, one per line
+	InteractshProtocolVariable = "interactsh_protocol"
+	// InteractshRequestVariable contains the raw requests of the interactions
+	InteractshRequestVariable = "interactsh_request"
+)
+
+// interactshVariables maps the interactsh parts to the variables holding their corpus
+var interactshVariables = map[Part]string{
+	InteractshProtocolPart: InteractshProtocolVariable,
+	InteractshRequestPart:  InteractshRequestVariable,
+}
+
+// matchInteractsh matches the interactions received for the request
+func (m *Matcher) matchInteractsh(data map[string]interface{}) bool {
+	corpus, _ := data[interactshVariables[m.part]].(string)
+
+	switch m.matcherType {
+	case WordsMatcher:
+		return m.matchWords(corpus)
+	case RegexMatcher:
+		return m.matchRegex(corpus)
+	case BinaryMatcher:
+		return m.matchBinary(corpus)
+	case DSLMatcher:
+		return m.matchDSL(data)
+	}
+
+	return false
+}
+
+// NeedsInteractions returns true if the matcher matches on the interactions
+// with the interactsh url, as a part or in its dsl expressions.
+func (m *Matcher) NeedsInteractions() bool {
+	if _, ok := interactshVariables[m.part]; ok {
+		return true
+	}
+	for _, expression := range m.DSL {
+		if strings.Contains(expression, "interactsh_") {
+			return true
+		}
+	}
+
+	return false
+}
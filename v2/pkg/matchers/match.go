@@ -1,17 +1,23 @@
 package matchers
 
 import (
-	"encoding/hex"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/miekg/dns"
 	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/saferegex"
 )
 
-// Match matches a http response again a given matcher
-func (m *Matcher) Match(resp *http.Response, body, headers string, duration time.Duration, data map[string]interface{}) bool {
+// Match matches a http response again a given matcher. The fields are the
+// map of the response built by the executer, for the matchers needing them.
+func (m *Matcher) Match(resp *http.Response, body, headers string, fields, data map[string]interface{}) bool {
+	if m.part == InteractshProtocolPart || m.part == InteractshRequestPart {
+		return m.isNegative(m.matchInteractsh(data))
+	}
+
+	body = m.rawBody(body, data)
+
 	switch m.matcherType {
 	case StatusMatcher:
 		return m.isNegative(m.matchStatusCode(resp.StatusCode))
@@ -23,6 +29,10 @@ func (m *Matcher) Match(resp *http.Response, body, headers string, duration time
 			return m.isNegative(m.matchWords(body))
 		} else if m.part == HeaderPart {
 			return m.isNegative(m.matchWords(headers))
+		} else if m.part == CachePart {
+			return m.isNegative(m.matchWords(CacheStatus(resp.Header)))
+		} else if m.part == CertificatePart {
+			return m.isNegative(m.matchWords(certificateText(fields)))
 		} else {
 			return m.isNegative(m.matchWords(headers) || m.matchWords(body))
 		}
@@ -32,6 +42,10 @@ func (m *Matcher) Match(resp *http.Response, body, headers string, duration time
 			return m.isNegative(m.matchRegex(body))
 		} else if m.part == HeaderPart {
 			return m.isNegative(m.matchRegex(headers))
+		} else if m.part == CachePart {
+			return m.isNegative(m.matchRegex(CacheStatus(resp.Header)))
+		} else if m.part == CertificatePart {
+			return m.isNegative(m.matchRegex(certificateText(fields)))
 		} else {
 			return m.isNegative(m.matchRegex(headers) || m.matchRegex(body))
 		}
@@ -41,19 +55,25 @@ func (m *Matcher) Match(resp *http.Response, body, headers string, duration time
 			return m.isNegative(m.matchBinary(body))
 		} else if m.part == HeaderPart {
 			return m.isNegative(m.matchBinary(headers))
+		} else if m.part == CachePart {
+			return m.isNegative(m.matchBinary(CacheStatus(resp.Header)))
+		} else if m.part == CertificatePart {
+			return m.isNegative(m.matchBinary(certificateText(fields)))
 		} else {
 			return m.isNegative(m.matchBinary(headers) || m.matchBinary(body))
 		}
 	case DSLMatcher:
 		// Match complex query
-		return m.isNegative(m.matchDSL(generators.MergeMaps(HTTPToMap(resp, body, headers, duration, ""), data)))
+		return m.isNegative(m.matchDSL(generators.MergeMaps(fields, data)))
+	case SimilarityMatcher:
+		return m.isNegative(m.matchSimilarity(m.HTTPCorpus(resp, body, headers, certificateText(fields)), data))
 	}
 
 	return false
 }
 
 // MatchDNS matches a dns response against a given matcher
-func (m *Matcher) MatchDNS(msg *dns.Msg) bool {
+func (m *Matcher) MatchDNS(msg *dns.Msg, fields map[string]interface{}) bool {
 	switch m.matcherType {
 	// [WIP] add dns status code matcher
 	case SizeMatcher:
@@ -69,7 +89,158 @@ func (m *Matcher) MatchDNS(msg *dns.Msg) bool {
 		return m.matchBinary(msg.String())
 	case DSLMatcher:
 		// Match complex query
-		return m.matchDSL(DNSToMap(msg, ""))
+		return m.matchDSL(fields)
+	case SimilarityMatcher:
+		return m.matchSimilarity(msg.String(), nil)
+	}
+
+	return false
+}
+
+// MatchNetwork matches the data read from a network connection against a given matcher
+func (m *Matcher) MatchNetwork(data string, fields map[string]interface{}) bool {
+	switch m.matcherType {
+	case SizeMatcher:
+		return m.isNegative(m.matchSizeCode(len(data)))
+	case WordsMatcher:
+		return m.isNegative(m.matchWords(data))
+	case RegexMatcher:
+		return m.isNegative(m.matchRegex(data))
+	case BinaryMatcher:
+		return m.isNegative(m.matchBinary(data))
+	case DSLMatcher:
+		return m.isNegative(m.matchDSL(fields))
+	case SimilarityMatcher:
+		return m.isNegative(m.matchSimilarity(data, nil))
+	}
+
+	return false
+}
+
+// MatchFile matches the content of a local file against a given matcher
+func (m *Matcher) MatchFile(data string, fields map[string]interface{}) bool {
+	switch m.matcherType {
+	case SizeMatcher:
+		return m.isNegative(m.matchSizeCode(len(data)))
+	case WordsMatcher:
+		return m.isNegative(m.matchWords(data))
+	case RegexMatcher:
+		return m.isNegative(m.matchRegex(data))
+	case BinaryMatcher:
+		return m.isNegative(m.matchBinary(data))
+	case DSLMatcher:
+		return m.isNegative(m.matchDSL(fields))
+	case SimilarityMatcher:
+		return m.isNegative(m.matchSimilarity(data, nil))
+	}
+
+	return false
+}
+
+// MatchWhois matches a whois response against a given matcher
+func (m *Matcher) MatchWhois(response string, fields map[string]interface{}) bool {
+	switch m.matcherType {
+	case SizeMatcher:
+		return m.isNegative(m.matchSizeCode(len(response)))
+	case WordsMatcher:
+		return m.isNegative(m.matchWords(response))
+	case RegexMatcher:
+		return m.isNegative(m.matchRegex(response))
+	case BinaryMatcher:
+		return m.isNegative(m.matchBinary(response))
+	case DSLMatcher:
+		return m.isNegative(m.matchDSL(fields))
+	case SimilarityMatcher:
+		return m.isNegative(m.matchSimilarity(response, nil))
+	}
+
+	return false
+}
+
+// MatchSSL matches the text of a tls handshake with an address against a
+// given matcher
+func (m *Matcher) MatchSSL(text string, fields map[string]interface{}) bool {
+	switch m.matcherType {
+	case WordsMatcher:
+		return m.isNegative(m.matchWords(text))
+	case RegexMatcher:
+		return m.isNegative(m.matchRegex(text))
+	case BinaryMatcher:
+		return m.isNegative(m.matchBinary(text))
+	case DSLMatcher:
+		return m.isNegative(m.matchDSL(fields))
+	case SimilarityMatcher:
+		return m.isNegative(m.matchSimilarity(text, nil))
+	}
+
+	return false
+}
+
+// MatchHeadless matches the state of a headless page against a given
+// matcher, the corpus being the part of the page the matcher is evaluated on
+func (m *Matcher) MatchHeadless(statusCode int, body, corpus string, fields map[string]interface{}) bool {
+	switch m.matcherType {
+	case StatusMatcher:
+		return m.isNegative(m.matchStatusCode(statusCode))
+	case SizeMatcher:
+		return m.isNegative(m.matchSizeCode(len(body)))
+	case WordsMatcher:
+		return m.isNegative(m.matchWords(corpus))
+	case RegexMatcher:
+		return m.isNegative(m.matchRegex(corpus))
+	case BinaryMatcher:
+		return m.isNegative(m.matchBinary(corpus))
+	case DSLMatcher:
+		return m.isNegative(m.matchDSL(fields))
+	case SimilarityMatcher:
+		return m.isNegative(m.matchSimilarity(corpus, nil))
+	}
+
+	return false
+}
+
+// MatchWebsocket matches a websocket connection against a given matcher,
+// the corpus being the part of the connection the matcher is evaluated on
+func (m *Matcher) MatchWebsocket(statusCode int, corpus string, fields map[string]interface{}) bool {
+	switch m.matcherType {
+	case StatusMatcher:
+		return m.isNegative(m.matchStatusCode(statusCode))
+	case SizeMatcher:
+		return m.isNegative(m.matchSizeCode(len(corpus)))
+	case WordsMatcher:
+		return m.isNegative(m.matchWords(corpus))
+	case RegexMatcher:
+		return m.isNegative(m.matchRegex(corpus))
+	case BinaryMatcher:
+		return m.isNegative(m.matchBinary(corpus))
+	case DSLMatcher:
+		return m.isNegative(m.matchDSL(fields))
+	case SimilarityMatcher:
+		return m.isNegative(m.matchSimilarity(corpus, nil))
+	}
+
+	return false
+}
+
+// MatchGRPC matches a grpc call against a given matcher, the status matchers
+// working on the grpc status code and the corpus being the part of the call
+// the matcher is evaluated on
+func (m *Matcher) MatchGRPC(status int, corpus string, fields map[string]interface{}) bool {
+	switch m.matcherType {
+	case StatusMatcher:
+		return m.isNegative(m.matchStatusCode(status))
+	case SizeMatcher:
+		return m.isNegative(m.matchSizeCode(len(corpus)))
+	case WordsMatcher:
+		return m.isNegative(m.matchWords(corpus))
+	case RegexMatcher:
+		return m.isNegative(m.matchRegex(corpus))
+	case BinaryMatcher:
+		return m.isNegative(m.matchBinary(corpus))
+	case DSLMatcher:
+		return m.isNegative(m.matchDSL(fields))
+	case SimilarityMatcher:
+		return m.isNegative(m.matchSimilarity(corpus, nil))
 	}
 
 	return false
@@ -143,7 +314,7 @@ func (m *Matcher) matchRegex(corpus string) bool {
 	// Iterate over all the regexes accepted as valid
 	for i, regex := range m.regexCompiled {
 		// Continue if the regex doesn't match
-		if !regex.MatchString(corpus) {
+		if !saferegex.MatchString(regex, corpus) {
 			// If we are in an AND request and a match failed,
 			// return false as the AND condition fails on any single mismatch.
 			if m.condition == ANDCondition {
@@ -170,10 +341,9 @@ func (m *Matcher) matchRegex(corpus string) bool {
 // matchWords matches a word check against an HTTP Response/Headers.
 func (m *Matcher) matchBinary(corpus string) bool {
 	// Iterate over all the words accepted as valid
-	for i, binary := range m.Binary {
+	for i, binary := range m.binaryDecoded {
 		// Continue if the word doesn't match
-		hexa, _ := hex.DecodeString(binary)
-		if !strings.Contains(corpus, string(hexa)) {
+		if !strings.Contains(corpus, binary) {
 			// If we are in an AND request and a match failed,
 			// return false as the AND condition fails on any single mismatch.
 			if m.condition == ANDCondition {
@@ -189,7 +359,7 @@ func (m *Matcher) matchBinary(corpus string) bool {
 		}
 
 		// If we are at the end of the words, return with true
-		if len(m.Binary)-1 == i {
+		if len(m.binaryDecoded)-1 == i {
 			return true
 		}
 	}
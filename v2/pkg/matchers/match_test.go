@@ -1,6 +1,7 @@
 package matchers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -28,3 +29,60 @@ func TestORCondition(t *testing.T) {
 	matched = m.matchWords("c")
 	require.False(t, matched, "Could match invalid OR condition")
 }
+
+func TestSimilarityMatcher(t *testing.T) {
+	m := &Matcher{Type: "similarity", Reference: "SQL syntax error near 'abc' at line 1", Threshold: 0.8}
+	require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+
+	matched := m.matchSimilarity("SQL syntax error near 'xyz' at line 1", nil)
+	require.True(t, matched, "Could not match similar response")
+
+	matched = m.matchSimilarity("Welcome to the home page", nil)
+	require.False(t, matched, "Could match different response")
+
+	m = &Matcher{Type: "similarity", Request: 1, Threshold: 0.9}
+	require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+
+	matched = m.matchSimilarity("page 1", map[string]interface{}{"body_1": "page 2"})
+	require.False(t, matched, "Could match response below the threshold")
+
+	matched = m.matchSimilarity("same page", map[string]interface{}{"body_1": "same page"})
+	require.True(t, matched, "Could not match identical earlier response")
+}
+
+func TestSimilarityMatcherProtocols(t *testing.T) {
+	m := &Matcher{Type: "similarity", Reference: "Domain Name: ACME.LOCAL", Threshold: 0.8}
+	require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+
+	require.True(t, m.MatchWhois("Domain Name: ACME.LOCAL\n", nil), "Could not match similar whois response")
+	require.False(t, m.MatchWhois("No match for domain", nil), "Could match different whois response")
+	require.True(t, m.MatchFile("Domain Name: ACME.LOCAL", nil), "Could not match similar file")
+}
+
+func BenchmarkMatchWords(b *testing.B) {
+	m := &Matcher{Type: "word", Words: []string{"nuclei", "template"}, Condition: "and"}
+	require.Nil(b, m.CompileMatchers(), "Could not compile matcher")
+
+	corpus := strings.Repeat("a nuclei response body with a template ", 256)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.matchWords(corpus)
+	}
+}
+
+func BenchmarkMatchBinary(b *testing.B) {
+	m := &Matcher{Type: "binary", Binary: []string{"6e75636c6569"}}
+	require.Nil(b, m.CompileMatchers(), "Could not compile matcher")
+
+	corpus := strings.Repeat("a nuclei response body ", 256)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.matchBinary(corpus)
+	}
+}
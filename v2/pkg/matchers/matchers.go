@@ -27,11 +27,23 @@ type Matcher struct {
 	regexCompiled []*regexp.Regexp
 	// Binary are the binary characters required to be present in the response
 	Binary []string `yaml:"binary,omitempty"`
+	// binaryDecoded is the decoded variant
+	binaryDecoded []string
 	// DSL are the dsl queries
 	DSL []string `yaml:"dsl,omitempty"`
 	// dslCompiled is the compiled variant
 	dslCompiled []*govaluate.EvaluableExpression
 
+	// Reference is the content the response is compared to by similarity matchers
+	Reference string `yaml:"reference,omitempty"`
+	// Request is the number of an earlier request of the chain whose body the
+	// response is compared to by similarity matchers, instead of the reference
+	Request int `yaml:"request,omitempty"`
+	// Algorithm is the similarity measure, levenshtein (the default) or simhash
+	Algorithm string `yaml:"algorithm,omitempty"`
+	// Threshold is the similarity between 0 and 1 from which a response matches
+	Threshold float64 `yaml:"threshold,omitempty"`
+
 	// Condition is the optional condition between two matcher variables
 	//
 	// By default, the condition is assumed to be OR.
@@ -49,6 +61,10 @@ type Matcher struct {
 	// Negative specifies if the match should be reversed
 	// It will only match if the condition is not true.
 	Negative bool `yaml:"negative,omitempty"`
+
+	// RawBody matches the http body as received, still compressed and in
+	// its original charset, instead of the decoded one.
+	RawBody bool `yaml:"raw-body,omitempty"`
 }
 
 // MatcherType is the type of the matcher specified
@@ -67,16 +83,24 @@ const (
 	SizeMatcher
 	// DSLMatcher matches based upon dsl syntax
 	DSLMatcher
+	// SimilarityMatcher matches responses similar to a reference or an earlier response
+	SimilarityMatcher
 )
 
 // MatcherTypes is an table for conversion of matcher type from string.
 var MatcherTypes = map[string]MatcherType{
-	"status": StatusMatcher,
-	"size":   SizeMatcher,
-	"word":   WordsMatcher,
-	"regex":  RegexMatcher,
-	"binary": BinaryMatcher,
-	"dsl":    DSLMatcher,
+	"status":     StatusMatcher,
+	"size":       SizeMatcher,
+	"word":       WordsMatcher,
+	"regex":      RegexMatcher,
+	"binary":     BinaryMatcher,
+	"dsl":        DSLMatcher,
+	"similarity": SimilarityMatcher,
+}
+
+// GetType returns the internal type of the matcher
+func (m *Matcher) GetType() MatcherType {
+	return m.matcherType
 }
 
 // ConditionType is the type of condition for matcher
@@ -105,13 +129,31 @@ const (
 	HeaderPart
 	// AllPart matches both response body and headers of the response.
 	AllPart
+	// ConsolePart matches the console messages of a headless page.
+	ConsolePart
+	// NetworkPart matches the URLs requested by a headless page.
+	NetworkPart
+	// CachePart matches the cache status (hit or miss) of the response.
+	CachePart
+	// CertificatePart matches the certificate chain of a https response.
+	CertificatePart
+	// InteractshProtocolPart matches the protocols of the interactions with the interactsh url.
+	InteractshProtocolPart
+	// InteractshRequestPart matches the requests of the interactions with the interactsh url.
+	InteractshRequestPart
 )
 
 // PartTypes is an table for conversion of part type from string.
 var PartTypes = map[string]Part{
-	"body":   BodyPart,
-	"header": HeaderPart,
-	"all":    AllPart,
+	"body":                BodyPart,
+	"header":              HeaderPart,
+	"all":                 AllPart,
+	"console":             ConsolePart,
+	"network":             NetworkPart,
+	"cache":               CachePart,
+	"certificate":         CertificatePart,
+	"interactsh_protocol": InteractshProtocolPart,
+	"interactsh_request":  InteractshRequestPart,
 }
 
 // GetPart returns the part of the matcher
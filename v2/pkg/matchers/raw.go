@@ -0,0 +1,34 @@
+package matchers
+
+import "strings"
+
+// RawBodyVariable contains the body of a http response as received, before
+// its decompression and charset conversion
+const RawBodyVariable = "raw_body"
+
+// rawBody returns the body matched, the received one for the raw-body matchers
+func (m *Matcher) rawBody(body string, data map[string]interface{}) string {
+	if !m.RawBody {
+		return body
+	}
+	if raw, ok := data[RawBodyVariable].(string); ok {
+		return raw
+	}
+
+	return body
+}
+
+// NeedsRawBody returns true if the matcher matches on the received body,
+// with the raw-body option or in its dsl expressions.
+func (m *Matcher) NeedsRawBody() bool {
+	if m.RawBody {
+		return true
+	}
+	for _, expression := range m.DSL {
+		if strings.Contains(expression, RawBodyVariable) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,31 @@
+package matchers
+
+import (
+	"strconv"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/similarity"
+)
+
+// matchSimilarity matches a corpus at least as similar as the threshold to
+// the reference, or to the body of an earlier request found in the data.
+func (m *Matcher) matchSimilarity(corpus string, data map[string]interface{}) bool {
+	reference := m.Reference
+	if m.Request > 0 {
+		body, ok := data["body_"+strconv.Itoa(m.Request)].(string)
+		if !ok {
+			return false
+		}
+		reference = body
+	}
+
+	if m.Algorithm == "simhash" {
+		return similarity.Simhash(corpus, reference) >= m.Threshold
+	}
+
+	return similarity.Levenshtein(corpus, reference) >= m.Threshold
+}
+
+// NeedsHistory returns true if the matcher reads the responses of the earlier requests
+func (m *Matcher) NeedsHistory() bool {
+	return m.matcherType == DSLMatcher || (m.matcherType == SimilarityMatcher && m.Request > 0)
+}
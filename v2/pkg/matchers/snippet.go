@@ -0,0 +1,79 @@
+package matchers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/saferegex"
+)
+
+// maxSnippets is the maximum number of snippets returned for a matcher
+const maxSnippets = 5
+
+// HTTPCorpus returns the part of a http response the matcher is evaluated on,
+// the certificate being the chain of a https response as text
+func (m *Matcher) HTTPCorpus(resp *http.Response, body, headers, certificate string) string {
+	switch m.part {
+	case BodyPart:
+		return body
+	case HeaderPart:
+		return headers
+	case CachePart:
+		return CacheStatus(resp.Header)
+	case CertificatePart:
+		return certificate
+	}
+
+	return headers + "\n" + body
+}
+
+// Snippets returns the parts of the corpus matched by the words,
+// regexes or binary values of the matcher, with up to context bytes
+// around each of them. Other matcher types have no snippets.
+func (m *Matcher) Snippets(corpus string, context int) []string {
+	var positions [][]int
+
+	switch m.matcherType {
+	case WordsMatcher:
+		positions = literalPositions(corpus, m.Words)
+	case BinaryMatcher:
+		positions = literalPositions(corpus, m.binaryDecoded)
+	case RegexMatcher:
+		for _, regex := range m.regexCompiled {
+			positions = append(positions, saferegex.FindAllStringIndex(regex, corpus, maxSnippets)...)
+		}
+	}
+
+	var snippets []string
+
+	for _, position := range positions {
+		if len(snippets) == maxSnippets {
+			break
+		}
+
+		start, end := position[0]-context, position[1]+context
+		if start < 0 {
+			start = 0
+		}
+		if end > len(corpus) {
+			end = len(corpus)
+		}
+
+		snippets = append(snippets, corpus[start:end])
+	}
+
+	return snippets
+}
+
+// literalPositions returns the position of the first occurrence of each value in the corpus
+func literalPositions(corpus string, values []string) [][]int {
+	var positions [][]int
+
+	for _, value := range values {
+		if index := strings.Index(corpus, value); index != -1 && value != "" {
+			positions = append(positions, []int{index, index + len(value)})
+		}
+	}
+
+	return positions
+}
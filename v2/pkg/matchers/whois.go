@@ -0,0 +1,78 @@
+package matchers
+
+import (
+	"strings"
+)
+
+// whoisAliases maps the names registries give to the common whois fields
+// to a single key, so templates work across the tlds.
+var whoisAliases = map[string]string{
+	"registrar_name":                         "registrar",
+	"sponsoring_registrar":                   "registrar",
+	"created":                                "creation_date",
+	"created_date":                           "creation_date",
+	"registered":                             "creation_date",
+	"registered_on":                          "creation_date",
+	"registration_time":                      "creation_date",
+	"domain_registration_date":               "creation_date",
+	"registry_expiry_date":                   "expiration_date",
+	"registrar_registration_expiration_date": "expiration_date",
+	"expiry_date":                            "expiration_date",
+	"expires":                                "expiration_date",
+	"expires_on":                             "expiration_date",
+	"paid_till":                              "expiration_date",
+	"changed":                                "updated_date",
+	"last_modified":                          "updated_date",
+	"last_updated":                           "updated_date",
+	"name_server":                            "name_servers",
+	"nserver":                                "name_servers",
+	"nameservers":                            "name_servers",
+	"domain_status":                          "status",
+	"registrant_organization":                "registrant",
+	"org":                                    "registrant",
+}
+
+// WhoisFields parses the "key: value" lines of a whois response. The keys
+// are lowercased with underscores and the common ones renamed, the values
+// of a key found several times being joined with commas.
+func WhoisFields(response string) map[string]string {
+	fields := make(map[string]string)
+	seen := make(map[string]map[string]struct{})
+
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '%' || line[0] == '#' || line[0] == '>' {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		key = strings.NewReplacer(" ", "_", "-", "_", "/", "_").Replace(key)
+		value := strings.TrimSpace(parts[1])
+		if key == "" || value == "" {
+			continue
+		}
+		if alias, ok := whoisAliases[key]; ok {
+			key = alias
+		}
+
+		if seen[key] == nil {
+			seen[key] = make(map[string]struct{})
+		}
+		if _, ok := seen[key][value]; ok {
+			continue
+		}
+		seen[key][value] = struct{}{}
+
+		if existing, ok := fields[key]; ok {
+			fields[key] = existing + "," + value
+		} else {
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
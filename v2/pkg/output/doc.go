@@ -0,0 +1,2 @@
+// Package output contains the writers the findings of a scan are sent to
+package output
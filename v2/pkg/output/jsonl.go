@@ -0,0 +1,58 @@
+package output
+
+import (
+	"bufio"
+	"os"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// jsonMarshaler marshals the events with sorted keys like the json output
+var jsonMarshaler = jsoniter.Config{EscapeHTML: true, SortMapKeys: true}.Froze()
+
+// JSONLWriter writes each finding as a json object on its own line.
+// The request and the response are included if the scan dumps them.
+type JSONLWriter struct {
+	mutex  sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// NewJSONLWriter creates a jsonl writer for a file
+func NewJSONLWriter(path string) (Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONLWriter{file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// Write writes a finding as a line of json
+func (w *JSONLWriter) Write(event Event) error {
+	data, err := jsonMarshaler.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, err := w.writer.Write(data); err != nil {
+		return err
+	}
+	return w.writer.WriteByte('\n')
+}
+
+// Close flushes the findings and closes the file
+func (w *JSONLWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := w.writer.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
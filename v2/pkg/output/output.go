@@ -0,0 +1,134 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Event is a finding, with the same fields as in the json output
+type Event map[string]interface{}
+
+// Writer writes the findings of a scan to a destination.
+//
+// Write is called concurrently by the executers, Close once the scan is done.
+type Writer interface {
+	Write(event Event) error
+	Close() error
+}
+
+// Factory creates a writer for a destination path
+type Factory func(path string) (Writer, error)
+
+var (
+	factoriesMutex sync.RWMutex
+	factories      = map[string]Factory{
+		"jsonl": NewJSONLWriter,
+		"sarif": NewSARIFWriter,
+	}
+)
+
+// Register adds a writer for a format, replacing the existing one if any
+func Register(format string, factory Factory) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+
+	factories[strings.ToLower(format)] = factory
+}
+
+// New creates a writer of a registered format for a destination path
+func New(format, path string) (Writer, error) {
+	factoriesMutex.RLock()
+	factory, ok := factories[strings.ToLower(format)]
+	factoriesMutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %s, must be one of %s", format, strings.Join(Formats(), ", "))
+	}
+
+	return factory(path)
+}
+
+// Formats returns the registered formats
+func Formats() []string {
+	factoriesMutex.RLock()
+	defer factoriesMutex.RUnlock()
+
+	formats := make([]string, 0, len(factories))
+	for format := range factories {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// Writers sends the findings to several writers
+type Writers struct {
+	writers []Writer
+}
+
+// NewWriters creates writers from a comma separated list of format=path
+// definitions, eg. sarif=results.sarif,jsonl=findings.jsonl.
+func NewWriters(definitions string) (*Writers, error) {
+	writers := &Writers{}
+
+	for _, definition := range strings.Split(definitions, ",") {
+		if definition = strings.TrimSpace(definition); definition == "" {
+			continue
+		}
+
+		parts := strings.SplitN(definition, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			writers.Close()
+			return nil, fmt.Errorf("invalid output writer %s, must be format=path", definition)
+		}
+
+		writer, err := New(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+		if err != nil {
+			writers.Close()
+			return nil, err
+		}
+		writers.Add(writer)
+	}
+
+	return writers, nil
+}
+
+// Add adds a writer the findings are sent to
+func (w *Writers) Add(writer Writer) {
+	w.writers = append(w.writers, writer)
+}
+
+// Write sends a finding to all the writers, returning the first error
+func (w *Writers) Write(event Event) error {
+	if w == nil {
+		return nil
+	}
+
+	var firstErr error
+	for _, writer := range w.writers {
+		if err := writer.Write(event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Close closes all the writers, returning the first error
+func (w *Writers) Close() error {
+	if w == nil {
+		return nil
+	}
+
+	var firstErr error
+	for _, writer := range w.writers {
+		if err := writer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,216 @@
+package output
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/severity"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFWriter collects the findings and writes them as a SARIF 2.1.0 log on
+// close, with a rule for each template.
+type SARIFWriter struct {
+	mutex   sync.Mutex
+	path    string
+	rules   map[string]sarifRule
+	results []sarifResult
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name,omitempty"`
+	ShortDescription sarifMessage           `json:"shortDescription"`
+	FullDescription  *sarifMessage          `json:"fullDescription,omitempty"`
+	HelpURI          string                 `json:"helpUri,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// NewSARIFWriter creates a sarif writer for a file, created right away so
+// that an invalid path is reported before the scan.
+func NewSARIFWriter(path string) (Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+
+	return &SARIFWriter{path: path, rules: make(map[string]sarifRule)}, nil
+}
+
+// Write adds a finding to the log
+func (w *SARIFWriter) Write(event Event) error {
+	template := eventString(event, "template")
+	matched := eventString(event, "matched")
+	if template == "" {
+		template = "unknown"
+	}
+
+	message := fmt.Sprintf("%s matched at %s", template, matched)
+	if name := eventString(event, "matcher_name"); name != "" {
+		message = fmt.Sprintf("%s:%s matched at %s", template, name, matched)
+	}
+	result := sarifResult{
+		RuleID:    template,
+		Level:     sarifLevel(eventString(event, "severity")),
+		Message:   sarifMessage{Text: message},
+		Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: matched}}}},
+	}
+	if extracted, ok := event["extracted_results"]; ok {
+		result.Properties = map[string]interface{}{"extracted_results": extracted}
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.rules[template]; !ok {
+		w.rules[template] = newSARIFRule(template, event)
+	}
+	w.results = append(w.results, result)
+
+	return nil
+}
+
+// Close writes the log with all the findings
+func (w *SARIFWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	rules := make([]sarifRule, 0, len(w.rules))
+	for _, rule := range w.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+
+	results := w.results
+	if results == nil {
+		results = []sarifResult{}
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "nuclei",
+				InformationURI: "https://github.com/projectdiscovery/nuclei",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	data, err := jsonMarshaler.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(w.path, data, 0644)
+}
+
+// newSARIFRule describes a template from the info of one of its findings
+func newSARIFRule(template string, event Event) sarifRule {
+	rule := sarifRule{ID: template, Name: eventString(event, "name")}
+
+	rule.ShortDescription.Text = rule.Name
+	if rule.ShortDescription.Text == "" {
+		rule.ShortDescription.Text = template
+	}
+	if description := eventString(event, "description"); description != "" {
+		rule.FullDescription = &sarifMessage{Text: description}
+	}
+	if reference := eventString(event, "reference"); strings.HasPrefix(reference, "http") {
+		rule.HelpURI = strings.TrimSpace(strings.Split(reference, ",")[0])
+	}
+
+	properties := make(map[string]interface{})
+	if value := eventString(event, "severity"); value != "" {
+		properties["severity"] = value
+	}
+	if tags := eventString(event, "tags"); tags != "" {
+		properties["tags"] = strings.Split(tags, ",")
+	}
+	if len(properties) > 0 {
+		rule.Properties = properties
+	}
+
+	return rule
+}
+
+// sarifLevel maps a severity to a sarif result level
+func sarifLevel(value string) string {
+	switch {
+	case severity.AtLeast(value, "high"):
+		return "error"
+	case severity.AtLeast(value, "medium"):
+		return "warning"
+	case severity.IsValid(value):
+		return "note"
+	}
+
+	return "none"
+}
+
+// eventString returns a field of an event as a string
+func eventString(event Event, key string) string {
+	if value, ok := event[key].(string); ok {
+		return value
+	}
+
+	return ""
+}
@@ -0,0 +1,3 @@
+// Package queue receives the targets to scan from message queues and
+// publishes the findings to them
+package queue
@@ -0,0 +1,314 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// natsDefaultPort is the port of a nats server without one in its url
+	natsDefaultPort = "4222"
+	// natsDialTimeout is the time the connection to a nats server can take
+	natsDialTimeout = 10 * time.Second
+	// natsSubscription is the id of the subscription to the targets subject
+	natsSubscription = "1"
+)
+
+// natsQueue is a queue on a nats server, spoken to with the text protocol
+// of the core nats. The targets received are kept until they are consumed
+// so that the pings of the server are answered during the scans.
+type natsQueue struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	findings string
+
+	writeMutex sync.Mutex
+
+	mutex   sync.Mutex
+	pending []string
+	err     error
+
+	received  chan struct{}
+	targets   chan string
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// natsConnect is the CONNECT message of the nats protocol
+type natsConnect struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Protocol  int    `json:"protocol"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// newNATS connects to a nats server and subscribes to the targets subject
+func newNATS(address *url.URL, options *Options) (*natsQueue, error) {
+	if options.Targets == "" || options.Findings == "" {
+		return nil, errors.New("the targets and findings subjects of the queue are required")
+	}
+
+	host := address.Host
+	if address.Port() == "" {
+		host = net.JoinHostPort(address.Hostname(), natsDefaultPort)
+	}
+	conn, err := net.DialTimeout("tcp", host, natsDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &natsQueue{
+		conn:     conn,
+		reader:   bufio.NewReader(conn),
+		findings: options.Findings,
+		received: make(chan struct{}, 1),
+		targets:  make(chan string),
+		closed:   make(chan struct{}),
+	}
+	if err := n.handshake(address, options); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go n.read()
+	go n.deliver()
+
+	return n, nil
+}
+
+// handshake authenticates to the server and subscribes to the targets,
+// the PONG answering the final PING acknowledging both.
+func (n *natsQueue) handshake(address *url.URL, options *Options) error {
+	//nolint:errcheck // the handshake fails on a read timeout anyway
+	n.conn.SetDeadline(time.Now().Add(natsDialTimeout))
+	defer n.conn.SetDeadline(time.Time{}) //nolint:errcheck // the connection is closed on errors
+
+	line, err := n.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(strings.ToUpper(line), "INFO") {
+		return fmt.Errorf("unexpected nats greeting %s", line)
+	}
+
+	connect := natsConnect{Name: "nuclei", Lang: "go", Protocol: 1}
+	if address.User != nil {
+		if pass, ok := address.User.Password(); ok {
+			connect.User, connect.Pass = address.User.Username(), pass
+		} else {
+			connect.AuthToken = address.User.Username()
+		}
+	}
+	data, err := json.Marshal(connect)
+	if err != nil {
+		return err
+	}
+
+	subscribe := "SUB " + options.Targets
+	if options.Group != "" {
+		subscribe += " " + options.Group
+	}
+	if err := n.write(fmt.Sprintf("CONNECT %s\r\n%s %s\r\nPING\r\n", data, subscribe, natsSubscription)); err != nil {
+		return err
+	}
+
+	for {
+		line, err := n.readLine()
+		if err != nil {
+			return err
+		}
+		switch operation(line) {
+		case "PONG":
+			return nil
+		case "-ERR":
+			return fmt.Errorf("nats error %s", strings.TrimSpace(line[len("-ERR"):]))
+		}
+	}
+}
+
+// read reads the messages of the server until the connection is closed
+func (n *natsQueue) read() {
+	for {
+		line, err := n.readLine()
+		if err != nil {
+			n.fail(err)
+			return
+		}
+
+		switch operation(line) {
+		case "MSG":
+			payload, err := n.readPayload(line)
+			if err != nil {
+				n.fail(err)
+				return
+			}
+			n.receive(payload)
+		case "PING":
+			if err := n.write("PONG\r\n"); err != nil {
+				n.fail(err)
+				return
+			}
+		case "-ERR":
+			n.fail(fmt.Errorf("nats error %s", strings.TrimSpace(line[len("-ERR"):])))
+			return
+		}
+	}
+}
+
+// readPayload reads the payload of a MSG subject sid [reply] size line
+func (n *natsQueue) readPayload(line string) ([]byte, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 4 && len(fields) != 5 {
+		return nil, fmt.Errorf("invalid nats message %s", line)
+	}
+	size, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("invalid nats message %s", line)
+	}
+
+	// the payload is followed by a CRLF
+	payload := make([]byte, size+2)
+	if _, err := io.ReadFull(n.reader, payload); err != nil {
+		return nil, err
+	}
+
+	return payload[:size], nil
+}
+
+// receive keeps the targets of a payload, one per line
+func (n *natsQueue) receive(payload []byte) {
+	n.mutex.Lock()
+	for _, target := range strings.Split(string(payload), "\n") {
+		if target = strings.TrimSpace(target); target != "" {
+			n.pending = append(n.pending, target)
+		}
+	}
+	n.mutex.Unlock()
+
+	select {
+	case n.received <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends the kept targets to the targets channel, closing it once
+// the queue is closed
+func (n *natsQueue) deliver() {
+	defer close(n.targets)
+
+	for {
+		n.mutex.Lock()
+		var target string
+		ok := len(n.pending) > 0
+		if ok {
+			target, n.pending = n.pending[0], n.pending[1:]
+		}
+		n.mutex.Unlock()
+
+		if !ok {
+			select {
+			case <-n.received:
+				continue
+			case <-n.closed:
+				return
+			}
+		}
+
+		select {
+		case n.targets <- target:
+		case <-n.closed:
+			return
+		}
+	}
+}
+
+// Targets returns the received targets
+func (n *natsQueue) Targets() <-chan string {
+	return n.targets
+}
+
+// Publish publishes a finding to the findings subject
+func (n *natsQueue) Publish(data []byte) error {
+	if err := n.Err(); err != nil {
+		return err
+	}
+
+	return n.write(fmt.Sprintf("PUB %s %d\r\n%s\r\n", n.findings, len(data), data))
+}
+
+// Err returns the error the connection was lost with, if any
+func (n *natsQueue) Err() error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n.err
+}
+
+// Close closes the connection to the server
+func (n *natsQueue) Close() error {
+	var err error
+	n.closeOnce.Do(func() {
+		close(n.closed)
+		err = n.conn.Close()
+	})
+
+	return err
+}
+
+// fail records the error the connection was lost with, unless it was closed
+func (n *natsQueue) fail(err error) {
+	select {
+	case <-n.closed:
+		return
+	default:
+	}
+
+	n.mutex.Lock()
+	if n.err == nil {
+		n.err = err
+	}
+	n.mutex.Unlock()
+
+	n.Close()
+}
+
+// write writes a message to the server
+func (n *natsQueue) write(message string) error {
+	n.writeMutex.Lock()
+	defer n.writeMutex.Unlock()
+
+	_, err := io.WriteString(n.conn, message)
+	return err
+}
+
+// readLine reads a line of the protocol without its CRLF
+func (n *natsQueue) readLine() (string, error) {
+	line, err := n.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// operation returns the uppercase operation of a line of the protocol
+func operation(line string) string {
+	if index := strings.IndexAny(line, " \t"); index >= 0 {
+		line = line[:index]
+	}
+
+	return strings.ToUpper(line)
+}
@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNATS accepts one connection and answers the handshake of a client
+func fakeNATS(t *testing.T) (string, <-chan net.Conn, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen")
+	t.Cleanup(func() { listener.Close() })
+
+	conns := make(chan net.Conn, 1)
+	lines := make(chan string, 16)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case line == "PING":
+				conn.Write([]byte("PONG\r\n"))
+				conns <- conn
+			case strings.HasPrefix(line, "PUB "):
+				payload, _ := reader.ReadString('\n')
+				lines <- line + " " + strings.TrimRight(payload, "\r\n")
+			default:
+				lines <- line
+			}
+		}
+	}()
+
+	return "nats://token@" + listener.Addr().String(), conns, lines
+}
+
+func TestNATSQueue(t *testing.T) {
+	address, conns, lines := fakeNATS(t)
+
+	q, err := New(&Options{URL: address, Targets: "targets", Findings: "findings", Group: "workers"})
+	require.Nil(t, err, "could not connect")
+	defer q.Close()
+
+	require.Contains(t, <-lines, `"auth_token":"token"`, "could not authenticate")
+	require.Equal(t, "SUB targets workers 1", <-lines, "could not subscribe")
+	conn := <-conns
+
+	conn.Write([]byte("MSG targets 1 26\r\nhttps://a.local\nb.local:80\r\nPING\r\n"))
+	require.Equal(t, "https://a.local", <-q.Targets(), "could not receive the first target")
+	require.Equal(t, "b.local:80", <-q.Targets(), "could not receive the second target")
+	require.Equal(t, "PONG", <-lines, "could not answer the ping")
+
+	require.Nil(t, q.Publish([]byte(`{"template":"x"}`)), "could not publish")
+	require.Equal(t, `PUB findings 16 {"template":"x"}`, <-lines, "could not publish the finding")
+
+	conn.Close()
+	select {
+	case _, ok := <-q.Targets():
+		require.False(t, ok, "received a target after the connection was lost")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the targets were not closed with the connection")
+	}
+	require.NotNil(t, q.Err(), "the lost connection was not reported")
+}
+
+func TestUnsupportedQueue(t *testing.T) {
+	_, err := New(&Options{URL: "kafka://localhost:9092", Targets: "targets", Findings: "findings"})
+	require.NotNil(t, err, "an unsupported queue was accepted")
+}
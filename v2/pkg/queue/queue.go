@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Options is the configuration of the queue of a worker
+type Options struct {
+	// URL is the address of the queue server, eg. nats://localhost:4222
+	URL string
+	// Targets is the subject the targets are received from, one or more per message
+	Targets string
+	// Findings is the subject the json findings are published to
+	Findings string
+	// Group is the queue group sharing the targets between the workers
+	Group string
+}
+
+// Queue is a message queue the targets are received from and the findings
+// published to
+type Queue interface {
+	// Targets returns the received targets, closed once the queue is closed or its connection lost
+	Targets() <-chan string
+	// Publish publishes a finding
+	Publish(data []byte) error
+	// Err returns the error the connection was lost with, if any
+	Err() error
+	// Close closes the connection to the queue
+	Close() error
+}
+
+// New connects to the queue of an url, nats:// being the only scheme
+// supported for now
+func New(options *Options) (Queue, error) {
+	parsed, err := url.Parse(options.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parsed.Scheme {
+	case "nats":
+		return newNATS(parsed, options)
+	default:
+		return nil, fmt.Errorf("unsupported queue %s, only nats:// queues are supported", options.URL)
+	}
+}
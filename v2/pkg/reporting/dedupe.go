@@ -0,0 +1,80 @@
+package reporting
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// dedupeDB records the issues filed for each template and host, so that the
+// next scans comment them instead of filing them again.
+type dedupeDB struct {
+	path    string
+	Issues  map[string]map[string]*dedupeRecord `json:"issues"`
+	changed bool
+}
+
+// dedupeRecord is an issue filed in a tracker
+type dedupeRecord struct {
+	Reference string    `json:"reference"`
+	Created   time.Time `json:"created"`
+}
+
+// openDedupeDB reads a dedupe database, empty if the file doesn't exist yet
+func openDedupeDB(path string) (*dedupeDB, error) {
+	db := &dedupeDB{path: path, Issues: make(map[string]map[string]*dedupeRecord)}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, db); err != nil {
+		return nil, err
+	}
+	if db.Issues == nil {
+		db.Issues = make(map[string]map[string]*dedupeRecord)
+	}
+
+	return db, nil
+}
+
+// issue returns the reference of the issue filed in a tracker for a key
+func (db *dedupeDB) issue(tracker, key string) (string, bool) {
+	record, ok := db.Issues[tracker][key]
+	if !ok {
+		return "", false
+	}
+
+	return record.Reference, true
+}
+
+// record stores the reference of the issue filed in a tracker for a key
+func (db *dedupeDB) record(tracker, key, reference string) {
+	if db.Issues[tracker] == nil {
+		db.Issues[tracker] = make(map[string]*dedupeRecord)
+	}
+	db.Issues[tracker][key] = &dedupeRecord{Reference: reference, Created: time.Now().UTC()}
+	db.changed = true
+}
+
+// save writes the database if issues were filed
+func (db *dedupeDB) save() error {
+	if !db.changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(db.path, data, 0600); err != nil {
+		return err
+	}
+	db.changed = false
+
+	return nil
+}
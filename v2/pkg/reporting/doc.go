@@ -0,0 +1,2 @@
+// Package reporting files the findings of the scans as issues in trackers
+package reporting
@@ -0,0 +1,84 @@
+package reporting
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultGitHubURL is the api of github.com
+const defaultGitHubURL = "https://api.github.com"
+
+// gitHubMaxBody is the maximum number of characters of an issue or a comment body
+const gitHubMaxBody = 65536
+
+// GitHubOptions configures the filing of issues in a github repository
+type GitHubOptions struct {
+	// URL is the api url, set for github enterprise
+	URL string `yaml:"url,omitempty"`
+	// Token is a token allowed to create issues in the repository
+	Token string `yaml:"token"`
+	// Owner is the user or organization owning the repository
+	Owner string `yaml:"owner"`
+	// Repository is the name of the repository
+	Repository string `yaml:"repository"`
+	// Labels are added to the issues
+	Labels []string `yaml:"labels,omitempty"`
+}
+
+type gitHub struct {
+	options *GitHubOptions
+	client  *http.Client
+	base    string
+}
+
+func newGitHub(options *GitHubOptions, client *http.Client) (*gitHub, error) {
+	if options.Token == "" || options.Owner == "" || options.Repository == "" {
+		return nil, errors.New("github reporting needs a token, an owner and a repository")
+	}
+
+	base := options.URL
+	if base == "" {
+		base = defaultGitHubURL
+	}
+	base = strings.TrimSuffix(base, "/") + "/repos/" + options.Owner + "/" + options.Repository + "/issues"
+
+	return &gitHub{options: options, client: client, base: base}, nil
+}
+
+func (g *gitHub) Name() string {
+	return "github"
+}
+
+func (g *gitHub) MaxBodyLength() int {
+	return gitHubMaxBody
+}
+
+func (g *gitHub) headers() map[string]string {
+	return map[string]string{"Authorization": "token " + g.options.Token}
+}
+
+func (g *gitHub) CreateIssue(title, body string) (string, error) {
+	payload := map[string]interface{}{"title": title, "body": body}
+	if len(g.options.Labels) > 0 {
+		payload["labels"] = g.options.Labels
+	}
+
+	var issue struct {
+		Number int `json:"number"`
+	}
+	if err := postJSON(g.client, g.base, g.headers(), payload, &issue); err != nil {
+		return "", err
+	}
+	if issue.Number == 0 {
+		return "", errors.New("no issue number returned")
+	}
+
+	return strconv.Itoa(issue.Number), nil
+}
+
+func (g *gitHub) CommentIssue(reference, body string) error {
+	return postJSON(g.client, fmt.Sprintf("%s/%s/comments", g.base, reference), g.headers(), map[string]string{"body": body}, nil)
+}
@@ -0,0 +1,83 @@
+package reporting
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultGitLabURL is the url of gitlab.com
+const defaultGitLabURL = "https://gitlab.com"
+
+// gitLabMaxBody is the maximum number of characters of an issue description or a note
+const gitLabMaxBody = 1000000
+
+// GitLabOptions configures the filing of issues in a gitlab project
+type GitLabOptions struct {
+	// URL is the url of the gitlab instance
+	URL string `yaml:"url,omitempty"`
+	// Token is a token allowed to create issues in the project
+	Token string `yaml:"token"`
+	// Project is the id or the path of the project, eg. group/project
+	Project string `yaml:"project"`
+	// Labels are added to the issues
+	Labels []string `yaml:"labels,omitempty"`
+}
+
+type gitLab struct {
+	options *GitLabOptions
+	client  *http.Client
+	base    string
+}
+
+func newGitLab(options *GitLabOptions, client *http.Client) (*gitLab, error) {
+	if options.Token == "" || options.Project == "" {
+		return nil, errors.New("gitlab reporting needs a token and a project")
+	}
+
+	base := options.URL
+	if base == "" {
+		base = defaultGitLabURL
+	}
+	base = strings.TrimSuffix(base, "/") + "/api/v4/projects/" + url.PathEscape(options.Project) + "/issues"
+
+	return &gitLab{options: options, client: client, base: base}, nil
+}
+
+func (g *gitLab) Name() string {
+	return "gitlab"
+}
+
+func (g *gitLab) MaxBodyLength() int {
+	return gitLabMaxBody
+}
+
+func (g *gitLab) headers() map[string]string {
+	return map[string]string{"PRIVATE-TOKEN": g.options.Token}
+}
+
+func (g *gitLab) CreateIssue(title, body string) (string, error) {
+	payload := map[string]interface{}{"title": title, "description": body}
+	if len(g.options.Labels) > 0 {
+		payload["labels"] = strings.Join(g.options.Labels, ",")
+	}
+
+	var issue struct {
+		IID int `json:"iid"`
+	}
+	if err := postJSON(g.client, g.base, g.headers(), payload, &issue); err != nil {
+		return "", err
+	}
+	if issue.IID == 0 {
+		return "", errors.New("no issue iid returned")
+	}
+
+	return strconv.Itoa(issue.IID), nil
+}
+
+func (g *gitLab) CommentIssue(reference, body string) error {
+	return postJSON(g.client, fmt.Sprintf("%s/%s/notes", g.base, reference), g.headers(), map[string]string{"body": body}, nil)
+}
@@ -0,0 +1,89 @@
+package reporting
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// defaultJiraIssueType is the type of the issues filed in jira
+const defaultJiraIssueType = "Bug"
+
+// jiraMaxBody is the maximum number of characters of a description or a comment
+const jiraMaxBody = 32767
+
+// JiraOptions configures the filing of issues in a jira project
+type JiraOptions struct {
+	// URL is the url of the jira instance
+	URL string `yaml:"url"`
+	// Email is the account the token belongs to
+	Email string `yaml:"email"`
+	// Token is an api token of the account
+	Token string `yaml:"token"`
+	// Project is the key of the project
+	Project string `yaml:"project"`
+	// IssueType is the type of the issues, Bug by default
+	IssueType string `yaml:"issue-type,omitempty"`
+	// Labels are added to the issues
+	Labels []string `yaml:"labels,omitempty"`
+}
+
+type jira struct {
+	options *JiraOptions
+	client  *http.Client
+	base    string
+}
+
+func newJira(options *JiraOptions, client *http.Client) (*jira, error) {
+	if options.URL == "" || options.Email == "" || options.Token == "" || options.Project == "" {
+		return nil, errors.New("jira reporting needs an url, an email, a token and a project")
+	}
+
+	return &jira{options: options, client: client, base: strings.TrimSuffix(options.URL, "/") + "/rest/api/2/issue"}, nil
+}
+
+func (j *jira) Name() string {
+	return "jira"
+}
+
+func (j *jira) MaxBodyLength() int {
+	return jiraMaxBody
+}
+
+func (j *jira) headers() map[string]string {
+	credentials := base64.StdEncoding.EncodeToString([]byte(j.options.Email + ":" + j.options.Token))
+	return map[string]string{"Authorization": "Basic " + credentials}
+}
+
+func (j *jira) CreateIssue(title, body string) (string, error) {
+	issueType := j.options.IssueType
+	if issueType == "" {
+		issueType = defaultJiraIssueType
+	}
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": j.options.Project},
+		"summary":     title,
+		"description": body,
+		"issuetype":   map[string]string{"name": issueType},
+	}
+	if len(j.options.Labels) > 0 {
+		fields["labels"] = j.options.Labels
+	}
+
+	var issue struct {
+		Key string `json:"key"`
+	}
+	if err := postJSON(j.client, j.base, j.headers(), map[string]interface{}{"fields": fields}, &issue); err != nil {
+		return "", err
+	}
+	if issue.Key == "" {
+		return "", errors.New("no issue key returned")
+	}
+
+	return issue.Key, nil
+}
+
+func (j *jira) CommentIssue(reference, body string) error {
+	return postJSON(j.client, j.base+"/"+reference+"/comment", j.headers(), map[string]string{"body": body}, nil)
+}
@@ -0,0 +1,374 @@
+package reporting
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/severity"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultDedupeDB is the file the filed issues are recorded in
+const defaultDedupeDB = ".nuclei-issues.json"
+
+// requestTimeout is the time a request to a tracker can take
+const requestTimeout = 30 * time.Second
+
+// queueSize is the number of findings waiting to be filed before Write blocks
+const queueSize = 1024
+
+// truncatedMarker ends the evidence cut to fit in the body of an issue
+const truncatedMarker = "\n... [truncated]"
+
+// evidenceFormat is the collapsed markdown block of a request or a response
+const evidenceFormat = "<details><summary>%s</summary>\n\n```\n%s\n```\n</details>\n\n"
+
+// Options is the reporting configuration, read from a yaml file
+type Options struct {
+	// Severity is the lowest severity of the findings filed, all if empty
+	Severity string `yaml:"severity,omitempty"`
+	// DedupeDB is the file the filed issues are recorded in across scans
+	DedupeDB string `yaml:"dedupe-db,omitempty"`
+	// GitHub files the issues in a github repository
+	GitHub *GitHubOptions `yaml:"github,omitempty"`
+	// GitLab files the issues in a gitlab project
+	GitLab *GitLabOptions `yaml:"gitlab,omitempty"`
+	// Jira files the issues in a jira project
+	Jira *JiraOptions `yaml:"jira,omitempty"`
+}
+
+// Tracker is an issue tracker the findings are filed in
+type Tracker interface {
+	// Name returns the name of the tracker, used in the dedupe database
+	Name() string
+	// CreateIssue files a new issue and returns its reference
+	CreateIssue(title, body string) (string, error)
+	// CommentIssue adds a comment to an existing issue
+	CommentIssue(reference, body string) error
+	// MaxBodyLength returns the maximum number of characters of an issue or a comment body
+	MaxBodyLength() int
+}
+
+// Reporter is an output writer filing an issue for each template and host
+// in the trackers. A finding already filed by a previous scan is added as a
+// comment to its issue instead.
+//
+// The findings are filed in the background, in the order they are written,
+// so that the executers don't wait for the trackers.
+type Reporter struct {
+	mutex    sync.Mutex
+	severity string
+	trackers []Tracker
+	db       *dedupeDB
+	// seen are the keys reported in this scan, only reported once
+	seen map[string]struct{}
+	// queue contains the findings waiting to be filed
+	queue  chan output.Event
+	done   chan struct{}
+	closed bool
+}
+
+// ReadOptions reads the reporting configuration from a yaml file
+func ReadOptions(file string) (*Options, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	options := &Options{}
+	if err := yaml.NewDecoder(f).Decode(options); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// New creates a reporter for the trackers of the options
+func New(options *Options) (*Reporter, error) {
+	if options.Severity != "" && !severity.IsValid(options.Severity) {
+		return nil, fmt.Errorf("invalid reporting severity %s", options.Severity)
+	}
+
+	client := &http.Client{Timeout: requestTimeout}
+
+	var trackers []Tracker
+	if options.GitHub != nil {
+		tracker, err := newGitHub(options.GitHub, client)
+		if err != nil {
+			return nil, err
+		}
+		trackers = append(trackers, tracker)
+	}
+	if options.GitLab != nil {
+		tracker, err := newGitLab(options.GitLab, client)
+		if err != nil {
+			return nil, err
+		}
+		trackers = append(trackers, tracker)
+	}
+	if options.Jira != nil {
+		tracker, err := newJira(options.Jira, client)
+		if err != nil {
+			return nil, err
+		}
+		trackers = append(trackers, tracker)
+	}
+	if len(trackers) == 0 {
+		return nil, errors.New("no issue tracker configured")
+	}
+
+	path := options.DedupeDB
+	if path == "" {
+		path = defaultDedupeDB
+	}
+	db, err := openDedupeDB(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReporter(options.Severity, trackers, db), nil
+}
+
+// newReporter creates a reporter and starts filing its findings
+func newReporter(severity string, trackers []Tracker, db *dedupeDB) *Reporter {
+	r := &Reporter{
+		severity: severity,
+		trackers: trackers,
+		db:       db,
+		seen:     make(map[string]struct{}),
+		queue:    make(chan output.Event, queueSize),
+		done:     make(chan struct{}),
+	}
+	go r.run()
+
+	return r
+}
+
+// Write queues a finding to be filed in the trackers, blocking only while
+// the queue is full.
+func (r *Reporter) Write(event output.Event) error {
+	if r.severity != "" && !severity.AtLeast(eventString(event, "severity"), r.severity) {
+		return nil
+	}
+	key := eventKey(event)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.seen[key]; ok || r.closed {
+		return nil
+	}
+	r.seen[key] = struct{}{}
+	r.queue <- event
+
+	return nil
+}
+
+// run files the queued findings until the reporter is closed
+func (r *Reporter) run() {
+	defer close(r.done)
+
+	for event := range r.queue {
+		if err := r.file(event); err != nil {
+			gologger.Errorf("%s\n", err)
+		}
+	}
+}
+
+// eventKey returns the template and host a finding is filed once for
+func eventKey(event output.Event) string {
+	return eventString(event, "template") + "|" + eventHost(eventString(event, "matched"))
+}
+
+// file files a finding in the trackers, or comments its existing issues
+func (r *Reporter) file(event output.Event) error {
+	key := eventKey(event)
+	host := eventHost(eventString(event, "matched"))
+	title := fmt.Sprintf("[%s] %s on %s", eventString(event, "severity"), issueName(event), host)
+
+	var errs []string
+	for _, tracker := range r.trackers {
+		if reference, ok := r.db.issue(tracker.Name(), key); ok {
+			comment := "Found again on " + time.Now().UTC().Format(time.RFC3339) + "\n\n"
+			body := issueBody(event, tracker.MaxBodyLength()-utf8.RuneCountInString(comment))
+			if err := tracker.CommentIssue(reference, comment+body); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", tracker.Name(), err))
+			}
+			continue
+		}
+
+		reference, err := tracker.CreateIssue(title, issueBody(event, tracker.MaxBodyLength()))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", tracker.Name(), err))
+			continue
+		}
+		r.db.record(tracker.Name(), key, reference)
+		// the issue is recorded at once, an interrupted scan would file it again otherwise
+		if err := r.db.save(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: could not save the dedupe database: %s", tracker.Name(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("could not report %s: %s", key, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Close files the queued findings and saves the filed issues to the dedupe database
+func (r *Reporter) Close() error {
+	r.mutex.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mutex.Unlock()
+
+	<-r.done
+
+	return r.db.save()
+}
+
+// issueName returns the name of the template of a finding
+func issueName(event output.Event) string {
+	if name := eventString(event, "name"); name != "" {
+		return name
+	}
+
+	return eventString(event, "template")
+}
+
+// issueBody describes a finding in markdown in at most limit characters,
+// the request and the response being truncated to fit.
+func issueBody(event output.Event, limit int) string {
+	head, tail := issueHead(event), issueTail(event)
+
+	var parts, values []string
+	budget := limit - utf8.RuneCountInString(head) - utf8.RuneCountInString(tail)
+	for _, part := range []string{"request", "response"} {
+		if value := eventString(event, part); value != "" {
+			parts = append(parts, part)
+			values = append(values, value)
+			budget -= utf8.RuneCountInString(fmt.Sprintf(evidenceFormat, part, ""))
+		}
+	}
+
+	// the shortest evidence is truncated first, what it doesn't use is left to the other
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return len(values[order[i]]) < len(values[order[j]]) })
+	for i, index := range order {
+		values[index] = truncate(values[index], budget/(len(order)-i))
+		budget -= utf8.RuneCountInString(values[index])
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString(head)
+	for i, part := range parts {
+		fmt.Fprintf(builder, evidenceFormat, part, values[i])
+	}
+	builder.WriteString(tail)
+
+	return builder.String()
+}
+
+// issueHead describes the template and the results of a finding in markdown
+func issueHead(event output.Event) string {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "**Template:** %s\n\n", eventString(event, "template"))
+	fmt.Fprintf(builder, "**Matched:** %s\n\n", eventString(event, "matched"))
+	if value := eventString(event, "severity"); value != "" {
+		fmt.Fprintf(builder, "**Severity:** %s\n\n", value)
+	}
+	if name := eventString(event, "matcher_name"); name != "" {
+		fmt.Fprintf(builder, "**Matcher:** %s\n\n", name)
+	}
+	if description := eventString(event, "description"); description != "" {
+		fmt.Fprintf(builder, "%s\n\n", description)
+	}
+	if extracted, ok := event["extracted_results"].([]string); ok && len(extracted) > 0 {
+		builder.WriteString("**Extracted results:**\n\n")
+		for _, result := range extracted {
+			fmt.Fprintf(builder, "- %s\n", result)
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// issueTail lists the references and the identifiers of a finding in markdown
+func issueTail(event output.Event) string {
+	builder := &strings.Builder{}
+
+	if reference := eventString(event, "reference"); reference != "" {
+		fmt.Fprintf(builder, "**References:** %s\n\n", reference)
+	}
+
+	var fields []string
+	for _, key := range []string{"fingerprint", "scan_id"} {
+		if value := eventString(event, key); value != "" {
+			fields = append(fields, key+": "+value)
+		}
+	}
+	if len(fields) > 0 {
+		builder.WriteString(strings.Join(fields, ", "))
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// truncate cuts a value to at most limit characters, ending it with the marker
+func truncate(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+
+	limit -= utf8.RuneCountInString(truncatedMarker)
+	if limit <= 0 {
+		return ""
+	}
+
+	count := 0
+	for i := range value {
+		if count == limit {
+			return value[:i] + truncatedMarker
+		}
+		count++
+	}
+
+	return value
+}
+
+// eventHost returns the host of the matched target of a finding
+func eventHost(matched string) string {
+	if parsed, err := url.Parse(matched); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+
+	return matched
+}
+
+// eventString returns a field of an event as a string
+func eventString(event output.Event, key string) string {
+	if value, ok := event[key].(string); ok {
+		return value
+	}
+
+	return ""
+}
@@ -0,0 +1,111 @@
+package reporting
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTracker records the bodies of the issues filed in it
+type fakeTracker struct {
+	limit  int
+	bodies []string
+}
+
+func (f *fakeTracker) Name() string { return "fake" }
+
+func (f *fakeTracker) MaxBodyLength() int { return f.limit }
+
+func (f *fakeTracker) CreateIssue(title, body string) (string, error) {
+	f.bodies = append(f.bodies, body)
+	return "1", nil
+}
+
+func (f *fakeTracker) CommentIssue(reference, body string) error {
+	f.bodies = append(f.bodies, body)
+	return nil
+}
+
+func TestIssueBodyTruncation(t *testing.T) {
+	event := output.Event{
+		"template": "exposed-logs",
+		"matched":  "https://acme.local/logs",
+		"request":  "GET /logs HTTP/1.1\r\nHost: acme.local\r\n\r\n",
+		"response": "HTTP/1.1 200 OK\r\n\r\n" + strings.Repeat("é", 100000),
+	}
+
+	for _, limit := range []int{gitHubMaxBody, gitLabMaxBody, jiraMaxBody} {
+		body := issueBody(event, limit)
+		require.True(t, utf8.RuneCountInString(body) <= limit, "the body exceeds %d characters", limit)
+		require.True(t, strings.Contains(body, "GET /logs HTTP/1.1"), "the short request was truncated")
+		if limit < 100000 {
+			require.True(t, strings.Contains(body, truncatedMarker), "the long response was not marked truncated")
+		}
+	}
+}
+
+func TestDedupeSavedOnRecord(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-reporting-")
+	require.Nil(t, err, "could not create directory")
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "issues.json")
+	db, err := openDedupeDB(path)
+	require.Nil(t, err, "could not open dedupe database")
+
+	tracker := &fakeTracker{limit: jiraMaxBody}
+	reporter := newReporter("", []Tracker{tracker}, db)
+	defer reporter.Close()
+	require.Nil(t, reporter.Write(output.Event{"template": "exposed-logs", "matched": "https://acme.local/logs"}), "could not report")
+
+	// the issue is filed in the background, the database is saved without closing the reporter
+	require.Eventually(t, func() bool {
+		saved, err := openDedupeDB(path)
+		if err != nil {
+			return false
+		}
+		_, ok := saved.issue("fake", "exposed-logs|acme.local")
+		return ok
+	}, 5*time.Second, 10*time.Millisecond, "the filed issue is missing from the saved dedupe database")
+}
+
+func TestReporterFilesInBackground(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-reporting-")
+	require.Nil(t, err, "could not create directory")
+	defer os.RemoveAll(directory)
+
+	db, err := openDedupeDB(filepath.Join(directory, "issues.json"))
+	require.Nil(t, err, "could not open dedupe database")
+
+	release := make(chan struct{})
+	tracker := &blockingTracker{fakeTracker: fakeTracker{limit: jiraMaxBody}, release: release}
+	reporter := newReporter("", []Tracker{tracker}, db)
+
+	start := time.Now()
+	for _, host := range []string{"a.acme.local", "b.acme.local", "c.acme.local"} {
+		require.Nil(t, reporter.Write(output.Event{"template": "exposed-logs", "matched": "https://" + host}), "could not report")
+	}
+	require.True(t, time.Since(start) < time.Second, "the findings waited for the tracker")
+
+	close(release)
+	require.Nil(t, reporter.Close(), "could not close the reporter")
+	require.Len(t, tracker.bodies, 3, "the queued findings were not filed on close")
+}
+
+// blockingTracker is a tracker whose requests wait to be released
+type blockingTracker struct {
+	fakeTracker
+	release chan struct{}
+}
+
+func (b *blockingTracker) CreateIssue(title, body string) (string, error) {
+	<-b.release
+	return b.fakeTracker.CreateIssue(title, body)
+}
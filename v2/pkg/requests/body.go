@@ -0,0 +1,94 @@
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// bodyFilePrefix marks a body read from a file instead of written inline
+const bodyFilePrefix = "@file:"
+
+// errRewind is returned when a file body is seeked anywhere but its start
+var errRewind = errors.New("file bodies can only be rewound")
+
+// ResolveBodyFile checks the file of a body written as @file:path, a
+// relative path being relative to the directory of the template.
+func (r *BulkHTTPRequest) ResolveBodyFile(templatePath string) error {
+	r.bodyFile, r.bodySize = "", 0
+	if !strings.HasPrefix(r.Body, bodyFilePrefix) {
+		return nil
+	}
+
+	file := strings.TrimSpace(strings.TrimPrefix(r.Body, bodyFilePrefix))
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(filepath.Dir(templatePath), file)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("could not read body file: %s", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("body file %s is a directory", file)
+	}
+
+	r.bodyFile, r.bodySize = file, info.Size()
+	return nil
+}
+
+// fileBody streams a request body from a file. The file is opened on the
+// first read and closed once read, and rewinding it lets the retries send
+// it again without holding the content in memory.
+type fileBody struct {
+	path string
+	file *os.File
+	done bool
+}
+
+// Read reads the next bytes of the file
+func (b *fileBody) Read(p []byte) (int, error) {
+	if b.done {
+		return 0, io.EOF
+	}
+	if b.file == nil {
+		file, err := os.Open(b.path)
+		if err != nil {
+			return 0, err
+		}
+		b.file = file
+	}
+
+	n, err := b.file.Read(p)
+	if err == io.EOF {
+		b.done = true
+		b.Close()
+	}
+
+	return n, err
+}
+
+// Seek rewinds the body to the start of the file
+func (b *fileBody) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekStart {
+		return 0, errRewind
+	}
+	b.done = false
+
+	return 0, b.Close()
+}
+
+// Close closes the file if it's open
+func (b *fileBody) Close() error {
+	if b.file == nil {
+		return nil
+	}
+
+	err := b.file.Close()
+	b.file = nil
+
+	return err
+}
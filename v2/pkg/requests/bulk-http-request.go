@@ -2,6 +2,7 @@ package requests
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -10,14 +11,14 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Knetic/govaluate"
-	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
-	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
 	"github.com/projectdiscovery/nuclei/v2/pkg/syncedreadcloser"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
 	"github.com/projectdiscovery/rawhttp"
 	retryablehttp "github.com/projectdiscovery/retryablehttp-go"
 )
@@ -27,39 +28,50 @@ const (
 	three = 3
 )
 
+const (
+	// HTTP11Protocol sends the requests over http/1.1 only
+	HTTP11Protocol = "http1.1"
+	// HTTP2Protocol sends the requests over http2 only
+	HTTP2Protocol = "http2"
+)
+
+// The transports of a fallback ladder
+const (
+	// HTTPSTransport sends the requests over tls
+	HTTPSTransport = "https"
+	// HTTPTransport sends the requests over cleartext
+	HTTPTransport = "http"
+	// H2CTransport sends the requests over cleartext http2 with prior knowledge
+	H2CTransport = "h2c"
+)
+
 var urlWithPortRgx = regexp.MustCompile(`{{BaseURL}}:(\d+)`)
 
 // BulkHTTPRequest contains a request to be made from a template
 type BulkHTTPRequest struct {
 	// Path contains the path/s for the request
 	Path []string `yaml:"path"`
-	// Matchers contains the detection mechanism for the request to identify
-	// whether the request was successful
-	Matchers []*matchers.Matcher `yaml:"matchers,omitempty"`
-	// Extractors contains the extraction mechanism for the request to identify
-	// and extract parts of the response.
-	Extractors []*extractors.Extractor `yaml:"extractors,omitempty"`
+	// Operators contains the matchers and the extractors of the request
+	Operators `yaml:",inline"`
 	// Raw contains raw requests
 	Raw  []string `yaml:"raw,omitempty"`
 	Name string   `yaml:"Name,omitempty"`
 	// AttackType is the attack type
-	// Sniper, PitchFork and ClusterBomb. Default is Sniper
+	// Sniper, PitchFork, ClusterBomb and BatteringRam. Default is Sniper
 	AttackType string `yaml:"attack,omitempty"`
 	// Method is the request method, whether GET, POST, PUT, etc
 	Method string `yaml:"method"`
-	// Body is an optional parameter which contains the request body for POST methods, etc
+	// Body is an optional parameter which contains the request body for POST methods, etc.
+	// A body written as @file:path is streamed from the file.
 	Body string `yaml:"body,omitempty"`
-	// MatchersCondition is the condition of the matchers
-	// whether to use AND or OR. Default is OR.
-	MatchersCondition string `yaml:"matchers-condition,omitempty"`
+	// Multipart contains the fields of a multipart/form-data body
+	Multipart []*MultipartField `yaml:"multipart,omitempty"`
 	// attackType is internal attack type
 	attackType generators.Type
 	// Path contains the path/s for the request variables
 	Payloads map[string]interface{} `yaml:"payloads,omitempty"`
 	// Headers contains headers to send with the request
 	Headers map[string]string `yaml:"headers,omitempty"`
-	// matchersCondition is internal condition for the matchers.
-	matchersCondition matchers.ConditionType
 	// MaxRedirects is the maximum number of redirects that should be followed.
 	MaxRedirects                  int `yaml:"max-redirects,omitempty"`
 	PipelineConcurrentConnections int `yaml:"pipeline-concurrent-connections,omitempty"`
@@ -85,16 +97,79 @@ type BulkHTTPRequest struct {
 	Race bool `yaml:"race,omitempty"`
 	// Number of same request to send in race condition attack
 	RaceNumberRequests int `yaml:"race_count,omitempty"`
+	// DisablePathAutomerge disables merging the input URL path with the template path
+	DisablePathAutomerge bool `yaml:"disable-path-automerge,omitempty"`
+	// SkipURLEncode sends the request path exactly as written without normalizing or escaping it
+	SkipURLEncode bool `yaml:"skip-url-encode,omitempty"`
+	// FreshConnection sends every request on a new connection which is closed after the response,
+	// so no state left on a connection by a previous request can affect the next one
+	FreshConnection bool `yaml:"fresh-connection,omitempty"`
+	// CacheVerify requests the URL again without the headers and body of the request after each
+	// response, and runs the matchers on that response to confirm the cache was poisoned
+	CacheVerify bool `yaml:"cache-verify,omitempty"`
+	// Delay is the pause between the requests sent to a target, either fixed (2s)
+	// or a range (2s-5s) in which a random duration is picked before each request
+	Delay string `yaml:"delay,omitempty"`
+	// delayMin and delayMax are the bounds of the parsed delay
+	delayMin, delayMax time.Duration
+	// TLS overrides the global tls settings for the request
+	TLS *tlsconfig.Options `yaml:"tls,omitempty"`
+	// CSRF extracts the csrf token of each response and adds it to the form
+	// bodies of the next requests, along with the csrf_token and csrf_name variables
+	CSRF bool `yaml:"csrf,omitempty"`
+	// Protocol forces the http version of the requests, http1.1 or http2, the
+	// version being negotiated with the server if unset. The http2 requests to
+	// http URLs are sent over cleartext with prior knowledge (h2c).
+	Protocol string `yaml:"protocol,omitempty"`
+	// Fallback lists the transports tried in order until one connects to the
+	// target, among https, http and h2c. The transport used is exposed to the
+	// matchers as the transport variable.
+	Fallback []string `yaml:"fallback,omitempty"`
+	// bodyFile and bodySize are the path and size of a body streamed from a file
+	bodyFile string
+	bodySize int64
 }
 
-// GetMatchersCondition returns the condition for the matcher
-func (r *BulkHTTPRequest) GetMatchersCondition() matchers.ConditionType {
-	return r.matchersCondition
+// ValidateProtocol checks the protocol of the request, the unsafe and the
+// pipelined requests being written on the connections as http/1.1
+func (r *BulkHTTPRequest) ValidateProtocol() error {
+	switch r.Protocol {
+	case "", HTTP11Protocol:
+		return nil
+	case HTTP2Protocol:
+		if r.Unsafe || r.Pipeline {
+			return fmt.Errorf("http2 can't be used with unsafe or pipeline requests")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown http protocol %s", r.Protocol)
 }
 
-// SetMatchersCondition sets the condition for the matcher
-func (r *BulkHTTPRequest) SetMatchersCondition(condition matchers.ConditionType) {
-	r.matchersCondition = condition
+// ValidateFallback checks the transports of the fallback ladder, which picks
+// the protocol itself and can't be used with the raw connections.
+func (r *BulkHTTPRequest) ValidateFallback() error {
+	if len(r.Fallback) == 0 {
+		return nil
+	}
+	if r.Protocol != "" || r.Unsafe || r.Pipeline {
+		return fmt.Errorf("fallback can't be used with protocol, unsafe or pipeline requests")
+	}
+
+	seen := make(map[string]struct{}, len(r.Fallback))
+	for _, transport := range r.Fallback {
+		switch transport {
+		case HTTPSTransport, HTTPTransport, H2CTransport:
+		default:
+			return fmt.Errorf("unknown fallback transport %s", transport)
+		}
+		if _, ok := seen[transport]; ok {
+			return fmt.Errorf("fallback transport %s is listed twice", transport)
+		}
+		seen[transport] = struct{}{}
+	}
+
+	return nil
 }
 
 // GetAttackType returns the attack
@@ -114,20 +189,17 @@ func (r *BulkHTTPRequest) GetRequestCount() int64 {
 
 // MakeHTTPRequest makes the HTTP request
 func (r *BulkHTTPRequest) MakeHTTPRequest(baseURL string, dynamicValues map[string]interface{}, data string) (*HTTPRequest, error) {
-	ctx := context.Background()
+	return r.MakeHTTPRequestContext(context.Background(), baseURL, dynamicValues, data)
+}
 
-	parsed, err := url.Parse(baseURL)
+// MakeHTTPRequestContext makes the HTTP request bound to a context, the
+// request being cancelled with it.
+func (r *BulkHTTPRequest) MakeHTTPRequestContext(ctx context.Context, baseURL string, dynamicValues map[string]interface{}, data string) (*HTTPRequest, error) {
+	values, err := r.requestValues(baseURL, dynamicValues, data)
 	if err != nil {
 		return nil, err
 	}
 
-	hostname := parsed.Host
-
-	values := generators.MergeMaps(dynamicValues, map[string]interface{}{
-		"BaseURL":  baseURLWithTemplatePrefs(data, parsed),
-		"Hostname": hostname,
-	})
-
 	// if data contains \n it's a raw request
 	if strings.Contains(data, "\n") {
 		return r.makeHTTPRequestFromRaw(ctx, baseURL, data, values)
@@ -136,13 +208,51 @@ func (r *BulkHTTPRequest) MakeHTTPRequest(baseURL string, dynamicValues map[stri
 	return r.makeHTTPRequestFromModel(ctx, data, values)
 }
 
+// MakeHTTPRequestWithPayloads makes the HTTP request with the given payload
+// values instead of reading the next ones from the generator of the URL,
+// so a request can be built again.
+func (r *BulkHTTPRequest) MakeHTTPRequestWithPayloads(baseURL string, dynamicValues map[string]interface{}, data string, payloads map[string]interface{}) (*HTTPRequest, error) {
+	ctx := context.Background()
+
+	values, err := r.requestValues(baseURL, dynamicValues, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(data, "\n") {
+		return r.handleRawWithPaylods(ctx, data+"\n", baseURL, values, payloads)
+	}
+
+	return r.makeHTTPRequestFromModel(ctx, data, values)
+}
+
+// requestValues returns the dynamic values merged with the URL variables of a target
+func (r *BulkHTTPRequest) requestValues(baseURL string, dynamicValues map[string]interface{}, data string) (map[string]interface{}, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname := parsed.Host
+
+	// BaseURL only contains the scheme and host so the template path is used as is
+	if r.DisablePathAutomerge {
+		parsed.Path, parsed.RawPath, parsed.RawQuery = "", "", ""
+	}
+
+	return generators.MergeMaps(dynamicValues, map[string]interface{}{
+		"BaseURL":  baseURLWithTemplatePrefs(data, parsed),
+		"Hostname": hostname,
+	}), nil
+}
+
 // MakeHTTPRequestFromModel creates a *http.Request from a request template
 func (r *BulkHTTPRequest) makeHTTPRequestFromModel(ctx context.Context, data string, values map[string]interface{}) (*HTTPRequest, error) {
 	replacer := newReplacer(values)
 	URL := replacer.Replace(data)
 
 	// Build a request on the specified URL
-	req, err := http.NewRequestWithContext(ctx, r.Method, URL, nil)
+	req, err := r.newRequest(ctx, r.Method, URL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -170,6 +280,11 @@ func (r *BulkHTTPRequest) HasGenerator(reqURL string) bool {
 	return r.gsfm.Has(reqURL)
 }
 
+// RemoveGenerator removes the generator of an URL so its requests can be sent again
+func (r *BulkHTTPRequest) RemoveGenerator(reqURL string) {
+	r.gsfm.Delete(reqURL)
+}
+
 // ReadOne reads and return a generator by URL
 func (r *BulkHTTPRequest) ReadOne(reqURL string) {
 	r.gsfm.ReadOne(reqURL)
@@ -229,6 +344,18 @@ func (r *BulkHTTPRequest) handleRawWithPaylods(ctx context.Context, raw, baseURL
 		return nil, err
 	}
 
+	if csrf := r.csrfFrom(values); csrf != nil {
+		if data := csrf.injectForm(rawRequest.Data, rawRequest.Headers["Content-Type"]); data != rawRequest.Data {
+			rawRequest.Data = data
+			if _, ok := rawRequest.Headers["Content-Length"]; ok {
+				rawRequest.Headers["Content-Length"] = strconv.Itoa(len(data))
+			}
+		}
+		if csrf.header != "" {
+			rawRequest.Headers[csrf.header] = csrf.token
+		}
+	}
+
 	// rawhttp
 	if r.Unsafe {
 		unsafeReq := &HTTPRequest{
@@ -251,7 +378,7 @@ func (r *BulkHTTPRequest) handleRawWithPaylods(ctx context.Context, raw, baseURL
 		body = syncedreadcloser.NewOpenGateWithTimeout(body, time.Duration(two)*time.Second)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, rawRequest.Method, rawRequest.FullURL, body)
+	req, err := r.newRequest(ctx, rawRequest.Method, rawRequest.FullURL, body)
 	if err != nil {
 		return nil, err
 	}
@@ -269,6 +396,29 @@ func (r *BulkHTTPRequest) handleRawWithPaylods(ctx context.Context, raw, baseURL
 	return &HTTPRequest{Request: request, Meta: genValues}, nil
 }
 
+// newRequest creates a http request, keeping the path exactly
+// as written if url encoding is skipped for the request.
+func (r *BulkHTTPRequest) newRequest(ctx context.Context, method, rawURL string, body io.Reader) (*http.Request, error) {
+	if !r.SkipURLEncode {
+		return http.NewRequestWithContext(ctx, method, rawURL, body)
+	}
+
+	base, path := splitLiteralURL(rawURL)
+	if path == "" {
+		return http.NewRequestWithContext(ctx, method, rawURL, body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, base, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// an opaque URL is sent verbatim in the request line
+	req.URL.Opaque = path
+
+	return req, nil
+}
+
 func (r *BulkHTTPRequest) fillRequest(req *http.Request, values map[string]interface{}) (*retryablehttp.Request, error) {
 	replacer := newReplacer(values)
 	// Set the header values requested
@@ -276,6 +426,13 @@ func (r *BulkHTTPRequest) fillRequest(req *http.Request, values map[string]inter
 		req.Header[header] = []string{replacer.Replace(value)}
 	}
 
+	// net/http ignores the Host header, so it's moved to the request host
+	// allowing virtual hosts to be iterated against a fixed address
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+
 	// In case of multiple threads the underlying connection should remain open to allow reuse
 	if r.Threads <= 0 && req.Header.Get("Connection") == "" {
 		setHeader(req, "Connection", "close")
@@ -283,8 +440,27 @@ func (r *BulkHTTPRequest) fillRequest(req *http.Request, values map[string]inter
 	}
 
 	// Check if the user requested a request body
-	if r.Body != "" {
-		req.Body = ioutil.NopCloser(strings.NewReader(r.Body))
+	if r.bodyFile != "" {
+		req.Body = &fileBody{path: r.bodyFile}
+		req.ContentLength = r.bodySize
+		req.GetBody = func() (io.ReadCloser, error) {
+			return &fileBody{path: r.bodyFile}, nil
+		}
+	} else if r.Body != "" {
+		body := r.csrfFrom(values).injectForm(r.Body, req.Header.Get("Content-Type"))
+		req.Body = ioutil.NopCloser(strings.NewReader(body))
+	} else if len(r.Multipart) > 0 {
+		body, contentType, err := r.multipartBody(replacer, r.csrfFrom(values))
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if csrf := r.csrfFrom(values); csrf != nil && csrf.header != "" {
+		setHeader(req, csrf.header, csrf.token)
 	}
 
 	setHeader(req, "User-Agent", "Nuclei - Open-source project (github.com/projectdiscovery/nuclei)")
@@ -305,6 +481,8 @@ type HTTPRequest struct {
 	Request    *retryablehttp.Request
 	RawRequest *RawRequest
 	Meta       map[string]interface{}
+	// Target is the input target the request was built for
+	Target string
 
 	// flags
 	Unsafe                       bool
@@ -318,6 +496,11 @@ type HTTPRequest struct {
 	PipelineClient               *rawhttp.PipelineClient
 }
 
+// Cancelled returns true if the context the request was made with is done
+func (r *HTTPRequest) Cancelled() bool {
+	return r.Request != nil && r.Request.Context().Err() != nil
+}
+
 func setHeader(req *http.Request, name, value string) {
 	// Set some headers only if the header wasn't supplied by the user
 	if _, ok := req.Header[name]; !ok {
@@ -432,7 +615,11 @@ func (r *BulkHTTPRequest) parseRawRequest(request, baseURL string) (*RawRequest,
 		hostURL = rawRequest.Headers["Host"]
 	}
 
-	if rawRequest.Path == "" {
+	if r.DisablePathAutomerge {
+		if rawRequest.Path == "" {
+			rawRequest.Path = "/"
+		}
+	} else if rawRequest.Path == "" {
 		rawRequest.Path = parsedURL.Path
 	} else if strings.HasPrefix(rawRequest.Path, "?") {
 		// requests generated from http.ReadRequest have incorrect RequestURI, so they
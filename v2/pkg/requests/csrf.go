@@ -0,0 +1,59 @@
+package requests
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	// CSRFTokenVariable contains the last csrf token found in the responses
+	CSRFTokenVariable = "csrf_token"
+	// CSRFNameVariable contains the form field name of the csrf token
+	CSRFNameVariable = "csrf_name"
+	// CSRFHeaderVariable contains the header the csrf token is sent in, for tokens set in cookies
+	CSRFHeaderVariable = "csrf_header"
+)
+
+// csrfToken is the csrf token of the previous responses to add to a request
+type csrfToken struct {
+	name   string
+	token  string
+	header string
+}
+
+// csrfFrom returns the csrf token found in the previous responses, nil if
+// there's no token or if the request doesn't handle the tokens.
+func (r *BulkHTTPRequest) csrfFrom(values map[string]interface{}) *csrfToken {
+	if !r.CSRF {
+		return nil
+	}
+
+	token, _ := values[CSRFTokenVariable].(string)
+	if token == "" {
+		return nil
+	}
+	name, _ := values[CSRFNameVariable].(string)
+	header, _ := values[CSRFHeaderVariable].(string)
+
+	return &csrfToken{name: name, token: token, header: header}
+}
+
+// injectForm adds the token to an url encoded form body missing it
+func (c *csrfToken) injectForm(body, contentType string) string {
+	if c == nil || c.name == "" || !strings.Contains(strings.ToLower(contentType), "application/x-www-form-urlencoded") {
+		return body
+	}
+
+	if form, err := url.ParseQuery(body); err == nil {
+		if _, ok := form[c.name]; ok {
+			return body
+		}
+	}
+
+	field := url.QueryEscape(c.name) + "=" + url.QueryEscape(c.token)
+	if body == "" {
+		return field
+	}
+
+	return body + "&" + field
+}
@@ -0,0 +1,49 @@
+package requests
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// ParseDelay parses the delay of the request, either a fixed
+// duration (eg. 2s) or a range of durations (eg. 2s-5s).
+func (r *BulkHTTPRequest) ParseDelay() error {
+	r.delayMin, r.delayMax = 0, 0
+	if r.Delay == "" {
+		return nil
+	}
+
+	bounds := strings.SplitN(r.Delay, "-", two)
+
+	min, err := time.ParseDuration(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return fmt.Errorf("invalid delay %s: %s", r.Delay, err)
+	}
+	max := min
+
+	if len(bounds) == two {
+		if max, err = time.ParseDuration(strings.TrimSpace(bounds[1])); err != nil {
+			return fmt.Errorf("invalid delay %s: %s", r.Delay, err)
+		}
+	}
+
+	if min < 0 || max < min {
+		return fmt.Errorf("invalid delay %s: the range must be increasing", r.Delay)
+	}
+
+	r.delayMin, r.delayMax = min, max
+	return nil
+}
+
+// NextDelay returns the pause before the next request, picked
+// at random in the delay range so the requests have jitter.
+func (r *BulkHTTPRequest) NextDelay() time.Duration {
+	if r.delayMax == r.delayMin {
+		return r.delayMin
+	}
+
+	//nolint:gosec // jitter doesn't need a secure random source
+	return r.delayMin + time.Duration(rand.Int63n(int64(r.delayMax-r.delayMin)+1))
+}
@@ -4,8 +4,6 @@ import (
 	"strings"
 
 	"github.com/miekg/dns"
-	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
-	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
 )
 
 // DNSRequest contains a request to be made from a template
@@ -18,28 +16,11 @@ type DNSRequest struct {
 	Retries int    `yaml:"retries"`
 	// Raw contains a raw request
 	Raw string `yaml:"raw,omitempty"`
+	// EDNSBufferSize is the EDNS0 udp buffer size advertised with the request, if any
+	EDNSBufferSize uint16 `yaml:"edns-buffer-size,omitempty"`
 
-	// Matchers contains the detection mechanism for the request to identify
-	// whether the request was successful
-	Matchers []*matchers.Matcher `yaml:"matchers,omitempty"`
-	// matchersCondition is internal condition for the matchers.
-	matchersCondition matchers.ConditionType
-	// MatchersCondition is the condition of the matchers
-	// whether to use AND or OR. Default is OR.
-	MatchersCondition string `yaml:"matchers-condition,omitempty"`
-	// Extractors contains the extraction mechanism for the request to identify
-	// and extract parts of the response.
-	Extractors []*extractors.Extractor `yaml:"extractors,omitempty"`
-}
-
-// GetMatchersCondition returns the condition for the matcher
-func (r *DNSRequest) GetMatchersCondition() matchers.ConditionType {
-	return r.matchersCondition
-}
-
-// SetMatchersCondition sets the condition for the matcher
-func (r *DNSRequest) SetMatchersCondition(condition matchers.ConditionType) {
-	r.matchersCondition = condition
+	// Operators contains the matchers and the extractors of the request
+	Operators `yaml:",inline"`
 }
 
 // Returns the total number of requests the YAML rule will perform
@@ -66,6 +47,11 @@ func (r *DNSRequest) MakeDNSRequest(domain string) (*dns.Msg, error) {
 
 	req.Question = append(req.Question, q)
 
+	// advertise a bigger buffer so large answers fit in a single udp response
+	if r.EDNSBufferSize > 0 {
+		req.SetEdns0(r.EDNSBufferSize, false)
+	}
+
 	return req, nil
 }
 
@@ -89,6 +75,19 @@ func toQType(ttype string) (rtype uint16) {
 		rtype = dns.TypeTXT
 	case "AAAA":
 		rtype = dns.TypeAAAA
+	case "SRV":
+		rtype = dns.TypeSRV
+	case "CAA":
+		rtype = dns.TypeCAA
+	case "NAPTR":
+		rtype = dns.TypeNAPTR
+	case "DS":
+		rtype = dns.TypeDS
+	case "ANY":
+		rtype = dns.TypeANY
+	case "AXFR":
+		// zone transfers are attempted on the nameservers of the zone
+		rtype = dns.TypeAXFR
 	default:
 		rtype = dns.TypeA
 	}
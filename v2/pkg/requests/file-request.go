@@ -0,0 +1,140 @@
+package requests
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultFileMaxSize is the size of the largest file read when a file request has no max-size
+const defaultFileMaxSize = 5 * 1024 * 1024
+
+// allExtensions is the extension scanning every file
+const allExtensions = "all"
+
+// sizeUnits are the multipliers of the units of the max-size field, besides bytes
+var sizeUnits = map[string]int64{
+	"kb": 1024,
+	"mb": 1024 * 1024,
+	"gb": 1024 * 1024 * 1024,
+}
+
+// FileRequest contains the local files to scan from a template, the targets
+// being the paths of files or directories, walked recursively.
+type FileRequest struct {
+	// Extensions contains the extensions of the files scanned, eg. env, or the
+	// glob patterns of their names, eg. *.log.*. all (the default) scans every file.
+	Extensions []string `yaml:"extensions,omitempty"`
+	// DenyList contains the glob patterns of the files and directories
+	// skipped, matched against their names and their paths
+	DenyList []string `yaml:"denylist,omitempty"`
+	// MaxSize is the size of the largest file read, eg. 10mb, 5mb if unset.
+	// The larger files are skipped.
+	MaxSize string `yaml:"max-size,omitempty"`
+	// Archive scans the files in the zip, tar and gzip archives, each one as a file
+	Archive bool `yaml:"archive,omitempty"`
+
+	// Operators contains the matchers and the extractors of the request
+	Operators `yaml:",inline"`
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *FileRequest) GetRequestCount() int64 {
+	return 1
+}
+
+// Validate checks the max size and the patterns of the request
+func (r *FileRequest) Validate() error {
+	if _, err := parseSize(r.MaxSize); err != nil {
+		return err
+	}
+
+	for _, pattern := range append(append([]string(nil), r.Extensions...), r.DenyList...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid file pattern %s: %s", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// GetMaxSize returns the size of the largest file read
+func (r *FileRequest) GetMaxSize() int64 {
+	size, err := parseSize(r.MaxSize)
+	if err != nil || size <= 0 {
+		return defaultFileMaxSize
+	}
+
+	return size
+}
+
+// Allows returns true if a file is scanned by the request
+func (r *FileRequest) Allows(path string) bool {
+	if r.Denies(path) {
+		return false
+	}
+	if len(r.Extensions) == 0 {
+		return true
+	}
+
+	name := filepath.Base(path)
+	for _, extension := range r.Extensions {
+		if extension == allExtensions {
+			return true
+		}
+		if strings.ContainsAny(extension, "*?[") {
+			if matched, _ := filepath.Match(extension, name); matched {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(filepath.Ext(name), "."+strings.TrimPrefix(extension, ".")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Denies returns true if a file or a directory matches the deny list
+func (r *FileRequest) Denies(path string) bool {
+	name := filepath.Base(path)
+	for _, pattern := range r.DenyList {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, filepath.ToSlash(path)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseSize parses a size with an optional unit, eg. 512kb
+func parseSize(raw string) (int64, error) {
+	size := strings.ToLower(strings.TrimSpace(raw))
+	if size == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for unit, value := range sizeUnits {
+		if strings.HasSuffix(size, unit) {
+			size, multiplier = strings.TrimSuffix(size, unit), value
+			break
+		}
+	}
+	// the sizes in bytes can be written with a b suffix
+	if multiplier == 1 {
+		size = strings.TrimSuffix(size, "b")
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid file max-size %s", raw)
+	}
+
+	return value * multiplier, nil
+}
@@ -56,6 +56,8 @@ func NewGeneratorFSM(typ generators.Type, payloads map[string]interface{}, paths
 			generatorFunc = generators.PitchforkGenerator
 		case generators.ClusterBomb:
 			generatorFunc = generators.ClusterbombGenerator
+		case generators.BatteringRam:
+			generatorFunc = generators.BatteringramGenerator
 		case generators.Sniper:
 			generatorFunc = generators.SniperGenerator
 		}
@@ -227,7 +229,7 @@ func (gfsm *GeneratorFSM) Total() int {
 	estimatedRequestsWithPayload := 0
 	if len(gfsm.basePayloads) > 0 {
 		switch gfsm.Type {
-		case generators.Sniper:
+		case generators.Sniper, generators.BatteringRam:
 			for _, kv := range gfsm.basePayloads {
 				estimatedRequestsWithPayload += len(kv)
 			}
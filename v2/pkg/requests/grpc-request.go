@@ -0,0 +1,121 @@
+package requests
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// defaultGRPCAddress is the address called when a grpc request has none
+const defaultGRPCAddress = "{{BaseURL}}"
+
+// GRPCRequest contains a unary grpc call to be made from a template, its
+// message being sent already serialized so no service has to be compiled.
+type GRPCRequest struct {
+	// Address is the URL of the server, {{BaseURL}} if unset. The https URLs
+	// are called over tls, the http ones over cleartext with prior knowledge.
+	Address string `yaml:"address,omitempty"`
+	// Method is the full name of the method called, eg. grpc.health.v1.Health/Check
+	Method string `yaml:"method"`
+	// Metadata contains the metadata sent in the headers of the call
+	Metadata map[string]string `yaml:"metadata,omitempty"`
+	// Message is the serialized protobuf message sent, empty for a message
+	// with the default values
+	Message string `yaml:"message,omitempty"`
+	// Encoding is the encoding of the message, hex (the default) or base64
+	Encoding string `yaml:"encoding,omitempty"`
+
+	// Operators contains the matchers and the extractors of the request
+	Operators `yaml:",inline"`
+}
+
+// GRPCCall is a call to make for a target
+type GRPCCall struct {
+	Address  string
+	Method   string
+	Metadata map[string]string
+	Message  []byte
+}
+
+// String returns the call as it is dumped in the json requests
+func (c *GRPCCall) String() string {
+	return fmt.Sprintf("%s/%s\n%s", strings.TrimSuffix(c.Address, "/"), strings.TrimPrefix(c.Method, "/"), hex.EncodeToString(c.Message))
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *GRPCRequest) GetRequestCount() int64 {
+	return 1
+}
+
+// Validate checks the method and the encoding of the message
+func (r *GRPCRequest) Validate() error {
+	if !strings.Contains(strings.TrimPrefix(r.Method, "/"), "/") {
+		return fmt.Errorf("invalid grpc method %s, expected service/method", r.Method)
+	}
+
+	_, err := r.message()
+	return err
+}
+
+// message decodes the serialized message of the request
+func (r *GRPCRequest) message() ([]byte, error) {
+	switch r.Encoding {
+	case "", "hex":
+		message, err := hex.DecodeString(r.Message)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex grpc message %s: %s", r.Message, err)
+		}
+		return message, nil
+	case "base64":
+		message, err := base64.StdEncoding.DecodeString(r.Message)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 grpc message %s: %s", r.Message, err)
+		}
+		return message, nil
+	}
+
+	return nil, fmt.Errorf("unknown grpc message encoding %s", r.Encoding)
+}
+
+// MakeGRPCRequest returns the call to make for a target URL
+func (r *GRPCRequest) MakeGRPCRequest(target string) (*GRPCCall, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Host == "" {
+		// plain hosts are called without tls
+		if parsed, err = url.Parse("http://" + target); err != nil {
+			return nil, err
+		}
+	}
+
+	replacer := newReplacer(map[string]interface{}{
+		"BaseURL":  parsed.Scheme + "://" + parsed.Host,
+		"Hostname": parsed.Host,
+		"Host":     parsed.Hostname(),
+	})
+
+	address := r.Address
+	if address == "" {
+		address = defaultGRPCAddress
+	}
+	address = replacer.Replace(address)
+	if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
+		return nil, fmt.Errorf("invalid grpc address %s", address)
+	}
+
+	metadata := make(map[string]string, len(r.Metadata))
+	for name, value := range r.Metadata {
+		metadata[name] = replacer.Replace(value)
+	}
+
+	message, err := r.message()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GRPCCall{Address: address, Method: r.Method, Metadata: metadata, Message: message}, nil
+}
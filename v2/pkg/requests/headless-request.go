@@ -0,0 +1,47 @@
+package requests
+
+import (
+	"net/url"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/headless"
+)
+
+// HeadlessRequest contains the browser actions to execute from a template
+type HeadlessRequest struct {
+	// Steps contains the actions executed in order on the page
+	Steps []*headless.Action `yaml:"steps"`
+
+	// Operators contains the matchers and the extractors of the request
+	Operators `yaml:",inline"`
+}
+
+// Returns the total number of requests the YAML rule will perform
+func (r *HeadlessRequest) GetRequestCount() int64 {
+	return 1
+}
+
+// MakeSteps returns the steps of the request with the variables replaced for a URL
+func (r *HeadlessRequest) MakeSteps(baseURL string) ([]*headless.Action, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	replacer := newReplacer(map[string]interface{}{
+		"BaseURL":  parsed.String(),
+		"Hostname": parsed.Host,
+	})
+
+	steps := make([]*headless.Action, 0, len(r.Steps))
+
+	for _, step := range r.Steps {
+		args := make(map[string]string, len(step.Args))
+		for name, value := range step.Args {
+			args[name] = replacer.Replace(value)
+		}
+
+		steps = append(steps, &headless.Action{Action: step.Action, Args: args, Name: step.Name})
+	}
+
+	return steps, nil
+}
@@ -0,0 +1,128 @@
+package requests
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+)
+
+// MultipartField is a field of a multipart/form-data body, either
+// a plain value or a file upload if it has a filename.
+type MultipartField struct {
+	// Name is the name of the form field
+	Name string `yaml:"name"`
+	// Value is the content of the field
+	Value string `yaml:"value,omitempty"`
+	// File is a file the content of the field is read from instead of value,
+	// relative to the directory of the template
+	File string `yaml:"file,omitempty"`
+	// Filename is the name of the uploaded file
+	Filename string `yaml:"filename,omitempty"`
+	// ContentType is the content type of the uploaded file, application/octet-stream if unset
+	ContentType string `yaml:"content-type,omitempty"`
+	// path is the resolved path of the file
+	path string
+}
+
+// ResolveMultipart checks the multipart fields of the request and
+// resolves the paths of their files from the directory of the template.
+func (r *BulkHTTPRequest) ResolveMultipart(templatePath string) error {
+	if len(r.Multipart) == 0 {
+		return nil
+	}
+	if r.Body != "" || len(r.Raw) > 0 {
+		return fmt.Errorf("multipart can't be used with a body or raw requests")
+	}
+
+	for _, field := range r.Multipart {
+		field.path = ""
+		if field.Name == "" {
+			return fmt.Errorf("multipart field without a name")
+		}
+		if field.File == "" {
+			continue
+		}
+		if field.Value != "" {
+			return fmt.Errorf("multipart field %s has both a value and a file", field.Name)
+		}
+
+		field.path = field.File
+		if !filepath.IsAbs(field.path) {
+			field.path = filepath.Join(filepath.Dir(templatePath), field.path)
+		}
+		if !generators.FileExists(field.path) {
+			return fmt.Errorf("the %s file for multipart field %s does not exist", field.File, field.Name)
+		}
+	}
+
+	return nil
+}
+
+// multipartBody encodes the multipart fields with the placeholders of the
+// values, names and filenames replaced, adding the csrf token if the fields
+// are missing it. It returns the body and its content type.
+func (r *BulkHTTPRequest) multipartBody(replacer *strings.Replacer, csrf *csrfToken) ([]byte, string, error) {
+	buffer := &bytes.Buffer{}
+	writer := multipart.NewWriter(buffer)
+
+	for _, field := range r.Multipart {
+		if csrf != nil && replacer.Replace(field.Name) == csrf.name {
+			csrf = nil
+		}
+
+		value := []byte(replacer.Replace(field.Value))
+		if field.path != "" {
+			data, err := ioutil.ReadFile(field.path)
+			if err != nil {
+				return nil, "", err
+			}
+			value = data
+		}
+
+		header := make(textproto.MIMEHeader)
+		disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(replacer.Replace(field.Name)))
+		if field.Filename != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(replacer.Replace(field.Filename)))
+
+			contentType := field.ContentType
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			header.Set("Content-Type", contentType)
+		} else if field.ContentType != "" {
+			header.Set("Content-Type", field.ContentType)
+		}
+		header.Set("Content-Disposition", disposition)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if csrf != nil && csrf.name != "" {
+		if err := writer.WriteField(csrf.name, csrf.token); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buffer.Bytes(), writer.FormDataContentType(), nil
+}
+
+// escapeQuotes escapes the quotes of a content disposition parameter
+func escapeQuotes(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
@@ -0,0 +1,147 @@
+package requests
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// tlsAddressPrefix marks the addresses of network requests connected over tls
+const tlsAddressPrefix = "tls://"
+
+// defaultReadSize is the number of bytes read from the connection after the inputs
+const defaultReadSize = 1024
+
+// NetworkRequest contains a raw tcp request to be made from a template
+type NetworkRequest struct {
+	// Address contains the addresses to connect to, eg. {{Host}}:6379.
+	// An address starting with tls:// is connected to over tls.
+	Address []string `yaml:"host"`
+	// Inputs contains the data sent in order once connected, none to read the banner
+	Inputs []*NetworkInput `yaml:"inputs,omitempty"`
+	// ReadSize is the number of bytes read once the inputs are sent, 1024 if unset
+	ReadSize int `yaml:"read-size,omitempty"`
+
+	// Operators contains the matchers and the extractors of the request
+	Operators `yaml:",inline"`
+}
+
+// NetworkInput is data sent on the connection of a network request
+type NetworkInput struct {
+	// Data is the data to send, with the {{Hostname}} and {{Host}} placeholders replaced
+	Data string `yaml:"data"`
+	// Type is the encoding of the data, text (the default) or hex
+	Type string `yaml:"type,omitempty"`
+	// Read is the number of bytes read after sending the data, 0 to send the next input directly
+	Read int `yaml:"read,omitempty"`
+}
+
+// NetworkAddress is an address to connect to with the inputs to send
+type NetworkAddress struct {
+	Address string
+	TLS     bool
+	Inputs  []*CompiledNetworkInput
+}
+
+// CompiledNetworkInput is an input decoded and ready to be sent
+type CompiledNetworkInput struct {
+	Data []byte
+	Read int
+}
+
+// String returns the data sent to the address as it is dumped in the json requests
+func (a *NetworkAddress) String() string {
+	builder := &strings.Builder{}
+	for _, input := range a.Inputs {
+		builder.Write(input.Data)
+	}
+
+	return builder.String()
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *NetworkRequest) GetRequestCount() int64 {
+	return int64(len(r.Address))
+}
+
+// GetReadSize returns the number of bytes read once the inputs are sent
+func (r *NetworkRequest) GetReadSize() int {
+	if r.ReadSize <= 0 {
+		return defaultReadSize
+	}
+
+	return r.ReadSize
+}
+
+// Validate checks the addresses and the encoding of the inputs
+func (r *NetworkRequest) Validate() error {
+	if len(r.Address) == 0 {
+		return fmt.Errorf("no host defined for network request")
+	}
+
+	for _, input := range r.Inputs {
+		switch input.Type {
+		case "", "text":
+		case "hex":
+			if _, err := hex.DecodeString(input.Data); err != nil {
+				return fmt.Errorf("invalid hex input %s: %s", input.Data, err)
+			}
+		default:
+			return fmt.Errorf("unknown network input type %s", input.Type)
+		}
+	}
+
+	return nil
+}
+
+// MakeNetworkRequests returns the addresses to connect to for a target,
+// which can be an URL, a host:port pair or a plain host.
+func (r *NetworkRequest) MakeNetworkRequests(target string) ([]*NetworkAddress, error) {
+	hostname := target
+	if strings.Contains(target, "://") {
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return nil, err
+		}
+		hostname = parsed.Host
+	}
+	host := hostname
+	if h, _, err := net.SplitHostPort(hostname); err == nil {
+		host = h
+	}
+
+	replacer := newReplacer(map[string]interface{}{"Hostname": hostname, "Host": host})
+
+	inputs := make([]*CompiledNetworkInput, 0, len(r.Inputs))
+	for _, input := range r.Inputs {
+		data := []byte(replacer.Replace(input.Data))
+		if input.Type == "hex" {
+			decoded, err := hex.DecodeString(input.Data)
+			if err != nil {
+				return nil, err
+			}
+			data = decoded
+		}
+		inputs = append(inputs, &CompiledNetworkInput{Data: data, Read: input.Read})
+	}
+
+	addresses := make([]*NetworkAddress, 0, len(r.Address))
+	for _, address := range r.Address {
+		address = replacer.Replace(address)
+
+		compiled := &NetworkAddress{Address: address, Inputs: inputs}
+		if strings.HasPrefix(address, tlsAddressPrefix) {
+			compiled.Address = strings.TrimPrefix(address, tlsAddressPrefix)
+			compiled.TLS = true
+		}
+		if _, _, err := net.SplitHostPort(compiled.Address); err != nil {
+			return nil, fmt.Errorf("invalid network address %s: %s", address, err)
+		}
+
+		addresses = append(addresses, compiled)
+	}
+
+	return addresses, nil
+}
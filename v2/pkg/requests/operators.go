@@ -0,0 +1,87 @@
+package requests
+
+import (
+	"fmt"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// Operators contains the matchers and the extractors evaluated on the
+// responses of a request, embedded in the requests of every protocol.
+type Operators struct {
+	// Matchers contains the detection mechanism for the request to identify
+	// whether the request was successful
+	Matchers []*matchers.Matcher `yaml:"matchers,omitempty"`
+	// matchersCondition is internal condition for the matchers.
+	matchersCondition matchers.ConditionType
+	// MatchersCondition is the condition of the matchers
+	// whether to use AND or OR. Default is OR.
+	MatchersCondition string `yaml:"matchers-condition,omitempty"`
+	// Extractors contains the extraction mechanism for the request to identify
+	// and extract parts of the response.
+	Extractors []*extractors.Extractor `yaml:"extractors,omitempty"`
+}
+
+// CompileOperators sets the condition between the matchers, OR if it's
+// unknown, and compiles the matchers and the extractors
+func (o *Operators) CompileOperators() error {
+	condition, ok := matchers.ConditionTypes[o.MatchersCondition]
+	if !ok {
+		condition = matchers.ORCondition
+	}
+	o.matchersCondition = condition
+
+	for _, matcher := range o.Matchers {
+		if err := matcher.CompileMatchers(); err != nil {
+			return err
+		}
+	}
+
+	for _, extractor := range o.Extractors {
+		if err := extractor.CompileExtractors(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateSimilarity returns an error if a similarity matcher compares the
+// responses to an earlier request, only the http requests keeping them
+func (o *Operators) ValidateSimilarity(protocol string) error {
+	for _, matcher := range o.Matchers {
+		if matcher.GetType() == matchers.SimilarityMatcher && matcher.Request > 0 {
+			return fmt.Errorf("similarity matchers of %s requests can only compare to a reference", protocol)
+		}
+	}
+
+	return nil
+}
+
+// NeedsFields returns true if a matcher or an extractor reads the map of the
+// responses, the executers only building it then
+func (o *Operators) NeedsFields() bool {
+	for _, matcher := range o.Matchers {
+		if matcher.NeedsFields() {
+			return true
+		}
+	}
+	for _, extractor := range o.Extractors {
+		if extractor.NeedsFields() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetMatchersCondition returns the condition for the matcher
+func (o *Operators) GetMatchersCondition() matchers.ConditionType {
+	return o.matchersCondition
+}
+
+// SetMatchersCondition sets the condition for the matcher
+func (o *Operators) SetMatchersCondition(condition matchers.ConditionType) {
+	o.matchersCondition = condition
+}
@@ -0,0 +1,98 @@
+package requests
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"golang.org/x/net/html/charset"
+)
+
+// maxDecodedBodySize caps the size of a decompressed body, the rest being dropped
+const maxDecodedBodySize = 10 * 1024 * 1024
+
+// DecodeBody returns the body of a response as seen by the matchers and the
+// extractors: decompressed following its Content-Encoding, unless the
+// transport already did it, then converted to utf-8 from the charset its
+// Content-Type declares. A body which can't be decompressed is returned as
+// received, without converting its compressed bytes.
+func DecodeBody(resp *http.Response, body []byte) []byte {
+	if !resp.Uncompressed {
+		decompressed, err := decompress(resp.Header.Get("Content-Encoding"), body)
+		if err != nil {
+			return body
+		}
+		body = decompressed
+	}
+
+	if converted, err := toUTF8(resp.Header.Get("Content-Type"), body); err == nil {
+		body = converted
+	}
+
+	return body
+}
+
+// decompress reverses the content encodings of a body, applied in the listed order
+func decompress(contentEncoding string, body []byte) ([]byte, error) {
+	encodings := strings.Split(contentEncoding, ",")
+
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var reader io.ReadCloser
+		var err error
+
+		switch strings.ToLower(strings.TrimSpace(encodings[i])) {
+		case "gzip", "x-gzip":
+			reader, err = gzip.NewReader(bytes.NewReader(body))
+		case "deflate":
+			// deflate should be zlib wrapped, some servers send the raw stream
+			reader, err = zlib.NewReader(bytes.NewReader(body))
+			if err != nil {
+				reader, err = flate.NewReader(bytes.NewReader(body)), nil
+			}
+		case "br":
+			reader = ioutil.NopCloser(brotli.NewReader(bytes.NewReader(body)))
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		body, err = ioutil.ReadAll(io.LimitReader(reader, maxDecodedBodySize))
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+// toUTF8 converts a body from the charset declared by a content type. The
+// bodies without charset are kept as they are, guessing it would mangle the
+// binary responses.
+func toUTF8(contentType string, body []byte) ([]byte, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body, nil
+	}
+
+	label := strings.ToLower(strings.TrimSpace(params["charset"]))
+	if label == "" || label == "utf-8" || label == "utf8" || label == "us-ascii" {
+		return body, nil
+	}
+
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadAll(reader)
+}
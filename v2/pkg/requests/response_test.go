@@ -0,0 +1,72 @@
+package requests
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+)
+
+// compressed returns the data written through a compressing writer
+func compressed(t *testing.T, writer func(io.Writer) io.WriteCloser, data string) []byte {
+	buffer := &bytes.Buffer{}
+	w := writer(buffer)
+	_, err := w.Write([]byte(data))
+	require.Nil(t, err, "could not compress body")
+	require.Nil(t, w.Close(), "could not compress body")
+
+	return buffer.Bytes()
+}
+
+// decodedBody decodes a body received with a content encoding and type
+func decodedBody(contentEncoding, contentType string, body []byte) string {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Content-Encoding", contentEncoding)
+	resp.Header.Set("Content-Type", contentType)
+
+	return string(DecodeBody(resp, body))
+}
+
+func TestDecodeBodyEncodings(t *testing.T) {
+	const body = "<html>nuclei decoded body</html>"
+
+	writers := map[string]func(io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"br":      func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+	}
+	for encoding, writer := range writers {
+		require.Equal(t, body, decodedBody(encoding, "text/html", compressed(t, writer, body)), "wrong %s body", encoding)
+	}
+
+	rawDeflate := compressed(t, func(w io.Writer) io.WriteCloser {
+		writer, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return writer
+	}, body)
+	require.Equal(t, body, decodedBody("deflate", "text/html", rawDeflate), "wrong raw deflate body")
+
+	gzipped := compressed(t, writers["gzip"], body)
+	require.Equal(t, body, decodedBody("gzip, br", "text/html", compressed(t, writers["br"], string(gzipped))), "wrong body of several encodings")
+}
+
+func TestDecodeBodyCharset(t *testing.T) {
+	// こんにちは in Shift-JIS
+	shiftJIS := []byte{0x82, 0xb1, 0x82, 0xf1, 0x82, 0xc9, 0x82, 0xbf, 0x82, 0xcd}
+
+	require.Equal(t, "こんにちは", decodedBody("", "text/html; charset=Shift_JIS", shiftJIS), "wrong shift-jis body")
+	require.Equal(t, "こんにちは", decodedBody("gzip", "text/html; charset=Shift_JIS", compressed(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, string(shiftJIS))), "wrong compressed shift-jis body")
+	require.Equal(t, string(shiftJIS), decodedBody("", "text/html", shiftJIS), "a body without charset was converted")
+}
+
+func TestDecodeBodyInvalidEncoding(t *testing.T) {
+	// the bytes of a body which can't be decompressed aren't converted either
+	invalid := []byte{0x82, 0xb1, 0x1f, 0x8b}
+
+	require.Equal(t, string(invalid), decodedBody("gzip", "text/html; charset=Shift_JIS", invalid), "an undecompressed body was converted")
+}
@@ -0,0 +1,74 @@
+package requests
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/tlsconfig"
+)
+
+// defaultSSLAddress is the address handshaked with when a ssl request has none
+const defaultSSLAddress = "{{Host}}:443"
+
+// SSLRequest contains a tls handshake to be made from a template, its matchers
+// and extractors working on the negotiated parameters and the certificates.
+type SSLRequest struct {
+	// Address contains the addresses to handshake with, {{Host}}:443 if unset.
+	// The port of a host:port or https target is used for {{Hostname}}.
+	Address []string `yaml:"address,omitempty"`
+	// TLS contains the versions and the cipher suites offered, eg. max-version
+	// tls10 to detect the servers still accepting deprecated versions
+	TLS *tlsconfig.Options `yaml:"tls,omitempty"`
+
+	// Operators contains the matchers and the extractors of the request
+	Operators `yaml:",inline"`
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *SSLRequest) GetRequestCount() int64 {
+	if len(r.Address) == 0 {
+		return 1
+	}
+
+	return int64(len(r.Address))
+}
+
+// Validate checks the tls settings of the request
+func (r *SSLRequest) Validate() error {
+	return r.TLS.Validate()
+}
+
+// MakeSSLRequests returns the addresses to handshake with for a target,
+// which can be an URL, a host:port pair or a plain host.
+func (r *SSLRequest) MakeSSLRequests(target string) ([]string, error) {
+	hostname := target
+	if parsed, err := url.Parse(target); err == nil && parsed.Host != "" {
+		hostname = parsed.Host
+		if parsed.Port() == "" && parsed.Scheme == "https" {
+			hostname = net.JoinHostPort(parsed.Hostname(), "443")
+		}
+	}
+	host := hostname
+	if h, _, err := net.SplitHostPort(hostname); err == nil {
+		host = h
+	}
+
+	replacer := newReplacer(map[string]interface{}{"Hostname": hostname, "Host": host})
+
+	definitions := r.Address
+	if len(definitions) == 0 {
+		definitions = []string{defaultSSLAddress}
+	}
+
+	addresses := make([]string, 0, len(definitions))
+	for _, address := range definitions {
+		address = replacer.Replace(address)
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return nil, fmt.Errorf("invalid ssl address %s: %s", address, err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	return addresses, nil
+}
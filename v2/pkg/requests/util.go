@@ -1,10 +1,7 @@
 package requests
 
 import (
-	"bytes"
-	"compress/gzip"
 	"fmt"
-	"io/ioutil"
 	"strings"
 )
 
@@ -29,29 +26,26 @@ func newReplacer(values map[string]interface{}) *strings.Replacer {
 	return strings.NewReplacer(replacerItems...)
 }
 
-// HandleDecompression if the user specified a custom encoding (as golang transport doesn't do this automatically)
-func HandleDecompression(r *HTTPRequest, bodyOrig []byte) (bodyDec []byte, err error) {
-	if r.Request == nil {
-		return bodyOrig, nil
+// splitLiteralURL splits a URL into its scheme and host part and its
+// path and query as written, without parsing the path.
+func splitLiteralURL(rawURL string) (base, path string) {
+	schemeEnd := strings.Index(rawURL, "://")
+	if schemeEnd == -1 {
+		return rawURL, ""
 	}
 
-	encodingHeader := strings.ToLower(r.Request.Header.Get("Accept-Encoding"))
-	if encodingHeader == "gzip" {
-		gzipreader, err := gzip.NewReader(bytes.NewReader(bodyOrig))
-		if err != nil {
-			return bodyDec, err
-		}
-		defer gzipreader.Close()
-
-		bodyDec, err = ioutil.ReadAll(gzipreader)
-		if err != nil {
-			return bodyDec, err
-		}
+	pathStart := strings.IndexAny(rawURL[schemeEnd+len("://"):], "/?")
+	if pathStart == -1 {
+		return rawURL, ""
+	}
+	pathStart += schemeEnd + len("://")
 
-		return bodyDec, nil
+	path = rawURL[pathStart:]
+	if strings.HasPrefix(path, "?") {
+		path = "/" + path
 	}
 
-	return bodyOrig, nil
+	return rawURL[:pathStart], path
 }
 
 // ZipMapValues converts values from strings slices to flat string
@@ -0,0 +1,157 @@
+package requests
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// defaultWebsocketAddress is the URL upgraded when a websocket request has none
+	defaultWebsocketAddress = "{{BaseURL}}"
+	// defaultMaxMessages is the number of messages read at most from a connection
+	defaultMaxMessages = 10
+)
+
+// WebsocketRequest contains a websocket connection to be made from a template,
+// its matchers working on the handshake, the messages received and the close
+// code sent by the server.
+type WebsocketRequest struct {
+	// Address is the URL upgraded, {{BaseURL}} if unset. The http and https
+	// URLs are connected to as ws and wss, eg. {{BaseURL}}/socket.io/?EIO=4&transport=websocket
+	Address string `yaml:"address,omitempty"`
+	// Headers contains the headers sent with the handshake, eg. an Origin
+	// to check the cross-site websocket hijacking
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Inputs contains the messages sent in order once upgraded
+	Inputs []*WebsocketInput `yaml:"inputs,omitempty"`
+	// MaxMessages is the number of messages read at most, 10 if unset
+	MaxMessages int `yaml:"max-messages,omitempty"`
+
+	// Operators contains the matchers and the extractors of the request
+	Operators `yaml:",inline"`
+}
+
+// WebsocketInput is a message sent on the connection of a websocket request
+type WebsocketInput struct {
+	// Data is the message to send, with the {{BaseURL}}, {{Hostname}} and {{Host}} placeholders replaced
+	Data string `yaml:"data"`
+	// Type is the message type, text (the default) or hex for a binary message of the decoded data
+	Type string `yaml:"type,omitempty"`
+	// Read is the number of messages awaited after sending the data, 0 to send
+	// the next input directly. The messages are read until the server is idle
+	// once all the inputs are sent.
+	Read int `yaml:"read,omitempty"`
+}
+
+// CompiledWebsocketInput is an input decoded and ready to be sent
+type CompiledWebsocketInput struct {
+	Data   []byte
+	Binary bool
+	Read   int
+}
+
+// WebsocketConnection is the URL to upgrade for a target with the messages to send
+type WebsocketConnection struct {
+	URL     string
+	Headers map[string]string
+	Inputs  []*CompiledWebsocketInput
+}
+
+// String returns the messages sent as they are dumped in the json requests
+func (c *WebsocketConnection) String() string {
+	messages := make([]string, 0, len(c.Inputs))
+	for _, input := range c.Inputs {
+		messages = append(messages, string(input.Data))
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *WebsocketRequest) GetRequestCount() int64 {
+	return 1
+}
+
+// GetMaxMessages returns the number of messages read at most from the connection
+func (r *WebsocketRequest) GetMaxMessages() int {
+	if r.MaxMessages <= 0 {
+		return defaultMaxMessages
+	}
+
+	return r.MaxMessages
+}
+
+// Validate checks the encoding of the inputs
+func (r *WebsocketRequest) Validate() error {
+	for _, input := range r.Inputs {
+		switch input.Type {
+		case "", "text":
+		case "hex":
+			if _, err := hex.DecodeString(input.Data); err != nil {
+				return fmt.Errorf("invalid hex input %s: %s", input.Data, err)
+			}
+		default:
+			return fmt.Errorf("unknown websocket input type %s", input.Type)
+		}
+	}
+
+	return nil
+}
+
+// MakeWebsocketRequest returns the connection to make for a target URL
+func (r *WebsocketRequest) MakeWebsocketRequest(target string) (*WebsocketConnection, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Host == "" {
+		// plain hosts are connected to without tls
+		if parsed, err = url.Parse("ws://" + target); err != nil {
+			return nil, err
+		}
+	}
+
+	replacer := newReplacer(map[string]interface{}{
+		"BaseURL":  strings.TrimSuffix(parsed.String(), "/"),
+		"Hostname": parsed.Host,
+		"Host":     parsed.Hostname(),
+	})
+
+	address := r.Address
+	if address == "" {
+		address = defaultWebsocketAddress
+	}
+	address = replacer.Replace(address)
+	switch {
+	case strings.HasPrefix(address, "http://"):
+		address = "ws://" + strings.TrimPrefix(address, "http://")
+	case strings.HasPrefix(address, "https://"):
+		address = "wss://" + strings.TrimPrefix(address, "https://")
+	}
+	if !strings.HasPrefix(address, "ws://") && !strings.HasPrefix(address, "wss://") {
+		return nil, fmt.Errorf("invalid websocket address %s", address)
+	}
+
+	headers := make(map[string]string, len(r.Headers))
+	for name, value := range r.Headers {
+		headers[name] = replacer.Replace(value)
+	}
+
+	inputs := make([]*CompiledWebsocketInput, 0, len(r.Inputs))
+	for _, input := range r.Inputs {
+		compiled := &CompiledWebsocketInput{Data: []byte(replacer.Replace(input.Data)), Read: input.Read}
+		if input.Type == "hex" {
+			decoded, err := hex.DecodeString(input.Data)
+			if err != nil {
+				return nil, err
+			}
+			compiled.Data = decoded
+			compiled.Binary = true
+		}
+		inputs = append(inputs, compiled)
+	}
+
+	return &WebsocketConnection{URL: address, Headers: headers, Inputs: inputs}, nil
+}
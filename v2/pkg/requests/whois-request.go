@@ -0,0 +1,58 @@
+package requests
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// defaultWhoisQuery is the object looked up when a whois request has no query
+const defaultWhoisQuery = "{{Domain}}"
+
+// WhoisRequest contains a whois lookup to be made from a template
+type WhoisRequest struct {
+	// Query is the object looked up, the registered domain of the target
+	// ({{Domain}}) if unset. {{Hostname}} is the host of the target.
+	Query string `yaml:"query,omitempty"`
+	// Server is the whois server queried, the one the iana whois server
+	// refers to for the query if unset
+	Server string `yaml:"server,omitempty"`
+
+	// Operators contains the matchers and the extractors of the request
+	Operators `yaml:",inline"`
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *WhoisRequest) GetRequestCount() int64 {
+	return 1
+}
+
+// MakeWhoisQuery returns the object to look up for a target, which can
+// be an URL, a host:port pair or a plain host.
+func (r *WhoisRequest) MakeWhoisQuery(target string) string {
+	host := target
+	if parsed, err := url.Parse(target); err == nil && parsed.Host != "" {
+		host = parsed.Hostname()
+	} else if h, _, err := net.SplitHostPort(target); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".")
+
+	// addresses are looked up as they are, the registries answering for their networks
+	domain := host
+	if net.ParseIP(host) == nil {
+		if registered, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+			domain = registered
+		}
+	}
+
+	query := r.Query
+	if query == "" {
+		query = defaultWhoisQuery
+	}
+	replacer := newReplacer(map[string]interface{}{"Hostname": host, "Domain": domain})
+
+	return replacer.Replace(query)
+}
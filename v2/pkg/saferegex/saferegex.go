@@ -0,0 +1,105 @@
+package saferegex
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sync"
+	"time"
+)
+
+var (
+	// MaxInputSize is the maximum number of bytes of a corpus evaluated by the regexes,
+	// zero means the whole corpus is evaluated.
+	MaxInputSize int
+	// SlowThreshold is the evaluation time above which a regex is reported as slow
+	SlowThreshold = time.Second
+)
+
+var (
+	slowMutex sync.Mutex
+	slow      = make(map[string]time.Duration)
+)
+
+// Compile compiles a regex, explaining the syntax not supported by RE2
+// such as lookarounds and backreferences.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err == nil {
+		return compiled, nil
+	}
+
+	if syntaxErr, ok := err.(*syntax.Error); ok {
+		switch syntaxErr.Code {
+		case syntax.ErrInvalidPerlOp, syntax.ErrInvalidEscape, syntax.ErrInvalidRepeatOp:
+			return nil, fmt.Errorf("could not compile regex %s: only RE2 syntax is supported (no lookarounds or backreferences): %s", pattern, syntaxErr.Code)
+		}
+	}
+
+	return nil, fmt.Errorf("could not compile regex %s: %s", pattern, err)
+}
+
+// MatchString reports whether the regex matches the corpus
+func MatchString(regex *regexp.Regexp, corpus string) bool {
+	start := time.Now()
+	matched := regex.MatchString(truncate(corpus))
+	observe(regex, start)
+
+	return matched
+}
+
+// FindAllStringSubmatch returns all the matches of the regex with their groups
+func FindAllStringSubmatch(regex *regexp.Regexp, corpus string) [][]string {
+	start := time.Now()
+	matches := regex.FindAllStringSubmatch(truncate(corpus), -1)
+	observe(regex, start)
+
+	return matches
+}
+
+// FindAllStringIndex returns the positions of at most n matches of the regex, all of them if n is negative
+func FindAllStringIndex(regex *regexp.Regexp, corpus string, n int) [][]int {
+	start := time.Now()
+	indices := regex.FindAllStringIndex(truncate(corpus), n)
+	observe(regex, start)
+
+	return indices
+}
+
+// Slow returns the regexes which took longer than the threshold
+// with their slowest evaluation time.
+func Slow() map[string]time.Duration {
+	slowMutex.Lock()
+	defer slowMutex.Unlock()
+
+	result := make(map[string]time.Duration, len(slow))
+	for pattern, duration := range slow {
+		result[pattern] = duration
+	}
+
+	return result
+}
+
+// truncate returns the part of the corpus evaluated by the regexes
+func truncate(corpus string) string {
+	if MaxInputSize > 0 && len(corpus) > MaxInputSize {
+		return corpus[:MaxInputSize]
+	}
+
+	return corpus
+}
+
+// observe records the regex if its evaluation was slow
+func observe(regex *regexp.Regexp, start time.Time) {
+	duration := time.Since(start)
+	if duration < SlowThreshold {
+		return
+	}
+
+	slowMutex.Lock()
+	defer slowMutex.Unlock()
+
+	if duration > slow[regex.String()] {
+		slow[regex.String()] = duration
+	}
+}
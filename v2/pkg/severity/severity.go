@@ -0,0 +1,30 @@
+package severity
+
+import "strings"
+
+// levels contains the known severities mapped to their rank
+var levels = map[string]int{
+	"info":     1,
+	"low":      2,
+	"medium":   3,
+	"high":     4,
+	"critical": 5,
+}
+
+// Rank returns the rank of a severity, higher being more severe.
+// Unknown severities have a rank of 0.
+func Rank(severity string) int {
+	return levels[strings.ToLower(strings.TrimSpace(severity))]
+}
+
+// IsValid returns true if the severity is a known one
+func IsValid(severity string) bool {
+	return Rank(severity) > 0
+}
+
+// AtLeast returns true if the severity is equal to or above the threshold
+func AtLeast(severity, threshold string) bool {
+	rank := Rank(severity)
+
+	return rank > 0 && rank >= Rank(threshold)
+}
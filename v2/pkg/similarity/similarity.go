@@ -0,0 +1,117 @@
+// Package similarity compares responses which are expected to vary, like
+// error pages embedding the payload, by how similar their content is.
+package similarity
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+)
+
+// maxLevenshtein is the maximum length of the texts compared by edit
+// distance, the longer ones are compared by simhash as the distance
+// grows with the product of the lengths.
+const maxLevenshtein = 4096
+
+// shingleSize is the number of words hashed together by simhash
+const shingleSize = 3
+
+// Levenshtein returns the similarity ratio of two texts between 0 and 1,
+// one minus their edit distance divided by the length of the longest.
+func Levenshtein(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if len(a) > maxLevenshtein || len(b) > maxLevenshtein {
+		return Simhash(a, b)
+	}
+
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+
+	return 1 - float64(distance(a, b))/float64(longest)
+}
+
+// Simhash returns the similarity of two texts between 0 and 1 from the
+// number of differing bits of their simhashes.
+func Simhash(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	return 1 - float64(bits.OnesCount64(simhash(a)^simhash(b)))/64
+}
+
+// distance returns the levenshtein distance of two strings in bytes
+func distance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// simhash returns the 64 bit simhash of the word shingles of a text
+func simhash(text string) uint64 {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(words) || i == 0; i++ {
+		end := i + shingleSize
+		if end > len(words) {
+			end = len(words)
+		}
+
+		hash := fnv.New64a()
+		hash.Write([]byte(strings.Join(words[i:end], " ")))
+		value := hash.Sum64()
+
+		for bit := 0; bit < 64; bit++ {
+			if value&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var result uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			result |= 1 << uint(bit)
+		}
+	}
+
+	return result
+}
+
+// min returns the smallest of three integers
+func min(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+
+	return a
+}
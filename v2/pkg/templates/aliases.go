@@ -0,0 +1,77 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// rawTemplate has the fields of a template without its yaml unmarshaler
+type rawTemplate Template
+
+// upstreamAliases are the names upstream nuclei gives to the request fields,
+// so that its templates load without modification.
+type upstreamAliases struct {
+	HTTP []*requests.BulkHTTPRequest `yaml:"http,omitempty"`
+	TCP  []*requests.NetworkRequest  `yaml:"tcp,omitempty"`
+}
+
+// UnmarshalYAML decodes a template, appending the requests written under
+// the upstream names to the ones of this engine.
+func (t *Template) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal((*rawTemplate)(t)); err != nil {
+		return err
+	}
+
+	aliases := &upstreamAliases{}
+	if err := unmarshal(aliases); err != nil {
+		return err
+	}
+	t.BulkRequestsHTTP = append(t.BulkRequestsHTTP, aliases.HTTP...)
+	t.RequestsNetwork = append(t.RequestsNetwork, aliases.TCP...)
+
+	return nil
+}
+
+// Info is the info of a template. Upstream templates give lists for some of
+// its fields (eg. author, reference and tags), joined with commas, and maps
+// for others (eg. classification and metadata), whose fields are added.
+type Info map[string]string
+
+// UnmarshalYAML decodes the info of a template, flattening the lists and maps
+func (i *Info) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	raw := make(map[string]interface{})
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	info := make(Info, len(raw))
+	for key, value := range raw {
+		info.add(key, value)
+	}
+	*i = info
+
+	return nil
+}
+
+// add adds a field of the info, the fields of a map being added in its place
+func (i Info) add(key string, value interface{}) {
+	switch value := value.(type) {
+	case nil:
+	case map[interface{}]interface{}:
+		for child, childValue := range value {
+			if name := fmt.Sprint(child); i[name] == "" {
+				i.add(name, childValue)
+			}
+		}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			values = append(values, fmt.Sprint(item))
+		}
+		i[key] = strings.Join(values, ",")
+	default:
+		i[key] = fmt.Sprint(value)
+	}
+}
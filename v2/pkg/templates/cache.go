@@ -0,0 +1,143 @@
+package templates
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// cacheLayout is the hash of the gob type descriptions of a template, which
+// change with its structure, so that the templates cached by a build with
+// another structure are never decoded
+var cacheLayout = templateLayout()
+
+func init() {
+	// list payloads are decoded from yaml as generic lists
+	gob.Register([]interface{}{})
+}
+
+// Cache stores the decoded templates on disk keyed by the hash of their
+// path and content, so that later runs can skip the yaml decoding.
+//
+// Compiled regexes and dsl expressions can't be serialized and are
+// rebuilt when a template is loaded from the cache.
+type Cache struct {
+	directory string
+}
+
+// NewCache creates a template cache in the given directory
+func NewCache(directory string) (*Cache, error) {
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &Cache{directory: directory}, nil
+}
+
+// Parse parses a yaml request template file, using the cached
+// version if the file didn't change since it was cached.
+func (c *Cache) Parse(file string) (*Template, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ParseBytes(file, data)
+}
+
+// ParseBytes parses the content of a yaml request template file, using
+// the cached version if the content didn't change since it was cached.
+func (c *Cache) ParseBytes(file string, data []byte) (*Template, error) {
+	if c == nil || cacheLayout == "" {
+		return ParseBytes(file, data)
+	}
+	// the markers are expanded for each compilation, so can't be cached
+	if hasPreprocessors(data) {
+		return ParseBytes(file, data)
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(cacheLayout + "\x00" + file + "\x00"))
+	hash.Write(data)
+	cached := filepath.Join(c.directory, hex.EncodeToString(hash.Sum(nil))+".gob")
+
+	if template, err := c.read(cached); err == nil {
+		template.path = file
+		if err := template.compile(); err == nil {
+			return template, nil
+		}
+	}
+
+	template := &Template{}
+	if err := yaml.Unmarshal(data, template); err != nil {
+		return nil, err
+	}
+
+	template.path = file
+
+	if err := template.compile(); err != nil {
+		return nil, err
+	}
+
+	// the cache is best effort, a failed write only costs a decoding next time
+	_ = c.write(cached, template)
+
+	return template, nil
+}
+
+// read decodes a cached template
+func (c *Cache) read(cached string) (*Template, error) {
+	data, err := ioutil.ReadFile(cached)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &Template{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// write encodes a template to the cache, replacing the file atomically
+func (c *Cache) write(cached string, template *Template) error {
+	buffer := &bytes.Buffer{}
+	if err := gob.NewEncoder(buffer).Encode(template); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(c.directory, "template-*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(buffer.Bytes()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		return err
+	}
+	f.Close()
+
+	return os.Rename(f.Name(), cached)
+}
+
+// templateLayout returns the hash of the gob type descriptions sent along
+// with a template, covering the types of all its fields. It's empty if a
+// template can't be encoded.
+func templateLayout() string {
+	buffer := &bytes.Buffer{}
+	if err := gob.NewEncoder(buffer).Encode(&Template{}); err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(buffer.Bytes())
+
+	return hex.EncodeToString(hash[:])
+}
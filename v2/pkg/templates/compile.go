@@ -2,45 +2,87 @@ package templates
 
 import (
 	"fmt"
-	"os"
+	"io/ioutil"
+	"net/url"
 	"path"
 	"strings"
 
 	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
-	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
 	"gopkg.in/yaml.v2"
 )
 
 // Parse parses a yaml request template file
 func Parse(file string) (*Template, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBytes(file, data)
+}
+
+// ParseBytes parses a yaml request template held in memory, the name being
+// used as its path. The payload and body files it references are read from
+// the disk relative to that name.
+func ParseBytes(name string, data []byte) (*Template, error) {
 	template := &Template{}
 
-	f, err := os.Open(file)
-	if err != nil {
+	if err := yaml.Unmarshal(preprocess(data), template); err != nil {
 		return nil, err
 	}
 
-	err = yaml.NewDecoder(f).Decode(template)
-	if err != nil {
+	template.path = name
+
+	if err := template.compile(); err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
-	template.path = file
+	return template, nil
+}
+
+// resolvePayloadFile returns the path of a wordlist file of a payload, which
+// is searched in the parent directories of the template if it's not found as is.
+func (t *Template) resolvePayloadFile(file string) (string, bool) {
+	if generators.FileExists(file) {
+		return file, true
+	}
+
+	pathTokens := strings.Split(t.path, "/")
+	for i := range pathTokens {
+		tpath := path.Join(strings.Join(pathTokens[:i], "/"), file)
+		if generators.FileExists(tpath) {
+			return tpath, true
+		}
+	}
 
+	return "", false
+}
+
+// compile validates the template and compiles its matchers and extractors
+func (t *Template) compile() error {
 	// If no requests, and it is also not a workflow, return error.
-	if len(template.BulkRequestsHTTP)+len(template.RequestsDNS) <= 0 {
-		return nil, fmt.Errorf("no requests defined for %s", template.ID)
+	if len(t.BulkRequestsHTTP)+len(t.RequestsDNS)+len(t.RequestsHeadless)+len(t.RequestsNetwork)+len(t.RequestsSSL)+len(t.RequestsWhois)+len(t.RequestsWebsocket)+len(t.RequestsFile)+len(t.RequestsGRPC) <= 0 {
+		return fmt.Errorf("no requests defined for %s", t.ID)
+	}
+
+	if err := t.validateSchema(); err != nil {
+		return err
+	}
+	if err := t.validateSafety(); err != nil {
+		return err
+	}
+	if err := ValidateProxy(t.Proxy); err != nil {
+		return err
+	}
+	if t.DoS() {
+		t.serializeRequests()
 	}
 
 	// Compile the matchers and the extractors for http requests
-	for _, request := range template.BulkRequestsHTTP {
-		// Get the condition between the matchers
-		condition, ok := matchers.ConditionTypes[request.MatchersCondition]
-		if !ok {
-			request.SetMatchersCondition(matchers.ORCondition)
-		} else {
-			request.SetMatchersCondition(condition)
+	for _, request := range t.BulkRequestsHTTP {
+		// the requests keep the cookies of their own steps too
+		if t.CookieReuse {
+			request.CookieReuse = true
 		}
 
 		// Set the attack type - used only in raw requests
@@ -57,77 +99,184 @@ func Parse(file string) (*Template, error) {
 			case string:
 				// check if it's a multiline string list
 				if len(strings.Split(pt, "\n")) <= 1 {
-					// check if it's a worldlist file
-					if !generators.FileExists(pt) {
-						// attempt to load the file by taking the full path, tokezining it and searching the template in such paths
-						changed := false
-						pathTokens := strings.Split(template.path, "/")
-
-						for i := range pathTokens {
-							tpath := path.Join(strings.Join(pathTokens[:i], "/"), pt)
-							if generators.FileExists(tpath) {
-								request.Payloads[name] = tpath
-								changed = true
-
-								break
-							}
-						}
-
-						if !changed {
-							return nil, fmt.Errorf("the %s file for payload %s does not exist or does not contain enough elements", pt, name)
-						}
+					file, ok := t.resolvePayloadFile(pt)
+					if !ok {
+						return fmt.Errorf("the %s file for payload %s does not exist or does not contain enough elements", pt, name)
 					}
+					request.Payloads[name] = file
 				}
 			case []string, []interface{}:
 				if len(payload.([]interface{})) == 0 {
-					return nil, fmt.Errorf("the payload %s does not contain enough elements", name)
+					return fmt.Errorf("the payload %s does not contain enough elements", name)
 				}
 			default:
-				return nil, fmt.Errorf("the payload %s has invalid type", name)
+				return fmt.Errorf("the payload %s has invalid type", name)
 			}
 		}
 
-		for _, matcher := range request.Matchers {
-			matchErr := matcher.CompileMatchers()
-			if matchErr != nil {
-				return nil, matchErr
-			}
+		if err := request.CompileOperators(); err != nil {
+			return err
 		}
 
-		for _, extractor := range request.Extractors {
-			extractErr := extractor.CompileExtractors()
-			if extractErr != nil {
-				return nil, extractErr
-			}
+		if request.FreshConnection && request.Pipeline {
+			return fmt.Errorf("fresh-connection can't be used with pipeline in %s", t.ID)
+		}
+
+		if err := request.ParseDelay(); err != nil {
+			return err
+		}
+		if request.Delay != "" && (request.Threads > 0 || request.Race || request.Pipeline) {
+			return fmt.Errorf("delay can't be used with threads, race or pipeline in %s", t.ID)
+		}
+
+		if err := request.TLS.Validate(); err != nil {
+			return err
+		}
+		if err := request.ValidateProtocol(); err != nil {
+			return err
+		}
+		if err := request.ValidateFallback(); err != nil {
+			return err
+		}
+
+		if err := request.ResolveBodyFile(t.path); err != nil {
+			return err
+		}
+		if err := request.ResolveMultipart(t.path); err != nil {
+			return err
 		}
 
 		request.InitGenerator()
 	}
 
 	// Compile the matchers and the extractors for dns requests
-	for _, request := range template.RequestsDNS {
-		// Get the condition between the matchers
-		condition, ok := matchers.ConditionTypes[request.MatchersCondition]
-		if !ok {
-			request.SetMatchersCondition(matchers.ORCondition)
-		} else {
-			request.SetMatchersCondition(condition)
+	for _, request := range t.RequestsDNS {
+		if err := request.CompileOperators(); err != nil {
+			return err
 		}
+		if err := request.ValidateSimilarity("dns"); err != nil {
+			return err
+		}
+	}
 
-		for _, matcher := range request.Matchers {
-			err = matcher.CompileMatchers()
-			if err != nil {
-				return nil, err
-			}
+	// Validate the steps and compile the matchers and the extractors for headless requests
+	for _, request := range t.RequestsHeadless {
+		if len(request.Steps) == 0 {
+			return fmt.Errorf("no steps defined for headless request of %s", t.ID)
 		}
 
-		for _, extractor := range request.Extractors {
-			err := extractor.CompileExtractors()
-			if err != nil {
-				return nil, err
+		for _, step := range request.Steps {
+			if err := step.Validate(); err != nil {
+				return err
 			}
 		}
+
+		if err := request.CompileOperators(); err != nil {
+			return err
+		}
+		if err := request.ValidateSimilarity("headless"); err != nil {
+			return err
+		}
 	}
 
-	return template, nil
+	// Validate the inputs and compile the matchers and the extractors for network requests
+	for _, request := range t.RequestsNetwork {
+		if err := request.Validate(); err != nil {
+			return err
+		}
+
+		if err := request.CompileOperators(); err != nil {
+			return err
+		}
+		if err := request.ValidateSimilarity("network"); err != nil {
+			return err
+		}
+	}
+
+	// Validate the tls settings and compile the matchers and the extractors for ssl requests
+	for _, request := range t.RequestsSSL {
+		if err := request.Validate(); err != nil {
+			return err
+		}
+
+		if err := request.CompileOperators(); err != nil {
+			return err
+		}
+		if err := request.ValidateSimilarity("ssl"); err != nil {
+			return err
+		}
+	}
+
+	// Compile the matchers and the extractors for whois requests
+	for _, request := range t.RequestsWhois {
+		if err := request.CompileOperators(); err != nil {
+			return err
+		}
+		if err := request.ValidateSimilarity("whois"); err != nil {
+			return err
+		}
+	}
+
+	// Validate the inputs and compile the matchers and the extractors for websocket requests
+	for _, request := range t.RequestsWebsocket {
+		if err := request.Validate(); err != nil {
+			return err
+		}
+
+		if err := request.CompileOperators(); err != nil {
+			return err
+		}
+		if err := request.ValidateSimilarity("websocket"); err != nil {
+			return err
+		}
+	}
+
+	// Validate the patterns and compile the matchers and the extractors for file requests
+	for _, request := range t.RequestsFile {
+		if err := request.Validate(); err != nil {
+			return err
+		}
+
+		if err := request.CompileOperators(); err != nil {
+			return err
+		}
+		if err := request.ValidateSimilarity("file"); err != nil {
+			return err
+		}
+	}
+
+	// Validate the methods and compile the matchers and the extractors for grpc requests
+	for _, request := range t.RequestsGRPC {
+		if err := request.Validate(); err != nil {
+			return err
+		}
+
+		if err := request.CompileOperators(); err != nil {
+			return err
+		}
+		if err := request.ValidateSimilarity("grpc"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateProxy checks a proxy overriding the ones of the runner, either a
+// http or a socks5 one.
+func ValidateProxy(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(proxy)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid proxy %s", proxy)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+		return nil
+	}
+
+	return fmt.Errorf("unsupported proxy scheme %s, expected http, https or socks5", parsed.Scheme)
 }
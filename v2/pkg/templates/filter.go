@@ -0,0 +1,121 @@
+package templates
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Filter selects the templates to load by the tags, severity and authors
+// of their info, so the excluded ones are never compiled.
+type Filter struct {
+	tags        []string
+	excludeTags []string
+	severities  []string
+	authors     []string
+}
+
+// NewFilter creates a filter from comma separated lists, nil if all are empty
+func NewFilter(tags, excludeTags, severities, authors string) *Filter {
+	filter := &Filter{
+		tags:        splitList(tags),
+		excludeTags: splitList(excludeTags),
+		severities:  splitList(severities),
+		authors:     splitList(authors),
+	}
+	if len(filter.tags)+len(filter.excludeTags)+len(filter.severities)+len(filter.authors) == 0 {
+		return nil
+	}
+
+	return filter
+}
+
+// Match returns true if a template info passes the filter, or else the reason it doesn't
+func (f *Filter) Match(info map[string]string) (bool, string) {
+	if f == nil {
+		return true, ""
+	}
+
+	tags := splitList(info["tags"])
+	if excluded := intersect(tags, f.excludeTags); excluded != "" {
+		return false, fmt.Sprintf("tag %s is excluded", excluded)
+	}
+	if len(f.tags) > 0 && intersect(tags, f.tags) == "" {
+		return false, fmt.Sprintf("tags [%s] not in [%s]", info["tags"], strings.Join(f.tags, ","))
+	}
+
+	// severities are matched by prefix so that crit matches critical
+	if len(f.severities) > 0 {
+		severity := strings.ToLower(info["severity"])
+		matched := false
+		for _, allowed := range f.severities {
+			if strings.HasPrefix(severity, allowed) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, fmt.Sprintf("severity %s not in [%s]", severity, strings.Join(f.severities, ","))
+		}
+	}
+
+	if len(f.authors) > 0 && intersect(splitList(info["author"]), f.authors) == "" {
+		return false, fmt.Sprintf("author %s not in [%s]", info["author"], strings.Join(f.authors, ","))
+	}
+
+	return true, ""
+}
+
+// ReadInfo decodes only the id and info of a template or workflow file,
+// reporting if it's a workflow as they aren't filtered like templates.
+func ReadInfo(file string) (id string, info map[string]string, workflow bool, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", nil, false, err
+	}
+	defer f.Close()
+
+	return readInfo(f)
+}
+
+// readInfo decodes the id and info of a template or workflow
+func readInfo(r io.Reader) (id string, info map[string]string, workflow bool, err error) {
+	header := &struct {
+		ID    string `yaml:"id"`
+		Info  Info   `yaml:"info"`
+		Logic string `yaml:"logic"`
+	}{}
+	if err := yaml.NewDecoder(r).Decode(header); err != nil {
+		return "", nil, false, err
+	}
+
+	return header.ID, header.Info, header.Logic != "", nil
+}
+
+// splitList splits a comma separated list, lowercasing and trimming its values
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
+
+// intersect returns the first value of a present in b, empty if none
+func intersect(a, b []string) string {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return x
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,56 @@
+//go:build go1.16
+// +build go1.16
+
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// ParseFS parses the yaml request templates of a file system, such as an
+// embed.FS baked in a binary. Workflows are skipped and the templates are
+// filtered by their info if a filter is set.
+func ParseFS(fsys fs.FS, filter *Filter) ([]*Template, error) {
+	var parsed []*Template
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || path.Ext(name) != ".yaml" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		_, info, workflow, err := readInfo(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("could not parse %s: %s", name, err)
+		}
+		if workflow {
+			return nil
+		}
+		if ok, _ := filter.Match(info); !ok {
+			return nil
+		}
+
+		template, err := ParseBytes(name, data)
+		if err != nil {
+			return fmt.Errorf("could not parse %s: %s", name, err)
+		}
+		parsed = append(parsed, template)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
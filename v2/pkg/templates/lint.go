@@ -0,0 +1,215 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/saferegex"
+	"github.com/projectdiscovery/nuclei/v2/pkg/severity"
+	"gopkg.in/yaml.v2"
+)
+
+// The rules checked by Lint
+const (
+	RuleSyntax          = "syntax"
+	RuleMissingID       = "missing-id"
+	RuleDuplicateID     = "duplicate-id"
+	RuleSeverity        = "severity"
+	RuleUnknownPart     = "unknown-part"
+	RuleInvalidRegex    = "invalid-regex"
+	RuleUnusedExtractor = "unused-extractor"
+	RulePayloadFile     = "payload-file"
+	RuleCompile         = "compile"
+)
+
+// partProtocols are the parts which are only matched or extracted from the
+// responses of some request types, the other parts being matched by all.
+var partProtocols = map[string][]string{
+	"cache":               {"http"},
+	"certificate":         {"http"},
+	"interactsh_protocol": {"http"},
+	"interactsh_request":  {"http"},
+	"console":             {"headless"},
+	"network":             {"headless"},
+}
+
+// definitionRegex finds the name fields, which don't count as uses of the extractors
+var definitionRegex = regexp.MustCompile(`(?m)^\s*(?:-\s+)?name:.*$`)
+
+// Issue is a problem found in a template by Lint
+type Issue struct {
+	File string
+	ID   string
+	// Rule is the check which found the issue, eg. duplicate-id
+	Rule    string
+	Message string
+}
+
+// String returns the issue as it's printed by the cli
+func (i *Issue) String() string {
+	return fmt.Sprintf("%s: [%s] %s", i.File, i.Rule, i.Message)
+}
+
+// Lint checks template and workflow files for the mistakes which don't stop
+// them from loading, or only one at a time: duplicate ids, unknown matcher
+// and extractor parts, internal extractors never used, invalid regexes,
+// missing severities and payload files. The files are only compiled once
+// they pass these checks, the compile errors being reported too.
+func Lint(files []string) []*Issue {
+	var issues []*Issue
+	ids := make(map[string]string)
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			issues = append(issues, &Issue{File: file, Rule: RuleSyntax, Message: err.Error()})
+			continue
+		}
+
+		id, _, _, err := readInfo(bytes.NewReader(data))
+		if err == nil && id != "" {
+			if first, ok := ids[id]; ok {
+				issues = append(issues, &Issue{File: file, ID: id, Rule: RuleDuplicateID, Message: fmt.Sprintf("id %s is already used by %s", id, first)})
+			} else {
+				ids[id] = file
+			}
+		}
+
+		issues = append(issues, LintBytes(file, data)...)
+	}
+
+	return issues
+}
+
+// LintBytes checks a template or workflow held in memory, the name being used
+// as its path. The duplicate ids can only be found by Lint.
+func LintBytes(name string, data []byte) []*Issue {
+	id, info, workflow, err := readInfo(bytes.NewReader(data))
+	if err != nil {
+		return []*Issue{{File: name, Rule: RuleSyntax, Message: err.Error()}}
+	}
+
+	l := &linter{file: name, id: id}
+	if id == "" {
+		l.report(RuleMissingID, "no id defined")
+	}
+	if workflow {
+		return l.issues
+	}
+
+	switch value := info["severity"]; {
+	case value == "":
+		l.report(RuleSeverity, "no severity defined")
+	case !severity.IsValid(value):
+		l.report(RuleSeverity, fmt.Sprintf("unknown severity %s", value))
+	}
+
+	template := &Template{}
+	if err := yaml.Unmarshal(preprocess(data), template); err != nil {
+		l.report(RuleSyntax, err.Error())
+		return l.issues
+	}
+	template.path = name
+
+	uses := definitionRegex.ReplaceAll(data, nil)
+
+	for _, request := range template.Requests() {
+		l.lintRequest(request.Protocol, request.Operators.Matchers, request.Operators.Extractors, uses)
+	}
+	for _, request := range template.BulkRequestsHTTP {
+		for payload, value := range request.Payloads {
+			if file, ok := value.(string); ok && !strings.Contains(file, "\n") {
+				if _, ok := template.resolvePayloadFile(file); !ok {
+					l.report(RulePayloadFile, fmt.Sprintf("the %s file of payload %s does not exist", file, payload))
+				}
+			}
+		}
+	}
+
+	// the compile errors would repeat the issues already found
+	if len(l.issues) == 0 {
+		if err := template.compile(); err != nil {
+			l.report(RuleCompile, err.Error())
+		}
+	}
+
+	return l.issues
+}
+
+// linter collects the issues of a template
+type linter struct {
+	file   string
+	id     string
+	issues []*Issue
+}
+
+// report adds an issue found by a rule
+func (l *linter) report(rule, message string) {
+	l.issues = append(l.issues, &Issue{File: l.file, ID: l.id, Rule: rule, Message: message})
+}
+
+// lintRequest checks the matchers and the extractors of a request, the
+// internal extractors having to be used somewhere else in the template.
+func (l *linter) lintRequest(protocol string, requestMatchers []*matchers.Matcher, requestExtractors []*extractors.Extractor, uses []byte) {
+	for _, matcher := range requestMatchers {
+		if matcher.Part != "" {
+			if _, ok := matchers.PartTypes[matcher.Part]; !ok {
+				l.report(RuleUnknownPart, fmt.Sprintf("unknown matcher part %s", matcher.Part))
+			} else {
+				l.lintPart("matcher", matcher.Part, protocol)
+			}
+		}
+		l.lintRegexes(matcher.Regex)
+	}
+
+	for _, extractor := range requestExtractors {
+		if extractor.Part != "" {
+			if _, ok := extractors.PartTypes[extractor.Part]; !ok {
+				l.report(RuleUnknownPart, fmt.Sprintf("unknown extractor part %s", extractor.Part))
+			} else {
+				l.lintPart("extractor", extractor.Part, protocol)
+			}
+		}
+		l.lintRegexes(extractor.Regex)
+
+		if !extractor.Internal {
+			continue
+		}
+		if extractor.Name == "" {
+			l.report(RuleUnusedExtractor, fmt.Sprintf("internal %s extractor without name can't be used", extractor.Type))
+			continue
+		}
+		if !regexp.MustCompile(`\b` + regexp.QuoteMeta(extractor.Name) + `\b`).Match(uses) {
+			l.report(RuleUnusedExtractor, fmt.Sprintf("internal extractor %s is never used", extractor.Name))
+		}
+	}
+}
+
+// lintPart reports the known parts which are never set for the responses of a protocol
+func (l *linter) lintPart(kind, part, protocol string) {
+	allowed, ok := partProtocols[part]
+	if !ok {
+		return
+	}
+
+	for _, value := range allowed {
+		if value == protocol {
+			return
+		}
+	}
+	l.report(RuleUnknownPart, fmt.Sprintf("%s part %s is only set for %s requests, not %s", kind, part, strings.Join(allowed, ", "), protocol))
+}
+
+// lintRegexes reports the regexes which don't compile
+func (l *linter) lintRegexes(regexes []string) {
+	for _, regex := range regexes {
+		if _, err := saferegex.Compile(regex); err != nil {
+			l.report(RuleInvalidRegex, err.Error())
+		}
+	}
+}
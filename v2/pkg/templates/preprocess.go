@@ -0,0 +1,40 @@
+package templates
+
+import (
+	"regexp"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+)
+
+const (
+	// randstrCharset contains the characters of the random markers
+	randstrCharset = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// randstrLength is the length of the random markers
+	randstrLength = 20
+)
+
+// randstrRegex finds the {{randstr}} and {{randstr_N}} markers of a template
+var randstrRegex = regexp.MustCompile(`{{randstr(?:_\w+)?}}`)
+
+// preprocess expands the markers of a template before it's decoded, once per
+// compilation. Each marker is replaced with a random string which is the same
+// everywhere it appears, so a value sent by a request can be matched in the
+// responses of the template. The numbered markers get values of their own.
+func preprocess(data []byte) []byte {
+	values := make(map[string][]byte)
+
+	return randstrRegex.ReplaceAllFunc(data, func(marker []byte) []byte {
+		value, ok := values[string(marker)]
+		if !ok {
+			value = []byte(generators.RandSeq(randstrCharset, randstrLength))
+			values[string(marker)] = value
+		}
+		return value
+	})
+}
+
+// hasPreprocessors returns true if a template has markers to expand, its
+// compiled form being different every time.
+func hasPreprocessors(data []byte) bool {
+	return randstrRegex.Match(data)
+}
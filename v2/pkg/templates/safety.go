@@ -0,0 +1,57 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// safetyLevels orders the safety classes of the templates declared in
+// their info, from the least to the most impactful on the target.
+var safetyLevels = map[string]int{
+	"passive":     1,
+	"safe":        2,
+	"intrusive":   3,
+	"destructive": 4,
+}
+
+// intrusiveLevel is the level from which templates need to be allowed explicitly
+const intrusiveLevel = 3
+
+// Safety returns the safety class of the template, empty if unclassified
+func (t *Template) Safety() string {
+	return strings.ToLower(strings.TrimSpace(t.Info["safety"]))
+}
+
+// Intrusive returns true if the template may change the state of the target
+func (t *Template) Intrusive() bool {
+	return safetyLevels[t.Safety()] >= intrusiveLevel
+}
+
+// dosTags are the tags of the templates exhausting the resources of the target
+var dosTags = []string{"dos", "resource-exhaustion"}
+
+// DoS returns true if the template is tagged as a denial of service
+func (t *Template) DoS() bool {
+	return intersect(splitList(t.Info["tags"]), dosTags) != ""
+}
+
+// serializeRequests disables the concurrent requests of a dos template so
+// that it never sends more than one request at a time.
+func (t *Template) serializeRequests() {
+	for _, request := range t.BulkRequestsHTTP {
+		request.Threads = 0
+		request.Race = false
+		request.RaceNumberRequests = 0
+	}
+}
+
+// validateSafety checks the safety class of the template, if any
+func (t *Template) validateSafety() error {
+	if safety := t.Safety(); safety != "" {
+		if _, ok := safetyLevels[safety]; !ok {
+			return fmt.Errorf("invalid safety class '%s' for %s, must be passive, safe, intrusive or destructive", safety, t.ID)
+		}
+	}
+
+	return nil
+}
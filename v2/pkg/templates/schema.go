@@ -0,0 +1,91 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// CurrentSchemaVersion is the version of the template format written by the
+// migrations. Templates without a schema-version field are version 1, the
+// format shared with upstream nuclei, which has no breaking change yet.
+const CurrentSchemaVersion = 1
+
+var (
+	// schemaVersionRegex finds the top level schema-version field of a template
+	schemaVersionRegex = regexp.MustCompile(`(?m)^schema-version:[ \t]*(\d+)[ \t]*$`)
+	// idLineRegex finds the top level id field of a template
+	idLineRegex = regexp.MustCompile(`(?m)^id:.*$`)
+)
+
+// schemaValidators check the fields of the templates of each version,
+// upgrading the deprecated ones in memory so they run as the current ones.
+var schemaValidators = map[int]func(t *Template) error{
+	1: func(t *Template) error {
+		return nil
+	},
+}
+
+// migrations rewrite the text of a template of a version to the next one,
+// keeping its comments and formatting.
+var migrations = map[int]func(data []byte) []byte{}
+
+// SchemaVersion returns the schema version of the template
+func (t *Template) SchemaVersion() int {
+	if t.Schema == 0 {
+		return 1
+	}
+
+	return t.Schema
+}
+
+// validateSchema checks the template against the validator of its schema version
+func (t *Template) validateSchema() error {
+	validator, ok := schemaValidators[t.SchemaVersion()]
+	if !ok {
+		return fmt.Errorf("unsupported schema version %d for %s, the latest is %d", t.SchemaVersion(), t.ID, CurrentSchemaVersion)
+	}
+
+	return validator(t)
+}
+
+// Migrate rewrites a template to the current schema version, returning the
+// version it was written for. Templates of the current version are returned
+// unchanged.
+func Migrate(data []byte) (migrated []byte, from int, err error) {
+	from = 1
+	if match := schemaVersionRegex.FindSubmatch(data); match != nil {
+		from, _ = strconv.Atoi(string(match[1]))
+	}
+	if from > CurrentSchemaVersion {
+		return nil, from, fmt.Errorf("unsupported schema version %d, the latest is %d", from, CurrentSchemaVersion)
+	}
+	if from == CurrentSchemaVersion {
+		return data, from, nil
+	}
+
+	migrated = data
+	for version := from; version < CurrentSchemaVersion; version++ {
+		migrated = migrations[version](migrated)
+	}
+
+	return setSchemaVersion(migrated, CurrentSchemaVersion), from, nil
+}
+
+// setSchemaVersion sets the schema-version field of a template, adding it
+// after the id if it has none.
+func setSchemaVersion(data []byte, version int) []byte {
+	line := []byte("schema-version: " + strconv.Itoa(version))
+	if schemaVersionRegex.Match(data) {
+		return schemaVersionRegex.ReplaceAll(data, line)
+	}
+
+	if location := idLineRegex.FindIndex(data); location != nil {
+		result := append([]byte(nil), data[:location[1]]...)
+		result = append(result, '\n')
+		result = append(result, line...)
+		return append(result, data[location[1]:]...)
+	}
+
+	return append(append(line, '\n'), data...)
+}
@@ -0,0 +1,124 @@
+package templates
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// digestPrefix starts the trailer line holding the signature of a template
+const digestPrefix = "# digest: "
+
+var (
+	// ErrUnsigned is returned when a template has no digest trailer
+	ErrUnsigned = errors.New("template is not signed")
+	// ErrUntrusted is returned when the signature of a template doesn't
+	// match any trusted key, either because it was modified or signed by another key
+	ErrUntrusted = errors.New("template signature doesn't match a trusted key")
+)
+
+// Sign returns the content of a template with a digest trailer signed by a
+// key, replacing the existing trailer if any.
+func Sign(data []byte, key ed25519.PrivateKey) []byte {
+	content, _ := splitDigest(data)
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+
+	signature := ed25519.Sign(key, content)
+
+	return append(content, []byte(digestPrefix+hex.EncodeToString(signature)+"\n")...)
+}
+
+// Verify checks that the digest trailer of a template was signed by one of
+// the trusted keys for its current content.
+func Verify(data []byte, keys []ed25519.PublicKey) error {
+	content, digest := splitDigest(data)
+	if digest == "" {
+		return ErrUnsigned
+	}
+
+	signature, err := hex.DecodeString(digest)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: invalid digest %s", ErrUntrusted, digest)
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key, content, signature) {
+			return nil
+		}
+	}
+
+	return ErrUntrusted
+}
+
+// splitDigest separates the content of a template from the signature
+// of its digest trailer, the last non empty line.
+func splitDigest(data []byte) (content []byte, digest string) {
+	trimmed := bytes.TrimRight(data, "\r\n")
+
+	start := bytes.LastIndexByte(trimmed, '\n') + 1
+	if !bytes.HasPrefix(trimmed[start:], []byte(digestPrefix)) {
+		return data, ""
+	}
+
+	return append([]byte(nil), trimmed[:start]...), string(bytes.TrimSpace(trimmed[start+len(digestPrefix):]))
+}
+
+// ReadPrivateKey reads an ed25519 private key from a PKCS #8 pem file,
+// eg. generated with openssl genpkey -algorithm ed25519.
+func ReadPrivateKey(file string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 private key", file)
+	}
+
+	return private, nil
+}
+
+// ReadPublicKey reads an ed25519 public key from a PKIX pem file
+func ReadPublicKey(file string) (ed25519.PublicKey, error) {
+	der, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 public key", file)
+	}
+
+	return public, nil
+}
+
+// readPEM returns the content of the first pem block of a file
+func readPEM(file string) ([]byte, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no pem data in %s", file)
+	}
+
+	return block.Bytes, nil
+}
@@ -8,13 +8,34 @@ import (
 type Template struct {
 	// ID is the unique id for the template
 	ID string `yaml:"id"`
+	// Schema is the schema version the template is written for, 1 if unset
+	Schema int `yaml:"schema-version,omitempty"`
 	// Info contains information about the template
-	Info map[string]string `yaml:"info"`
+	Info Info `yaml:"info"`
+	// CookieReuse shares the cookies set on a target between all the http requests of the template
+	CookieReuse bool `yaml:"cookie-reuse,omitempty"`
+	// Proxy is the http or socks5 proxy the http requests of the template are
+	// sent through, instead of the proxies of the runner
+	Proxy string `yaml:"proxy,omitempty"`
 	// BulkRequestsHTTP contains the http request to make in the template
 	BulkRequestsHTTP []*requests.BulkHTTPRequest `yaml:"requests,omitempty"`
 	// RequestsDNS contains the dns request to make in the template
 	RequestsDNS []*requests.DNSRequest `yaml:"dns,omitempty"`
-	path        string
+	// RequestsHeadless contains the browser actions to execute in the template
+	RequestsHeadless []*requests.HeadlessRequest `yaml:"headless,omitempty"`
+	// RequestsNetwork contains the raw tcp requests to make in the template
+	RequestsNetwork []*requests.NetworkRequest `yaml:"network,omitempty"`
+	// RequestsSSL contains the tls handshakes to make in the template
+	RequestsSSL []*requests.SSLRequest `yaml:"ssl,omitempty"`
+	// RequestsWhois contains the whois lookups to make in the template
+	RequestsWhois []*requests.WhoisRequest `yaml:"whois,omitempty"`
+	// RequestsWebsocket contains the websocket connections to make in the template
+	RequestsWebsocket []*requests.WebsocketRequest `yaml:"websocket,omitempty"`
+	// RequestsFile contains the local files to scan in the template
+	RequestsFile []*requests.FileRequest `yaml:"file,omitempty"`
+	// RequestsGRPC contains the grpc calls to make in the template
+	RequestsGRPC []*requests.GRPCRequest `yaml:"grpc,omitempty"`
+	path         string
 }
 
 // GetPath of the workflow
@@ -39,3 +60,110 @@ func (t *Template) GetDNSRequestCount() int64 {
 
 	return count
 }
+
+func (t *Template) GetHeadlessRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsHeadless {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+func (t *Template) GetNetworkRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsNetwork {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+func (t *Template) GetSSLRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsSSL {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+func (t *Template) GetWhoisRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsWhois {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+func (t *Template) GetWebsocketRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsWebsocket {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+func (t *Template) GetFileRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsFile {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+func (t *Template) GetGRPCRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsGRPC {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+// ProtocolRequest is a request of a template with its protocol and its
+// position among the requests of that protocol
+type ProtocolRequest struct {
+	Protocol  string
+	Index     int
+	Request   interface{}
+	Operators *requests.Operators
+}
+
+// Requests returns the requests of all the protocols of the template
+func (t *Template) Requests() []ProtocolRequest {
+	var all []ProtocolRequest
+
+	for i, request := range t.BulkRequestsHTTP {
+		all = append(all, ProtocolRequest{"http", i, request, &request.Operators})
+	}
+	for i, request := range t.RequestsDNS {
+		all = append(all, ProtocolRequest{"dns", i, request, &request.Operators})
+	}
+	for i, request := range t.RequestsHeadless {
+		all = append(all, ProtocolRequest{"headless", i, request, &request.Operators})
+	}
+	for i, request := range t.RequestsNetwork {
+		all = append(all, ProtocolRequest{"network", i, request, &request.Operators})
+	}
+	for i, request := range t.RequestsSSL {
+		all = append(all, ProtocolRequest{"ssl", i, request, &request.Operators})
+	}
+	for i, request := range t.RequestsWhois {
+		all = append(all, ProtocolRequest{"whois", i, request, &request.Operators})
+	}
+	for i, request := range t.RequestsWebsocket {
+		all = append(all, ProtocolRequest{"websocket", i, request, &request.Operators})
+	}
+	for i, request := range t.RequestsFile {
+		all = append(all, ProtocolRequest{"file", i, request, &request.Operators})
+	}
+	for i, request := range t.RequestsGRPC {
+		all = append(all, ProtocolRequest{"grpc", i, request, &request.Operators})
+	}
+
+	return all
+}
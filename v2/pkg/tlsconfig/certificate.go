@@ -0,0 +1,60 @@
+package tlsconfig
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+	"time"
+)
+
+// CertificateText returns the fields of the certificate chain of a tls
+// connection as text, one "name: value" line per field, so the chain
+// can be matched by words and regexes. The leaf certificate comes first.
+func CertificateText(state *tls.ConnectionState) string {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return ""
+	}
+
+	builder := &strings.Builder{}
+	for i, certificate := range state.PeerCertificates {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		writeField(builder, "subject", certificate.Subject.String())
+		writeField(builder, "issuer", certificate.Issuer.String())
+		writeField(builder, "dns_names", strings.Join(certificate.DNSNames, ","))
+		writeField(builder, "serial", certificate.SerialNumber.String())
+		writeField(builder, "not_before", certificate.NotBefore.UTC().Format(time.RFC3339))
+		writeField(builder, "not_after", certificate.NotAfter.UTC().Format(time.RFC3339))
+		writeField(builder, "signature_algorithm", certificate.SignatureAlgorithm.String())
+	}
+
+	return builder.String()
+}
+
+// Leaf returns the certificate of the server of a tls connection, if any
+func Leaf(state *tls.ConnectionState) *x509.Certificate {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	return state.PeerCertificates[0]
+}
+
+// SelfSigned returns true if a certificate is issued by its own key
+func SelfSigned(certificate *x509.Certificate) bool {
+	if !bytes.Equal(certificate.RawIssuer, certificate.RawSubject) {
+		return false
+	}
+
+	return certificate.CheckSignatureFrom(certificate) == nil
+}
+
+// writeField writes a field line of a certificate
+func writeField(builder *strings.Builder, name, value string) {
+	builder.WriteString(name)
+	builder.WriteString(": ")
+	builder.WriteString(value)
+	builder.WriteString("\n")
+}
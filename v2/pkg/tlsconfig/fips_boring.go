@@ -0,0 +1,44 @@
+//go:build boringcrypto
+// +build boringcrypto
+
+package tlsconfig
+
+import (
+	"crypto/tls"
+	// restricts all the tls connections to the fips approved settings
+	_ "crypto/tls/fipsonly"
+	"fmt"
+)
+
+// FIPS is true if the binary only allows fips approved tls settings
+const FIPS = true
+
+// fipsCipherSuites are the cipher suites allowed by crypto/tls/fipsonly
+var fipsCipherSuites = map[uint16]struct{}{
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   {},
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   {},
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: {},
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: {},
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256:         {},
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384:         {},
+}
+
+// checkFIPS rejects the settings fipsonly would silently override, so
+// templates asking for legacy versions or suites fail instead of
+// connecting with a different configuration than the one requested.
+func checkFIPS(config *tls.Config) error {
+	if config.MinVersion != 0 && config.MinVersion < tls.VersionTLS12 {
+		return fmt.Errorf("tls versions below tls12 are not allowed in fips mode")
+	}
+	if config.MaxVersion != 0 && config.MaxVersion < tls.VersionTLS12 {
+		return fmt.Errorf("tls versions below tls12 are not allowed in fips mode")
+	}
+
+	for _, suite := range config.CipherSuites {
+		if _, ok := fipsCipherSuites[suite]; !ok {
+			return fmt.Errorf("tls cipher suite %s is not allowed in fips mode", tls.CipherSuiteName(suite))
+		}
+	}
+
+	return nil
+}
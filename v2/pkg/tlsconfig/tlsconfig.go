@@ -0,0 +1,222 @@
+// Package tlsconfig builds the tls configuration of the clients from
+// the global options and the per-request settings of the templates.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// Versions maps the accepted tls version names to their values
+var Versions = map[string]uint16{
+	"tls10": tls.VersionTLS10,
+	"tls11": tls.VersionTLS11,
+	"tls12": tls.VersionTLS12,
+	"tls13": tls.VersionTLS13,
+}
+
+// Curves maps the accepted key exchange curve names to their values
+var Curves = map[string]tls.CurveID{
+	"x25519": tls.X25519,
+	"p256":   tls.CurveP256,
+	"p384":   tls.CurveP384,
+	"p521":   tls.CurveP521,
+}
+
+// Options contains the tls settings of a client.
+type Options struct {
+	// MinVersion is the minimum tls version accepted (eg. tls12)
+	MinVersion string `yaml:"min-version,omitempty"`
+	// MaxVersion is the maximum tls version accepted (eg. tls13)
+	MaxVersion string `yaml:"max-version,omitempty"`
+	// CipherSuites contains the names of the cipher suites offered, all the supported ones if empty
+	CipherSuites []string `yaml:"cipher-suites,omitempty"`
+	// InsecureSkipVerify disables the verification of the server certificates, true if unset
+	InsecureSkipVerify *bool `yaml:"insecure-skip-verify,omitempty"`
+	// CACert is a pem bundle of root certificates trusted along with the system ones
+	CACert string `yaml:"-"`
+	// ALPN contains the protocols offered with the alpn extension (eg. h2, http/1.1), none if empty
+	ALPN []string `yaml:"alpn,omitempty"`
+	// SNI is the server name sent instead of the request host
+	SNI string `yaml:"sni,omitempty"`
+	// Curves contains the names of the key exchange curves offered, the default ones if empty
+	Curves []string `yaml:"curves,omitempty"`
+	// SessionTickets enables the session ticket extension, true if unset
+	SessionTickets *bool `yaml:"session-tickets,omitempty"`
+}
+
+var (
+	rootCAsMutex sync.Mutex
+	// rootCAs caches the certificate pools so each bundle is read once for all the clients
+	rootCAs = make(map[string]*x509.CertPool)
+)
+
+// Merge returns the options with the settings of override taking precedence
+func (o *Options) Merge(override *Options) *Options {
+	merged := &Options{}
+	if o != nil {
+		*merged = *o
+	}
+	if override == nil {
+		return merged
+	}
+
+	if override.MinVersion != "" {
+		merged.MinVersion = override.MinVersion
+	}
+	if override.MaxVersion != "" {
+		merged.MaxVersion = override.MaxVersion
+	}
+	if len(override.CipherSuites) > 0 {
+		merged.CipherSuites = override.CipherSuites
+	}
+	if override.InsecureSkipVerify != nil {
+		merged.InsecureSkipVerify = override.InsecureSkipVerify
+	}
+	if len(override.ALPN) > 0 {
+		merged.ALPN = override.ALPN
+	}
+	if override.SNI != "" {
+		merged.SNI = override.SNI
+	}
+	if len(override.Curves) > 0 {
+		merged.Curves = override.Curves
+	}
+	if override.SessionTickets != nil {
+		merged.SessionTickets = override.SessionTickets
+	}
+
+	return merged
+}
+
+// Validate checks the version, cipher suite and curve names
+func (o *Options) Validate() error {
+	_, err := New(o)
+	return err
+}
+
+// New returns the tls configuration for the options, nil options
+// give the default configuration which skips the verification.
+func New(options *Options) (*tls.Config, error) {
+	config := &tls.Config{
+		Renegotiation:      tls.RenegotiateOnceAsClient,
+		InsecureSkipVerify: true,
+	}
+	if options == nil {
+		return config, checkFIPS(config)
+	}
+
+	if options.InsecureSkipVerify != nil {
+		config.InsecureSkipVerify = *options.InsecureSkipVerify
+	}
+
+	if options.CACert != "" {
+		pool, err := loadRootCAs(options.CACert)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	if options.MinVersion != "" {
+		version, ok := Versions[strings.ToLower(options.MinVersion)]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls min-version %s", options.MinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	if options.MaxVersion != "" {
+		version, ok := Versions[strings.ToLower(options.MaxVersion)]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls max-version %s", options.MaxVersion)
+		}
+		config.MaxVersion = version
+	}
+
+	if config.MinVersion != 0 && config.MaxVersion != 0 && config.MinVersion > config.MaxVersion {
+		return nil, fmt.Errorf("tls min-version %s is above max-version %s", options.MinVersion, options.MaxVersion)
+	}
+
+	for _, name := range options.CipherSuites {
+		id, ok := cipherSuite(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown tls cipher suite %s", name)
+		}
+		config.CipherSuites = append(config.CipherSuites, id)
+	}
+
+	for _, name := range options.Curves {
+		curve, ok := Curves[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown tls curve %s", name)
+		}
+		config.CurvePreferences = append(config.CurvePreferences, curve)
+	}
+
+	config.NextProtos = options.ALPN
+	config.ServerName = options.SNI
+	if options.SessionTickets != nil {
+		config.SessionTicketsDisabled = !*options.SessionTickets
+	}
+
+	return config, checkFIPS(config)
+}
+
+// VersionName returns the name of a tls version, or an empty string for unknown versions
+func VersionName(version uint16) string {
+	for name, value := range Versions {
+		if value == version {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// loadRootCAs returns the system certificate pool with the certificates of a bundle added
+func loadRootCAs(file string) (*x509.CertPool, error) {
+	rootCAsMutex.Lock()
+	defer rootCAsMutex.Unlock()
+
+	if pool, ok := rootCAs[file]; ok {
+		return pool, nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read ca bundle: %s", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate found in ca bundle %s", file)
+	}
+
+	rootCAs[file] = pool
+	return pool, nil
+}
+
+// cipherSuite returns the id of a cipher suite from its name, the
+// insecure suites are accepted as scanning legacy servers requires them.
+func cipherSuite(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if strings.EqualFold(suite.Name, name) {
+			return suite.ID, true
+		}
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		if strings.EqualFold(suite.Name, name) {
+			return suite.ID, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,246 @@
+// Package versions compares the software versions extracted from responses
+// against the vulnerable ranges of a template.
+package versions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is a dot separated part of a version, eg. 4p1 in 7.4p1
+type segment struct {
+	number int
+	suffix string
+}
+
+// version is a parsed version with its pre-release parts, eg. 2.0.0-rc.1
+type version struct {
+	release    []segment
+	prerelease []segment
+}
+
+// operators contains the constraint operators, the longer ones first
+var operators = []string{"<=", ">=", "==", "!=", "<", ">", "="}
+
+// parse parses a version, ignoring a leading v and the build metadata
+func parse(value string) (*version, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "v"), "V")
+	if i := strings.IndexByte(value, '+'); i >= 0 {
+		value = value[:i]
+	}
+	if value == "" || value[0] < '0' || value[0] > '9' {
+		return nil, fmt.Errorf("invalid version '%s'", value)
+	}
+
+	parsed := &version{}
+	release := value
+	if i := strings.IndexByte(value, '-'); i >= 0 {
+		release = value[:i]
+		parsed.prerelease = segments(value[i+1:])
+	}
+	parsed.release = segments(release)
+
+	return parsed, nil
+}
+
+// segments splits the dot separated parts of a version into their number and suffix
+func segments(value string) []segment {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == '.' || r == '_'
+	})
+
+	result := make([]segment, 0, len(parts))
+	for _, part := range parts {
+		digits := 0
+		for digits < len(part) && part[digits] >= '0' && part[digits] <= '9' {
+			digits++
+		}
+		number, _ := strconv.Atoi(part[:digits])
+		result = append(result, segment{number: number, suffix: strings.ToLower(part[digits:])})
+	}
+
+	return result
+}
+
+// compareSegments compares two lists of segments, the missing ones being zero
+func compareSegments(a, b []segment) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y segment
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+
+		if x.number != y.number {
+			return compareInts(x.number, y.number)
+		}
+		// the spellings of a pre-release, eg. a1 and alpha1, are equal
+		if result := compareSuffixes(x.suffix, y.suffix); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+// prereleaseRanks orders the words marking a pre-release without a hyphen, eg. rc in 2.0rc1
+var prereleaseRanks = map[string]int{"dev": 0, "alpha": 1, "a": 1, "beta": 2, "b": 2, "pre": 3, "rc": 3}
+
+// prerelease returns the rank and the number of a pre-release suffix, eg. rc1.
+// The a and b letters alone are post-releases, eg. 1.1.1a, so they must be
+// followed by a number, eg. 3.9.0a4.
+func prerelease(suffix string) (rank, number int, ok bool) {
+	letters := 0
+	for letters < len(suffix) && suffix[letters] >= 'a' && suffix[letters] <= 'z' {
+		letters++
+	}
+	word, rest := suffix[:letters], suffix[letters:]
+
+	rank, ok = prereleaseRanks[word]
+	if !ok || (rest == "" && len(word) == 1) {
+		return 0, 0, false
+	}
+	if rest != "" {
+		var err error
+		if number, err = strconv.Atoi(rest); err != nil {
+			return 0, 0, false
+		}
+	}
+
+	return rank, number, true
+}
+
+// compareSuffixes compares the suffixes of two segments with the same number.
+// The pre-release ones are lower than no suffix and the other ones, eg. p1
+// in 7.4p1, greater.
+func compareSuffixes(x, y string) int {
+	xRank, xNumber, xPrerelease := prerelease(x)
+	yRank, yNumber, yPrerelease := prerelease(y)
+
+	switch {
+	case xPrerelease && yPrerelease:
+		if xRank != yRank {
+			return compareInts(xRank, yRank)
+		}
+		return compareInts(xNumber, yNumber)
+	case xPrerelease:
+		return -1
+	case yPrerelease:
+		return 1
+	}
+
+	return strings.Compare(x, y)
+}
+
+// compareInts returns -1, 0 or 1 if a is lower, equal or greater than b
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// compare returns -1, 0 or 1 if a is lower, equal or greater than b.
+// A pre-release is lower than the release of the same version, with or
+// without a hyphen, eg. 2.0-rc.1 and 2.0rc1.
+func (a *version) compare(b *version) int {
+	if result := compareSegments(a.release, b.release); result != 0 {
+		return result
+	}
+
+	switch {
+	case len(a.prerelease) == 0 && len(b.prerelease) == 0:
+		return 0
+	case len(a.prerelease) == 0:
+		return 1
+	case len(b.prerelease) == 0:
+		return -1
+	}
+
+	return compareSegments(a.prerelease, b.prerelease)
+}
+
+// Compare returns -1, 0 or 1 if the version a is lower, equal or greater than b
+func Compare(a, b string) (int, error) {
+	first, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	second, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+
+	return first.compare(second), nil
+}
+
+// Satisfies returns true if a version satisfies all the constraints, eg. "< 2.4.50".
+// A constraint may contain several comma separated comparisons, a version
+// without operator must be equal.
+func Satisfies(value string, constraints ...string) (bool, error) {
+	parsed, err := parse(value)
+	if err != nil {
+		return false, err
+	}
+
+	for _, constraint := range constraints {
+		for _, comparison := range strings.Split(constraint, ",") {
+			ok, err := satisfies(parsed, strings.TrimSpace(comparison))
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// satisfies checks a version against a single comparison
+func satisfies(parsed *version, comparison string) (bool, error) {
+	operator := "="
+	for _, candidate := range operators {
+		if strings.HasPrefix(comparison, candidate) {
+			operator = candidate
+			comparison = comparison[len(candidate):]
+			break
+		}
+	}
+
+	other, err := parse(comparison)
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint: %s", err)
+	}
+	result := parsed.compare(other)
+
+	switch operator {
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	case ">=":
+		return result >= 0, nil
+	case "!=":
+		return result != 0, nil
+	default:
+		return result == 0, nil
+	}
+}
+
+// Valid returns true if a value can be parsed as a version
+func Valid(value string) bool {
+	_, err := parse(value)
+	return err == nil
+}
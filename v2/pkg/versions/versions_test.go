@@ -0,0 +1,74 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"2.4.49", "2.4.50", -1},
+		{"2.4.50", "2.4.50", 0},
+		{"v2.4.50", "2.4.50", 0},
+		{"2.4.50+build.7", "2.4.50", 0},
+		{"2.10", "2.9", 1},
+		{"2.0", "2.0.0", 0},
+		{"2.0-rc.1", "2.0", -1},
+		{"2.0-rc.1", "2.0-rc.2", -1},
+		{"2.0rc1", "2.0", -1},
+		{"2.0rc1", "2.0rc2", -1},
+		{"2.0rc9", "2.0rc10", -1},
+		{"2.0.0rc1", "2.0", -1},
+		{"2.0beta2", "2.0rc1", -1},
+		{"2.0alpha3", "2.0beta1", -1},
+		{"2.0dev1", "2.0alpha1", -1},
+		{"3.9.0a4", "3.9.0", -1},
+		{"3.9.0a4", "3.9.0b1", -1},
+		{"3.9.0a4", "3.9.0alpha4", 0},
+		{"1.1.1b", "1.1.1a", 1},
+		{"1.1.1a", "1.1.1", 1},
+		{"7.4p1", "7.4", 1},
+		{"7.4p1", "7.4p2", -1},
+		{"7.4p1", "7.4rc1", 1},
+	}
+
+	for _, test := range tests {
+		result, err := Compare(test.a, test.b)
+		require.Nil(t, err, "could not compare %s and %s", test.a, test.b)
+		require.Equal(t, test.expected, result, "unexpected comparison of %s and %s", test.a, test.b)
+
+		reversed, err := Compare(test.b, test.a)
+		require.Nil(t, err, "could not compare %s and %s", test.b, test.a)
+		require.Equal(t, -test.expected, reversed, "unexpected comparison of %s and %s", test.b, test.a)
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		version     string
+		constraints []string
+		expected    bool
+	}{
+		{"2.4.49", []string{"< 2.4.50"}, true},
+		{"2.4.50", []string{"< 2.4.50"}, false},
+		{"2.4.50rc1", []string{"< 2.4.50"}, true},
+		{"2.4.48", []string{">= 2.4.49, <= 2.4.50"}, false},
+		{"2.4.49", []string{">= 2.4.49", "<= 2.4.50"}, true},
+		{"2.4.49", []string{"2.4.49"}, true},
+		{"2.4.49", []string{"!= 2.4.49"}, false},
+	}
+
+	for _, test := range tests {
+		result, err := Satisfies(test.version, test.constraints...)
+		require.Nil(t, err, "could not check %s against %v", test.version, test.constraints)
+		require.Equal(t, test.expected, result, "unexpected result of %s against %v", test.version, test.constraints)
+	}
+
+	_, err := Satisfies("2.4.49", "< latest")
+	require.NotNil(t, err, "an invalid constraint was accepted")
+	require.False(t, Valid("latest"), "an invalid version was accepted")
+}
@@ -0,0 +1,382 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by the websocket handshake
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// acceptGUID is appended to the key of the handshake to compute its accept header
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// MaxMessageSize is the maximum size of a message read from a connection
+const MaxMessageSize = 1024 * 1024
+
+// The opcodes of the frames
+const (
+	ContinuationMessage = 0x0
+	TextMessage         = 0x1
+	BinaryMessage       = 0x2
+	CloseMessage        = 0x8
+	PingMessage         = 0x9
+	PongMessage         = 0xa
+)
+
+// The close codes
+const (
+	// NormalClosure is the code of a connection closed without error
+	NormalClosure = 1000
+	// NoStatusReceived is reported for the close frames without a code
+	NoStatusReceived = 1005
+)
+
+// ErrBadHandshake is returned when the server doesn't switch protocols,
+// the response of the server being returned with it.
+var ErrBadHandshake = errors.New("websocket: bad handshake")
+
+// errClosed is returned when writing to a closed connection
+var errClosed = errors.New("websocket: use of closed connection")
+
+// CloseError is returned by ReadMessage when the server closes the connection
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+// Error returns the close code and reason
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", e.Code, e.Reason)
+}
+
+// Conn is a client websocket connection
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	writeMutex sync.Mutex
+	closed     bool
+}
+
+// Dial upgrades a connection to a ws, wss, http or https URL, sending the
+// headers along the handshake. The response of the handshake is returned
+// with a read body when the server refuses the upgrade.
+func Dial(ctx context.Context, dial func(ctx context.Context, network, address string) (net.Conn, error), tlsConfig *tls.Config, rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secure := false
+	switch parsed.Scheme {
+	case "ws", "http":
+	case "wss", "https":
+		secure = true
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported scheme %s", parsed.Scheme)
+	}
+
+	address := parsed.Host
+	if parsed.Port() == "" {
+		port := "80"
+		if secure {
+			port = "443"
+		}
+		address = net.JoinHostPort(parsed.Hostname(), port)
+	}
+
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		//nolint:errcheck // the deadline only fails on closed connections
+		conn.SetDeadline(deadline)
+	}
+
+	if secure {
+		config := tlsConfig.Clone()
+		if config == nil {
+			config = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // scanned hosts often have invalid certificates
+		}
+		if config.ServerName == "" {
+			config.ServerName = parsed.Hostname()
+		}
+		client := tls.Client(conn, config)
+		if err := client.Handshake(); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		conn = client
+	}
+
+	ws, response, err := handshake(conn, parsed, header)
+	if err != nil {
+		return nil, response, err
+	}
+	//nolint:errcheck // the deadline only fails on closed connections
+	conn.SetDeadline(time.Time{})
+
+	return ws, response, nil
+}
+
+// handshake sends the upgrade request on a connection and checks the response
+func handshake(conn net.Conn, parsed *url.URL, header http.Header) (*Conn, *http.Response, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	request := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Scheme: "http", Host: parsed.Host, Path: parsed.Path, RawPath: parsed.RawPath, RawQuery: parsed.RawQuery},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       parsed.Host,
+	}
+	for name, values := range header {
+		if strings.EqualFold(name, "Host") && len(values) > 0 {
+			request.Host = values[0]
+			continue
+		}
+		request.Header[name] = values
+	}
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Sec-WebSocket-Key", key)
+	request.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := request.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	if response.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.EqualFold(response.Header.Get("Upgrade"), "websocket") ||
+		response.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		body, _ := ioutil.ReadAll(io.LimitReader(response.Body, MaxMessageSize))
+		response.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+		conn.Close()
+		return nil, response, ErrBadHandshake
+	}
+	response.Body = ioutil.NopCloser(strings.NewReader(""))
+
+	return &Conn{conn: conn, reader: reader}, response, nil
+}
+
+// acceptKey returns the accept header expected for a handshake key
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID)) //nolint:gosec // required by the websocket handshake
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// SetReadDeadline sets the deadline of the next reads
+func (c *Conn) SetReadDeadline(deadline time.Time) error {
+	return c.conn.SetReadDeadline(deadline)
+}
+
+// WriteMessage sends a text or binary message in a single frame
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	return c.writeFrame(opcode, data)
+}
+
+// writeFrame sends a masked frame, as all the frames of the clients are
+func (c *Conn) writeFrame(opcode int, data []byte) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if c.closed {
+		return errClosed
+	}
+
+	frame := make([]byte, 0, len(data)+14)
+	frame = append(frame, 0x80|byte(opcode))
+	switch {
+	case len(data) < 126:
+		frame = append(frame, 0x80|byte(len(data)))
+	case len(data) <= 0xffff:
+		frame = append(frame, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(data)))
+	default:
+		frame = append(frame, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(len(data)))
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range data {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// ReadMessage returns the next text or binary message, joining its
+// fragments. Pings are answered while reading, and a *CloseError is
+// returned once the server closes the connection.
+func (c *Conn) ReadMessage() (opcode int, data []byte, err error) {
+	opcode = -1
+	for {
+		fin, frameOpcode, payload, err := c.readFrame()
+		if err != nil {
+			return -1, nil, err
+		}
+
+		switch frameOpcode {
+		case PingMessage:
+			if err := c.writeFrame(PongMessage, payload); err != nil {
+				return -1, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: NoStatusReceived}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			// the close is echoed with its code, as the protocol requires
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			//nolint:errcheck // the connection is closed either way
+			c.writeFrame(CloseMessage, payload)
+			c.shutdown()
+			return -1, nil, closeErr
+		case ContinuationMessage:
+			if opcode == -1 {
+				return -1, nil, errors.New("websocket: continuation without a message")
+			}
+		default:
+			if opcode != -1 {
+				return -1, nil, errors.New("websocket: message interrupts a fragmented one")
+			}
+			opcode = frameOpcode
+		}
+
+		if len(data)+len(payload) > MaxMessageSize {
+			return -1, nil, fmt.Errorf("websocket: message larger than %d bytes", MaxMessageSize)
+		}
+		data = append(data, payload...)
+		if fin {
+			return opcode, data, nil
+		}
+	}
+}
+
+// readFrame reads a frame from the connection
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if length > MaxMessageSize {
+		return false, 0, nil, fmt.Errorf("websocket: frame larger than %d bytes", MaxMessageSize)
+	}
+
+	mask := make([]byte, 4)
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// Close sends a normal closure to the server and closes the connection
+func (c *Conn) Close() error {
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, NormalClosure)
+	//nolint:errcheck // the connection is closed either way
+	c.writeFrame(CloseMessage, payload)
+
+	return c.shutdown()
+}
+
+// shutdown closes the underlying connection once
+func (c *Conn) shutdown() error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	return c.conn.Close()
+}
+
+// Response contains the handshake of a connection and the messages received on it
+type Response struct {
+	// StatusCode is the status code of the handshake, 101 once upgraded
+	StatusCode int
+	// Headers are the headers of the handshake response
+	Headers http.Header
+	// Body is the body of a refused handshake
+	Body string
+	// Messages are the text and binary messages received
+	Messages []string
+	// CloseCode is the close code sent by the server, 0 if it didn't close the connection
+	CloseCode int
+	// CloseReason is the reason sent with the close code
+	CloseReason string
+}
@@ -2,23 +2,28 @@ package workflows
 
 import (
 	"errors"
-	"os"
+	"fmt"
+	"io/ioutil"
 
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
 	"gopkg.in/yaml.v2"
 )
 
 // Parse a yaml workflow file
 func Parse(file string) (*Workflow, error) {
-	workflow := &Workflow{}
-
-	f, err := os.Open(file)
+	data, err := ioutil.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
-	err = yaml.NewDecoder(f).Decode(workflow)
-	if err != nil {
+	return ParseBytes(file, data)
+}
+
+// ParseBytes parses the content of a yaml workflow file, the name being used as its path
+func ParseBytes(file string, data []byte) (*Workflow, error) {
+	workflow := &Workflow{}
+
+	if err := yaml.Unmarshal(data, workflow); err != nil {
 		return nil, err
 	}
 
@@ -26,6 +31,22 @@ func Parse(file string) (*Workflow, error) {
 		return nil, errors.New("no logic provided")
 	}
 
+	if workflow.Timeout < 0 || workflow.StepTimeout < 0 {
+		return nil, errors.New("timeouts can't be negative")
+	}
+
+	switch workflow.OnFailure {
+	case "":
+		workflow.OnFailure = OnFailureContinue
+	case OnFailureContinue, OnFailureAbort:
+	default:
+		return nil, fmt.Errorf("invalid on-failure policy %s", workflow.OnFailure)
+	}
+
+	if err := templates.ValidateProxy(workflow.Proxy); err != nil {
+		return nil, err
+	}
+
 	workflow.path = file
 
 	return workflow, nil
@@ -0,0 +1,136 @@
+package workflows
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Graph is a dependency graph between workflows and the templates
+// or workflows they reference.
+type Graph struct {
+	labels map[string]string
+	edges  map[string][]string
+}
+
+// NewGraph creates a new empty dependency graph
+func NewGraph() *Graph {
+	return &Graph{labels: make(map[string]string), edges: make(map[string][]string)}
+}
+
+// AddNode adds a node identified by a path with a display label
+func (g *Graph) AddNode(path, label string) {
+	if _, ok := g.labels[path]; !ok || label != "" {
+		g.labels[path] = label
+	}
+}
+
+// HasNode returns true if the node was already added to the graph
+func (g *Graph) HasNode(path string) bool {
+	_, ok := g.labels[path]
+	return ok
+}
+
+// AddEdge adds a dependency from a node to another one
+func (g *Graph) AddEdge(from, to string) {
+	g.AddNode(from, "")
+	g.AddNode(to, "")
+
+	for _, existing := range g.edges[from] {
+		if existing == to {
+			return
+		}
+	}
+	g.edges[from] = append(g.edges[from], to)
+}
+
+// Cycles returns the dependency cycles of the graph, each one
+// as the list of nodes starting and ending with the same node.
+func (g *Graph) Cycles() [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var cycles [][]string
+
+	state := make(map[string]int)
+	var stack []string
+
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = visiting
+		stack = append(stack, node)
+
+		for _, next := range g.edges[node] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				// the cycle goes from the first occurrence of next in the stack back to it
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == next {
+						cycle := append([]string{}, stack[i:]...)
+						cycles = append(cycles, append(cycle, next))
+						break
+					}
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[node] = visited
+	}
+
+	for _, node := range g.nodes() {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+
+	return cycles
+}
+
+// Label returns the display label of a node, defaulting to its path
+func (g *Graph) Label(path string) string {
+	if label := g.labels[path]; label != "" {
+		return label
+	}
+
+	return path
+}
+
+// WriteDOT writes the graph in graphviz DOT format
+func (g *Graph) WriteDOT(w io.Writer) error {
+	builder := &strings.Builder{}
+	builder.WriteString("digraph workflows {\n")
+
+	for _, node := range g.nodes() {
+		fmt.Fprintf(builder, "  %q [label=%q];\n", node, g.Label(node))
+	}
+
+	for _, node := range g.nodes() {
+		for _, next := range g.edges[node] {
+			fmt.Fprintf(builder, "  %q -> %q;\n", node, next)
+		}
+	}
+
+	builder.WriteString("}\n")
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// nodes returns the nodes of the graph in a stable order
+func (g *Graph) nodes() []string {
+	nodes := make([]string, 0, len(g.labels))
+	for node := range g.labels {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	return nodes
+}
@@ -1,7 +1,10 @@
 package workflows
 
 import (
+	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	tengo "github.com/d5/tengo/v2"
 	"github.com/logrusorgru/aurora"
@@ -11,6 +14,7 @@ import (
 	"github.com/projectdiscovery/nuclei/v2/pkg/colorizer"
 	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
 	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/remeh/sizedwaitgroup"
 )
 
 const two = 2
@@ -21,6 +25,22 @@ type NucleiVar struct {
 	Templates    []*Template
 	URL          string
 	InternalVars map[string]interface{}
+	// Concurrency is the number of templates of the variable executed in parallel
+	Concurrency int
+	// StepTimeout is the maximum time a call of the variable can take, zero means no limit
+	StepTimeout time.Duration
+	// OnFailure is the policy applied when the call times out
+	OnFailure string
+	// Context bounds the calls of the variable to the run of the workflow if set
+	Context context.Context
+	// Trace records the http requests of the templates of the run if set
+	Trace *executer.Trace
+	// Disabled returns true for the templates which must not be executed anymore
+	Disabled func(templateID string) bool
+	// Report records the outcome of a request of a template on the variable URL
+	Report func(templateID, templateSeverity string, result *executer.Result)
+	// Recovered records a panic recovered during the execution of a template
+	Recovered func(templateID, target string, recovered interface{})
 	sync.RWMutex
 }
 
@@ -31,6 +51,30 @@ type Template struct {
 	Progress    progress.IProgress
 }
 
+// ID returns the ID of the template
+func (t *Template) ID() string {
+	if t.HTTPOptions != nil {
+		return t.HTTPOptions.Template.ID
+	}
+	if t.DNSOptions != nil {
+		return t.DNSOptions.Template.ID
+	}
+
+	return ""
+}
+
+// Severity returns the severity of the template
+func (t *Template) Severity() string {
+	if t.HTTPOptions != nil {
+		return t.HTTPOptions.Template.Info["severity"]
+	}
+	if t.DNSOptions != nil {
+		return t.DNSOptions.Template.Info["severity"]
+	}
+
+	return ""
+}
+
 // TypeName of the variable
 func (n *NucleiVar) TypeName() string {
 	return "nuclei-var"
@@ -43,7 +87,10 @@ func (n *NucleiVar) CanCall() bool {
 
 // Call logic - args[0]=headers, args[1]=payloads
 func (n *NucleiVar) Call(args ...tengo.Object) (ret tengo.Object, err error) {
+	n.Lock()
 	n.InternalVars = make(map[string]interface{})
+	n.Unlock()
+
 	headers := make(map[string]string)
 	externalVars := make(map[string]interface{})
 
@@ -57,75 +104,183 @@ func (n *NucleiVar) Call(args ...tengo.Object) (ret tengo.Object, err error) {
 		externalVars = iterableToMap(args[1])
 	}
 
+	// results are collected apart so that the requests still running
+	// after a timeout can't change the outcome of the step
+	results := make(map[string]interface{})
+	done := make(chan bool, 1)
+
+	// the script only stops between calls, a call must end with the run itself
+	parent := n.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	// the requests of the step are cancelled once the call returned, eg. on a timeout
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	go func() {
+		done <- n.executeTemplates(ctx, results, headers, externalVars)
+	}()
+
+	var timeout <-chan time.Time
+	if n.StepTimeout > 0 {
+		timer := time.NewTimer(n.StepTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case gotResult := <-done:
+		n.Lock()
+		n.InternalVars = results
+		n.Unlock()
+
+		if gotResult {
+			return tengo.TrueValue, nil
+		}
+	case <-timeout:
+		if n.OnFailure == OnFailureAbort {
+			return nil, fmt.Errorf("step timed out after %s on %s", n.StepTimeout, n.URL)
+		}
+		gologger.Warningf("Workflow step timed out after %s on %s\n", n.StepTimeout, n.URL)
+	case <-parent.Done():
+		return nil, parent.Err()
+	}
+
+	return tengo.FalseValue, nil
+}
+
+// executeTemplates executes the templates of the variable on its URL with the configured concurrency
+func (n *NucleiVar) executeTemplates(ctx context.Context, results map[string]interface{}, headers map[string]string, externalVars map[string]interface{}) bool {
 	var gotResult atomicboolean.AtomBool
 
-	for _, template := range n.Templates {
-		p := template.Progress
+	concurrency := n.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
 
-		if template.HTTPOptions != nil {
-			p.AddToTotal(template.HTTPOptions.Template.GetHTTPRequestCount())
+	// sibling templates are independent so they can run in parallel
+	swg := sizedwaitgroup.New(concurrency)
 
-			for _, request := range template.HTTPOptions.Template.BulkRequestsHTTP {
-				// apply externally supplied payloads if any
-				request.Headers = generators.MergeMapsWithStrings(request.Headers, headers)
-				// apply externally supplied payloads if any
-				request.Payloads = generators.MergeMaps(request.Payloads, externalVars)
+	for _, template := range n.Templates {
+		if n.Disabled != nil && n.Disabled(template.ID()) {
+			continue
+		}
 
-				template.HTTPOptions.BulkHTTPRequest = request
+		// no template is started anymore once the step timed out
+		if ctx.Err() != nil {
+			break
+		}
 
-				if template.HTTPOptions.Colorizer == nil {
-					template.HTTPOptions.Colorizer = colorizer.NewNucleiColorizer(aurora.NewAurora(true))
+		swg.Add()
+
+		go func(template *Template) {
+			defer swg.Done()
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					if n.Recovered != nil {
+						n.Recovered(template.ID(), n.URL, recovered)
+						return
+					}
+					gologger.Errorf("[%s] Recovered from panic in workflow template on %s: %v\n", template.ID(), n.URL, recovered)
 				}
+			}()
 
-				httpExecuter, err := executer.NewHTTPExecuter(template.HTTPOptions)
+			gotResult.Or(n.executeTemplate(ctx, template, results, headers, externalVars))
+		}(template)
+	}
 
-				if err != nil {
-					p.Drop(request.GetRequestCount())
-					gologger.Warningf("Could not compile request for template '%s': %s\n", template.HTTPOptions.Template.ID, err)
+	swg.Wait()
 
-					continue
-				}
+	return gotResult.Get()
+}
 
-				result := httpExecuter.ExecuteHTTP(p, n.URL)
+// executeTemplate executes all the requests of a template on the variable URL
+func (n *NucleiVar) executeTemplate(ctx context.Context, template *Template, results map[string]interface{}, headers map[string]string, externalVars map[string]interface{}) bool {
+	p := template.Progress
+	gotResult := false
 
-				if result.Error != nil {
-					gologger.Warningf("Could not send request for template '%s': %s\n", template.HTTPOptions.Template.ID, result.Error)
-					continue
-				}
+	if template.HTTPOptions != nil {
+		p.AddToTotal(template.HTTPOptions.Template.GetHTTPRequestCount())
 
-				if result.GotResults {
-					gotResult.Or(result.GotResults)
-					n.addResults(result)
-				}
+		for _, request := range template.HTTPOptions.Template.BulkRequestsHTTP {
+			if ctx.Err() != nil {
+				return false
 			}
-		}
+			// apply externally supplied payloads if any
+			request.Headers = generators.MergeMapsWithStrings(request.Headers, headers)
+			// apply externally supplied payloads if any
+			request.Payloads = generators.MergeMaps(request.Payloads, externalVars)
 
-		if template.DNSOptions != nil {
-			p.AddToTotal(template.DNSOptions.Template.GetDNSRequestCount())
+			template.HTTPOptions.BulkHTTPRequest = request
 
-			for _, request := range template.DNSOptions.Template.RequestsDNS {
-				template.DNSOptions.DNSRequest = request
-				dnsExecuter := executer.NewDNSExecuter(template.DNSOptions)
-				result := dnsExecuter.ExecuteDNS(p, n.URL)
+			if template.HTTPOptions.Colorizer.Colorizer == nil {
+				template.HTTPOptions.Colorizer = *colorizer.NewNucleiColorizer(aurora.NewAurora(true))
+			}
 
-				if result.Error != nil {
-					gologger.Warningf("Could not compile request for template '%s': %s\n", template.HTTPOptions.Template.ID, result.Error)
-					continue
-				}
+			httpExecuter, err := executer.NewHTTPExecuter(template.HTTPOptions)
 
-				if result.GotResults {
-					gotResult.Or(result.GotResults)
-					n.addResults(result)
-				}
+			if err != nil {
+				p.Drop(request.GetRequestCount())
+				gologger.Warningf("Could not compile request for template '%s': %s\n", template.HTTPOptions.Template.ID, err)
+
+				continue
+			}
+
+			result := httpExecuter.ExecuteHTTPWithTrace(ctx, p, n.URL, n.Trace)
+			// the outcome of the requests cancelled with the step is discarded
+			if ctx.Err() != nil {
+				return false
+			}
+			n.report(template, result)
+
+			if result.Error != nil {
+				gologger.Warningf("Could not send request for template '%s': %s\n", template.HTTPOptions.Template.ID, result.Error)
+				continue
+			}
+
+			if result.GotResults {
+				gotResult = true
+				n.addResults(results, result)
 			}
 		}
 	}
 
-	if gotResult.Get() {
-		return tengo.TrueValue, nil
+	if template.DNSOptions != nil {
+		p.AddToTotal(template.DNSOptions.Template.GetDNSRequestCount())
+
+		for _, request := range template.DNSOptions.Template.RequestsDNS {
+			if ctx.Err() != nil {
+				return false
+			}
+			template.DNSOptions.DNSRequest = request
+			dnsExecuter := executer.NewDNSExecuter(template.DNSOptions)
+			result := dnsExecuter.ExecuteDNS(p, n.URL)
+			if ctx.Err() != nil {
+				return false
+			}
+			n.report(template, result)
+
+			if result.Error != nil {
+				gologger.Warningf("Could not compile request for template '%s': %s\n", template.DNSOptions.Template.ID, result.Error)
+				continue
+			}
+
+			if result.GotResults {
+				gotResult = true
+				n.addResults(results, result)
+			}
+		}
 	}
 
-	return tengo.FalseValue, nil
+	return gotResult
+}
+
+// report records the outcome of a request of a template if a reporter is set
+func (n *NucleiVar) report(template *Template, result *executer.Result) {
+	if n.Report != nil {
+		n.Report(template.ID(), template.Severity(), result)
+	}
 }
 
 func (n *NucleiVar) IsFalsy() bool {
@@ -135,22 +290,22 @@ func (n *NucleiVar) IsFalsy() bool {
 	return len(n.InternalVars) == 0
 }
 
-func (n *NucleiVar) addResults(r *executer.Result) {
-	n.RLock()
-	defer n.RUnlock()
+func (n *NucleiVar) addResults(results map[string]interface{}, r *executer.Result) {
+	n.Lock()
+	defer n.Unlock()
 
 	// add payload values as first, they will be accessible if not overwritter through
 	// payload_name (from template) => value
 	for k, v := range r.Meta {
-		n.InternalVars[k] = v
+		results[k] = v
 	}
 
 	for k := range r.Matches {
-		n.InternalVars[k] = true
+		results[k] = true
 	}
 
 	for k, v := range r.Extractions {
-		n.InternalVars[k] = v
+		results[k] = v
 	}
 }
 
@@ -8,13 +8,31 @@ type Workflow struct {
 	Info map[string]string `yaml:"info"`
 	// CookieReuse makes all cookies shared by templates within the workflow
 	CookieReuse bool `yaml:"cookie-reuse,omitempty"`
+	// Proxy is the http or socks5 proxy the http requests of the templates of
+	// the workflow are sent through, unless a template sets its own
+	Proxy string `yaml:"proxy,omitempty"`
 	// Variables contains the variables accessible to the pseudo-code
 	Variables map[string]string `yaml:"variables"`
 	// Logic contains the workflow pseudo-code
 	Logic string `yaml:"logic"`
-	path  string
+	// Concurrency is the number of templates of a variable executed in parallel
+	Concurrency int `yaml:"concurrency,omitempty"`
+	// Timeout is the maximum time in seconds the workflow can run on a target
+	Timeout int `yaml:"timeout,omitempty"`
+	// StepTimeout is the maximum time in seconds a single variable can run on a target
+	StepTimeout int `yaml:"step-timeout,omitempty"`
+	// OnFailure is the policy applied when a step times out, either continue or abort
+	OnFailure string `yaml:"on-failure,omitempty"`
+	path      string
 }
 
+const (
+	// OnFailureContinue treats a failed step as not matched and runs the rest of the logic
+	OnFailureContinue = "continue"
+	// OnFailureAbort stops the workflow for the target when a step fails
+	OnFailureAbort = "abort"
+)
+
 // GetPath of the workflow
 func (w *Workflow) GetPath() string {
 	return w.path
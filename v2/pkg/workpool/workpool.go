@@ -0,0 +1,127 @@
+package workpool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Pool is a fixed set of workers executing the tasks submitted to a bounded queue.
+type Pool struct {
+	name    string
+	workers int
+	tasks   chan func()
+	wg      sync.WaitGroup
+
+	submitted  int64
+	completed  int64
+	running    int64
+	peakQueued int64
+}
+
+// Metrics contains the queue metrics of a pool
+type Metrics struct {
+	Name       string
+	Workers    int
+	Queued     int64
+	Running    int64
+	Submitted  int64
+	Completed  int64
+	PeakQueued int64
+}
+
+// New creates a pool with the given number of workers and queue size
+func New(name string, workers, queueSize int) *Pool {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &Pool{name: name, workers: workers, tasks: make(chan func(), queueSize)}
+
+	p.wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// work executes the queued tasks until the pool is stopped
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for task := range p.tasks {
+		atomic.AddInt64(&p.running, 1)
+		task()
+		atomic.AddInt64(&p.running, -1)
+		atomic.AddInt64(&p.completed, 1)
+	}
+}
+
+// Submit queues a task for execution, blocking while the queue is full.
+//
+// Tasks must not submit other tasks to the same pool as it could deadlock.
+func (p *Pool) Submit(task func()) {
+	atomic.AddInt64(&p.submitted, 1)
+	p.tasks <- task
+
+	queued := int64(len(p.tasks))
+	for {
+		peak := atomic.LoadInt64(&p.peakQueued)
+		if queued <= peak || atomic.CompareAndSwapInt64(&p.peakQueued, peak, queued) {
+			break
+		}
+	}
+}
+
+// Run executes a task on the pool and waits for its completion.
+// On a nil pool the task is executed by the caller.
+//
+// A panic of the task is propagated to the caller instead of the worker.
+func (p *Pool) Run(task func()) {
+	if p == nil {
+		task()
+		return
+	}
+
+	done := make(chan struct{})
+
+	var recovered interface{}
+
+	p.Submit(func() {
+		defer close(done)
+		defer func() {
+			recovered = recover()
+		}()
+		task()
+	})
+
+	<-done
+
+	if recovered != nil {
+		panic(recovered)
+	}
+}
+
+// Stop waits for the queued tasks to complete and stops the workers
+func (p *Pool) Stop() {
+	close(p.tasks)
+	p.wg.Wait()
+}
+
+// Metrics returns a snapshot of the queue metrics of the pool
+func (p *Pool) Metrics() Metrics {
+	return Metrics{
+		Name:       p.name,
+		Workers:    p.workers,
+		Queued:     int64(len(p.tasks)),
+		Running:    atomic.LoadInt64(&p.running),
+		Submitted:  atomic.LoadInt64(&p.submitted),
+		Completed:  atomic.LoadInt64(&p.completed),
+		PeakQueued: atomic.LoadInt64(&p.peakQueued),
+	}
+}
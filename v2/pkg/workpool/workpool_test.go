@@ -0,0 +1,86 @@
+package workpool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitBlocksAtCapacity(t *testing.T) {
+	p := New("test", 1, 1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	// the worker is busy, the queue holds a single task
+	p.Submit(func() {})
+
+	submitted := make(chan struct{})
+	go func() {
+		p.Submit(func() {})
+		close(submitted)
+	}()
+
+	select {
+	case <-submitted:
+		t.Fatal("a task was submitted to a full queue")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatal("the task was not submitted once the queue had room")
+	}
+
+	p.Stop()
+	metrics := p.Metrics()
+	require.Equal(t, int64(3), metrics.Submitted, "wrong number of submitted tasks")
+	require.Equal(t, int64(3), metrics.Completed, "wrong number of completed tasks")
+	require.Equal(t, int64(1), metrics.PeakQueued, "wrong peak of queued tasks")
+}
+
+func TestRunPropagatesPanic(t *testing.T) {
+	p := New("test", 2, 0)
+	defer p.Stop()
+
+	require.PanicsWithValue(t, "boom", func() {
+		p.Run(func() { panic("boom") })
+	}, "the panic of the task was not propagated")
+
+	// the worker survived the panic
+	ran := false
+	p.Run(func() { ran = true })
+	require.True(t, ran, "the pool stopped running tasks after a panic")
+}
+
+func TestRunNilPool(t *testing.T) {
+	var p *Pool
+
+	ran := false
+	p.Run(func() { ran = true })
+	require.True(t, ran, "the task was not run inline on a nil pool")
+}
+
+func TestStopDrainsQueue(t *testing.T) {
+	p := New("test", 1, 16)
+
+	var completed int64
+	for i := 0; i < 16; i++ {
+		p.Submit(func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt64(&completed, 1)
+		})
+	}
+	p.Stop()
+
+	require.Equal(t, int64(16), atomic.LoadInt64(&completed), "queued tasks were dropped on stop")
+	require.Equal(t, int64(0), p.Metrics().Queued, "tasks are still queued after stop")
+}